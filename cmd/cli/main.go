@@ -1,41 +1,93 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os"
+	"strings"
 
 	"time"
 
+	"GoTask_Management/internal/backup"
+	"GoTask_Management/internal/cleanup"
+	"GoTask_Management/internal/importer"
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
 	"GoTask_Management/internal/storage"
 	"GoTask_Management/internal/task"
+	"GoTask_Management/internal/views"
 
 	"github.com/spf13/cobra"
 )
 
 var (
+	store       storage.Storage
+	storageType storage.StorageType
 	taskService *task.Service
 	rootCmd     = &cobra.Command{
 		Use:   "gotasker",
 		Short: "A task management CLI tool",
 		Long:  `GoTasker is a simple and efficient task management tool for personal and team use.`,
+		// Commands other than help open the storage, which main closes
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Name() == "help" || cmd.Name() == "completion" || (cmd.HasParent() && cmd.Parent().Name() == "completion") {
+				return nil
+			}
+			if err := openStorage(); err != nil {
+				// main reports the error; usage would not help
+				cmd.SilenceUsage, cmd.SilenceErrors = true, true
+				return err
+			}
+			return nil
+		},
 	}
 )
 
-func init() {
-	// Initialize storage
-	store, err := storage.NewJSONStorage("tasks.json")
+// openStorage opens the storage from STORAGE_TYPE and related variables,
+// defaulting to tasks.json
+func openStorage() error {
+	config, err := storage.LoadConfigFromEnv()
 	if err != nil {
-		log.Fatal("Failed to initialize storage:", err)
+		return fmt.Errorf("failed to load storage configuration: %w", err)
 	}
+	store, err = storage.NewStorage(config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	storageType = config.Type
 	taskService = task.NewService(store)
+	return nil
+}
 
+// closeStorage closes the storage if a command opened it, so that backends
+// finish their work: the WAL compacts its log, the memory backend writes its
+// snapshot and Bolt releases its file
+func closeStorage() error {
+	if store == nil {
+		return nil
+	}
+	if err := store.Close(); err != nil {
+		return fmt.Errorf("failed to close storage: %w", err)
+	}
+	return nil
+}
+
+func init() {
 	// Add commands
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(doneCmd)
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(dueCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(viewCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(cleanupCmd)
+	rootCmd.AddCommand(importCmd)
+
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
 }
 
 var addCmd = &cobra.Command{
@@ -56,7 +108,7 @@ var addCmd = &cobra.Command{
 			dueDate = &parsed
 		}
 
-		task, err := taskService.CreateTask(title, dueDate)
+		task, err := taskService.CreateTask(cmd.Context(), title, dueDate)
 		if err != nil {
 			fmt.Printf("Error creating task: %v\n", err)
 			return
@@ -70,8 +122,15 @@ var listCmd = &cobra.Command{
 	Short: "List all tasks",
 	Run: func(cmd *cobra.Command, args []string) {
 		statusFilter, _ := cmd.Flags().GetString("status")
+		queryString, _ := cmd.Flags().GetString("query")
 
-		tasks, err := taskService.ListTasks(statusFilter)
+		var tasks []*models.Task
+		var err error
+		if queryString != "" {
+			tasks, err = findTasks(cmd.Context(), queryString, statusFilter)
+		} else {
+			tasks, err = taskService.ListTasks(cmd.Context(), statusFilter)
+		}
 		if err != nil {
 			fmt.Printf("Error listing tasks: %v\n", err)
 			return
@@ -101,6 +160,19 @@ var listCmd = &cobra.Command{
 	},
 }
 
+// findTasks lists the tasks matching queryString and, when it is set, status
+func findTasks(ctx context.Context, queryString, status string) ([]*models.Task, error) {
+	parsed, err := query.Parse(queryString)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := parsed.WithStatus(status).Compile(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return taskService.FindTasks(ctx, filter)
+}
+
 var doneCmd = &cobra.Command{
 	Use:   "done [id]",
 	Short: "Mark a task as done",
@@ -108,7 +180,7 @@ var doneCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		id := args[0]
 
-		err := taskService.MarkTaskDone(id, true)
+		err := taskService.MarkTaskDone(cmd.Context(), id, true)
 		if err != nil {
 			fmt.Printf("Error marking task as done: %v\n", err)
 			return
@@ -124,7 +196,7 @@ var deleteCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		id := args[0]
 
-		err := taskService.DeleteTask(id)
+		err := taskService.DeleteTask(cmd.Context(), id)
 		if err != nil {
 			fmt.Printf("Error deleting task: %v\n", err)
 			return
@@ -139,7 +211,7 @@ var dueCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		days, _ := cmd.Flags().GetInt("days")
 
-		tasks, err := taskService.GetDueTasks(days)
+		tasks, err := taskService.GetDueTasks(cmd.Context(), days)
 		if err != nil {
 			fmt.Printf("Error getting due tasks: %v\n", err)
 			return
@@ -169,14 +241,301 @@ var dueCmd = &cobra.Command{
 	},
 }
 
+var searchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search task titles, most relevant first",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		query := strings.Join(args, " ")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		results, err := taskService.SearchTasks(cmd.Context(), query, limit)
+		if err != nil {
+			fmt.Printf("Error searching tasks: %v\n", err)
+			return
+		}
+
+		if len(results) == 0 {
+			fmt.Printf("No tasks match %q.\n", query)
+			return
+		}
+
+		// Matches are shown in bold on terminals and between asterisks otherwise
+		open, close := "*", "*"
+		if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			open, close = "\033[1m", "\033[0m"
+		}
+
+		fmt.Printf("\n🔍 Tasks matching %q:\n", query)
+		fmt.Println("─────────────────────────────────────────")
+		for _, r := range results {
+			status := "⬜"
+			if r.Task.Done {
+				status = "✅"
+			}
+			fmt.Printf("%s [%s] %s\n", status, r.Task.ID, r.Highlight(open, close, nil))
+		}
+		fmt.Println("─────────────────────────────────────────")
+	},
+}
+
+var viewCmd = &cobra.Command{
+	Use:   "view [name]",
+	Short: "Run a saved view, or list the saved views without a name",
+	Long:  `View runs a saved view created through /api/v1/views: your own view with the name or, failing that, a shared one. Its query is filtered as with "list --query" and the tasks are shown in the view's sort order.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		owner, _ := cmd.Flags().GetString("owner")
+
+		viewRecords, ok := storage.AsViewStore(store)
+		if !ok {
+			fmt.Printf("Error: %s storage cannot keep saved views\n", storageType)
+			return
+		}
+		viewStore := views.NewStore(viewRecords)
+
+		if len(args) == 0 {
+			saved, err := viewStore.Views(owner)
+			if err != nil {
+				fmt.Printf("Error loading saved views: %v\n", err)
+				return
+			}
+			if len(saved) == 0 {
+				fmt.Println("No saved views found.")
+				return
+			}
+			fmt.Println("\n🔖 Saved views:")
+			fmt.Println("─────────────────────────────────────────")
+			for _, v := range saved {
+				shared := ""
+				if v.Shared {
+					shared = " (shared)"
+				}
+				fmt.Printf("%s%s: %s\n", v.Name, shared, v.Query)
+			}
+			fmt.Println("─────────────────────────────────────────")
+			return
+		}
+
+		view, err := viewStore.Lookup(args[0], owner)
+		if err != nil {
+			fmt.Printf("Error finding view %q: %v\n", args[0], err)
+			return
+		}
+		tasks, err := view.Evaluate(cmd.Context(), taskService, time.Now())
+		if err != nil {
+			fmt.Printf("Error running view: %v\n", err)
+			return
+		}
+
+		if len(tasks) == 0 {
+			fmt.Printf("No tasks in view %q.\n", view.Name)
+			return
+		}
+
+		fmt.Printf("\n🔖 %s:\n", view.Name)
+		fmt.Println("─────────────────────────────────────────")
+		for _, t := range tasks {
+			status := "⬜"
+			if t.Done {
+				status = "✅"
+			}
+
+			dueStr := ""
+			if t.DueDate != nil {
+				dueStr = fmt.Sprintf(" (Due: %s)", t.DueDate.Format("2006-01-02"))
+			}
+
+			fmt.Printf("%s [%s] %s%s\n", status, t.ID, t.Title, dueStr)
+		}
+		fmt.Println("─────────────────────────────────────────")
+	},
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Create, list and restore task backups",
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Write a compressed snapshot of all tasks",
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+
+		manager := backup.NewManager(dir, backup.WithSource(string(storageType)))
+		info, err := manager.Create(cmd.Context(), store)
+		if err != nil {
+			fmt.Printf("Error creating backup: %v\n", err)
+			return
+		}
+		fmt.Printf("Backup created: %s (%d tasks) 💾\n", info.Path, info.Manifest.TaskCount)
+	},
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups, newest first",
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+
+		infos, err := backup.NewManager(dir).List()
+		if err != nil {
+			fmt.Printf("Error listing backups: %v\n", err)
+			return
+		}
+
+		if len(infos) == 0 {
+			fmt.Printf("No backups found in %s.\n", dir)
+			return
+		}
+
+		fmt.Println("\n💾 Backups:")
+		fmt.Println("─────────────────────────────────────────")
+		for _, info := range infos {
+			fmt.Printf("%s  %s  %d tasks  %d bytes\n",
+				info.Manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+				info.Path, info.Manifest.TaskCount, info.Manifest.SizeBytes)
+		}
+		fmt.Println("─────────────────────────────────────────")
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore [file]",
+	Short: "Restore tasks from a backup",
+	Long:  `Restore verifies the backup's checksum, then creates missing tasks and overwrites existing tasks with the same ID. With --prune, tasks that are not in the backup are deleted.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		prune, _ := cmd.Flags().GetBool("prune")
+
+		result, err := backup.Restore(cmd.Context(), store, args[0], backup.RestoreOptions{Prune: prune})
+		if err != nil {
+			fmt.Printf("Error restoring backup: %v\n", err)
+			if result != nil && *result != (backup.RestoreResult{}) {
+				fmt.Printf("Changes made before the error: %d created, %d updated, %d deleted\n", result.Created, result.Updated, result.Deleted)
+			}
+			return
+		}
+		fmt.Printf("Backup restored: %d created, %d updated, %d deleted ✅\n", result.Created, result.Updated, result.Deleted)
+	},
+}
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Remove tasks completed more than the given number of days ago",
+	Run: func(cmd *cobra.Command, args []string) {
+		days, _ := cmd.Flags().GetInt("days")
+		mode, _ := cmd.Flags().GetString("mode")
+		archiveDir, _ := cmd.Flags().GetString("archive-dir")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		cleaner, err := cleanup.New(taskService, cleanup.Config{
+			After:      time.Duration(days) * 24 * time.Hour,
+			Mode:       cleanup.Mode(mode),
+			ArchiveDir: archiveDir,
+			DryRun:     dryRun,
+		})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		report, err := cleaner.Run(cmd.Context())
+		if err != nil {
+			fmt.Printf("Error cleaning up tasks: %v\n", err)
+			return
+		}
+
+		if report.DryRun {
+			if len(report.TaskIDs) == 0 {
+				fmt.Printf("No tasks completed before %s.\n", report.Cutoff.Format("2006-01-02"))
+				return
+			}
+			fmt.Printf("\n🧹 Would remove %d tasks completed before %s:\n", len(report.TaskIDs), report.Cutoff.Format("2006-01-02"))
+			for _, id := range report.TaskIDs {
+				fmt.Printf("  [%s]\n", id)
+			}
+			return
+		}
+
+		fmt.Printf("Removed %d tasks completed before %s 🧹\n", report.Removed, report.Cutoff.Format("2006-01-02"))
+		if len(report.Skipped) > 0 {
+			fmt.Printf("Kept %d tasks changed during cleanup: %s\n", len(report.Skipped), strings.Join(report.Skipped, ", "))
+		}
+		if report.Archive != "" {
+			fmt.Printf("Archived to %s\n", report.Archive)
+		}
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import tasks from a file, keeping their IDs",
+	Long:  `Import reads a JSON array of tasks, as in tasks.json, or one JSON task per line; "-" reads standard input. Tasks are written in batches using the storage's native bulk writes. Tasks whose ID already exists are skipped unless --update is given.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		update, _ := cmd.Flags().GetBool("update")
+		atomic, _ := cmd.Flags().GetBool("atomic")
+
+		input := os.Stdin
+		if args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				fmt.Printf("Error opening file: %v\n", err)
+				return
+			}
+			defer file.Close()
+			input = file
+		}
+
+		tasks, err := importer.Read(input)
+		if err != nil {
+			fmt.Printf("Error reading tasks: %v\n", err)
+			return
+		}
+
+		result, err := importer.Import(cmd.Context(), store, tasks, importer.Options{
+			BatchSize: batchSize,
+			Update:    update,
+			Atomic:    atomic,
+		})
+		if err != nil {
+			fmt.Printf("Error importing tasks: %v\n", err)
+			if result != nil && !atomic {
+				fmt.Printf("Imported before the error: %d created, %d updated\n", result.Created, result.Updated)
+			}
+			return
+		}
+		fmt.Printf("Tasks imported: %d created, %d updated, %d skipped 📥\n", result.Created, result.Updated, result.Skipped)
+	},
+}
+
 func init() {
 	addCmd.Flags().StringP("due", "d", "", "Due date (YYYY-MM-DD)")
 	listCmd.Flags().StringP("status", "s", "", "Filter by status (done/undone)")
+	listCmd.Flags().StringP("query", "q", "", `Filter with a query, e.g. 'status:open due<=+7d -title:draft "login bug"'`)
 	dueCmd.Flags().IntP("days", "d", 7, "Number of days to look ahead")
+	searchCmd.Flags().IntP("limit", "n", storage.DefaultSearchLimit, "Maximum number of tasks to show")
+	viewCmd.Flags().String("owner", os.Getenv("USER"), "Owner whose views are run before shared views")
+	backupCmd.PersistentFlags().String("dir", "backups", "Backup directory")
+	backupRestoreCmd.Flags().Bool("prune", false, "Delete tasks that are not in the backup")
+	cleanupCmd.Flags().Int("days", 30, "Remove tasks completed more than this many days ago")
+	cleanupCmd.Flags().String("mode", "purge", "purge, or archive to snapshot removed tasks first")
+	cleanupCmd.Flags().String("archive-dir", "archive", "Archive directory used with --mode archive")
+	cleanupCmd.Flags().Bool("dry-run", false, "Show what would be removed without removing it")
+	importCmd.Flags().Int("batch-size", importer.DefaultBatchSize, "Tasks written per batch")
+	importCmd.Flags().Bool("update", false, "Overwrite existing tasks with the same ID")
+	importCmd.Flags().Bool("atomic", false, "Import all tasks or, if any write fails, none")
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if closeErr := closeStorage(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
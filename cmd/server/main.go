@@ -3,56 +3,242 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"GoTask_Management/internal/api"
+	"GoTask_Management/internal/backup"
+	"GoTask_Management/internal/cleanup"
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/health"
+	"GoTask_Management/internal/logging"
+	"GoTask_Management/internal/metrics"
+	"GoTask_Management/internal/notify"
 	"GoTask_Management/internal/scheduler"
 	"GoTask_Management/internal/storage"
 	"GoTask_Management/internal/task"
+	"GoTask_Management/internal/tracing"
+	"GoTask_Management/internal/views"
+	"GoTask_Management/internal/webhook"
 
 	"github.com/spf13/viper"
 )
 
 func main() {
-	log.Println("🚀 Starting GoTask Management Server...")
-
 	// Load configuration with enhanced defaults
-	setupConfig()
+	configErr := setupConfig()
+
+	// Build the structured logger before anything else logs
+	logger, logCloser, err := initializeLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logCloser.Close()
+	slog.SetDefault(logger)
+
+	logger.Info("starting GoTask Management server")
+	if configErr != nil {
+		logger.Warn("config file not found, using defaults and environment variables", "error", configErr)
+	} else {
+		logger.Info("configuration loaded", "file", viper.ConfigFileUsed())
+	}
 
 	// Initialize storage using the new factory pattern
-	store, err := initializeStorage()
+	store, err := initializeStorage(logger)
 	if err != nil {
-		log.Fatalf("❌ Failed to initialize storage: %v", err)
+		fatal(logger, logCloser, "failed to initialize storage", err)
 	}
 	defer func() {
 		if err := store.Close(); err != nil {
-			log.Printf("⚠️ Error closing storage: %v", err)
+			logger.Warn("error closing storage", "error", err)
 		}
 	}()
 
+	// Wrap storage with metrics collection if enabled
+	var registry *metrics.Registry
+	if viper.GetBool("monitoring.metrics.enabled") {
+		registry = metrics.NewRegistry()
+		store = storage.NewInstrumentedStorage(store, registry)
+	}
+
+	// Serve repeated reads from memory; metrics above count only backend calls
+	if viper.GetBool("storage.cache.enabled") {
+		store = storage.NewCachedStorage(store, storage.CacheConfig{
+			TTL:        viper.GetDuration("storage.cache.ttl"),
+			MaxEntries: viper.GetInt("storage.cache.max_entries"),
+		})
+	}
+
+	// Attribute storage operations to the request that caused them
+	store = storage.NewTracingStorage(store, logger)
+
 	// Perform health check on storage
-	if err := performStorageHealthCheck(store); err != nil {
-		log.Fatalf("❌ Storage health check failed: %v", err)
+	if err := performStorageHealthCheck(logger, store); err != nil {
+		fatal(logger, logCloser, "storage health check failed", err)
 	}
 
 	// Initialize service
-	taskService := task.NewService(store)
+	// Task lifecycle events; subscribers are registered below
+	bus := events.NewBus(events.WithLogger(logger))
+
+	// Deliver task events to webhook subscribers
+	var webhooks *webhook.Dispatcher
+	if viper.GetBool("webhooks.enabled") {
+		// Keep subscriptions next to the tasks, so every server sharing the database delivers to them
+		webhookRecords, ok := storage.AsWebhookStore(store)
+		if !ok {
+			fatal(logger, logCloser, "invalid webhook configuration",
+				fmt.Errorf("%s storage cannot keep webhooks; set webhooks.enabled to false", viper.GetString("storage.type")))
+		}
+		webhooks = initializeWebhooks(logger, webhookRecords)
+		webhooks.Start()
+		defer webhooks.Stop()
+		bus.Subscribe("webhooks", webhooks.HandleEvent, events.Async(viper.GetInt("events.queue_size")))
+	}
+
+	// Closed before the webhook dispatcher stops so queued events are still recorded
+	defer bus.Close()
+
+	// Recent events for the SSE and WebSocket endpoints
+	var stream *events.Stream
+	if viper.GetBool("events.stream.enabled") || viper.GetBool("api.websocket.enabled") {
+		stream = events.NewStream(bus, viper.GetInt("events.stream.replay_size"))
+		defer stream.Close()
+	}
+
+	taskService := task.NewService(store, task.WithLogger(logger), task.WithEventBus(bus))
+
+	// Publish events for changes made by other processes, such as the CLI
+	if viper.GetBool("storage.watch.enabled") {
+		if watcher, ok := storage.AsWatcher(store); ok {
+			watchCtx, stopWatch := context.WithCancel(context.Background())
+			defer stopWatch()
+			go func() {
+				if err := taskService.Watch(watchCtx, watcher); err != nil {
+					logger.Warn("not watching storage for external changes", "error", err)
+				}
+			}()
+		} else {
+			logger.Info("storage does not report external changes", "storage", viper.GetString("storage.type"))
+		}
+	}
+
+	// Keep responses to requests retried with an Idempotency-Key next to the tasks
+	var idempotency storage.IdempotencyStore
+	if viper.GetBool("api.idempotency.enabled") {
+		var ok bool
+		if idempotency, ok = storage.AsIdempotencyStore(store); !ok {
+			fatal(logger, logCloser, "invalid idempotency configuration",
+				fmt.Errorf("%s storage cannot keep idempotency records; set api.idempotency.enabled to false", viper.GetString("storage.type")))
+		}
+	}
 
 	// Start scheduler if enabled
 	var sched *scheduler.Scheduler
 	if viper.GetBool("scheduler.enabled") {
-		sched = scheduler.New(taskService, viper.GetInt("scheduler.interval"))
+		jobConfig, err := loadJobConfig()
+		if err != nil {
+			fatal(logger, logCloser, "invalid scheduler job configuration", err)
+		}
+		schedOpts := []scheduler.Option{scheduler.WithLogger(logger), scheduler.WithJobConfig(jobConfig)}
+		if registry != nil {
+			schedOpts = append(schedOpts, scheduler.WithMetrics(registry))
+		}
+		sched = scheduler.New(taskService, viper.GetInt("scheduler.interval"), schedOpts...)
+		if viper.GetBool("scheduler.cleanup_completed_tasks") {
+			if err := registerCleanupJob(logger, sched, taskService); err != nil {
+				fatal(logger, logCloser, "failed to configure task cleanup", err)
+			}
+		}
+		if viper.GetBool("backup.enabled") {
+			if err := registerBackupJob(logger, sched, store); err != nil {
+				fatal(logger, logCloser, "failed to configure backups", err)
+			}
+		}
+		if idempotency != nil {
+			if err := registerIdempotencyCleanupJob(sched, idempotency); err != nil {
+				fatal(logger, logCloser, "failed to configure idempotency record cleanup", err)
+			}
+		}
+		if viper.GetBool("features.notifications") {
+			sentRecords, ok := storage.AsNotificationStore(store)
+			if !ok {
+				fatal(logger, logCloser, "invalid notification configuration",
+					fmt.Errorf("%s storage cannot keep sent notifications; set features.notifications to false", viper.GetString("storage.type")))
+			}
+			if err := registerNotificationJob(logger, sched, taskService, sentRecords); err != nil {
+				fatal(logger, logCloser, "failed to configure notifications", err)
+			}
+		}
 		sched.Start()
 		defer sched.Stop()
-		log.Printf("📅 Scheduler started with %d second interval", viper.GetInt("scheduler.interval"))
 	}
 
 	// Initialize API server
-	server := api.NewServer(taskService, viper.GetInt("server.port"))
+	corsConfig := loadCORSConfig()
+	if err := corsConfig.Validate(); err != nil {
+		fatal(logger, logCloser, "invalid CORS configuration", err)
+	}
+	serverOpts := []api.Option{api.WithCORS(corsConfig), api.WithLogger(logger)}
+	if tracer := initializeTracer(logger); tracer != nil {
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tracer.Shutdown(ctx); err != nil {
+				logger.Warn("error flushing spans", "error", err)
+			}
+		}()
+		serverOpts = append(serverOpts, api.WithTracer(tracer))
+	}
+	serverOpts = append(serverOpts, loadHealthOptions(store, sched)...)
+	if sched != nil && viper.GetBool("features.admin_endpoints") {
+		serverOpts = append(serverOpts, api.WithAdminJobs(sched))
+	}
+	if viper.GetBool("features.bulk_operations") {
+		serverOpts = append(serverOpts, api.WithBatch(taskService, api.BatchConfig{
+			MaxItems: viper.GetInt("api.batch.max_items"),
+		}))
+	}
+	if idempotency != nil {
+		serverOpts = append(serverOpts, api.WithIdempotency(idempotency, api.IdempotencyConfig{
+			TTL: viper.GetDuration("api.idempotency.ttl"),
+		}))
+	}
+	if webhooks != nil {
+		serverOpts = append(serverOpts, api.WithWebhooks(webhooks))
+	}
+	if viper.GetBool("views.enabled") {
+		// Keep views next to the tasks, so every server sharing the database sees them
+		viewRecords, ok := storage.AsViewStore(store)
+		if !ok {
+			fatal(logger, logCloser, "invalid saved view configuration",
+				fmt.Errorf("%s storage cannot keep saved views; set views.enabled to false", viper.GetString("storage.type")))
+		}
+		serverOpts = append(serverOpts, api.WithViews(views.NewStore(viewRecords)))
+	}
+	if viper.GetBool("api.websocket.enabled") {
+		wsConfig := loadWebSocketConfig()
+		if err := wsConfig.Validate(); err != nil {
+			fatal(logger, logCloser, "invalid WebSocket configuration", err)
+		}
+		serverOpts = append(serverOpts, api.WithWebSocket(taskService, stream, wsConfig))
+	}
+	if viper.GetBool("events.stream.enabled") {
+		serverOpts = append(serverOpts, api.WithEventStream(stream, api.StreamConfig{
+			Heartbeat: viper.GetDuration("events.stream.heartbeat"),
+		}))
+	}
+	if registry != nil {
+		serverOpts = append(serverOpts, api.WithMetrics(registry, viper.GetString("monitoring.metrics.path")))
+	}
+	server := api.NewServer(taskService, viper.GetInt("server.port"), serverOpts...)
 
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -63,12 +249,15 @@ func main() {
 		port := viper.GetInt("server.port")
 		storageType := viper.GetString("storage.type")
 
-		log.Printf("🌐 Server starting on port %d with %s storage", port, storageType)
-		log.Printf("📡 API available at: http://localhost:%d", port)
-		log.Printf("🏥 Health check: http://localhost:%d/health", port)
+		logger.Info("server starting",
+			"port", port,
+			"storage", storageType,
+			"api_url", fmt.Sprintf("http://localhost:%d", port),
+			"health_url", fmt.Sprintf("http://localhost:%d%s", port, viper.GetString("monitoring.health_check.path")),
+		)
 
 		if err := server.Start(); err != nil {
-			log.Printf("❌ Server error: %v", err)
+			logger.Error("server error", "error", err)
 			cancel()
 		}
 	}()
@@ -87,21 +276,21 @@ func main() {
 
 	select {
 	case <-quit:
-		log.Println("🛑 Shutdown signal received...")
+		logger.Info("shutdown signal received")
 
 		// Start listening for second signal for force quit
 		go func() {
 			<-forceQuit
-			log.Println("🚨 Force quit signal received, exiting immediately!")
+			logger.Warn("force quit signal received, exiting immediately")
 			os.Exit(1)
 		}()
 
 	case <-ctx.Done():
-		log.Println("🛑 Context cancelled...")
+		logger.Info("context cancelled")
 	}
 
 	// Graceful shutdown with timeout
-	log.Println("🔄 Shutting down server gracefully...")
+	logger.Info("shutting down server gracefully")
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
@@ -116,22 +305,29 @@ func main() {
 	select {
 	case err := <-shutdownComplete:
 		if err != nil {
-			log.Printf("⚠️ Error during server shutdown: %v", err)
-			os.Exit(1)
+			fatal(logger, logCloser, "error during server shutdown", err)
 		} else {
-			log.Println("✅ Server stopped gracefully")
+			logger.Info("server stopped gracefully")
 		}
 	case <-shutdownCtx.Done():
-		log.Println("⚠️ Shutdown timeout exceeded, forcing exit")
-		os.Exit(1)
+		fatal(logger, logCloser, "shutdown timeout exceeded, forcing exit", shutdownCtx.Err())
 	}
 
 	// Final cleanup
-	log.Println("🏁 Application shutdown complete")
+	logger.Info("application shutdown complete")
+}
+
+// fatal logs err, flushes the log output and terminates the process
+func fatal(logger *slog.Logger, logCloser io.Closer, msg string, err error) {
+	logger.Error(msg, "error", err)
+	logCloser.Close()
+	os.Exit(1)
 }
 
-// setupConfig initializes Viper configuration with enhanced defaults
-func setupConfig() {
+// setupConfig initializes Viper configuration with enhanced defaults.
+// It returns the error from reading the config file so it can be logged once
+// the logger exists.
+func setupConfig() error {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath("./configs")
@@ -146,6 +342,12 @@ func setupConfig() {
 	// Storage configuration
 	viper.SetDefault("storage.type", "json")
 	viper.SetDefault("storage.path", "tasks.json")
+	viper.SetDefault("storage.lock_timeout", "5s")
+	viper.SetDefault("storage.snapshot_path", "")
+	viper.SetDefault("storage.watch.enabled", true)
+	viper.SetDefault("storage.cache.enabled", false)
+	viper.SetDefault("storage.cache.ttl", "30s")
+	viper.SetDefault("storage.cache.max_entries", storage.DefaultCacheMaxEntries)
 
 	// Database configuration
 	viper.SetDefault("database.host", "localhost")
@@ -165,43 +367,385 @@ func setupConfig() {
 	// Scheduler configuration
 	viper.SetDefault("scheduler.enabled", true)
 	viper.SetDefault("scheduler.interval", 300)
+	viper.SetDefault("scheduler.cleanup_completed_tasks", false)
+	viper.SetDefault("scheduler.cleanup_after_days", 30)
+	viper.SetDefault("scheduler.cleanup_mode", "purge")
+	viper.SetDefault("scheduler.cleanup_archive_dir", "archive")
+	viper.SetDefault("scheduler.cleanup_dry_run", false)
+	viper.SetDefault("features.admin_endpoints", false)
+	viper.SetDefault("features.notifications", false)
+	viper.SetDefault("features.bulk_operations", false)
+
+	// Event bus defaults
+	viper.SetDefault("events.queue_size", events.DefaultQueueSize)
+	viper.SetDefault("events.stream.enabled", true)
+	viper.SetDefault("events.stream.replay_size", events.DefaultReplaySize)
+	viper.SetDefault("events.stream.heartbeat", "15s")
+
+	// Webhook defaults
+	viper.SetDefault("webhooks.enabled", false)
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.max_attempts", 5)
+	viper.SetDefault("webhooks.retry_backoff", "10s")
+
+	// Saved view defaults
+	viper.SetDefault("views.enabled", false)
+
+	// Notification defaults
+	viper.SetDefault("notifications.schedule", "1m")
+	viper.SetDefault("notifications.reminder_offsets", []string{"24h", "1h"})
+	viper.SetDefault("notifications.overdue", true)
+	viper.SetDefault("notifications.email.enabled", false)
+	viper.SetDefault("notifications.email.port", 587)
+	viper.SetDefault("notifications.email.timeout", "30s")
+	viper.SetDefault("notifications.webhook.enabled", false)
+	viper.SetDefault("notifications.webhook.timeout", "10s")
+
+	// Backup configuration
+	viper.SetDefault("backup.enabled", false)
+	viper.SetDefault("backup.directory", "backups")
+	viper.SetDefault("backup.schedule", "@daily")
+	viper.SetDefault("backup.timeout", "5m")
+	viper.SetDefault("backup.retention.max_count", 7)
+	viper.SetDefault("backup.retention.max_age", "720h")
 
 	// Logging configuration
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "text")
+	viper.SetDefault("logging.output", "stdout")
+	viper.SetDefault("logging.file_path", "logs/gotask.log")
+	viper.SetDefault("logging.max_size", 100)
+	viper.SetDefault("logging.max_backups", 3)
+	viper.SetDefault("logging.max_age", 28)
+	viper.SetDefault("logging.compress", true)
+	for key, env := range map[string]string{
+		"logging.level":       "LOG_LEVEL",
+		"logging.format":      "LOG_FORMAT",
+		"logging.output":      "LOG_OUTPUT",
+		"logging.file_path":   "LOG_FILE_PATH",
+		"logging.max_size":    "LOG_MAX_SIZE",
+		"logging.max_backups": "LOG_MAX_BACKUPS",
+		"logging.max_age":     "LOG_MAX_AGE",
+		"logging.compress":    "LOG_COMPRESS",
+	} {
+		_ = viper.BindEnv(key, env)
+	}
+
+	// API configuration
+	viper.SetDefault("api.cors.enabled", false)
+	viper.SetDefault("api.cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
+	viper.SetDefault("api.cors.allowed_headers", []string{"Content-Type", "Authorization", api.IdempotencyKeyHeader})
+	viper.SetDefault("api.cors.allow_credentials", false)
+	viper.SetDefault("api.cors.max_age", 86400)
+	viper.SetDefault("api.idempotency.enabled", true)
+	viper.SetDefault("api.idempotency.ttl", api.DefaultIdempotencyTTL.String())
+	viper.SetDefault("api.websocket.enabled", false)
+	viper.SetDefault("api.websocket.ping_interval", "30s")
+	viper.SetDefault("api.websocket.send_buffer", 64)
+	viper.SetDefault("api.websocket.max_message_size", 65536)
+	viper.SetDefault("api.batch.max_items", api.DefaultBatchMaxItems)
+
+	// Monitoring configuration
+	viper.SetDefault("monitoring.metrics.enabled", false)
+	viper.SetDefault("monitoring.metrics.path", "/metrics")
+
+	viper.SetDefault("monitoring.health_check.path", "/health")
+	viper.SetDefault("monitoring.health_check.detailed", true)
+	viper.SetDefault("monitoring.health_check.timeout", "2s")
+	viper.SetDefault("monitoring.health_check.min_free_disk_mb", 100)
+
+	viper.SetDefault("monitoring.tracing.enabled", false)
+	viper.SetDefault("monitoring.tracing.exporter", "stdout")
+	viper.SetDefault("monitoring.tracing.otlp_endpoint", "http://localhost:4318/v1/traces")
+	viper.SetDefault("monitoring.tracing.service_name", "gotask-api")
 
 	// Allow environment variables to override config
 	viper.AutomaticEnv()
 
-	if err := viper.ReadInConfig(); err != nil {
-		log.Printf("📄 Config file not found, using defaults and environment variables: %v", err)
-	} else {
-		log.Printf("📄 Configuration loaded from: %s", viper.ConfigFileUsed())
+	return viper.ReadInConfig()
+}
+
+// initializeLogger builds the application logger from the logging block
+func initializeLogger() (*slog.Logger, io.Closer, error) {
+	return logging.New(logging.Config{
+		Level:      viper.GetString("logging.level"),
+		Format:     viper.GetString("logging.format"),
+		Output:     viper.GetString("logging.output"),
+		FilePath:   viper.GetString("logging.file_path"),
+		MaxSize:    viper.GetInt("logging.max_size"),
+		MaxBackups: viper.GetInt("logging.max_backups"),
+		MaxAge:     viper.GetInt("logging.max_age"),
+		Compress:   viper.GetBool("logging.compress"),
+	})
+}
+
+// initializeTracer creates the span tracer configured in monitoring.tracing, or nil when disabled
+func initializeTracer(logger *slog.Logger) *tracing.Tracer {
+	if !viper.GetBool("monitoring.tracing.enabled") {
+		return nil
 	}
+
+	serviceName := viper.GetString("monitoring.tracing.service_name")
+	var exporter tracing.Exporter
+	switch viper.GetString("monitoring.tracing.exporter") {
+	case "otlp":
+		exporter = tracing.NewOTLPExporter(tracing.OTLPConfig{
+			Endpoint:    viper.GetString("monitoring.tracing.otlp_endpoint"),
+			ServiceName: serviceName,
+			Logger:      logger,
+		})
+	default:
+		exporter = tracing.NewStdoutExporter(os.Stdout)
+	}
+
+	logger.Info("tracing enabled", "exporter", viper.GetString("monitoring.tracing.exporter"))
+	return tracing.NewTracer(serviceName, exporter)
+}
+
+// loadCORSConfig builds the CORS middleware configuration from the api.cors block
+func loadCORSConfig() api.CORSConfig {
+	return api.CORSConfig{
+		Enabled:          viper.GetBool("api.cors.enabled"),
+		AllowedOrigins:   viper.GetStringSlice("api.cors.allowed_origins"),
+		AllowedMethods:   viper.GetStringSlice("api.cors.allowed_methods"),
+		AllowedHeaders:   viper.GetStringSlice("api.cors.allowed_headers"),
+		AllowCredentials: viper.GetBool("api.cors.allow_credentials"),
+		MaxAge:           viper.GetInt("api.cors.max_age"),
+	}
+}
+
+// loadWebSocketConfig builds the WebSocket endpoint configuration from the api.websocket block
+func loadWebSocketConfig() api.WebSocketConfig {
+	return api.WebSocketConfig{
+		Tokens:         viper.GetStringMapString("api.websocket.tokens"),
+		AllowedOrigins: viper.GetStringSlice("api.websocket.allowed_origins"),
+		PingInterval:   viper.GetDuration("api.websocket.ping_interval"),
+		SendBuffer:     viper.GetInt("api.websocket.send_buffer"),
+		MaxMessageSize: viper.GetInt64("api.websocket.max_message_size"),
+	}
+}
+
+// loadJobConfig reads per-job overrides from the scheduler.jobs block, keyed by job name
+func loadJobConfig() (map[string]scheduler.JobConfig, error) {
+	config := map[string]scheduler.JobConfig{}
+	if err := viper.UnmarshalKey("scheduler.jobs", &config); err != nil {
+		return nil, err
+	}
+	for name, cfg := range config {
+		if cfg.Schedule == "" {
+			continue
+		}
+		if _, err := scheduler.ParseSchedule(cfg.Schedule); err != nil {
+			return nil, fmt.Errorf("job %s: %w", name, err)
+		}
+	}
+	return config, nil
+}
+
+// registerCleanupJob schedules removal of tasks completed more than
+// scheduler.cleanup_after_days ago, running every scheduler.interval seconds
+func registerCleanupJob(logger *slog.Logger, sched *scheduler.Scheduler, taskService *task.Service) error {
+	cleaner, err := cleanup.New(taskService, cleanup.Config{
+		After:      time.Duration(viper.GetInt("scheduler.cleanup_after_days")) * 24 * time.Hour,
+		Mode:       cleanup.Mode(viper.GetString("scheduler.cleanup_mode")),
+		ArchiveDir: viper.GetString("scheduler.cleanup_archive_dir"),
+		DryRun:     viper.GetBool("scheduler.cleanup_dry_run"),
+	}, cleanup.WithLogger(logger))
+	if err != nil {
+		return err
+	}
+
+	return sched.Register(scheduler.Job{
+		Name:     "cleanup",
+		Schedule: scheduler.Every(sched.Interval()),
+		Spec:     fmt.Sprintf("@every %s", sched.Interval()),
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := cleaner.Run(ctx)
+			return err
+		},
+	})
+}
+
+// registerBackupJob schedules snapshots of all tasks into backup.directory
+func registerBackupJob(logger *slog.Logger, sched *scheduler.Scheduler, store storage.Storage) error {
+	spec := viper.GetString("backup.schedule")
+	schedule, err := scheduler.ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	manager := backup.NewManager(viper.GetString("backup.directory"),
+		backup.WithSource(viper.GetString("storage.type")),
+		backup.WithLogger(logger),
+		backup.WithRetention(backup.Retention{
+			MaxCount: viper.GetInt("backup.retention.max_count"),
+			MaxAge:   viper.GetDuration("backup.retention.max_age"),
+		}),
+	)
+
+	return sched.Register(scheduler.Job{
+		Name:     "backup",
+		Schedule: schedule,
+		Spec:     spec,
+		Timeout:  viper.GetDuration("backup.timeout"),
+		Retry:    scheduler.RetryPolicy{MaxAttempts: 3, Backoff: 30 * time.Second},
+		Run: func(ctx context.Context) error {
+			_, err := manager.Run(ctx, store)
+			return err
+		},
+	})
+}
+
+// registerIdempotencyCleanupJob removes expired idempotency records every hour
+func registerIdempotencyCleanupJob(sched *scheduler.Scheduler, store storage.IdempotencyStore) error {
+	return sched.Register(scheduler.Job{
+		Name:     "idempotency_cleanup",
+		Schedule: scheduler.Every(time.Hour),
+		Spec:     "@every 1h",
+		Timeout:  time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := store.DeleteExpiredIdempotencyRecords(time.Now())
+			return err
+		},
+	})
+}
+
+// initializeWebhooks creates the dispatcher for the subscriptions and
+// delivery log kept in records
+func initializeWebhooks(logger *slog.Logger, records storage.WebhookStore) *webhook.Dispatcher {
+	return webhook.NewDispatcher(webhook.NewStore(records),
+		webhook.WithLogger(logger),
+		webhook.WithClient(&http.Client{Timeout: viper.GetDuration("webhooks.timeout")}),
+		webhook.WithRetry(webhook.RetryPolicy{
+			MaxAttempts: viper.GetInt("webhooks.max_attempts"),
+			Backoff:     viper.GetDuration("webhooks.retry_backoff"),
+		}),
+	)
+}
+
+// registerNotificationJob schedules due-date reminders through the enabled
+// notification channels, recording sent notifications in sentRecords
+func registerNotificationJob(logger *slog.Logger, sched *scheduler.Scheduler, taskService *task.Service, sentRecords storage.NotificationStore) error {
+	var offsets []time.Duration
+	for _, value := range viper.GetStringSlice("notifications.reminder_offsets") {
+		offset, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid reminder offset %q: %w", value, err)
+		}
+		offsets = append(offsets, offset)
+	}
+
+	var channels []notify.Channel
+	if viper.GetBool("notifications.email.enabled") {
+		email, err := notify.NewSMTPChannel(notify.SMTPConfig{
+			Host:     viper.GetString("notifications.email.host"),
+			Port:     viper.GetInt("notifications.email.port"),
+			Username: viper.GetString("notifications.email.username"),
+			Password: viper.GetString("notifications.email.password"),
+			From:     viper.GetString("notifications.email.from"),
+			To:       viper.GetStringSlice("notifications.email.to"),
+			Timeout:  viper.GetDuration("notifications.email.timeout"),
+		})
+		if err != nil {
+			return err
+		}
+		channels = append(channels, email)
+	}
+	if viper.GetBool("notifications.webhook.enabled") {
+		webhook, err := notify.NewWebhookChannel(notify.WebhookConfig{
+			URL:     viper.GetString("notifications.webhook.url"),
+			Headers: viper.GetStringMapString("notifications.webhook.headers"),
+			Timeout: viper.GetDuration("notifications.webhook.timeout"),
+		})
+		if err != nil {
+			return err
+		}
+		channels = append(channels, webhook)
+	}
+	if len(channels) == 0 {
+		return fmt.Errorf("no notification channels are enabled")
+	}
+
+	spec := viper.GetString("notifications.schedule")
+	schedule, err := scheduler.ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	notifier := notify.New(taskService, notify.Config{
+		Offsets: offsets,
+		Overdue: viper.GetBool("notifications.overdue"),
+	}, channels, notify.WithLogger(logger), notify.WithDedupStore(sentRecords))
+
+	return sched.Register(scheduler.Job{
+		Name:     "notifications",
+		Schedule: schedule,
+		Spec:     spec,
+		Timeout:  time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := notifier.Run(ctx)
+			return err
+		},
+	})
+}
+
+// loadHealthOptions configures the health endpoints and the readiness checks
+// for storage, the scheduler heartbeat and, for file backends, free disk space
+func loadHealthOptions(store storage.Storage, sched *scheduler.Scheduler) []api.Option {
+	opts := []api.Option{api.WithHealth(api.HealthConfig{
+		Path:     viper.GetString("monitoring.health_check.path"),
+		Detailed: viper.GetBool("monitoring.health_check.detailed"),
+		Timeout:  viper.GetDuration("monitoring.health_check.timeout"),
+	})}
+
+	if healthChecker, ok := store.(interface{ HealthCheck() error }); ok {
+		opts = append(opts, api.WithReadinessCheck("storage", func(ctx context.Context) error {
+			return healthChecker.HealthCheck()
+		}))
+	}
+
+	if sched != nil {
+		// Allow one missed tick before reporting the scheduler as stuck
+		maxAge := 2*sched.Interval() + 30*time.Second
+		opts = append(opts, api.WithReadinessCheck("scheduler", health.HeartbeatCheck(sched.LastHeartbeat, maxAge)))
+	}
+
+	switch storage.StorageType(viper.GetString("storage.type")) {
+	case storage.StorageTypeJSON, storage.StorageTypeSQLite, storage.StorageTypeWAL, storage.StorageTypeBolt:
+		dir := filepath.Dir(viper.GetString("storage.path"))
+		minFree := uint64(viper.GetInt64("monitoring.health_check.min_free_disk_mb")) << 20
+		opts = append(opts, api.WithReadinessCheck("disk", health.DiskSpaceCheck(dir, minFree)))
+	}
+
+	return opts
 }
 
 // initializeStorage creates and configures the storage backend
-func initializeStorage() (storage.Storage, error) {
+func initializeStorage(logger *slog.Logger) (storage.Storage, error) {
 	storageType := viper.GetString("storage.type")
 
 	// Create storage configuration
 	config := &storage.StorageConfig{
-		Type:     storage.StorageType(storageType),
-		FilePath: viper.GetString("storage.path"),
-		Host:     viper.GetString("database.host"),
-		Port:     viper.GetInt("database.port"),
-		User:     viper.GetString("database.user"),
-		Password: viper.GetString("database.password"),
-		DBName:   viper.GetString("database.name"),
-		SSLMode:  viper.GetString("database.ssl_mode"),
-		TimeZone: viper.GetString("database.timezone"),
-		Charset:  viper.GetString("database.charset"),
-		ParseTime: viper.GetBool("database.parse_time"),
-		Loc:      viper.GetString("database.location"),
-		URI:      viper.GetString("mongodb.uri"),
-		Collection: viper.GetString("mongodb.collection"),
+		Type:           storage.StorageType(storageType),
+		FilePath:       viper.GetString("storage.path"),
+		LockTimeout:    viper.GetDuration("storage.lock_timeout"),
+		SnapshotPath:   viper.GetString("storage.snapshot_path"),
+		Host:           viper.GetString("database.host"),
+		Port:           viper.GetInt("database.port"),
+		User:           viper.GetString("database.user"),
+		Password:       viper.GetString("database.password"),
+		DBName:         viper.GetString("database.name"),
+		SSLMode:        viper.GetString("database.ssl_mode"),
+		TimeZone:       viper.GetString("database.timezone"),
+		Charset:        viper.GetString("database.charset"),
+		ParseTime:      viper.GetBool("database.parse_time"),
+		Loc:            viper.GetString("database.location"),
+		URI:            viper.GetString("mongodb.uri"),
+		Collection:     viper.GetString("mongodb.collection"),
 		ConnectTimeout: viper.GetDuration("mongodb.connect_timeout"),
 		QueryTimeout:   viper.GetDuration("mongodb.query_timeout"),
+		Logger:         logger,
 	}
 
 	// Validate configuration
@@ -215,19 +759,19 @@ func initializeStorage() (storage.Storage, error) {
 		return nil, fmt.Errorf("failed to create storage: %w", err)
 	}
 
-	log.Printf("✅ Storage initialized: %s", storageType)
+	logger.Info("storage initialized", "type", storageType)
 	return store, nil
 }
 
 // performStorageHealthCheck checks if the storage backend is healthy
-func performStorageHealthCheck(store storage.Storage) error {
+func performStorageHealthCheck(logger *slog.Logger, store storage.Storage) error {
 	if healthChecker, ok := store.(interface{ HealthCheck() error }); ok {
 		if err := healthChecker.HealthCheck(); err != nil {
 			return fmt.Errorf("storage health check failed: %w", err)
 		}
-		log.Println("✅ Storage health check passed")
+		logger.Info("storage health check passed")
 	} else {
-		log.Println("ℹ️ Storage does not support health checks")
+		logger.Info("storage does not support health checks")
 	}
 	return nil
 }
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"GoTask_Management/internal/scheduler"
+
+	"github.com/gorilla/mux"
+)
+
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, s.jobs.Jobs())
+}
+
+func (s *Server) handleRunJob(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	err := s.jobs.Trigger(name)
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		respondWithError(w, http.StatusNotFound, "Job not found")
+		return
+	case errors.Is(err, scheduler.ErrJobRunning):
+		respondWithError(w, http.StatusConflict, "Job is already running")
+		return
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]string{"message": "Job triggered", "job": name})
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"GoTask_Management/internal/scheduler"
+)
+
+// mockJobManager records triggered jobs and reports a fixed status list
+type mockJobManager struct {
+	statuses  []scheduler.JobStatus
+	running   map[string]bool
+	triggered []string
+}
+
+func (m *mockJobManager) Jobs() []scheduler.JobStatus {
+	return m.statuses
+}
+
+func (m *mockJobManager) Trigger(name string) error {
+	for _, status := range m.statuses {
+		if status.Name != name {
+			continue
+		}
+		if m.running[name] {
+			return scheduler.ErrJobRunning
+		}
+		m.triggered = append(m.triggered, name)
+		return nil
+	}
+	return scheduler.ErrJobNotFound
+}
+
+func TestAdminJobs(t *testing.T) {
+	jobs := &mockJobManager{
+		statuses: []scheduler.JobStatus{
+			{Name: "backup", Schedule: "0 3 * * *", MaxAttempts: 3},
+			{Name: "task_summary", Schedule: "@every 5m0s", MaxAttempts: 1, Running: true},
+		},
+		running: map[string]bool{"task_summary": true},
+	}
+	server := NewServer(NewMockTaskService(), 8080, WithAdminJobs(jobs))
+
+	serve := func(method, url string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest(method, url, nil))
+		return rr
+	}
+
+	t.Run("lists jobs", func(t *testing.T) {
+		rr := serve("GET", "/api/v1/admin/jobs")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rr.Code)
+		}
+
+		var statuses []scheduler.JobStatus
+		if err := json.Unmarshal(rr.Body.Bytes(), &statuses); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(statuses) != 2 || statuses[0].Name != "backup" || !statuses[1].Running {
+			t.Errorf("Unexpected job list: %+v", statuses)
+		}
+	})
+
+	t.Run("triggers a job", func(t *testing.T) {
+		rr := serve("POST", "/api/v1/admin/jobs/backup/run")
+		if rr.Code != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d", rr.Code)
+		}
+		if len(jobs.triggered) != 1 || jobs.triggered[0] != "backup" {
+			t.Errorf("Expected backup to be triggered, got %v", jobs.triggered)
+		}
+	})
+
+	t.Run("rejects unknown and running jobs", func(t *testing.T) {
+		if rr := serve("POST", "/api/v1/admin/jobs/missing/run"); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+		if rr := serve("POST", "/api/v1/admin/jobs/task_summary/run"); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", rr.Code)
+		}
+	})
+}
+
+func TestAdminJobs_Disabled(t *testing.T) {
+	server := NewServer(NewMockTaskService(), 8080)
+
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/admin/jobs", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected admin routes to be absent, got status %d", rr.Code)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/task"
+)
+
+// DefaultBatchMaxItems is the largest batch accepted when BatchConfig.MaxItems is not set
+const DefaultBatchMaxItems = 1000
+
+// BatchConfig configures the batch endpoint
+type BatchConfig struct {
+	// MaxItems limits the items in one request. Default 1000.
+	MaxItems int
+}
+
+// BatchRequest is the body of POST /api/v1/tasks:batch
+type BatchRequest struct {
+	// Atomic applies every item or, when one fails, none
+	Atomic bool               `json:"atomic"`
+	Items  []BatchItemRequest `json:"items"`
+}
+
+// BatchItemRequest is one change in a batch. Create needs a title; update
+// and delete need an id, and apply only at version when it is given.
+type BatchItemRequest struct {
+	Op      string     `json:"op"`
+	ID      string     `json:"id,omitempty"`
+	Version *int64     `json:"version,omitempty"`
+	Title   *string    `json:"title,omitempty"`
+	Done    *bool      `json:"done,omitempty"`
+	DueDate *time.Time `json:"due_date,omitempty"`
+}
+
+// BatchResponse reports the outcome of every item, in request order
+type BatchResponse struct {
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []BatchItemResponse `json:"results"`
+}
+
+// BatchItemResponse is the outcome of one item, with the status code the
+// single-task endpoint would have returned
+type BatchItemResponse struct {
+	Status int          `json:"status"`
+	Task   *models.Task `json:"task,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// handleBatch applies a batch of creates, updates and deletes. The response
+// is 200 when every item succeeded and 207 when some failed. A failed atomic
+// batch is answered with the status of the item that failed.
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.Items) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Batch has no items")
+		return
+	}
+	maxItems := s.batchConfig.MaxItems
+	if maxItems <= 0 {
+		maxItems = DefaultBatchMaxItems
+	}
+	if len(req.Items) > maxItems {
+		respondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Batch exceeds %d items", maxItems))
+		return
+	}
+
+	items := make([]task.BatchItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = task.BatchItem{
+			Op:      task.BatchOp(item.Op),
+			ID:      item.ID,
+			Version: item.Version,
+			Changes: task.Changes{Title: item.Title, Done: item.Done, DueDate: item.DueDate},
+		}
+	}
+
+	results, err := s.batch.ApplyBatch(r.Context(), items, req.Atomic)
+
+	response := BatchResponse{Results: make([]BatchItemResponse, len(results))}
+	for i, result := range results {
+		if result.Err != nil {
+			response.Failed++
+			response.Results[i] = BatchItemResponse{Status: batchErrorStatus(result.Err), Error: result.Err.Error()}
+			continue
+		}
+		response.Succeeded++
+		status := http.StatusOK
+		if items[i].Op == task.BatchCreate {
+			status = http.StatusCreated
+		}
+		response.Results[i] = BatchItemResponse{Status: status, Task: result.Task}
+	}
+
+	code := http.StatusOK
+	var batchErr *storage.BatchError
+	switch {
+	case errors.As(err, &batchErr):
+		code = batchErrorStatus(batchErr.Err)
+	case errors.Is(err, storage.ErrTxUnsupported):
+		code = http.StatusNotImplemented
+	case err != nil:
+		code = http.StatusInternalServerError
+	case response.Failed > 0:
+		code = http.StatusMultiStatus
+	}
+	respondWithJSON(w, code, response)
+}
+
+// batchErrorStatus maps an item error to an HTTP status code
+func batchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, task.ErrInvalidBatchItem):
+		return http.StatusBadRequest
+	case errors.Is(err, task.ErrTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, task.ErrVersionConflict):
+		return http.StatusConflict
+	case errors.Is(err, task.ErrBatchAborted), errors.Is(err, task.ErrEarlierItemFailed):
+		return http.StatusFailedDependency
+	default:
+		return http.StatusInternalServerError
+	}
+}
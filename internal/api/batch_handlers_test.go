@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/task"
+)
+
+func TestBatch(t *testing.T) {
+	service := NewMockTaskService()
+	server := NewServer(service, 8080, WithBatch(service, BatchConfig{MaxItems: 3}))
+
+	post := func(body string) (*httptest.ResponseRecorder, BatchResponse) {
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/tasks:batch", bytes.NewBufferString(body)))
+		var response BatchResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+	statuses := func(response BatchResponse) []int {
+		var codes []int
+		for _, result := range response.Results {
+			codes = append(codes, result.Status)
+		}
+		return codes
+	}
+
+	t.Run("applies every item", func(t *testing.T) {
+		service.Reset()
+		service.AddTask(NewTestHelper(t).CreateSampleTask("t1", "Existing"))
+
+		rr, response := post(`{"items": [
+			{"op": "create", "title": "Created"},
+			{"op": "update", "id": "t1", "version": 0, "done": true},
+			{"op": "delete", "id": "t1"}
+		]}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body)
+		}
+		if got := statuses(response); len(got) != 3 || got[0] != 201 || got[1] != 200 || got[2] != 200 {
+			t.Errorf("Expected 201, 200, 200, got %v", got)
+		}
+		if response.Succeeded != 3 || response.Results[0].Task.Title != "Created" || !response.Results[1].Task.Done {
+			t.Errorf("Unexpected response %+v", response)
+		}
+		if tasks, _ := service.ListTasks(t.Context(), ""); len(tasks) != 1 {
+			t.Errorf("Expected only the created task, got %d tasks", len(tasks))
+		}
+	})
+
+	t.Run("reports failed items with 207", func(t *testing.T) {
+		service.Reset()
+		rr, response := post(`{"items": [
+			{"op": "create", "title": "Created"},
+			{"op": "create", "title": ""},
+			{"op": "delete", "id": "missing"}
+		]}`)
+		if rr.Code != http.StatusMultiStatus {
+			t.Fatalf("Expected status 207, got %d: %s", rr.Code, rr.Body)
+		}
+		if got := statuses(response); got[0] != 201 || got[1] != 400 || got[2] != 404 {
+			t.Errorf("Expected 201, 400, 404, got %v", got)
+		}
+		if response.Succeeded != 1 || response.Failed != 2 || response.Results[2].Error == "" {
+			t.Errorf("Unexpected response %+v", response)
+		}
+	})
+
+	t.Run("atomic batch fails as a whole", func(t *testing.T) {
+		service.Reset()
+		service.AddTask(NewTestHelper(t).CreateSampleTask("t1", "Existing"))
+
+		rr, response := post(`{"atomic": true, "items": [
+			{"op": "create", "title": "Rolled back"},
+			{"op": "update", "id": "t1", "version": 5, "title": "Stale"}
+		]}`)
+		if rr.Code != http.StatusConflict {
+			t.Fatalf("Expected status 409, got %d: %s", rr.Code, rr.Body)
+		}
+		if got := statuses(response); got[0] != http.StatusFailedDependency || got[1] != http.StatusConflict {
+			t.Errorf("Expected 424, 409, got %v", got)
+		}
+		if tasks, _ := service.ListTasks(t.Context(), ""); len(tasks) != 1 || tasks[0].Title != "Existing" {
+			t.Errorf("Expected the unchanged task only, got %+v", tasks)
+		}
+	})
+
+	t.Run("atomic batch without transactions is not implemented", func(t *testing.T) {
+		// Embedding only the Storage interface hides WithTx
+		plain := task.NewService(struct{ storage.Storage }{storage.NewMemoryStorage()})
+		server := NewServer(plain, 8080, WithBatch(plain, BatchConfig{}))
+
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/tasks:batch",
+			bytes.NewBufferString(`{"atomic": true, "items": [{"op": "create", "title": "Created"}]}`)))
+		if rr.Code != http.StatusNotImplemented {
+			t.Fatalf("Expected status 501, got %d: %s", rr.Code, rr.Body)
+		}
+		if tasks, _ := plain.ListTasks(t.Context(), ""); len(tasks) != 0 {
+			t.Errorf("Expected nothing to be applied, got %d tasks", len(tasks))
+		}
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			body string
+			code int
+		}{
+			"malformed": {`{"items": [`, http.StatusBadRequest},
+			"empty":     {`{"items": []}`, http.StatusBadRequest},
+			"too large": {`{"items": [{"op": "delete", "id": "1"}, {"op": "delete", "id": "2"}, {"op": "delete", "id": "3"}, {"op": "delete", "id": "4"}]}`, http.StatusRequestEntityTooLarge},
+		} {
+			if rr, _ := post(tc.body); rr.Code != tc.code {
+				t.Errorf("%s: expected status %d, got %d", name, tc.code, rr.Code)
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		NewServer(service, 8080).router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/tasks:batch", bytes.NewBufferString(`{}`)))
+		if rr.Code == http.StatusOK || rr.Code == http.StatusMultiStatus {
+			t.Errorf("Expected the endpoint to be missing, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig mirrors the api.cors block of the configuration file
+type CORSConfig struct {
+	Enabled          bool
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int // seconds
+}
+
+// Validate rejects configurations that browsers would refuse or that would be unsafe
+func (c CORSConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.AllowCredentials && containsString(c.AllowedOrigins, "*") {
+		return fmt.Errorf("cors: wildcard origin cannot be combined with credentials")
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("cors: max_age must not be negative")
+	}
+	return nil
+}
+
+// corsMiddleware adds CORS headers to matched routes and answers preflight requests.
+// Preflight requests are routed to handlePreflight through a catch-all OPTIONS route
+// so that the middleware runs for them as well.
+func corsMiddleware(config CORSConfig) func(http.Handler) http.Handler {
+	allowAnyOrigin := containsString(config.AllowedOrigins, "*")
+	allowAnyHeader := containsString(config.AllowedHeaders, "*")
+	methods := strings.Join(upperAll(config.AllowedMethods), ", ")
+	headers := strings.Join(config.AllowedHeaders, ", ")
+
+	originAllowed := func(origin string) bool {
+		if allowAnyOrigin {
+			return true
+		}
+		for _, allowed := range config.AllowedOrigins {
+			if strings.EqualFold(allowed, origin) {
+				return true
+			}
+		}
+		return false
+	}
+
+	methodAllowed := func(method string) bool {
+		if method == http.MethodOptions {
+			return true
+		}
+		for _, allowed := range config.AllowedMethods {
+			if strings.EqualFold(allowed, method) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			w.Header().Add("Vary", "Origin")
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" || !originAllowed(origin) {
+				if preflight {
+					// Answer without CORS headers so the browser blocks the actual request
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowAnyOrigin && !config.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if config.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !methodAllowed(r.Header.Get("Access-Control-Request-Method")) {
+				w.Header().Del("Access-Control-Allow-Origin")
+				w.Header().Del("Access-Control-Allow-Credentials")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			if allowAnyHeader {
+				if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+					w.Header().Set("Access-Control-Allow-Headers", requested)
+				}
+			} else if headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			if config.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// handlePreflight is the target of the catch-all OPTIONS route. The CORS middleware
+// answers real preflight requests before this handler is reached.
+func (s *Server) handlePreflight(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func upperAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToUpper(v)
+	}
+	return out
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newCORSTestServer(config CORSConfig) *Server {
+	config.Enabled = true
+	return NewServer(NewMockTaskService(), 8080, WithCORS(config))
+}
+
+func TestCORSConfig_Validate(t *testing.T) {
+	t.Run("rejects wildcard with credentials", func(t *testing.T) {
+		config := CORSConfig{Enabled: true, AllowedOrigins: []string{"*"}, AllowCredentials: true}
+		if err := config.Validate(); err == nil {
+			t.Error("Expected error for wildcard origin with credentials")
+		}
+	})
+
+	t.Run("accepts explicit origins with credentials", func(t *testing.T) {
+		config := CORSConfig{Enabled: true, AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}
+		if err := config.Validate(); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("ignores disabled configuration", func(t *testing.T) {
+		config := CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+		if err := config.Validate(); err != nil {
+			t.Errorf("Expected no error for disabled config, got %v", err)
+		}
+	})
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	t.Run("answers preflight for allowed origin", func(t *testing.T) {
+		server := newCORSTestServer(CORSConfig{
+			AllowedOrigins: []string{"https://dashboard.example.com"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+			AllowedHeaders: []string{"Content-Type", "Authorization"},
+			MaxAge:         600,
+		})
+
+		req := httptest.NewRequest("OPTIONS", "/api/v1/tasks/123", nil)
+		req.Header.Set("Origin", "https://dashboard.example.com")
+		req.Header.Set("Access-Control-Request-Method", "PUT")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://dashboard.example.com" {
+			t.Errorf("Expected origin to be echoed, got '%s'", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, "PUT") {
+			t.Errorf("Expected allowed methods to contain PUT, got '%s'", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type, Authorization" {
+			t.Errorf("Unexpected allowed headers '%s'", got)
+		}
+		if got := rr.Header().Get("Access-Control-Max-Age"); got != "600" {
+			t.Errorf("Expected max age 600, got '%s'", got)
+		}
+		if !containsString(rr.Header().Values("Vary"), "Origin") {
+			t.Errorf("Expected Vary: Origin, got %v", rr.Header().Values("Vary"))
+		}
+	})
+
+	t.Run("rejects preflight for unknown origin", func(t *testing.T) {
+		server := newCORSTestServer(CORSConfig{
+			AllowedOrigins: []string{"https://dashboard.example.com"},
+			AllowedMethods: []string{"GET"},
+		})
+
+		req := httptest.NewRequest("OPTIONS", "/api/v1/tasks", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		req.Header.Set("Access-Control-Request-Method", "GET")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Expected no allow-origin header, got '%s'", got)
+		}
+	})
+
+	t.Run("rejects preflight for disallowed method", func(t *testing.T) {
+		server := newCORSTestServer(CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET"},
+		})
+
+		req := httptest.NewRequest("OPTIONS", "/api/v1/tasks", nil)
+		req.Header.Set("Origin", "https://dashboard.example.com")
+		req.Header.Set("Access-Control-Request-Method", "DELETE")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Expected no allow-origin header, got '%s'", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); got != "" {
+			t.Errorf("Expected no allow-methods header, got '%s'", got)
+		}
+	})
+
+	t.Run("uses wildcard for simple requests without credentials", func(t *testing.T) {
+		server := newCORSTestServer(CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET"},
+		})
+
+		req := httptest.NewRequest("GET", "/api/v1/tasks", nil)
+		req.Header.Set("Origin", "https://dashboard.example.com")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("Expected wildcard allow-origin, got '%s'", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Errorf("Expected no credentials header, got '%s'", got)
+		}
+	})
+
+	t.Run("echoes origin when credentials are allowed", func(t *testing.T) {
+		server := newCORSTestServer(CORSConfig{
+			AllowedOrigins:   []string{"https://dashboard.example.com"},
+			AllowedMethods:   []string{"GET"},
+			AllowCredentials: true,
+		})
+
+		req := httptest.NewRequest("GET", "/api/v1/tasks", nil)
+		req.Header.Set("Origin", "https://dashboard.example.com")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://dashboard.example.com" {
+			t.Errorf("Expected echoed origin, got '%s'", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+			t.Errorf("Expected credentials header, got '%s'", got)
+		}
+	})
+
+	t.Run("reflects requested headers for wildcard headers", func(t *testing.T) {
+		server := newCORSTestServer(CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"POST"},
+			AllowedHeaders: []string{"*"},
+		})
+
+		req := httptest.NewRequest("OPTIONS", "/api/v1/tasks", nil)
+		req.Header.Set("Origin", "https://dashboard.example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "X-Custom, Content-Type")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Access-Control-Allow-Headers"); got != "X-Custom, Content-Type" {
+			t.Errorf("Expected requested headers to be reflected, got '%s'", got)
+		}
+	})
+
+	t.Run("does not register OPTIONS routes when disabled", func(t *testing.T) {
+		server := NewServer(NewMockTaskService(), 8080)
+
+		req := httptest.NewRequest("OPTIONS", "/api/v1/tasks", nil)
+		req.Header.Set("Origin", "https://dashboard.example.com")
+		req.Header.Set("Access-Control-Request-Method", "GET")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Expected no CORS headers when disabled, got '%s'", got)
+		}
+	})
+}
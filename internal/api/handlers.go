@@ -2,11 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"html"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/version"
+
 	"github.com/gorilla/mux"
 )
 
@@ -22,12 +28,42 @@ type ErrorResponse struct {
 
 func (s *Server) handleGetTasks(w http.ResponseWriter, r *http.Request) {
 	status := r.URL.Query().Get("status")
+	if q := r.URL.Query().Get("q"); q != "" {
+		s.handleQueryTasks(w, r, q, status)
+		return
+	}
+
+	tasks, err := s.taskService.ListTasks(r.Context(), status)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tasks)
+}
+
+// handleQueryTasks lists the tasks matching a query, and status when it is
+// given as well
+func (s *Server) handleQueryTasks(w http.ResponseWriter, r *http.Request, q, status string) {
+	parsed, err := query.Parse(q)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter, err := parsed.WithStatus(status).Compile(time.Now())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	tasks, err := s.taskService.ListTasks(status)
+	tasks, err := s.taskService.FindTasks(r.Context(), filter)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if tasks == nil {
+		tasks = []*models.Task{}
+	}
 
 	respondWithJSON(w, http.StatusOK, tasks)
 }
@@ -44,7 +80,7 @@ func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := s.taskService.CreateTask(req.Title, req.DueDate)
+	task, err := s.taskService.CreateTask(r.Context(), req.Title, req.DueDate)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -57,7 +93,7 @@ func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	task, err := s.taskService.GetTask(id)
+	task, err := s.taskService.GetTask(r.Context(), id)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Task not found")
 		return
@@ -76,7 +112,7 @@ func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := s.taskService.UpdateTask(id, req.Title, req.Done, req.DueDate)
+	task, err := s.taskService.UpdateTask(r.Context(), id, req.Title, req.Done, req.DueDate)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -89,7 +125,7 @@ func (s *Server) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := s.taskService.DeleteTask(id); err != nil {
+	if err := s.taskService.DeleteTask(r.Context(), id); err != nil {
 		respondWithError(w, http.StatusNotFound, "Task not found")
 		return
 	}
@@ -107,7 +143,7 @@ func (s *Server) handleGetDueTasks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	tasks, err := s.taskService.GetDueTasks(days)
+	tasks, err := s.taskService.GetDueTasks(r.Context(), days)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -116,40 +152,100 @@ func (s *Server) handleGetDueTasks(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, tasks)
 }
 
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	startTime := time.Now()
+// maxSearchLimit bounds the limit parameter of a search
+const maxSearchLimit = 100
+
+// SearchResponse is the body of GET /api/v1/tasks/search
+type SearchResponse struct {
+	Query   string                 `json:"query"`
+	Results []SearchResultResponse `json:"results"`
+}
+
+// SearchResultResponse is a matching task with its relevance and its title
+// HTML-escaped with matches in <mark> elements
+type SearchResultResponse struct {
+	Task      *models.Task        `json:"task"`
+	Score     float64             `json:"score"`
+	Highlight string              `json:"highlight"`
+	Matches   []storage.TextRange `json:"matches"`
+}
 
-	response := map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": startTime.UTC(),
-		"service":   "gotask-api",
-		"version":   "1.0.0", // This could be injected from build
-	}
-
-	// Check storage health if available
-	if healthChecker, ok := s.taskService.(interface{ HealthCheck() error }); ok {
-		if err := healthChecker.HealthCheck(); err != nil {
-			response["status"] = "unhealthy"
-			response["storage"] = map[string]interface{}{
-				"status": "unhealthy",
-				"error":  err.Error(),
-			}
-			response["response_time_ms"] = time.Since(startTime).Milliseconds()
-			respondWithJSON(w, http.StatusServiceUnavailable, response)
+// handleSearchTasks searches task titles for any word of the q parameter
+func (s *Server) handleSearchTasks(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		respondWithError(w, http.StatusBadRequest, "Query parameter q is required")
+		return
+	}
+
+	limit := storage.DefaultSearchLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
 			return
 		}
-		response["storage"] = map[string]interface{}{
-			"status": "healthy",
+		limit = min(l, maxSearchLimit)
+	}
+
+	results, err := s.taskService.SearchTasks(r.Context(), query, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := SearchResponse{Query: query, Results: make([]SearchResultResponse, len(results))}
+	for i, result := range results {
+		matches := result.Matches
+		if matches == nil {
+			matches = []storage.TextRange{}
+		}
+		response.Results[i] = SearchResultResponse{
+			Task:      result.Task,
+			Score:     result.Score,
+			Highlight: result.Highlight("<mark>", "</mark>", html.EscapeString),
+			Matches:   matches,
 		}
 	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// handleLiveness reports that the process is up without touching dependencies
+func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	response := s.healthInfo("alive")
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// handleReadiness runs the readiness checks and reports 503 if any fail
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	report := s.checker.Run(r.Context())
 
-	// Add response time
+	response := s.healthInfo(report.Status)
+	if s.health.Detailed {
+		response["checks"] = report.Checks
+	}
 	response["response_time_ms"] = time.Since(startTime).Milliseconds()
 
-	// Add uptime if available (this would need to be tracked globally)
-	response["uptime"] = "unknown" // Placeholder for actual uptime tracking
+	code := http.StatusOK
+	if !report.Healthy() {
+		code = http.StatusServiceUnavailable
+	}
+	respondWithJSON(w, code, response)
+}
 
-	respondWithJSON(w, http.StatusOK, response)
+func (s *Server) healthInfo(status string) map[string]interface{} {
+	uptime := time.Since(s.startedAt)
+	return map[string]interface{}{
+		"status":         status,
+		"timestamp":      time.Now().UTC(),
+		"service":        "gotask-api",
+		"version":        version.Version,
+		"commit":         version.GitCommit,
+		"build_time":     version.BuildTime,
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+	}
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
@@ -1,11 +1,18 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/version"
 )
 
 func TestHandleGetTasks(t *testing.T) {
@@ -80,6 +87,49 @@ func TestHandleGetTasks(t *testing.T) {
 		}
 	})
 
+	t.Run("filters tasks with a query", func(t *testing.T) {
+		tests := []struct {
+			path string
+			want int
+		}{
+			{path: "/api/v1/tasks?q=" + url.QueryEscape(`status:open -"task 3"`), want: 1},
+			{path: "/api/v1/tasks?q=task&status=done", want: 1},
+			{path: "/api/v1/tasks?q=" + url.QueryEscape("due:none task"), want: 3},
+			{path: "/api/v1/tasks?q=missing", want: 0},
+		}
+		for _, tt := range tests {
+			rr := helper.ExecuteRequest(helper.CreateRequest("GET", tt.path, nil))
+			helper.AssertStatusCode(rr, http.StatusOK)
+
+			var responseTasks []*models.Task
+			helper.AssertJSONResponse(rr, &responseTasks)
+			if responseTasks == nil || len(responseTasks) != tt.want {
+				t.Errorf("%s: expected %d tasks, got %v", tt.path, tt.want, responseTasks)
+			}
+		}
+	})
+
+	t.Run("rejects invalid queries", func(t *testing.T) {
+		tests := []struct {
+			path string
+			want string
+		}{
+			{path: "/api/v1/tasks?q=" + url.QueryEscape(`"unterminated`), want: "invalid query at position 0: unterminated quoted string"},
+			{path: "/api/v1/tasks?q=" + url.QueryEscape("task priority>=high"), want: `invalid query at position 5: unknown field "priority"`},
+			{path: "/api/v1/tasks?q=task&status=blocked", want: `invalid query: invalid status "blocked"`},
+		}
+		for _, tt := range tests {
+			rr := helper.ExecuteRequest(helper.CreateRequest("GET", tt.path, nil))
+			helper.AssertStatusCode(rr, http.StatusBadRequest)
+
+			var response ErrorResponse
+			helper.AssertJSONResponse(rr, &response)
+			if !strings.HasPrefix(response.Error, tt.want) {
+				t.Errorf("%s: expected error %q, got %q", tt.path, tt.want, response.Error)
+			}
+		}
+	})
+
 	t.Run("handles service error", func(t *testing.T) {
 		helper.GetMockService().SetError(true, "service error")
 
@@ -412,6 +462,85 @@ func TestHandleGetDueTasks(t *testing.T) {
 	})
 }
 
+func TestHandleSearchTasks(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.GetMockService().Reset()
+
+	helper.GetMockService().AddTask(helper.CreateSampleTask("s1", "Write <release> notes"))
+	helper.GetMockService().AddTask(helper.CreateSampleTask("s2", "Release release checklist"))
+	helper.GetMockService().AddTask(helper.CreateSampleTask("s3", "Water the plants"))
+
+	t.Run("ranks and highlights matches", func(t *testing.T) {
+		req := helper.CreateRequest("GET", "/api/v1/tasks/search?q=release", nil)
+		rr := helper.ExecuteRequest(req)
+
+		helper.AssertStatusCode(rr, http.StatusOK)
+
+		var response SearchResponse
+		helper.AssertJSONResponse(rr, &response)
+
+		if response.Query != "release" || len(response.Results) != 2 {
+			t.Fatalf("Expected 2 results for release, got %+v", response)
+		}
+		if response.Results[0].Task.ID != "s2" || response.Results[0].Score < response.Results[1].Score {
+			t.Errorf("Expected s2 to rank first, got %s", response.Results[0].Task.ID)
+		}
+		if got := response.Results[1].Highlight; got != "Write &lt;<mark>release</mark>&gt; notes" {
+			t.Errorf("Unexpected highlight %q", got)
+		}
+		if matches := response.Results[1].Matches; len(matches) != 1 || matches[0].Start != 7 || matches[0].End != 14 {
+			t.Errorf("Unexpected matches %+v", matches)
+		}
+	})
+
+	t.Run("applies the limit", func(t *testing.T) {
+		req := helper.CreateRequest("GET", "/api/v1/tasks/search?q=release+plants&limit=1", nil)
+		rr := helper.ExecuteRequest(req)
+
+		helper.AssertStatusCode(rr, http.StatusOK)
+
+		var response SearchResponse
+		helper.AssertJSONResponse(rr, &response)
+		if len(response.Results) != 1 {
+			t.Errorf("Expected 1 result, got %d", len(response.Results))
+		}
+	})
+
+	t.Run("returns an empty list without matches", func(t *testing.T) {
+		req := helper.CreateRequest("GET", "/api/v1/tasks/search?q=groceries", nil)
+		rr := helper.ExecuteRequest(req)
+
+		helper.AssertStatusCode(rr, http.StatusOK)
+		if body := rr.Body.String(); body != `{"query":"groceries","results":[]}` {
+			t.Errorf("Unexpected body %q", body)
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, path := range []string{
+			"/api/v1/tasks/search",
+			"/api/v1/tasks/search?q=+",
+			"/api/v1/tasks/search?q=release&limit=0",
+			"/api/v1/tasks/search?q=release&limit=many",
+		} {
+			rr := helper.ExecuteRequest(helper.CreateRequest("GET", path, nil))
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", path, rr.Code)
+			}
+		}
+	})
+
+	t.Run("handles service error", func(t *testing.T) {
+		helper.GetMockService().SetError(true, "service error")
+		defer helper.GetMockService().SetError(false, "")
+
+		rr := helper.ExecuteRequest(helper.CreateRequest("GET", "/api/v1/tasks/search?q=release", nil))
+
+		helper.AssertStatusCode(rr, http.StatusInternalServerError)
+		helper.AssertErrorResponse(rr, "service error")
+	})
+}
+
 func TestHandleHealth(t *testing.T) {
 	helper := NewTestHelper(t)
 
@@ -422,11 +551,122 @@ func TestHandleHealth(t *testing.T) {
 		helper.AssertStatusCode(rr, http.StatusOK)
 		helper.AssertContentType(rr, "application/json")
 
-		var response map[string]string
+		var response map[string]interface{}
 		helper.AssertJSONResponse(rr, &response)
 
 		if response["status"] != "healthy" {
-			t.Errorf("Expected status 'healthy', got '%s'", response["status"])
+			t.Errorf("Expected status 'healthy', got '%v'", response["status"])
+		}
+		if response["version"] != version.Version {
+			t.Errorf("Expected version %q, got '%v'", version.Version, response["version"])
+		}
+		if _, ok := response["uptime_seconds"].(float64); !ok {
+			t.Errorf("Expected numeric uptime_seconds, got %v", response["uptime_seconds"])
+		}
+	})
+}
+
+func TestHandleLiveness(t *testing.T) {
+	failing := func(ctx context.Context) error { return errors.New("down") }
+	server := NewServer(NewMockTaskService(), 8080, WithReadinessCheck("storage", failing))
+
+	req := httptest.NewRequest("GET", "/health/live", nil)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected liveness to ignore failing checks, got %d", rr.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response["status"] != "alive" {
+		t.Errorf("Expected status 'alive', got '%v'", response["status"])
+	}
+	if _, ok := response["checks"]; ok {
+		t.Error("Expected liveness response without checks")
+	}
+}
+
+func TestHandleReadiness(t *testing.T) {
+	healthy := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("disk full") }
+
+	get := func(t *testing.T, server *Server) (int, map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/health/ready", nil)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		var response map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return rr.Code, response
+	}
+
+	t.Run("reports each component", func(t *testing.T) {
+		server := NewServer(NewMockTaskService(), 8080,
+			WithReadinessCheck("storage", healthy),
+			WithReadinessCheck("disk", failing),
+		)
+
+		code, response := get(t, server)
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", code)
+		}
+		if response["status"] != "unhealthy" {
+			t.Errorf("Expected status 'unhealthy', got '%v'", response["status"])
+		}
+
+		checks, ok := response["checks"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("Expected checks in response, got %v", response)
+		}
+		disk := checks["disk"].(map[string]interface{})
+		if disk["status"] != "unhealthy" || disk["error"] != "disk full" {
+			t.Errorf("Unexpected disk check result: %v", disk)
+		}
+		storage := checks["storage"].(map[string]interface{})
+		if storage["status"] != "healthy" {
+			t.Errorf("Unexpected storage check result: %v", storage)
+		}
+	})
+
+	t.Run("times out slow checks", func(t *testing.T) {
+		slow := func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}
+		server := NewServer(NewMockTaskService(), 8080,
+			WithHealth(HealthConfig{Path: "/health", Detailed: true, Timeout: 20 * time.Millisecond}),
+			WithReadinessCheck("storage", slow),
+		)
+
+		start := time.Now()
+		code, _ := get(t, server)
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", code)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Expected readiness to return after the timeout, took %s", elapsed)
+		}
+	})
+
+	t.Run("omits components when not detailed", func(t *testing.T) {
+		server := NewServer(NewMockTaskService(), 8080,
+			WithHealth(HealthConfig{Path: "/health", Detailed: false}),
+			WithReadinessCheck("storage", healthy),
+		)
+
+		code, response := get(t, server)
+		if code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", code)
+		}
+		if _, ok := response["checks"]; ok {
+			t.Errorf("Expected no checks in response, got %v", response["checks"])
 		}
 	})
 }
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"GoTask_Management/internal/storage"
+)
+
+const (
+	// IdempotencyKeyHeader names the request header holding an idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on responses replayed from an earlier request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// DefaultIdempotencyTTL is how long responses are kept when IdempotencyConfig.TTL is not set
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// maxIdempotencyKeyLength bounds keys to what every backend can index
+	maxIdempotencyKeyLength = 255
+	// idempotencyLockTimeout is how long a key stays reserved by a request
+	// that has not finished, e.g. because the server stopped, before a retry
+	// may run it again
+	idempotencyLockTimeout = time.Minute
+)
+
+// IdempotencyConfig configures Idempotency-Key handling
+type IdempotencyConfig struct {
+	// TTL is how long a response is replayed for retries. Default 24h.
+	TTL time.Duration
+}
+
+// idempotencyMiddleware makes POST, PUT, PATCH and DELETE requests that
+// carry an Idempotency-Key safe to retry. The first request with a key
+// reserves it and runs; its response is stored and replayed for later
+// requests with the same key and the same method, URL and body. A key reused
+// for a different request is rejected with 422, and one whose request is
+// still running with 409. Server errors are not stored, so the request can
+// be retried.
+func idempotencyMiddleware(store storage.IdempotencyStore, config IdempotencyConfig, logger *slog.Logger) func(http.Handler) http.Handler {
+	ttl := config.TTL
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || !isMutatingMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				respondWithError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+			record := &storage.IdempotencyRecord{
+				Key:         key,
+				RequestHash: requestHash(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(idempotencyLockTimeout),
+			}
+			err = store.CreateIdempotencyRecord(record)
+			if errors.Is(err, storage.ErrIdempotencyKeyExists) {
+				replayIdempotentResponse(w, r, store, record, logger)
+				return
+			}
+			if err != nil {
+				logger.ErrorContext(r.Context(), "failed to reserve idempotency key", "error", err)
+				respondWithError(w, http.StatusInternalServerError, "Failed to process Idempotency-Key")
+				return
+			}
+
+			recorder := &responseRecorder{statusRecorder: newStatusRecorder(w)}
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status >= http.StatusInternalServerError {
+				if err := store.DeleteIdempotencyRecord(key); err != nil {
+					logger.WarnContext(r.Context(), "failed to release idempotency key", "error", err)
+				}
+				return
+			}
+			record.StatusCode = recorder.status
+			record.ContentType = recorder.Header().Get("Content-Type")
+			record.Body = recorder.body.Bytes()
+			record.ExpiresAt = time.Now().Add(ttl)
+			if err := store.SaveIdempotencyRecord(record); err != nil {
+				logger.WarnContext(r.Context(), "failed to save idempotent response", "error", err)
+			}
+		})
+	}
+}
+
+// replayIdempotentResponse answers a request whose key is already held by record's key
+func replayIdempotentResponse(w http.ResponseWriter, r *http.Request, store storage.IdempotencyStore, record *storage.IdempotencyRecord, logger *slog.Logger) {
+	existing, err := store.GetIdempotencyRecord(record.Key)
+	switch {
+	case errors.Is(err, storage.ErrIdempotencyRecordNotFound):
+		// Released by a failed request, or expired, since the key was reserved
+		respondWithError(w, http.StatusConflict, "A request with this Idempotency-Key is in progress; retry later")
+	case err != nil:
+		logger.ErrorContext(r.Context(), "failed to read idempotency record", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to process Idempotency-Key")
+	case existing.RequestHash != record.RequestHash:
+		respondWithError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+	case existing.StatusCode == 0:
+		respondWithError(w, http.StatusConflict, "A request with this Idempotency-Key is in progress; retry later")
+	default:
+		if existing.ContentType != "" {
+			w.Header().Set("Content-Type", existing.ContentType)
+		}
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.WriteHeader(existing.StatusCode)
+		w.Write(existing.Body)
+	}
+}
+
+// requestHash identifies a request by method, URL and body
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	*statusRecorder
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	n, err := r.statusRecorder.Write(b)
+	r.body.Write(b[:n])
+	return n, err
+}
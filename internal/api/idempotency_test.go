@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/storage"
+)
+
+func TestIdempotency(t *testing.T) {
+	service := NewMockTaskService()
+	store := storage.NewMemoryIdempotencyStore()
+	server := NewServer(service, 8080, WithIdempotency(store, IdempotencyConfig{TTL: time.Hour}))
+
+	send := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+	countTasks := func(t *testing.T) int {
+		tasks, err := service.ListTasks(t.Context(), "")
+		if err != nil {
+			t.Fatalf("Failed to list tasks: %v", err)
+		}
+		return len(tasks)
+	}
+
+	t.Run("replays a retried request", func(t *testing.T) {
+		service.Reset()
+		first := send("POST", "/api/v1/tasks", "create-1", `{"title": "Once"}`)
+		if first.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", first.Code, first.Body)
+		}
+
+		retry := send("POST", "/api/v1/tasks", "create-1", `{"title": "Once"}`)
+		if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+			t.Errorf("Expected the first response, got %d: %s", retry.Code, retry.Body)
+		}
+		if retry.Header().Get(IdempotentReplayedHeader) != "true" || first.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Error("Expected only the retry to be marked as replayed")
+		}
+		if retry.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Expected the stored content type, got %q", retry.Header().Get("Content-Type"))
+		}
+		if n := countTasks(t); n != 1 {
+			t.Errorf("Expected one task, got %d", n)
+		}
+	})
+
+	t.Run("rejects a key reused for a different request", func(t *testing.T) {
+		service.Reset()
+		send("POST", "/api/v1/tasks", "create-2", `{"title": "First"}`)
+
+		rr := send("POST", "/api/v1/tasks", "create-2", `{"title": "Second"}`)
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", rr.Code)
+		}
+		rr = send("DELETE", "/api/v1/tasks/1", "create-2", `{"title": "First"}`)
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422 for another path, got %d", rr.Code)
+		}
+		if n := countTasks(t); n != 1 {
+			t.Errorf("Expected one task, got %d", n)
+		}
+	})
+
+	t.Run("stores client errors", func(t *testing.T) {
+		service.Reset()
+		first := send("DELETE", "/api/v1/tasks/missing", "delete-1", "")
+		if first.Code != http.StatusNotFound {
+			t.Fatalf("Expected status 404, got %d", first.Code)
+		}
+		if rr := send("DELETE", "/api/v1/tasks/missing", "delete-1", ""); rr.Code != http.StatusNotFound || rr.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Errorf("Expected a replayed 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("releases the key after a server error", func(t *testing.T) {
+		service.Reset()
+		service.SetError(true, "storage unavailable")
+		if rr := send("POST", "/api/v1/tasks", "create-3", `{"title": "Retry me"}`); rr.Code != http.StatusInternalServerError {
+			t.Fatalf("Expected status 500, got %d", rr.Code)
+		}
+
+		service.SetError(false, "")
+		rr := send("POST", "/api/v1/tasks", "create-3", `{"title": "Retry me"}`)
+		if rr.Code != http.StatusCreated || rr.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Errorf("Expected the retry to run, got %d", rr.Code)
+		}
+	})
+
+	t.Run("rejects a retry while the request is in progress", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v1/tasks", nil)
+		body := []byte(`{"title": "Slow"}`)
+		store.CreateIdempotencyRecord(&storage.IdempotencyRecord{
+			Key:         "create-4",
+			RequestHash: requestHash(req, body),
+			CreatedAt:   time.Now(),
+			ExpiresAt:   time.Now().Add(time.Minute),
+		})
+
+		if rr := send("POST", "/api/v1/tasks", "create-4", string(body)); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", rr.Code)
+		}
+	})
+
+	t.Run("ignores requests without a key and reads", func(t *testing.T) {
+		service.Reset()
+		send("POST", "/api/v1/tasks", "", `{"title": "Twice"}`)
+		send("POST", "/api/v1/tasks", "", `{"title": "Twice"}`)
+		if n := countTasks(t); n != 2 {
+			t.Errorf("Expected two tasks, got %d", n)
+		}
+		if rr := send("GET", "/api/v1/tasks", "read-1", ""); rr.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Error("Expected GET not to be replayed")
+		}
+		if _, err := store.GetIdempotencyRecord("read-1"); err == nil {
+			t.Error("Expected no record for a GET")
+		}
+	})
+
+	t.Run("rejects overlong keys", func(t *testing.T) {
+		if rr := send("POST", "/api/v1/tasks", strings.Repeat("k", 256), `{"title": "Long"}`); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rr.Code)
+		}
+	})
+}
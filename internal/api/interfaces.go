@@ -1,18 +1,67 @@
 package api
 
 import (
+	"context"
 	"time"
 
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+	"GoTask_Management/internal/scheduler"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/task"
+	"GoTask_Management/internal/views"
+	"GoTask_Management/internal/webhook"
 )
 
 // TaskService defines the interface for task operations
 type TaskService interface {
-	CreateTask(title string, dueDate *time.Time) (*models.Task, error)
-	ListTasks(status string) ([]*models.Task, error)
-	GetTask(id string) (*models.Task, error)
-	UpdateTask(id string, title string, done bool, dueDate *time.Time) (*models.Task, error)
-	DeleteTask(id string) error
-	GetDueTasks(days int) ([]*models.Task, error)
-	GetTasksSummary() (int, int, int, error)
+	CreateTask(ctx context.Context, title string, dueDate *time.Time) (*models.Task, error)
+	ListTasks(ctx context.Context, status string) ([]*models.Task, error)
+	FindTasks(ctx context.Context, filter *query.Filter) ([]*models.Task, error)
+	GetTask(ctx context.Context, id string) (*models.Task, error)
+	UpdateTask(ctx context.Context, id string, title string, done bool, dueDate *time.Time) (*models.Task, error)
+	DeleteTask(ctx context.Context, id string) error
+	GetDueTasks(ctx context.Context, days int) ([]*models.Task, error)
+	GetTasksSummary(ctx context.Context) (int, int, int, error)
+	SearchTasks(ctx context.Context, query string, limit int) ([]storage.SearchResult, error)
+}
+
+// LiveTaskService defines the task operations used by the WebSocket API, where
+// changes to existing tasks must name the version the client last saw
+type LiveTaskService interface {
+	CreateTask(ctx context.Context, title string, dueDate *time.Time) (*models.Task, error)
+	ListTasks(ctx context.Context, status string) ([]*models.Task, error)
+	GetTask(ctx context.Context, id string) (*models.Task, error)
+	UpdateTaskVersion(ctx context.Context, id string, version int64, changes task.Changes) (*models.Task, error)
+	DeleteTaskVersion(ctx context.Context, id string, version int64) error
+}
+
+// BatchTaskService defines the task operations used by the batch endpoint
+type BatchTaskService interface {
+	ApplyBatch(ctx context.Context, items []task.BatchItem, atomic bool) ([]task.BatchResult, error)
+}
+
+// JobManager defines the interface for inspecting and triggering scheduled jobs
+type JobManager interface {
+	Jobs() []scheduler.JobStatus
+	Trigger(name string) error
+}
+
+// WebhookManager defines the interface for managing webhook subscriptions
+type WebhookManager interface {
+	Subscriptions() ([]webhook.Subscription, error)
+	Subscription(id string) (webhook.Subscription, error)
+	Subscribe(sub webhook.Subscription) (webhook.Subscription, error)
+	Unsubscribe(id string) error
+	Deliveries(subscriptionID string) ([]webhook.Delivery, error)
+	Redeliver(subscriptionID, deliveryID string) (webhook.Delivery, error)
+}
+
+// ViewManager defines the interface for managing saved views
+type ViewManager interface {
+	Views(owner string) ([]views.SavedView, error)
+	View(id string) (views.SavedView, error)
+	Create(view views.SavedView) (views.SavedView, error)
+	Update(view views.SavedView) (views.SavedView, error)
+	Delete(id string) error
 }
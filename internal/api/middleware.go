@@ -1,30 +1,204 @@
 package api
 
 import (
-	"log"
+	"bufio"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
+
+	"GoTask_Management/internal/metrics"
+	"GoTask_Management/internal/tracing"
+
+	"github.com/gorilla/mux"
 )
 
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+// statusRecorder captures the status code and body size written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
 
-		next.ServeHTTP(w, r)
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Hijack lets WebSocket upgrades take over the connection; the WebSocket
+// library asserts http.Hijacker directly rather than using Unwrap
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// secretQueryParams are query parameters carrying credentials, such as the
+// WebSocket access_token, which are kept out of logs and spans
+var secretQueryParams = []string{"access_token"}
+
+// redactedURI returns the request URI of u with the values of secret query
+// parameters replaced
+func redactedURI(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.RequestURI()
+	}
+	query := u.Query()
+	redacted := false
+	for _, name := range secretQueryParams {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u.RequestURI()
+	}
+	clean := *u
+	clean.RawQuery = query.Encode()
+	return clean.RequestURI()
+}
+
+// requestLoggingMiddleware logs one structured record per request, with the level
+// derived from the response status
+func requestLoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := newStatusRecorder(w)
 
-		log.Printf(
-			"%s %s %s %v",
-			r.Method,
-			r.RequestURI,
-			r.RemoteAddr,
-			time.Since(start),
-		)
+			next.ServeHTTP(recorder, r)
+
+			level := slog.LevelInfo
+			switch {
+			case recorder.status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case recorder.status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+
+			logger.LogAttrs(r.Context(), level, "http request",
+				slog.String("method", r.Method),
+				slog.String("uri", redactedURI(r.URL)),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.Int("status", recorder.status),
+				slog.Int("bytes", recorder.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("request_id", tracing.RequestIDFromContext(r.Context())),
+			)
+		})
+	}
+}
+
+// requestIDMiddleware reuses a valid client X-Request-ID or generates one, echoes it
+// in the response and makes it available to handlers through the request context
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(tracing.RequestIDHeader)
+		if !tracing.IsValidRequestID(id) {
+			id = tracing.NewRequestID()
+		}
+
+		w.Header().Set(tracing.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(tracing.ContextWithRequestID(r.Context(), id)))
 	})
 }
 
+// tracingMiddleware starts a root span per request, joining the caller's trace
+// when a W3C traceparent header is present. Spans are named after the route
+// of routes that the request matches.
+func tracingMiddleware(tracer *tracing.Tracer, routes *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := tracing.ContextWithTraceParent(r.Context(), r.Header.Get("traceparent"))
+			route := routeTemplate(routes, r)
+			ctx, span := tracer.Start(ctx, r.Method+" "+route)
+			defer span.End()
+
+			span.SetAttribute("http.method", r.Method)
+			span.SetAttribute("http.route", route)
+			span.SetAttribute("http.target", redactedURI(r.URL))
+			if id := tracing.RequestIDFromContext(ctx); id != "" {
+				span.SetAttribute("request.id", id)
+			}
+			w.Header().Set("traceparent", tracing.TraceParent(span))
+
+			recorder := newStatusRecorder(w)
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			span.SetAttribute("http.status_code", recorder.status)
+			if recorder.status >= http.StatusInternalServerError {
+				span.SetStatus(tracing.StatusError, http.StatusText(recorder.status))
+			}
+		})
+	}
+}
+
 func jsonMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		next.ServeHTTP(w, r)
 	})
 }
+
+// metricsMiddleware records request counts and latencies labelled by the
+// template of the route of routes that the request matches, and status
+func metricsMiddleware(registry *metrics.Registry, routes *mux.Router) func(http.Handler) http.Handler {
+	requests := registry.NewCounterVec(
+		"gotask_http_requests_total",
+		"Total number of HTTP requests by method, route and status.",
+		"method", "route", "status",
+	)
+	duration := registry.NewHistogramVec(
+		"gotask_http_request_duration_seconds",
+		"HTTP request latency in seconds by method, route and status.",
+		metrics.DefaultBuckets,
+		"method", "route", "status",
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := newStatusRecorder(w)
+			route := routeTemplate(routes, r)
+
+			next.ServeHTTP(recorder, r)
+
+			status := strconv.Itoa(recorder.status)
+			requests.Inc(r.Method, route, status)
+			duration.Observe(time.Since(start).Seconds(), r.Method, route, status)
+		})
+	}
+}
+
+// routeTemplate returns the path template of the route of routes matching r,
+// which keeps label cardinality bounded regardless of the IDs in the request
+// path. Requests answered with 404 or 405 are "unmatched".
+func routeTemplate(routes *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+	if routes.Match(r, &match) && match.MatchErr == nil && match.Route != nil {
+		if template, err := match.Route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unmatched"
+}
@@ -2,21 +2,24 @@ package api
 
 import (
 	"bytes"
-	"log"
+	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
+
+	"GoTask_Management/internal/metrics"
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/tracing"
 )
 
 func TestLoggingMiddleware(t *testing.T) {
 	t.Run("logs request details", func(t *testing.T) {
 		// Capture log output
 		var logBuffer bytes.Buffer
-		originalOutput := log.Writer()
-		log.SetOutput(&logBuffer)
-		defer log.SetOutput(originalOutput) // Reset to original
+		logger := slog.New(slog.NewTextHandler(&logBuffer, nil))
 
 		// Create a test handler
 		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -26,7 +29,7 @@ func TestLoggingMiddleware(t *testing.T) {
 		})
 
 		// Wrap with logging middleware
-		wrappedHandler := loggingMiddleware(testHandler)
+		wrappedHandler := requestLoggingMiddleware(logger)(testHandler)
 
 		// Create test request
 		req := httptest.NewRequest("GET", "/test/path", nil)
@@ -65,15 +68,13 @@ func TestLoggingMiddleware(t *testing.T) {
 
 	t.Run("logs different HTTP methods", func(t *testing.T) {
 		var logBuffer bytes.Buffer
-		originalOutput := log.Writer()
-		log.SetOutput(&logBuffer)
-		defer log.SetOutput(originalOutput)
+		logger := slog.New(slog.NewTextHandler(&logBuffer, nil))
 
 		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusCreated)
 		})
 
-		wrappedHandler := loggingMiddleware(testHandler)
+		wrappedHandler := requestLoggingMiddleware(logger)(testHandler)
 
 		// Test POST request
 		req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"test"}`))
@@ -96,15 +97,13 @@ func TestLoggingMiddleware(t *testing.T) {
 
 	t.Run("logs query parameters", func(t *testing.T) {
 		var logBuffer bytes.Buffer
-		originalOutput := log.Writer()
-		log.SetOutput(&logBuffer)
-		defer log.SetOutput(originalOutput)
+		logger := slog.New(slog.NewTextHandler(&logBuffer, nil))
 
 		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		})
 
-		wrappedHandler := loggingMiddleware(testHandler)
+		wrappedHandler := requestLoggingMiddleware(logger)(testHandler)
 
 		req := httptest.NewRequest("GET", "/api/tasks?status=done&limit=10", nil)
 		rr := httptest.NewRecorder()
@@ -119,9 +118,7 @@ func TestLoggingMiddleware(t *testing.T) {
 
 	t.Run("handles different status codes", func(t *testing.T) {
 		var logBuffer bytes.Buffer
-		originalOutput := log.Writer()
-		log.SetOutput(&logBuffer)
-		defer log.SetOutput(originalOutput)
+		logger := slog.New(slog.NewTextHandler(&logBuffer, nil))
 
 		testCases := []int{
 			http.StatusOK,
@@ -138,7 +135,7 @@ func TestLoggingMiddleware(t *testing.T) {
 				w.WriteHeader(statusCode)
 			})
 
-			wrappedHandler := loggingMiddleware(testHandler)
+			wrappedHandler := requestLoggingMiddleware(logger)(testHandler)
 
 			req := httptest.NewRequest("GET", "/test", nil)
 			rr := httptest.NewRecorder()
@@ -270,9 +267,7 @@ func TestJSONMiddleware(t *testing.T) {
 func TestMiddlewareChaining(t *testing.T) {
 	t.Run("chains middleware correctly", func(t *testing.T) {
 		var logBuffer bytes.Buffer
-		originalOutput := log.Writer()
-		log.SetOutput(&logBuffer)
-		defer log.SetOutput(originalOutput)
+		logger := slog.New(slog.NewTextHandler(&logBuffer, nil))
 
 		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -280,7 +275,7 @@ func TestMiddlewareChaining(t *testing.T) {
 		})
 
 		// Chain both middleware
-		wrappedHandler := loggingMiddleware(jsonMiddleware(testHandler))
+		wrappedHandler := requestLoggingMiddleware(logger)(jsonMiddleware(testHandler))
 
 		req := httptest.NewRequest("GET", "/test", nil)
 		req.RemoteAddr = "127.0.0.1:8080"
@@ -312,16 +307,14 @@ func TestMiddlewareChaining(t *testing.T) {
 
 	t.Run("middleware order independence", func(t *testing.T) {
 		var logBuffer bytes.Buffer
-		originalOutput := log.Writer()
-		log.SetOutput(&logBuffer)
-		defer log.SetOutput(originalOutput)
+		logger := slog.New(slog.NewTextHandler(&logBuffer, nil))
 
 		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		})
 
 		// Test different order: JSON then logging
-		wrappedHandler := jsonMiddleware(loggingMiddleware(testHandler))
+		wrappedHandler := jsonMiddleware(requestLoggingMiddleware(logger)(testHandler))
 
 		req := httptest.NewRequest("POST", "/api/test", nil)
 		rr := httptest.NewRecorder()
@@ -339,3 +332,233 @@ func TestMiddlewareChaining(t *testing.T) {
 		}
 	})
 }
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Run("records requests by route template and status", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		service := NewMockTaskService()
+		service.AddTask(&models.Task{ID: "task_1", Title: "Metrics", CreatedAt: time.Now()})
+		server := NewServer(service, 8080, WithMetrics(registry, "/metrics"))
+
+		for _, path := range []string{"/api/v1/tasks/task_1", "/api/v1/tasks/missing", "/api/v1/tasks/missing"} {
+			rr := httptest.NewRecorder()
+			server.router.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		}
+		for _, req := range []*http.Request{
+			httptest.NewRequest("GET", "/no/such/route", nil),
+			httptest.NewRequest("PATCH", "/api/v1/tasks/task_1", nil),
+		} {
+			server.router.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != metrics.ContentType {
+			t.Errorf("Expected Prometheus content type, got '%s'", got)
+		}
+
+		body := rr.Body.String()
+		expected := []string{
+			`gotask_http_requests_total{method="GET",route="/api/v1/tasks/{id}",status="200"} 1`,
+			`gotask_http_requests_total{method="GET",route="/api/v1/tasks/{id}",status="404"} 2`,
+			`gotask_http_request_duration_seconds_count{method="GET",route="/api/v1/tasks/{id}",status="404"} 2`,
+			`gotask_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+			`gotask_http_requests_total{method="PATCH",route="unmatched",status="405"} 1`,
+		}
+		for _, line := range expected {
+			if !strings.Contains(body, line) {
+				t.Errorf("Expected metrics to contain %q, got:\n%s", line, body)
+			}
+		}
+	})
+
+	t.Run("does not expose metrics when disabled", func(t *testing.T) {
+		server := NewServer(NewMockTaskService(), 8080)
+
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+}
+
+func TestRequestLoggingMiddleware(t *testing.T) {
+	t.Run("logs status, bytes and request ID as structured fields", func(t *testing.T) {
+		var logBuffer bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("missing"))
+		})
+
+		req := httptest.NewRequest("GET", "/api/v1/tasks/unknown", nil)
+		req.Header.Set("X-Request-ID", "req-123")
+		rr := httptest.NewRecorder()
+		requestIDMiddleware(requestLoggingMiddleware(logger)(testHandler)).ServeHTTP(rr, req)
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(logBuffer.Bytes(), &record); err != nil {
+			t.Fatalf("Expected a JSON log record, got %q: %v", logBuffer.String(), err)
+		}
+
+		if record["level"] != "WARN" {
+			t.Errorf("Expected WARN level for 404, got %v", record["level"])
+		}
+		if record["status"] != float64(404) {
+			t.Errorf("Expected status 404, got %v", record["status"])
+		}
+		if record["bytes"] != float64(len("missing")) {
+			t.Errorf("Expected bytes %d, got %v", len("missing"), record["bytes"])
+		}
+		if record["request_id"] != "req-123" {
+			t.Errorf("Expected request ID req-123, got %v", record["request_id"])
+		}
+	})
+
+	t.Run("logs server errors at error level", func(t *testing.T) {
+		var logBuffer bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		rr := httptest.NewRecorder()
+		requestLoggingMiddleware(logger)(testHandler).ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/tasks", nil))
+
+		if !strings.Contains(logBuffer.String(), `"level":"ERROR"`) {
+			t.Errorf("Expected ERROR level record, got %s", logBuffer.String())
+		}
+	})
+
+	t.Run("logs requests that match no route", func(t *testing.T) {
+		var logBuffer bytes.Buffer
+		server := NewServer(NewMockTaskService(), 8080, WithLogger(slog.New(slog.NewJSONHandler(&logBuffer, nil))))
+
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest("GET", "/no/such/route", nil))
+
+		if !strings.Contains(logBuffer.String(), `"status":404`) || rr.Header().Get("X-Request-ID") == "" {
+			t.Errorf("Expected a logged 404 with a request ID, got %s", logBuffer.String())
+		}
+	})
+
+	t.Run("redacts access tokens", func(t *testing.T) {
+		var logBuffer bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/ws?access_token=s3cret&tasks=a", nil)
+		requestLoggingMiddleware(logger)(http.NotFoundHandler()).ServeHTTP(rr, req)
+
+		out := logBuffer.String()
+		if strings.Contains(out, "s3cret") || !strings.Contains(out, "access_token=REDACTED") || !strings.Contains(out, "tasks=a") {
+			t.Errorf("Expected the token to be redacted from the URI, got %s", out)
+		}
+	})
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = tracing.RequestIDFromContext(r.Context())
+	})
+
+	t.Run("reuses a valid client request ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-Request-ID", "client-id_1.2")
+		rr := httptest.NewRecorder()
+		requestIDMiddleware(testHandler).ServeHTTP(rr, req)
+
+		if seen != "client-id_1.2" {
+			t.Errorf("Expected context request ID client-id_1.2, got '%s'", seen)
+		}
+		if got := rr.Header().Get("X-Request-ID"); got != "client-id_1.2" {
+			t.Errorf("Expected response header client-id_1.2, got '%s'", got)
+		}
+	})
+
+	t.Run("generates an ID when missing or invalid", func(t *testing.T) {
+		for _, header := range []string{"", "bad id with spaces", strings.Repeat("a", 200)} {
+			req := httptest.NewRequest("GET", "/test", nil)
+			if header != "" {
+				req.Header.Set("X-Request-ID", header)
+			}
+			rr := httptest.NewRecorder()
+			requestIDMiddleware(testHandler).ServeHTTP(rr, req)
+
+			if seen == "" || seen == header {
+				t.Errorf("Expected a generated request ID for %q, got '%s'", header, seen)
+			}
+			if rr.Header().Get("X-Request-ID") != seen {
+				t.Errorf("Expected response header to match context ID")
+			}
+		}
+	})
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	t.Run("records a span per request joining the caller's trace", func(t *testing.T) {
+		var spans bytes.Buffer
+		tracer := tracing.NewTracer("test", tracing.NewStdoutExporter(&spans))
+		server := NewServer(NewMockTaskService(), 8080, WithTracer(tracer))
+
+		req := httptest.NewRequest("GET", "/api/v1/tasks", nil)
+		req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		// The service's span ends, and is exported, before the request's
+		var exported []tracing.SpanData
+		decoder := json.NewDecoder(&spans)
+		for decoder.More() {
+			var data tracing.SpanData
+			if err := decoder.Decode(&data); err != nil {
+				t.Fatalf("Failed to decode exported span: %v", err)
+			}
+			exported = append(exported, data)
+		}
+		if len(exported) != 2 {
+			t.Fatalf("Expected a service span and a request span, got %+v", exported)
+		}
+		span := exported[1]
+		if span.Name != "GET /api/v1/tasks" {
+			t.Errorf("Expected span name 'GET /api/v1/tasks', got '%s'", span.Name)
+		}
+		if exported[0].ParentSpanID != span.SpanID {
+			t.Errorf("Expected the service span to be a child of the request span, got %+v", exported[0])
+		}
+		if span.TraceID != "0af7651916cd43dd8448eb211c80319c" || span.ParentSpanID != "b7ad6b7169203331" {
+			t.Errorf("Expected span to join the incoming trace, got %+v", span)
+		}
+		if span.Attributes["request.id"] != rr.Header().Get("X-Request-ID") {
+			t.Errorf("Expected request ID attribute to match response header, got %v", span.Attributes["request.id"])
+		}
+		if span.Attributes["http.status_code"] != float64(http.StatusOK) {
+			t.Errorf("Expected status code attribute 200, got %v", span.Attributes["http.status_code"])
+		}
+		if !strings.HasPrefix(rr.Header().Get("traceparent"), "00-0af7651916cd43dd8448eb211c80319c-") {
+			t.Errorf("Expected traceparent response header, got '%s'", rr.Header().Get("traceparent"))
+		}
+	})
+
+	t.Run("redacts access tokens from the target", func(t *testing.T) {
+		var spans bytes.Buffer
+		tracer := tracing.NewTracer("test", tracing.NewStdoutExporter(&spans))
+		server := NewServer(NewMockTaskService(), 8080, WithTracer(tracer))
+
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/ws?access_token=s3cret", nil))
+
+		if out := spans.String(); strings.Contains(out, "s3cret") || !strings.Contains(out, "access_token=REDACTED") {
+			t.Errorf("Expected the token to be redacted from the span, got %s", out)
+		}
+	})
+}
@@ -3,23 +3,197 @@ package api
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/health"
+	"GoTask_Management/internal/metrics"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/tracing"
+
 	"github.com/gorilla/mux"
 )
 
 type Server struct {
 	taskService TaskService
-	router      *mux.Router
+	// router is the routes wrapped in the request-scoped middleware
+	router      http.Handler
+	routes      *mux.Router
 	httpServer  *http.Server
 	port        int
+	cors        CORSConfig
+	metrics     *metrics.Registry
+	metricsPath string
+	logger      *slog.Logger
+	tracer      *tracing.Tracer
+	health      HealthConfig
+	checker     *health.Checker
+	startedAt   time.Time
+	jobs        JobManager
+	webhooks    WebhookManager
+	views       ViewManager
+	batch       BatchTaskService
+	batchConfig BatchConfig
+
+	idempotency       storage.IdempotencyStore
+	idempotencyConfig IdempotencyConfig
+
+	stream       *events.Stream
+	streamConfig StreamConfig
+	live         LiveTaskService
+	wsStream     *events.Stream
+	wsConfig     WebSocketConfig
+	// shutdown is closed when the server shuts down, ending event streams and WebSocket connections
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+
+	readinessChecks []namedCheck
+}
+
+type namedCheck struct {
+	name  string
+	check health.CheckFunc
+}
+
+// HealthConfig controls the health endpoints
+type HealthConfig struct {
+	// Path is the base path; liveness and readiness are served at Path+"/live" and Path+"/ready"
+	Path string
+	// Detailed includes per-component results in readiness responses
+	Detailed bool
+	// Timeout bounds each readiness check
+	Timeout time.Duration
+}
+
+// Option configures optional Server behaviour
+type Option func(*Server)
+
+// WithCORS enables CORS handling using the given configuration
+func WithCORS(config CORSConfig) Option {
+	return func(s *Server) {
+		s.cors = config
+	}
+}
+
+// WithLogger sets the logger used for request logs
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithTracer records a span for every request, propagated to the service and storage layers
+func WithTracer(tracer *tracing.Tracer) Option {
+	return func(s *Server) {
+		s.tracer = tracer
+	}
+}
+
+// WithHealth configures the health endpoints
+func WithHealth(config HealthConfig) Option {
+	return func(s *Server) {
+		s.health = config
+	}
+}
+
+// WithReadinessCheck adds a named check to the readiness endpoint. When no
+// "storage" check is registered the task service's HealthCheck is used.
+func WithReadinessCheck(name string, check health.CheckFunc) Option {
+	return func(s *Server) {
+		s.readinessChecks = append(s.readinessChecks, namedCheck{name: name, check: check})
+	}
+}
+
+// WithAdminJobs exposes the scheduler's jobs under /api/v1/admin/jobs
+func WithAdminJobs(jobs JobManager) Option {
+	return func(s *Server) {
+		s.jobs = jobs
+	}
+}
+
+// WithWebhooks exposes webhook subscriptions under /api/v1/webhooks
+func WithWebhooks(webhooks WebhookManager) Option {
+	return func(s *Server) {
+		s.webhooks = webhooks
+	}
+}
+
+// WithViews exposes saved views under /api/v1/views
+func WithViews(views ViewManager) Option {
+	return func(s *Server) {
+		s.views = views
+	}
+}
+
+// WithBatch serves batches of task changes at /api/v1/tasks:batch
+func WithBatch(batch BatchTaskService, config BatchConfig) Option {
+	return func(s *Server) {
+		s.batch = batch
+		s.batchConfig = config
+	}
+}
+
+// WithIdempotency replays responses to mutating requests retried with the
+// same Idempotency-Key, keeping them in store
+func WithIdempotency(store storage.IdempotencyStore, config IdempotencyConfig) Option {
+	return func(s *Server) {
+		s.idempotency = store
+		s.idempotencyConfig = config
+	}
 }
 
-func NewServer(taskService TaskService, port int) *Server {
+// WithEventStream serves task changes from stream as server-sent events at /api/v1/events
+func WithEventStream(stream *events.Stream, config StreamConfig) Option {
+	return func(s *Server) {
+		s.stream = stream
+		s.streamConfig = config
+	}
+}
+
+// WithWebSocket serves the live task API at /api/v1/ws, with events from stream
+func WithWebSocket(live LiveTaskService, stream *events.Stream, config WebSocketConfig) Option {
+	return func(s *Server) {
+		s.live = live
+		s.wsStream = stream
+		s.wsConfig = config
+	}
+}
+
+// WithMetrics records HTTP metrics into registry and exposes it at path
+func WithMetrics(registry *metrics.Registry, path string) Option {
+	return func(s *Server) {
+		s.metrics = registry
+		s.metricsPath = path
+	}
+}
+
+func NewServer(taskService TaskService, port int, opts ...Option) *Server {
 	s := &Server{
 		taskService: taskService,
 		port:        port,
+		logger:      slog.Default(),
+		health:      HealthConfig{Path: "/health", Detailed: true},
+		startedAt:   time.Now(),
+		shutdown:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.checker = health.NewChecker(s.health.Timeout)
+	for _, c := range s.readinessChecks {
+		s.checker.Register(c.name, c.check)
+	}
+	if !s.checker.Has("storage") {
+		if hc, ok := s.taskService.(interface{ HealthCheck() error }); ok {
+			s.checker.Register("storage", func(ctx context.Context) error {
+				return hc.HealthCheck()
+			})
+		}
 	}
 
 	s.setupRoutes()
@@ -27,25 +201,105 @@ func NewServer(taskService TaskService, port int) *Server {
 }
 
 func (s *Server) setupRoutes() {
-	s.router = mux.NewRouter()
+	s.routes = mux.NewRouter()
+
+	// Request IDs, tracing, logging and metrics wrap the router rather than
+	// being added with Use, which mux only runs for matched routes, so that
+	// 404s and 405s are recorded too
+	var handler http.Handler = s.routes
+	if s.metrics != nil {
+		handler = metricsMiddleware(s.metrics, s.routes)(handler)
+	}
+	handler = requestLoggingMiddleware(s.logger)(handler)
+	if s.tracer != nil {
+		handler = tracingMiddleware(s.tracer, s.routes)(handler)
+	}
+	s.router = requestIDMiddleware(handler)
 
 	// Add middleware
-	s.router.Use(loggingMiddleware)
-	s.router.Use(jsonMiddleware)
+	if s.cors.Enabled {
+		s.routes.Use(corsMiddleware(s.cors))
+	}
+	s.routes.Use(jsonMiddleware)
 
 	// API routes
-	api := s.router.PathPrefix("/api/v1").Subrouter()
+	api := s.routes.PathPrefix("/api/v1").Subrouter()
+	if s.idempotency != nil {
+		api.Use(idempotencyMiddleware(s.idempotency, s.idempotencyConfig, s.logger))
+	}
 
 	// Task routes - specific routes must come before parameterized routes
 	api.HandleFunc("/tasks", s.handleGetTasks).Methods("GET")
 	api.HandleFunc("/tasks", s.handleCreateTask).Methods("POST")
 	api.HandleFunc("/tasks/due", s.handleGetDueTasks).Methods("GET")
+	api.HandleFunc("/tasks/search", s.handleSearchTasks).Methods("GET")
 	api.HandleFunc("/tasks/{id}", s.handleGetTask).Methods("GET")
 	api.HandleFunc("/tasks/{id}", s.handleUpdateTask).Methods("PUT")
 	api.HandleFunc("/tasks/{id}", s.handleDeleteTask).Methods("DELETE")
 
-	// Health check
-	s.router.HandleFunc("/health", s.handleHealth).Methods("GET")
+	// Batch route
+	if s.batch != nil {
+		api.HandleFunc("/tasks:batch", s.handleBatch).Methods("POST")
+	}
+
+	// Admin routes
+	if s.jobs != nil {
+		api.HandleFunc("/admin/jobs", s.handleListJobs).Methods("GET")
+		api.HandleFunc("/admin/jobs/{name}/run", s.handleRunJob).Methods("POST")
+	}
+
+	// Event stream
+	if s.stream != nil {
+		api.HandleFunc("/events", s.handleEventStream).Methods("GET")
+	}
+
+	// Live task API
+	if s.live != nil {
+		api.HandleFunc("/ws", s.handleWebSocket).Methods("GET")
+	}
+
+	// Webhook routes
+	if s.webhooks != nil {
+		api.HandleFunc("/webhooks", s.handleListWebhooks).Methods("GET")
+		api.HandleFunc("/webhooks", s.handleCreateWebhook).Methods("POST")
+		api.HandleFunc("/webhooks/{id}", s.handleGetWebhook).Methods("GET")
+		api.HandleFunc("/webhooks/{id}", s.handleDeleteWebhook).Methods("DELETE")
+		api.HandleFunc("/webhooks/{id}/deliveries", s.handleListDeliveries).Methods("GET")
+		api.HandleFunc("/webhooks/{id}/deliveries/{deliveryID}/redeliver", s.handleRedeliver).Methods("POST")
+	}
+
+	// Saved view routes
+	if s.views != nil {
+		api.HandleFunc("/views", s.handleListViews).Methods("GET")
+		api.HandleFunc("/views", s.handleCreateView).Methods("POST")
+		api.HandleFunc("/views/{id}", s.handleGetView).Methods("GET")
+		api.HandleFunc("/views/{id}", s.handleUpdateView).Methods("PUT")
+		api.HandleFunc("/views/{id}", s.handleDeleteView).Methods("DELETE")
+		api.HandleFunc("/views/{id}/tasks", s.handleViewTasks).Methods("GET")
+	}
+
+	// Health checks
+	healthPath := s.health.Path
+	if healthPath == "" {
+		healthPath = "/health"
+	}
+	s.routes.HandleFunc(healthPath, s.handleReadiness).Methods("GET")
+	s.routes.HandleFunc(healthPath+"/live", s.handleLiveness).Methods("GET")
+	s.routes.HandleFunc(healthPath+"/ready", s.handleReadiness).Methods("GET")
+
+	// Prometheus scrape endpoint
+	if s.metrics != nil {
+		path := s.metricsPath
+		if path == "" {
+			path = "/metrics"
+		}
+		s.routes.Handle(path, s.metrics.Handler()).Methods("GET")
+	}
+
+	// Preflight requests must match a route for the CORS middleware to run
+	if s.cors.Enabled {
+		s.routes.PathPrefix("/").HandlerFunc(s.handlePreflight).Methods("OPTIONS")
+	}
 }
 
 func (s *Server) Start() error {
@@ -56,10 +310,17 @@ func (s *Server) Start() error {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Shutdown waits for active requests, so long-lived event streams must end
+	s.httpServer.RegisterOnShutdown(s.closeStreams)
 
 	return s.httpServer.ListenAndServe()
 }
 
+// closeStreams ends open event streams and WebSocket connections
+func (s *Server) closeStreams() {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+}
+
 func (s *Server) Shutdown() error {
 	if s.httpServer == nil {
 		return nil // Server was never started
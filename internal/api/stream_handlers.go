@@ -0,0 +1,168 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/models"
+)
+
+// streamWriteTimeout bounds each write to an event stream. It replaces the
+// server's WriteTimeout, which would otherwise end the response mid-stream.
+const streamWriteTimeout = 10 * time.Second
+
+// StreamConfig controls the server-sent events endpoint
+type StreamConfig struct {
+	// Heartbeat is the interval between keep-alive comments; default 15s
+	Heartbeat time.Duration
+}
+
+// StreamEvent is the data of one server-sent event
+type StreamEvent struct {
+	Type   events.Type `json:"type"`
+	TaskID string      `json:"task_id"`
+	// Task is the task after the change, or before it for deletions
+	Task *models.Task `json:"task"`
+	// Previous is the task before an update or completion
+	Previous   *models.Task `json:"previous,omitempty"`
+	OccurredAt time.Time    `json:"occurred_at"`
+}
+
+func newStreamEvent(e events.Event) StreamEvent {
+	data := StreamEvent{Type: e.Type, TaskID: e.TaskID, Task: e.Task(), OccurredAt: e.OccurredAt}
+	if e.After != nil {
+		data.Previous = e.Before
+	}
+	return data
+}
+
+// eventFilter selects which events a client receives
+type eventFilter struct {
+	types  map[events.Type]bool
+	taskID string
+}
+
+func parseEventFilter(query url.Values) (eventFilter, error) {
+	var filter eventFilter
+	if query.Has("project") || query.Has("assignee") {
+		return filter, errors.New("project and assignee are not supported: tasks have no project or assignee")
+	}
+
+	// type may be repeated or comma separated
+	for _, value := range query["type"] {
+		for _, name := range strings.Split(value, ",") {
+			t := events.Type(strings.TrimSpace(name))
+			if !t.Valid() {
+				return filter, fmt.Errorf("unknown event type %q", t)
+			}
+			if filter.types == nil {
+				filter.types = make(map[events.Type]bool)
+			}
+			filter.types[t] = true
+		}
+	}
+	filter.taskID = query.Get("task_id")
+	return filter, nil
+}
+
+func (f eventFilter) match(e events.Event) bool {
+	if f.types != nil && !f.types[e.Type] {
+		return false
+	}
+	return f.taskID == "" || f.taskID == e.TaskID
+}
+
+// handleEventStream streams task changes as server-sent events. Clients resume
+// with the Last-Event-ID header, or the last_event_id query parameter for the
+// first connection; a "reset" event means events were missed and the client
+// should reload its tasks.
+func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid filter: "+err.Error())
+		return
+	}
+
+	after := r.Header.Get("Last-Event-ID")
+	if after == "" {
+		after = r.URL.Query().Get("last_event_id")
+	}
+	listener, replay, complete := s.stream.Listen(after)
+	defer listener.Close()
+
+	rc := http.NewResponseController(w)
+	write := func(frame string) error {
+		if err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if _, err := io.WriteString(w, frame); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	send := func(e events.StreamEvent) error {
+		if !filter.match(e.Event) {
+			return nil
+		}
+		payload, err := json.Marshal(newStreamEvent(e.Event))
+		if err != nil {
+			return err
+		}
+		return write(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", e.Cursor, e.Type, payload))
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := write("retry: 3000\n\n"); err != nil {
+		return
+	}
+	if !complete {
+		if err := write(fmt.Sprintf("id: %s\nevent: reset\ndata: {}\n\n", listener.Start)); err != nil {
+			return
+		}
+	}
+	for _, e := range replay {
+		if err := send(e); err != nil {
+			return
+		}
+	}
+
+	heartbeat := s.streamConfig.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+			if err := write(": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-listener.C:
+			// A closed listener fell behind; the client reconnects and resumes
+			if !ok {
+				return
+			}
+			if err := send(e); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/models"
+)
+
+// sseFrame is one parsed server-sent event; comments are skipped
+type sseFrame struct {
+	id, event, data string
+}
+
+type sseClient struct {
+	t      *testing.T
+	resp   *http.Response
+	frames chan sseFrame
+}
+
+// openStream connects to the event stream and parses frames in the background
+func openStream(t *testing.T, url string, header http.Header) *sseClient {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	c := &sseClient{t: t, resp: resp, frames: make(chan sseFrame, 100)}
+	go func() {
+		defer close(c.frames)
+		var frame sseFrame
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if frame.event != "" {
+					c.frames <- frame
+				}
+				frame = sseFrame{}
+			case strings.HasPrefix(line, "id: "):
+				frame.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				frame.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				frame.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return c
+}
+
+func (c *sseClient) next() sseFrame {
+	c.t.Helper()
+	select {
+	case frame, ok := <-c.frames:
+		if !ok {
+			c.t.Fatal("Stream ended unexpectedly")
+		}
+		return frame
+	case <-time.After(2 * time.Second):
+		c.t.Fatal("Timed out waiting for an event")
+	}
+	return sseFrame{}
+}
+
+// expectClosed discards unread events until the stream ends
+func (c *sseClient) expectClosed() {
+	c.t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-c.frames:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			c.t.Fatal("Timed out waiting for the stream to end")
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	bus := events.NewBus()
+	defer bus.Close()
+	stream := events.NewStream(bus, 100)
+	defer stream.Close()
+
+	server := NewServer(NewMockTaskService(), 8080, WithEventStream(stream, StreamConfig{Heartbeat: 20 * time.Millisecond}))
+	httpServer := httptest.NewUnstartedServer(server.router)
+	// Far shorter than the stream's lifetime in this test
+	httpServer.Config.WriteTimeout = 100 * time.Millisecond
+	httpServer.Start()
+	defer httpServer.Close()
+	url := httpServer.URL + "/api/v1/events"
+
+	publish := func(eventType events.Type, id string) {
+		task := &models.Task{ID: id, Title: "Task " + id}
+		event := events.Event{Type: eventType, TaskID: id, After: task}
+		if eventType != events.TaskCreated {
+			event.Before = &models.Task{ID: id, Title: "Old"}
+		}
+		bus.Publish(context.Background(), event)
+	}
+
+	all := openStream(t, url, nil)
+	if ct := all.resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+	completed := openStream(t, url+"?type=task.completed&task_id=t2", nil)
+
+	// Outlive the server's WriteTimeout before sending anything
+	time.Sleep(250 * time.Millisecond)
+	publish(events.TaskCreated, "t1")
+	publish(events.TaskCompleted, "t1")
+	publish(events.TaskCompleted, "t2")
+
+	first := all.next()
+	if first.event != "task.created" || first.id == "" {
+		t.Fatalf("Expected a task.created event with an id, got %+v", first)
+	}
+	var data StreamEvent
+	if err := json.Unmarshal([]byte(first.data), &data); err != nil || data.Task.ID != "t1" || data.Previous != nil {
+		t.Errorf("Unexpected event data %s (%v)", first.data, err)
+	}
+	all.next()
+	all.next()
+
+	got := completed.next()
+	json.Unmarshal([]byte(got.data), &data)
+	if got.event != "task.completed" || data.TaskID != "t2" || data.Previous == nil || data.Previous.Title != "Old" {
+		t.Errorf("Expected only t2's completion with its previous state, got %+v", got)
+	}
+
+	t.Run("resumes from Last-Event-ID", func(t *testing.T) {
+		resumed := openStream(t, url, http.Header{"Last-Event-Id": {first.id}})
+		for _, want := range []string{"task.completed", "task.completed"} {
+			if got := resumed.next(); got.event != want {
+				t.Errorf("Expected %s, got %+v", want, got)
+			}
+		}
+
+		resumed = openStream(t, url+"?last_event_id="+first.id, nil)
+		if got := resumed.next(); got.event != "task.completed" {
+			t.Errorf("Expected replay via query parameter, got %+v", got)
+		}
+	})
+
+	t.Run("resets on unknown cursor", func(t *testing.T) {
+		stale := openStream(t, url, http.Header{"Last-Event-Id": {"previous-process-42"}})
+		reset := stale.next()
+		if reset.event != "reset" || reset.id == "" {
+			t.Fatalf("Expected a reset event, got %+v", reset)
+		}
+
+		resumed := openStream(t, url, http.Header{"Last-Event-Id": {reset.id}})
+		publish(events.TaskDeleted, "t3")
+		if got := resumed.next(); got.event != "task.deleted" {
+			t.Errorf("Expected to resume after the reset, got %+v", got)
+		}
+	})
+
+	t.Run("rejects unsupported filters", func(t *testing.T) {
+		for _, query := range []string{"?project=ops", "?assignee=sam", "?type=task.exploded"} {
+			resp, err := http.Get(url + query)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", query, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("ends on shutdown", func(t *testing.T) {
+		server.closeStreams()
+		all.expectClosed()
+		completed.expectClosed()
+	})
+}
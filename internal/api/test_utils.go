@@ -3,203 +3,72 @@ package api
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
 	"time"
 
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/task"
 )
 
-// MockTaskService implements TaskService interface for testing
+// MockTaskService is the task service over in-memory storage, with a
+// switch that makes every storage operation fail
 type MockTaskService struct {
-	tasks       map[string]*models.Task
-	shouldError bool
-	errorMsg    string
-	idCounter   int
+	*task.Service
+	store *task.MockStorage
 }
 
-// NewMockTaskService creates a new mock task service
+// NewMockTaskService creates a task service with empty storage
 func NewMockTaskService() *MockTaskService {
+	store := task.NewMockStorage()
 	return &MockTaskService{
-		tasks: make(map[string]*models.Task),
+		Service: task.NewService(store),
+		store:   store,
 	}
 }
 
-// SetError configures the mock to return errors
+// SetError configures the storage to return errors
 func (m *MockTaskService) SetError(shouldError bool, errorMsg string) {
-	m.shouldError = shouldError
-	m.errorMsg = errorMsg
+	m.store.SetError(shouldError, errorMsg)
 }
 
 // Reset clears all tasks and error state
 func (m *MockTaskService) Reset() {
-	m.tasks = make(map[string]*models.Task)
-	m.shouldError = false
-	m.errorMsg = ""
-	m.idCounter = 0
+	m.store.Clear()
+	m.store.SetError(false, "")
 }
 
-// AddTask adds a task to the mock storage
+// AddTask stores task, replacing a task with the same ID
 func (m *MockTaskService) AddTask(task *models.Task) {
-	m.tasks[task.ID] = task
-}
-
-// CreateTask implements TaskService interface
-func (m *MockTaskService) CreateTask(title string, dueDate *time.Time) (*models.Task, error) {
-	if m.shouldError {
-		return nil, errors.New(m.errorMsg)
-	}
-	
-	if strings.TrimSpace(title) == "" {
-		return nil, errors.New("task title cannot be empty")
-	}
-	
-	m.idCounter++
-	task := &models.Task{
-		ID:        m.generateID(),
-		Title:     title,
-		Done:      false,
-		CreatedAt: time.Now(),
-		DueDate:   dueDate,
-	}
-	
-	m.tasks[task.ID] = task
-	return task, nil
-}
-
-// ListTasks implements TaskService interface
-func (m *MockTaskService) ListTasks(status string) ([]*models.Task, error) {
-	if m.shouldError {
-		return nil, errors.New(m.errorMsg)
-	}
-	
-	tasks := make([]*models.Task, 0, len(m.tasks))
-	for _, task := range m.tasks {
-		if status == "" || 
-		   (status == "done" && task.Done) || 
-		   (status == "undone" && !task.Done) {
-			tasks = append(tasks, task)
-		}
-	}
-	return tasks, nil
-}
-
-// GetTask implements TaskService interface
-func (m *MockTaskService) GetTask(id string) (*models.Task, error) {
-	if m.shouldError {
-		return nil, errors.New(m.errorMsg)
-	}
-	
-	task, exists := m.tasks[id]
-	if !exists {
-		return nil, errors.New("task not found")
-	}
-	return task, nil
-}
-
-// UpdateTask implements TaskService interface
-func (m *MockTaskService) UpdateTask(id string, title string, done bool, dueDate *time.Time) (*models.Task, error) {
-	if m.shouldError {
-		return nil, errors.New(m.errorMsg)
-	}
-	
-	task, exists := m.tasks[id]
-	if !exists {
-		return nil, errors.New("task not found")
+	if err := m.store.Update(task); err != nil {
+		m.store.Create(task)
 	}
-	
-	if title != "" {
-		task.Title = title
-	}
-	task.Done = done
-	task.DueDate = dueDate
-	
-	return task, nil
-}
-
-// DeleteTask implements TaskService interface
-func (m *MockTaskService) DeleteTask(id string) error {
-	if m.shouldError {
-		return errors.New(m.errorMsg)
-	}
-	
-	if _, exists := m.tasks[id]; !exists {
-		return errors.New("task not found")
-	}
-	
-	delete(m.tasks, id)
-	return nil
-}
-
-// GetDueTasks implements TaskService interface
-func (m *MockTaskService) GetDueTasks(days int) ([]*models.Task, error) {
-	if m.shouldError {
-		return nil, errors.New(m.errorMsg)
-	}
-	
-	now := time.Now()
-	deadline := now.AddDate(0, 0, days)
-	
-	dueTasks := make([]*models.Task, 0)
-	for _, task := range m.tasks {
-		if task.DueDate != nil && !task.DueDate.After(deadline) {
-			dueTasks = append(dueTasks, task)
-		}
-	}
-	
-	return dueTasks, nil
-}
-
-// GetTasksSummary implements TaskService interface
-func (m *MockTaskService) GetTasksSummary() (int, int, int, error) {
-	if m.shouldError {
-		return 0, 0, 0, errors.New(m.errorMsg)
-	}
-	
-	total := len(m.tasks)
-	done := 0
-	overdue := 0
-	now := time.Now()
-	
-	for _, task := range m.tasks {
-		if task.Done {
-			done++
-		}
-		if task.DueDate != nil && task.DueDate.Before(now) && !task.Done {
-			overdue++
-		}
-	}
-	
-	return total, done, overdue, nil
 }
 
 // TestHelper provides utilities for API testing
 type TestHelper struct {
-	t           *testing.T
-	mockService *MockTaskService
-	server      *Server
+	t       *testing.T
+	service *MockTaskService
+	server  *Server
 }
 
 // NewTestHelper creates a new API test helper
 func NewTestHelper(t *testing.T) *TestHelper {
-	mockService := NewMockTaskService()
-	server := NewServer(mockService, 8080)
+	service := NewMockTaskService()
+	server := NewServer(service, 8080)
 
 	return &TestHelper{
-		t:           t,
-		mockService: mockService,
-		server:      server,
+		t:       t,
+		service: service,
+		server:  server,
 	}
 }
 
 // GetMockService returns the mock task service
 func (h *TestHelper) GetMockService() *MockTaskService {
-	return h.mockService
+	return h.service
 }
 
 // CreateRequest creates an HTTP request for testing
@@ -291,10 +160,5 @@ func (h *TestHelper) CreateSampleTaskWithDueDate(id, title string, dueDate time.
 	}
 }
 
-// generateID generates a unique ID for the mock service
-func (m *MockTaskService) generateID() string {
-	return fmt.Sprintf("mock_task_%d", m.idCounter)
-}
-
 // Verify that MockTaskService implements TaskService interface
 var _ TaskService = (*MockTaskService)(nil)
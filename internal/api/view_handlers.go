@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+	"GoTask_Management/internal/views"
+
+	"github.com/gorilla/mux"
+)
+
+type ViewRequest struct {
+	Name   string `json:"name"`
+	Owner  string `json:"owner,omitempty"`
+	Query  string `json:"query"`
+	Sort   string `json:"sort,omitempty"`
+	Shared bool   `json:"shared"`
+}
+
+// handleListViews lists every view, or with ?owner= the owner's views and
+// shared views
+func (s *Server) handleListViews(w http.ResponseWriter, r *http.Request) {
+	list, err := s.views.Views(r.URL.Query().Get("owner"))
+	if err != nil {
+		respondWithViewError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleCreateView(w http.ResponseWriter, r *http.Request) {
+	var req ViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	view, err := s.views.Create(req.view())
+	if err != nil {
+		respondWithViewError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, view)
+}
+
+func (s *Server) handleGetView(w http.ResponseWriter, r *http.Request) {
+	view, err := s.views.View(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithViewError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, view)
+}
+
+// handleUpdateView replaces the name, owner, query, sort order and sharing of a view
+func (s *Server) handleUpdateView(w http.ResponseWriter, r *http.Request) {
+	var req ViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	view := req.view()
+	view.ID = mux.Vars(r)["id"]
+	view, err := s.views.Update(view)
+	if err != nil {
+		respondWithViewError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, view)
+}
+
+func (s *Server) handleDeleteView(w http.ResponseWriter, r *http.Request) {
+	if err := s.views.Delete(mux.Vars(r)["id"]); err != nil {
+		respondWithViewError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "View deleted successfully"})
+}
+
+// handleViewTasks lists the tasks matching a view, in its sort order. The
+// query runs through FindTasks as it does for GET /tasks?q=.
+func (s *Server) handleViewTasks(w http.ResponseWriter, r *http.Request) {
+	view, err := s.views.View(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithViewError(w, err)
+		return
+	}
+
+	tasks, err := view.Evaluate(r.Context(), s.taskService, time.Now())
+	var qerr *query.Error
+	if errors.As(err, &qerr) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if tasks == nil {
+		tasks = []*models.Task{}
+	}
+
+	respondWithJSON(w, http.StatusOK, tasks)
+}
+
+func (req ViewRequest) view() views.SavedView {
+	return views.SavedView{Name: req.Name, Owner: req.Owner, Query: req.Query, Sort: req.Sort, Shared: req.Shared}
+}
+
+// respondWithViewError maps view store errors to status codes
+func respondWithViewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, views.ErrViewNotFound):
+		respondWithError(w, http.StatusNotFound, "View not found")
+	case errors.Is(err, views.ErrInvalidView):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, views.ErrDuplicateView):
+		respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
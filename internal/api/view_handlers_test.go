@@ -0,0 +1,141 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/views"
+)
+
+func TestViewEndpoints(t *testing.T) {
+	service := NewMockTaskService()
+	now := time.Now()
+	soon, later := now.AddDate(0, 0, 1), now.AddDate(0, 0, 3)
+	service.AddTask(&models.Task{ID: "later", Title: "Deploy release", CreatedAt: now, DueDate: &later})
+	service.AddTask(&models.Task{ID: "soon", Title: "Review release notes", CreatedAt: now, DueDate: &soon})
+	service.AddTask(&models.Task{ID: "done", Title: "Tag release", Done: true, CreatedAt: now, DueDate: &soon})
+	service.AddTask(&models.Task{ID: "other", Title: "Plan offsite", CreatedAt: now})
+
+	store := views.NewStore(storage.NewMemoryStorage())
+	server := NewServer(service, 8080, WithViews(store))
+
+	serve := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest(method, url, &buf))
+		return rr
+	}
+
+	t.Run("rejects invalid views", func(t *testing.T) {
+		for _, req := range []ViewRequest{
+			{Query: "status:open"},
+			{Name: "Bad query", Query: "status:blocked"},
+			{Name: "Bad sort", Sort: "priority"},
+		} {
+			if rr := serve("POST", "/api/v1/views", req); rr.Code != http.StatusBadRequest {
+				t.Errorf("POST %+v: expected status 400, got %d", req, rr.Code)
+			}
+		}
+	})
+
+	rr := serve("POST", "/api/v1/views", ViewRequest{Name: "Release", Owner: "alice", Query: "status:open release", Sort: "due", Shared: true})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created views.SavedView
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if created.ID == "" || created.Query != "status:open release" {
+		t.Fatalf("Expected the created view, got %+v", created)
+	}
+
+	t.Run("rejects duplicate names", func(t *testing.T) {
+		if rr := serve("POST", "/api/v1/views", ViewRequest{Name: "release", Owner: "alice"}); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", rr.Code)
+		}
+	})
+
+	t.Run("evaluates through the task query", func(t *testing.T) {
+		rr := serve("GET", "/api/v1/views/"+created.ID+"/tasks", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var tasks []*models.Task
+		json.Unmarshal(rr.Body.Bytes(), &tasks)
+		if len(tasks) != 2 || tasks[0].ID != "soon" || tasks[1].ID != "later" {
+			t.Errorf("Expected [soon later] in due order, got %s", rr.Body.String())
+		}
+
+		listed := serve("GET", "/api/v1/tasks?q=status:open+release", nil)
+		var queried []*models.Task
+		json.Unmarshal(listed.Body.Bytes(), &queried)
+		if len(queried) != len(tasks) {
+			t.Errorf("Expected the view to match GET /tasks?q=, got %d and %d tasks", len(tasks), len(queried))
+		}
+	})
+
+	t.Run("lists views for an owner", func(t *testing.T) {
+		serve("POST", "/api/v1/views", ViewRequest{Name: "Mine", Owner: "bob"})
+
+		var listed []views.SavedView
+		json.Unmarshal(serve("GET", "/api/v1/views?owner=carol", nil).Body.Bytes(), &listed)
+		if len(listed) != 1 || listed[0].ID != created.ID {
+			t.Errorf("Expected only the shared view, got %+v", listed)
+		}
+		json.Unmarshal(serve("GET", "/api/v1/views", nil).Body.Bytes(), &listed)
+		if len(listed) != 2 {
+			t.Errorf("Expected every view, got %+v", listed)
+		}
+	})
+
+	t.Run("updates", func(t *testing.T) {
+		rr := serve("PUT", "/api/v1/views/"+created.ID, ViewRequest{Name: "Release", Owner: "alice", Query: "release", Sort: "-title"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		var tasks []*models.Task
+		json.Unmarshal(serve("GET", "/api/v1/views/"+created.ID+"/tasks", nil).Body.Bytes(), &tasks)
+		if len(tasks) != 3 || tasks[0].ID != "done" || tasks[2].ID != "later" {
+			t.Errorf("Expected the updated query and sort order, got %+v", tasks)
+		}
+
+		if rr := serve("PUT", "/api/v1/views/"+created.ID, ViewRequest{Name: "Release", Query: "due<"}); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rr.Code)
+		}
+		if rr := serve("PUT", "/api/v1/views/view_missing", ViewRequest{Name: "Missing"}); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("deletes", func(t *testing.T) {
+		if rr := serve("DELETE", "/api/v1/views/"+created.ID, nil); rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rr.Code)
+		}
+		for _, url := range []string{"/api/v1/views/" + created.ID, "/api/v1/views/" + created.ID + "/tasks"} {
+			if rr := serve("GET", url, nil); rr.Code != http.StatusNotFound {
+				t.Errorf("GET %s: expected status 404, got %d", url, rr.Code)
+			}
+		}
+		if rr := serve("DELETE", "/api/v1/views/"+created.ID, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("not routed without views", func(t *testing.T) {
+		plain := NewServer(NewMockTaskService(), 8080)
+		rr := httptest.NewRecorder()
+		plain.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/views", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/webhook"
+
+	"github.com/gorilla/mux"
+)
+
+type WebhookRequest struct {
+	URL    string        `json:"url"`
+	Secret string        `json:"secret,omitempty"`
+	Events []events.Type `json:"events,omitempty"`
+}
+
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := s.webhooks.Subscriptions()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	redacted := make([]webhook.Subscription, 0, len(subs))
+	for _, sub := range subs {
+		redacted = append(redacted, sub.Redacted())
+	}
+
+	respondWithJSON(w, http.StatusOK, redacted)
+}
+
+// handleCreateWebhook returns the secret once; later reads omit it
+func (s *Server) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	sub, err := s.webhooks.Subscribe(webhook.Subscription{URL: req.URL, Secret: req.Secret, Events: req.Events})
+	if errors.Is(err, webhook.ErrInvalidSubscription) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, sub)
+}
+
+func (s *Server) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	sub, err := s.webhooks.Subscription(mux.Vars(r)["id"])
+	if errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		respondWithError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, sub.Redacted())
+}
+
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	err := s.webhooks.Unsubscribe(mux.Vars(r)["id"])
+	if errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		respondWithError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook deleted successfully"})
+}
+
+func (s *Server) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := s.webhooks.Deliveries(mux.Vars(r)["id"])
+	if errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		respondWithError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []webhook.Delivery{}
+	}
+
+	respondWithJSON(w, http.StatusOK, deliveries)
+}
+
+func (s *Server) handleRedeliver(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	delivery, err := s.webhooks.Redeliver(vars["id"], vars["deliveryID"])
+	switch {
+	case errors.Is(err, webhook.ErrSubscriptionNotFound), errors.Is(err, webhook.ErrDeliveryNotFound):
+		respondWithError(w, http.StatusNotFound, "Delivery not found")
+		return
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, delivery)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/webhook"
+)
+
+func TestWebhookEndpoints(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	store := webhook.NewStore(storage.NewMemoryWebhookStore())
+	dispatcher := webhook.NewDispatcher(store)
+	defer dispatcher.Stop()
+	server := NewServer(NewMockTaskService(), 8080, WithWebhooks(dispatcher))
+
+	serve := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest(method, url, &buf))
+		return rr
+	}
+
+	t.Run("rejects invalid subscriptions", func(t *testing.T) {
+		rr := serve("POST", "/api/v1/webhooks", WebhookRequest{URL: "not a url"})
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %d", rr.Code)
+		}
+	})
+
+	rr := serve("POST", "/api/v1/webhooks", WebhookRequest{URL: receiver.URL, Events: []events.Type{events.TaskCreated}})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created webhook.Subscription
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if created.ID == "" || created.Secret == "" {
+		t.Fatalf("Expected the created subscription to include its secret, got %+v", created)
+	}
+
+	t.Run("lists without secrets", func(t *testing.T) {
+		rr := serve("GET", "/api/v1/webhooks", nil)
+		var subs []webhook.Subscription
+		json.Unmarshal(rr.Body.Bytes(), &subs)
+		if rr.Code != http.StatusOK || len(subs) != 1 || subs[0].Secret != "" {
+			t.Errorf("Expected one redacted subscription, got %d %s", rr.Code, rr.Body.String())
+		}
+
+		rr = serve("GET", "/api/v1/webhooks/"+created.ID, nil)
+		if rr.Code != http.StatusOK || bytes.Contains(rr.Body.Bytes(), []byte(created.Secret)) {
+			t.Errorf("Expected a redacted subscription, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("redelivers", func(t *testing.T) {
+		failed := webhook.Delivery{ID: "dlv_1", SubscriptionID: created.ID, Event: "task.created", Payload: json.RawMessage(`{}`), Status: webhook.StatusFailed, Attempts: 5, CreatedAt: time.Now()}
+		if err := store.SaveDelivery(failed); err != nil {
+			t.Fatalf("Failed to seed delivery: %v", err)
+		}
+
+		rr := serve("POST", "/api/v1/webhooks/"+created.ID+"/deliveries/dlv_1/redeliver", nil)
+		if rr.Code != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d", rr.Code)
+		}
+
+		rr = serve("GET", "/api/v1/webhooks/"+created.ID+"/deliveries", nil)
+		var deliveries []webhook.Delivery
+		json.Unmarshal(rr.Body.Bytes(), &deliveries)
+		if len(deliveries) != 2 || deliveries[0].RedeliveryOf != "dlv_1" {
+			t.Errorf("Expected the redelivery to be logged first, got %s", rr.Body.String())
+		}
+
+		rr = serve("POST", "/api/v1/webhooks/"+created.ID+"/deliveries/dlv_missing/redeliver", nil)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("deletes", func(t *testing.T) {
+		if rr := serve("DELETE", "/api/v1/webhooks/"+created.ID, nil); rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rr.Code)
+		}
+		for _, url := range []string{"/api/v1/webhooks/" + created.ID, "/api/v1/webhooks/" + created.ID + "/deliveries"} {
+			if rr := serve("GET", url, nil); rr.Code != http.StatusNotFound {
+				t.Errorf("GET %s: expected status 404, got %d", url, rr.Code)
+			}
+		}
+		if rr := serve("DELETE", "/api/v1/webhooks/"+created.ID, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("not routed without webhooks", func(t *testing.T) {
+		plain := NewServer(NewMockTaskService(), 8080)
+		rr := httptest.NewRecorder()
+		plain.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/webhooks", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+}
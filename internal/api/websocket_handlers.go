@@ -0,0 +1,434 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/task"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket message types sent by clients
+const (
+	MessageSubscribe   = "subscribe"
+	MessageUnsubscribe = "unsubscribe"
+	MessageMutate      = "mutate"
+)
+
+// WebSocket message types sent by the server
+const (
+	MessageAck   = "ack"
+	MessageError = "error"
+	MessageEvent = "event"
+)
+
+// Error codes in WebSocket error messages
+const (
+	CodeBadRequest      = "bad_request"
+	CodeNotFound        = "not_found"
+	CodeVersionConflict = "version_conflict"
+	CodeInternal        = "internal"
+)
+
+// allTasks subscribes to every task, including ones created later
+const allTasks = "*"
+
+// WebSocketConfig controls the WebSocket endpoint
+type WebSocketConfig struct {
+	// Tokens maps client names to the bearer tokens they authenticate with
+	Tokens map[string]string
+	// AllowedOrigins lists browser origins allowed besides the server's own; "*" allows any
+	AllowedOrigins []string
+	// PingInterval is how often the server pings; a connection that has not
+	// answered within two intervals is closed. Default 30s.
+	PingInterval time.Duration
+	// SendBuffer is how many messages may wait for a slow client before it is
+	// disconnected. Default 64.
+	SendBuffer int
+	// MaxMessageSize limits client messages in bytes. Default 64KiB.
+	MaxMessageSize int64
+}
+
+// Validate rejects configurations that would leave the endpoint unusable
+func (c WebSocketConfig) Validate() error {
+	if len(c.Tokens) == 0 {
+		return errors.New("at least one WebSocket token is required")
+	}
+	for name, token := range c.Tokens {
+		if token == "" {
+			return fmt.Errorf("WebSocket token for %q is empty", name)
+		}
+	}
+	return nil
+}
+
+func (c WebSocketConfig) withDefaults() WebSocketConfig {
+	if c.PingInterval <= 0 {
+		c.PingInterval = 30 * time.Second
+	}
+	if c.SendBuffer <= 0 {
+		c.SendBuffer = 64
+	}
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = 64 << 10
+	}
+	return c
+}
+
+// ClientMessage is a request from a WebSocket client. ID is chosen by the
+// client and echoed in the matching ack or error.
+type ClientMessage struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	// TaskIDs are the tasks to (un)subscribe; "*" means all tasks
+	TaskIDs []string `json:"task_ids,omitempty"`
+	// Op is create, update or delete for mutate messages
+	Op     string `json:"op,omitempty"`
+	TaskID string `json:"task_id,omitempty"`
+	// Version is the task version the client last saw; required for update and delete
+	Version *int64     `json:"version,omitempty"`
+	Title   *string    `json:"title,omitempty"`
+	Done    *bool      `json:"done,omitempty"`
+	DueDate *time.Time `json:"due_date,omitempty"`
+}
+
+// ServerMessage is an ack, error or event sent to a WebSocket client
+type ServerMessage struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	// Task is the result of a mutation, or the current task on a version conflict
+	Task *models.Task `json:"task,omitempty"`
+	// Tasks are the current state of newly subscribed tasks
+	Tasks []*models.Task `json:"tasks,omitempty"`
+	Event *StreamEvent   `json:"event,omitempty"`
+	Code  string         `json:"code,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
+// authenticateWebSocket returns the name of the client whose token the request
+// carries, from the Authorization header or, for browsers, the access_token
+// query parameter
+func (s *Server) authenticateWebSocket(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("access_token")
+	}
+	if token == "" {
+		return "", false
+	}
+	for name, want := range s.wsConfig.Tokens {
+		if want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// checkWebSocketOrigin allows non-browser clients, the server's own origin and
+// the configured origins
+func (s *Server) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range s.wsConfig.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleWebSocket serves the live task API. Clients subscribe to tasks,
+// receive their change events and mutate them with optimistic version checks.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	client, ok := s.authenticateWebSocket(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing token")
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkWebSocketOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied to the client
+		return
+	}
+
+	config := s.wsConfig.withDefaults()
+	c := &wsConn{
+		server: s,
+		conn:   conn,
+		config: config,
+		send:   make(chan ServerMessage, config.SendBuffer),
+		done:   make(chan struct{}),
+		tasks:  make(map[string]bool),
+	}
+	listener, _, _ := s.wsStream.Listen("")
+	defer listener.Close()
+
+	s.logger.InfoContext(r.Context(), "websocket connected", "client", client)
+	go c.writeLoop()
+	go c.eventLoop(listener)
+	c.readLoop(r.Context())
+	s.logger.InfoContext(r.Context(), "websocket disconnected", "client", client)
+}
+
+// wsConn is one WebSocket client. Only writeLoop writes messages; close may
+// be called from any goroutine.
+type wsConn struct {
+	server *Server
+	conn   *websocket.Conn
+	config WebSocketConfig
+	send   chan ServerMessage
+
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu    sync.Mutex
+	all   bool
+	tasks map[string]bool
+}
+
+// close sends a close frame, unless the connection already failed, and ends
+// the connection
+func (c *wsConn) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		if code != websocket.CloseAbnormalClosure {
+			message := websocket.FormatCloseMessage(code, reason)
+			c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+		}
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// enqueue queues a message for the client, disconnecting clients that do not
+// keep up; they reconnect and resubscribe to get the current state
+func (c *wsConn) enqueue(msg ServerMessage) {
+	select {
+	case <-c.done:
+	case c.send <- msg:
+	default:
+		c.close(websocket.CloseTryAgainLater, "client too slow")
+	}
+}
+
+func (c *wsConn) writeLoop() {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.server.shutdown:
+			c.close(websocket.CloseGoingAway, "server shutting down")
+			return
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+// eventLoop forwards events for subscribed tasks
+func (c *wsConn) eventLoop(listener *events.Listener) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case e, ok := <-listener.C:
+			if !ok {
+				c.close(websocket.CloseTryAgainLater, "client too slow")
+				return
+			}
+			if c.subscribed(e.Event) {
+				data := newStreamEvent(e.Event)
+				c.enqueue(ServerMessage{Type: MessageEvent, Event: &data})
+			}
+		}
+	}
+}
+
+// readLoop handles client messages one at a time until the connection ends
+func (c *wsConn) readLoop(ctx context.Context) {
+	defer c.close(websocket.CloseNormalClosure, "")
+
+	pongWait := 2 * c.config.PingInterval
+	c.conn.SetReadLimit(c.config.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		var msg ClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.fail(msg, CodeBadRequest, "Invalid message")
+			continue
+		}
+
+		switch msg.Type {
+		case MessageSubscribe:
+			c.subscribe(ctx, msg)
+		case MessageUnsubscribe:
+			c.unsubscribe(msg)
+		case MessageMutate:
+			c.mutate(ctx, msg)
+		default:
+			c.fail(msg, CodeBadRequest, fmt.Sprintf("Unknown message type %q", msg.Type))
+		}
+	}
+}
+
+func (c *wsConn) fail(msg ClientMessage, code, message string) {
+	c.enqueue(ServerMessage{Type: MessageError, ID: msg.ID, Code: code, Error: message})
+}
+
+// subscribed reports whether the client wants an event. Creations only reach
+// clients subscribed to all tasks, since nobody can know the new task's ID.
+func (c *wsConn) subscribed(e events.Event) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.all || (e.Type != events.TaskCreated && c.tasks[e.TaskID])
+}
+
+// subscribe starts sending events for the given tasks and acks with their
+// current state, so the client knows which versions to mutate
+func (c *wsConn) subscribe(ctx context.Context, msg ClientMessage) {
+	if len(msg.TaskIDs) == 0 {
+		c.fail(msg, CodeBadRequest, "task_ids is required")
+		return
+	}
+
+	c.mu.Lock()
+	for _, id := range msg.TaskIDs {
+		if id == allTasks {
+			c.all = true
+		} else {
+			c.tasks[id] = true
+		}
+	}
+	c.mu.Unlock()
+
+	tasks := make([]*models.Task, 0, len(msg.TaskIDs))
+	for _, id := range msg.TaskIDs {
+		if id == allTasks {
+			all, err := c.server.live.ListTasks(ctx, "")
+			if err != nil {
+				c.fail(msg, CodeInternal, err.Error())
+				return
+			}
+			tasks = all
+			break
+		}
+		// Unknown tasks stay subscribed but have no current state
+		if t, err := c.server.live.GetTask(ctx, id); err == nil {
+			tasks = append(tasks, t)
+		}
+	}
+	c.enqueue(ServerMessage{Type: MessageAck, ID: msg.ID, Tasks: tasks})
+}
+
+func (c *wsConn) unsubscribe(msg ClientMessage) {
+	c.mu.Lock()
+	for _, id := range msg.TaskIDs {
+		if id == allTasks {
+			c.all = false
+		} else {
+			delete(c.tasks, id)
+		}
+	}
+	c.mu.Unlock()
+	c.enqueue(ServerMessage{Type: MessageAck, ID: msg.ID})
+}
+
+// mutate applies a change through the task service. Subscribers, including
+// this connection, also receive the resulting event; a client that creates a
+// task is subscribed to its later changes.
+func (c *wsConn) mutate(ctx context.Context, msg ClientMessage) {
+	live := c.server.live
+
+	if msg.Op == "create" {
+		if msg.Title == nil || strings.TrimSpace(*msg.Title) == "" {
+			c.fail(msg, CodeBadRequest, "Title is required")
+			return
+		}
+		created, err := live.CreateTask(ctx, *msg.Title, msg.DueDate)
+		if err != nil {
+			c.fail(msg, CodeInternal, err.Error())
+			return
+		}
+		c.mu.Lock()
+		c.tasks[created.ID] = true
+		c.mu.Unlock()
+		c.enqueue(ServerMessage{Type: MessageAck, ID: msg.ID, Task: created})
+		return
+	}
+
+	if msg.Op != "update" && msg.Op != "delete" {
+		c.fail(msg, CodeBadRequest, fmt.Sprintf("Unknown operation %q", msg.Op))
+		return
+	}
+	if msg.TaskID == "" || msg.Version == nil {
+		c.fail(msg, CodeBadRequest, "task_id and version are required")
+		return
+	}
+	if msg.Title != nil && strings.TrimSpace(*msg.Title) == "" {
+		c.fail(msg, CodeBadRequest, "Title cannot be empty")
+		return
+	}
+
+	var updated *models.Task
+	var err error
+	if msg.Op == "update" {
+		changes := task.Changes{Title: msg.Title, Done: msg.Done, DueDate: msg.DueDate}
+		updated, err = live.UpdateTaskVersion(ctx, msg.TaskID, *msg.Version, changes)
+	} else {
+		err = live.DeleteTaskVersion(ctx, msg.TaskID, *msg.Version)
+	}
+	if err == nil {
+		c.enqueue(ServerMessage{Type: MessageAck, ID: msg.ID, Task: updated})
+		return
+	}
+
+	current, getErr := live.GetTask(ctx, msg.TaskID)
+	switch {
+	case getErr != nil:
+		c.fail(msg, CodeNotFound, "Task not found")
+	case errors.Is(err, task.ErrVersionConflict):
+		// The current task lets the client rebase its change
+		c.enqueue(ServerMessage{Type: MessageError, ID: msg.ID, Code: CodeVersionConflict, Error: err.Error(), Task: current})
+	default:
+		c.fail(msg, CodeInternal, err.Error())
+	}
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/task"
+
+	"github.com/gorilla/websocket"
+)
+
+type wsTestServer struct {
+	server  *Server
+	url     string
+	service *task.Service
+}
+
+func newWSTestServer(t *testing.T, config WebSocketConfig) *wsTestServer {
+	t.Helper()
+
+	store := storage.NewMemoryStorage()
+	bus := events.NewBus()
+	t.Cleanup(bus.Close)
+	stream := events.NewStream(bus, 100)
+	t.Cleanup(stream.Close)
+
+	service := task.NewService(store, task.WithEventBus(bus))
+	server := NewServer(service, 8080, WithWebSocket(service, stream, config))
+	httpServer := httptest.NewUnstartedServer(server.router)
+	httpServer.Config.WriteTimeout = 100 * time.Millisecond
+	httpServer.Start()
+	t.Cleanup(httpServer.Close)
+
+	return &wsTestServer{
+		server:  server,
+		url:     "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/api/v1/ws",
+		service: service,
+	}
+}
+
+func (ts *wsTestServer) dial(t *testing.T, token string) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial(ts.url, http.Header{"Authorization": {"Bearer " + token}})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v (%v)", err, resp)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg ClientMessage) {
+	t.Helper()
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatalf("Failed to send %s: %v", msg.Type, err)
+	}
+}
+
+func receiveMessage(t *testing.T, conn *websocket.Conn) ServerMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg ServerMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Failed to receive message: %v", err)
+	}
+	return msg
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func stringPtr(v string) *string { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestWebSocket(t *testing.T) {
+	ts := newWSTestServer(t, WebSocketConfig{
+		Tokens:       map[string]string{"alice": "alice-token", "bob": "bob-token"},
+		PingInterval: time.Second,
+	})
+	ctx := t.Context()
+	first, _ := ts.service.CreateTask(ctx, "First", nil)
+	second, _ := ts.service.CreateTask(ctx, "Second", nil)
+
+	t.Run("requires a token", func(t *testing.T) {
+		for _, header := range []http.Header{nil, {"Authorization": {"Bearer wrong"}}} {
+			_, resp, err := websocket.DefaultDialer.Dial(ts.url, header)
+			if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("Expected status 401, got %v", resp)
+			}
+		}
+		conn, _, err := websocket.DefaultDialer.Dial(ts.url+"?access_token=bob-token", nil)
+		if err != nil {
+			t.Fatalf("Expected the query token to be accepted: %v", err)
+		}
+		conn.Close()
+	})
+
+	alice := ts.dial(t, "alice-token")
+	bob := ts.dial(t, "bob-token")
+
+	send(t, alice, ClientMessage{Type: MessageSubscribe, ID: "s1", TaskIDs: []string{first.ID, second.ID}})
+	ack := receiveMessage(t, alice)
+	if ack.Type != MessageAck || ack.ID != "s1" || len(ack.Tasks) != 2 {
+		t.Fatalf("Expected an ack with both tasks, got %+v", ack)
+	}
+
+	// Outlive the server's WriteTimeout before mutating
+	time.Sleep(250 * time.Millisecond)
+
+	t.Run("mutations reach subscribers", func(t *testing.T) {
+		send(t, bob, ClientMessage{Type: MessageMutate, ID: "m1", Op: "update", TaskID: first.ID, Version: int64Ptr(1), Title: stringPtr("Edited by bob")})
+		ack := receiveMessage(t, bob)
+		if ack.Type != MessageAck || ack.ID != "m1" || ack.Task.Version != 2 {
+			t.Fatalf("Expected an ack with version 2, got %+v", ack)
+		}
+
+		event := receiveMessage(t, alice)
+		if event.Type != MessageEvent || event.Event.Type != events.TaskUpdated || event.Event.Task.Title != "Edited by bob" || event.Event.Previous.Version != 1 {
+			t.Errorf("Expected alice to receive the update, got %+v", event)
+		}
+	})
+
+	t.Run("stale versions are rejected", func(t *testing.T) {
+		send(t, alice, ClientMessage{Type: MessageMutate, ID: "m2", Op: "update", TaskID: first.ID, Version: int64Ptr(1), Done: boolPtr(true)})
+		msg := receiveMessage(t, alice)
+		if msg.Type != MessageError || msg.Code != CodeVersionConflict || msg.Task == nil || msg.Task.Version != 2 {
+			t.Fatalf("Expected a version conflict with the current task, got %+v", msg)
+		}
+		if current, _ := ts.service.GetTask(ctx, first.ID); current.Done {
+			t.Error("Expected the stale change not to be applied")
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, msg := range []ClientMessage{
+			{Type: "shout", ID: "e1"},
+			{Type: MessageMutate, ID: "e2", Op: "update", TaskID: first.ID},
+			{Type: MessageMutate, ID: "e3", Op: "create", Title: stringPtr(" ")},
+			{Type: MessageSubscribe, ID: "e4"},
+		} {
+			send(t, bob, msg)
+			if reply := receiveMessage(t, bob); reply.Code != CodeBadRequest || reply.ID != msg.ID {
+				t.Errorf("Expected bad_request for %s, got %+v", msg.ID, reply)
+			}
+		}
+
+		bob.WriteMessage(websocket.TextMessage, []byte("{not json"))
+		if reply := receiveMessage(t, bob); reply.Code != CodeBadRequest {
+			t.Errorf("Expected bad_request for invalid JSON, got %+v", reply)
+		}
+
+		send(t, bob, ClientMessage{Type: MessageMutate, ID: "e5", Op: "delete", TaskID: "missing", Version: int64Ptr(1)})
+		if reply := receiveMessage(t, bob); reply.Code != CodeNotFound {
+			t.Errorf("Expected not_found, got %+v", reply)
+		}
+	})
+
+	t.Run("unsubscribe and create", func(t *testing.T) {
+		send(t, alice, ClientMessage{Type: MessageUnsubscribe, ID: "u1", TaskIDs: []string{first.ID}})
+		if ack := receiveMessage(t, alice); ack.Type != MessageAck || ack.ID != "u1" {
+			t.Fatalf("Expected an unsubscribe ack, got %+v", ack)
+		}
+
+		send(t, bob, ClientMessage{Type: MessageMutate, ID: "m3", Op: "delete", TaskID: first.ID, Version: int64Ptr(2)})
+		receiveMessage(t, bob)
+		send(t, bob, ClientMessage{Type: MessageMutate, ID: "m4", Op: "update", TaskID: second.ID, Version: int64Ptr(1), Done: boolPtr(true)})
+		receiveMessage(t, bob)
+
+		// The deletion of the unsubscribed task is skipped
+		if event := receiveMessage(t, alice); event.Event == nil || event.Event.TaskID != second.ID || event.Event.Type != events.TaskCompleted {
+			t.Errorf("Expected only the completion of the second task, got %+v", event)
+		}
+
+		send(t, alice, ClientMessage{Type: MessageMutate, ID: "c1", Op: "create", Title: stringPtr("Made live")})
+		created := receiveMessage(t, alice)
+		if created.Type != MessageAck || created.Task == nil || created.Task.Version != 1 {
+			t.Fatalf("Expected an ack with the new task, got %+v", created)
+		}
+		// Creators are subscribed to later changes of their new task
+		send(t, bob, ClientMessage{Type: MessageMutate, ID: "m5", Op: "update", TaskID: created.Task.ID, Version: int64Ptr(1), Title: stringPtr("Renamed live")})
+		receiveMessage(t, bob)
+		if event := receiveMessage(t, alice); event.Event == nil || event.Event.TaskID != created.Task.ID || event.Event.Type != events.TaskUpdated {
+			t.Errorf("Expected the new task's update, got %+v", event)
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		ts.server.closeStreams()
+		alice.SetReadDeadline(time.Now().Add(2 * time.Second))
+		for {
+			if _, _, err := alice.ReadMessage(); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+					t.Errorf("Expected a going-away close, got %v", err)
+				}
+				break
+			}
+		}
+	})
+}
+
+func TestWebSocket_Heartbeat(t *testing.T) {
+	ts := newWSTestServer(t, WebSocketConfig{
+		Tokens:       map[string]string{"app": "token"},
+		PingInterval: 50 * time.Millisecond,
+	})
+
+	// The client library answers pings while it reads
+	responsive := ts.dial(t, "token")
+	pinged := make(chan struct{}, 1)
+	responsive.SetPingHandler(func(data string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return responsive.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	received := make(chan ServerMessage, 1)
+	go func() {
+		var msg ServerMessage
+		for responsive.ReadJSON(&msg) == nil {
+			received <- msg
+		}
+	}()
+
+	silent := ts.dial(t, "token")
+
+	select {
+	case <-pinged:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a ping from the server")
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	send(t, responsive, ClientMessage{Type: MessageSubscribe, ID: "s1", TaskIDs: []string{"*"}})
+	select {
+	case msg := <-received:
+		if msg.Type != MessageAck {
+			t.Errorf("Expected an ack, got %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the responsive client to stay connected")
+	}
+
+	// A client that stops answering pings is disconnected
+	silent.SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		if _, _, err := silent.ReadMessage(); err != nil {
+			var netErr interface{ Timeout() bool }
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				t.Error("Expected the silent client to be disconnected")
+			}
+			break
+		}
+	}
+}
+
+func TestWebSocketConfig_Validate(t *testing.T) {
+	if err := (WebSocketConfig{}).Validate(); err == nil {
+		t.Error("Expected an error without tokens")
+	}
+	if err := (WebSocketConfig{Tokens: map[string]string{"app": ""}}).Validate(); err == nil {
+		t.Error("Expected an error for an empty token")
+	}
+	if err := (WebSocketConfig{Tokens: map[string]string{"app": "secret"}}).Validate(); err != nil {
+		t.Errorf("Expected a valid config, got %v", err)
+	}
+}
+
+func TestCheckWebSocketOrigin(t *testing.T) {
+	server := NewServer(NewMockTaskService(), 8080, WithWebSocket(nil, nil, WebSocketConfig{
+		AllowedOrigins: []string{"https://dashboard.example.com"},
+	}))
+
+	tests := map[string]bool{
+		"":                              true,
+		"http://api.example.com":        true,
+		"https://dashboard.example.com": true,
+		"https://evil.example.com":      false,
+	}
+	for origin, want := range tests {
+		req := httptest.NewRequest("GET", "http://api.example.com/api/v1/ws", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if got := server.checkWebSocketOrigin(req); got != want {
+			t.Errorf("Origin %q: expected %v, got %v", origin, want, got)
+		}
+	}
+}
@@ -0,0 +1,518 @@
+// Package backup writes and restores backend-neutral snapshots of all tasks.
+//
+// A snapshot is a gzip-compressed NDJSON file with one task per line, plus a
+// JSON manifest next to it recording the task count, size and SHA-256 of the
+// compressed file:
+//
+//	tasks-20240115T030000Z.ndjson.gz
+//	tasks-20240115T030000Z.manifest.json
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+)
+
+// FormatVersion is written to every manifest and checked on restore
+const FormatVersion = 1
+
+const (
+	filePrefix     = "tasks-"
+	dataSuffix     = ".ndjson.gz"
+	manifestSuffix = ".manifest.json"
+	timeLayout     = "20060102T150405Z"
+)
+
+// Manifest describes a snapshot file
+type Manifest struct {
+	Version   int       `json:"version"`
+	File      string    `json:"file"`
+	CreatedAt time.Time `json:"created_at"`
+	Source    string    `json:"source,omitempty"`
+	TaskCount int       `json:"task_count"`
+	SizeBytes int64     `json:"size_bytes"`
+	SHA256    string    `json:"sha256"`
+}
+
+// Info locates a snapshot on disk
+type Info struct {
+	Path     string
+	Manifest Manifest
+}
+
+// Retention limits how many snapshots are kept. Zero values disable the
+// corresponding limit; the newest snapshot is never removed.
+type Retention struct {
+	MaxCount int
+	MaxAge   time.Duration
+}
+
+// RestoreOptions controls how a snapshot is applied to a store
+type RestoreOptions struct {
+	// Prune deletes tasks that are not in the snapshot
+	Prune bool
+}
+
+// RestoreResult counts the changes made by a restore
+type RestoreResult struct {
+	Created int
+	Updated int
+	Deleted int
+}
+
+// Manager creates, lists and prunes snapshots in a directory
+type Manager struct {
+	dir       string
+	source    string
+	retention Retention
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// Option configures optional Manager behaviour
+type Option func(*Manager)
+
+// WithRetention sets the limits applied by Prune
+func WithRetention(retention Retention) Option {
+	return func(m *Manager) {
+		m.retention = retention
+	}
+}
+
+// WithSource records the storage backend name in manifests
+func WithSource(source string) Option {
+	return func(m *Manager) {
+		m.source = source
+	}
+}
+
+// WithLogger sets the logger used for backup runs
+func WithLogger(logger *slog.Logger) Option {
+	return func(m *Manager) {
+		m.logger = logger
+	}
+}
+
+// NewManager creates a manager for snapshots stored in dir
+func NewManager(dir string, opts ...Option) *Manager {
+	m := &Manager{
+		dir:    dir,
+		logger: slog.Default(),
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Dir returns the snapshot directory
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// Run creates a snapshot and then applies the retention policy
+func (m *Manager) Run(ctx context.Context, store storage.Storage) (*Info, error) {
+	info, err := m.Create(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.Prune(); err != nil {
+		return info, fmt.Errorf("backup created but pruning failed: %w", err)
+	}
+	return info, nil
+}
+
+// Create writes a snapshot of every task in store
+func (m *Manager) Create(ctx context.Context, store storage.Storage) (*Info, error) {
+	tasks, err := storage.WithContext(ctx, store).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tasks: %w", err)
+	}
+	return m.CreateFrom(ctx, tasks)
+}
+
+// CreateFrom writes a snapshot of the given tasks
+func (m *Manager) CreateFrom(ctx context.Context, tasks []*models.Task) (*Info, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	createdAt := m.now().UTC()
+	base := m.uniqueBase(createdAt)
+	dataPath := filepath.Join(m.dir, base+dataSuffix)
+
+	size, sum, err := writeSnapshot(dataPath, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := Manifest{
+		Version:   FormatVersion,
+		File:      filepath.Base(dataPath),
+		CreatedAt: createdAt,
+		Source:    m.source,
+		TaskCount: len(tasks),
+		SizeBytes: size,
+		SHA256:    sum,
+	}
+	if err := writeManifest(manifestPath(dataPath), manifest); err != nil {
+		os.Remove(dataPath)
+		return nil, err
+	}
+
+	m.logger.InfoContext(ctx, "backup created", "file", dataPath, "tasks", len(tasks), "bytes", size)
+	return &Info{Path: dataPath, Manifest: manifest}, nil
+}
+
+// List returns the snapshots in the directory, newest first. Data files
+// without a manifest are incomplete and are skipped.
+func (m *Manager) List() ([]Info, error) {
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var infos []Info
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, manifestSuffix) {
+			continue
+		}
+
+		manifest, err := readManifest(filepath.Join(m.dir, name))
+		if err != nil {
+			m.logger.Warn("skipping unreadable backup manifest", "file", name, "error", err)
+			continue
+		}
+		// The data file is named after its manifest. A manifest naming any other
+		// file, such as "../tasks.json", must not lead Prune to delete it.
+		if want := filepath.Base(DataPath(name)); manifest.File != want {
+			m.logger.Warn("skipping backup manifest naming another data file", "file", name, "data_file", manifest.File)
+			continue
+		}
+		infos = append(infos, Info{Path: filepath.Join(m.dir, manifest.File), Manifest: *manifest})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Manifest.CreatedAt.After(infos[j].Manifest.CreatedAt)
+	})
+	return infos, nil
+}
+
+// Prune removes snapshots beyond the retention limits and returns them
+func (m *Manager) Prune() ([]Info, error) {
+	if m.retention.MaxCount <= 0 && m.retention.MaxAge <= 0 {
+		return nil, nil
+	}
+
+	infos, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := m.now().Add(-m.retention.MaxAge)
+	var removed []Info
+	for i, info := range infos {
+		// Always keep the newest snapshot
+		if i == 0 {
+			continue
+		}
+
+		tooMany := m.retention.MaxCount > 0 && i >= m.retention.MaxCount
+		tooOld := m.retention.MaxAge > 0 && info.Manifest.CreatedAt.Before(cutoff)
+		if !tooMany && !tooOld {
+			continue
+		}
+
+		if err := remove(info.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, info)
+		m.logger.Info("backup removed by retention policy", "file", info.Path)
+	}
+
+	return removed, nil
+}
+
+// uniqueBase returns a file name stem for t that is not used in the directory yet
+func (m *Manager) uniqueBase(t time.Time) string {
+	base := filePrefix + t.Format(timeLayout)
+	candidate := base
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filepath.Join(m.dir, candidate+dataSuffix)); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// Verify checks a snapshot against its manifest. path may name either the
+// data file or the manifest.
+func Verify(path string) (*Manifest, error) {
+	dataPath := DataPath(path)
+
+	manifest, err := readManifest(manifestPath(dataPath))
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d", manifest.Version)
+	}
+
+	file, err := os.Open(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	if size != manifest.SizeBytes {
+		return nil, fmt.Errorf("backup size %d does not match manifest size %d", size, manifest.SizeBytes)
+	}
+	if sum := hex.EncodeToString(hasher.Sum(nil)); sum != manifest.SHA256 {
+		return nil, fmt.Errorf("backup checksum %s does not match manifest checksum %s", sum, manifest.SHA256)
+	}
+
+	return manifest, nil
+}
+
+// ReadTasks verifies a snapshot and decodes its tasks
+func ReadTasks(path string) ([]*models.Task, error) {
+	manifest, err := Verify(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(DataPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress backup: %w", err)
+	}
+	defer gz.Close()
+
+	tasks := make([]*models.Task, 0, manifest.TaskCount)
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var task models.Task
+		if err := json.Unmarshal(scanner.Bytes(), &task); err != nil {
+			return nil, fmt.Errorf("invalid task on line %d: %w", line, err)
+		}
+		tasks = append(tasks, &task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if len(tasks) != manifest.TaskCount {
+		return nil, fmt.Errorf("backup contains %d tasks but manifest lists %d", len(tasks), manifest.TaskCount)
+	}
+	return tasks, nil
+}
+
+// Restore verifies a snapshot and writes its tasks to store, creating missing
+// tasks and overwriting existing ones with the same ID. The restore runs in a
+// transaction, so on error the store is left as it was. Storages that cannot
+// run transactions are restored one change at a time, and on error the result
+// counts the changes already made.
+func Restore(ctx context.Context, store storage.Storage, path string, opts RestoreOptions) (*RestoreResult, error) {
+	tasks, err := ReadTasks(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{}
+	err = storage.WithTx(ctx, store, func(tx storage.Storage) error {
+		*result = RestoreResult{}
+		return restore(ctx, tx, tasks, opts, result)
+	})
+	if errors.Is(err, storage.ErrTxUnsupported) {
+		*result = RestoreResult{}
+		return result, restore(ctx, store, tasks, opts, result)
+	}
+	if err != nil {
+		*result = RestoreResult{}
+	}
+	return result, err
+}
+
+// restore writes tasks to store, counting the changes in result
+func restore(ctx context.Context, store storage.Storage, tasks []*models.Task, opts RestoreOptions, result *RestoreResult) error {
+	store = storage.WithContext(ctx, store)
+	existing, err := store.GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to read current tasks: %w", err)
+	}
+	current := make(map[string]bool, len(existing))
+	for _, task := range existing {
+		current[task.ID] = true
+	}
+
+	restored := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		restored[task.ID] = true
+		if current[task.ID] {
+			if err := store.Update(task); err != nil {
+				return fmt.Errorf("failed to update task %s: %w", task.ID, err)
+			}
+			result.Updated++
+			continue
+		}
+		if err := store.Create(task); err != nil {
+			return fmt.Errorf("failed to create task %s: %w", task.ID, err)
+		}
+		result.Created++
+	}
+
+	if opts.Prune {
+		for _, task := range existing {
+			if restored[task.ID] {
+				continue
+			}
+			if err := store.Delete(task.ID); err != nil {
+				return fmt.Errorf("failed to delete task %s: %w", task.ID, err)
+			}
+			result.Deleted++
+		}
+	}
+
+	return nil
+}
+
+// DataPath returns the data file for path, which may name the data file or its manifest
+func DataPath(path string) string {
+	if strings.HasSuffix(path, manifestSuffix) {
+		return strings.TrimSuffix(path, manifestSuffix) + dataSuffix
+	}
+	return path
+}
+
+func manifestPath(dataPath string) string {
+	return strings.TrimSuffix(dataPath, dataSuffix) + manifestSuffix
+}
+
+// writeSnapshot writes tasks as gzip NDJSON via a temporary file and returns
+// the compressed size and SHA-256
+func writeSnapshot(path string, tasks []*models.Task) (int64, string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".backup-*")
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(tmp, hasher)}
+	gz := gzip.NewWriter(counter)
+	encoder := json.NewEncoder(gz)
+	for _, task := range tasks {
+		if err := encoder.Encode(task); err != nil {
+			return 0, "", fmt.Errorf("failed to encode task %s: %w", task.ID, err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return 0, "", fmt.Errorf("failed to compress backup: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return 0, "", fmt.Errorf("failed to flush backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, "", fmt.Errorf("failed to close backup: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, "", fmt.Errorf("failed to finalize backup: %w", err)
+	}
+
+	return counter.n, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func writeManifest(path string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to finalize manifest: %w", err)
+	}
+	return nil
+}
+
+func readManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// remove deletes a snapshot's manifest first so a partial removal is never listed
+func remove(dataPath string) error {
+	if err := os.Remove(manifestPath(dataPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove manifest: %w", err)
+	}
+	if err := os.Remove(dataPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove backup: %w", err)
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,311 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+)
+
+func newTestStorage(t *testing.T) storage.Storage {
+	t.Helper()
+
+	store := storage.NewMemoryStorage()
+	return store
+}
+
+func seed(t *testing.T, store storage.Storage, ids ...string) {
+	t.Helper()
+
+	due := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range ids {
+		task := &models.Task{
+			ID:        id,
+			Title:     "Task " + id,
+			Done:      i%2 == 0,
+			CreatedAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+			DueDate:   &due,
+		}
+		if err := store.Create(task); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+}
+
+func TestManager_CreateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	source := newTestStorage(t)
+	seed(t, source, "a", "b", "c")
+
+	manager := NewManager(t.TempDir(), WithSource("json"))
+	info, err := manager.Create(ctx, source)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+
+	if info.Manifest.TaskCount != 3 || info.Manifest.Source != "json" || info.Manifest.SHA256 == "" {
+		t.Errorf("Unexpected manifest: %+v", info.Manifest)
+	}
+	if !strings.HasSuffix(info.Path, ".ndjson.gz") {
+		t.Errorf("Expected gzip NDJSON file, got %s", info.Path)
+	}
+
+	target := newTestStorage(t)
+	seed(t, target, "b", "stale")
+
+	result, err := Restore(ctx, target, info.Path, RestoreOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+	if result.Created != 2 || result.Updated != 1 || result.Deleted != 1 {
+		t.Errorf("Unexpected restore result: %+v", result)
+	}
+
+	tasks, err := target.GetAll()
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("Expected 3 tasks after restore, got %d", len(tasks))
+	}
+	restored, err := target.GetByID("a")
+	if err != nil {
+		t.Fatalf("Failed to get restored task: %v", err)
+	}
+	if restored.Title != "Task a" || !restored.Done || restored.DueDate == nil {
+		t.Errorf("Restored task does not match original: %+v", restored)
+	}
+}
+
+func TestRestore_AcceptsManifestPath(t *testing.T) {
+	ctx := context.Background()
+	source := newTestStorage(t)
+	seed(t, source, "a")
+
+	info, err := NewManager(t.TempDir()).Create(ctx, source)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+
+	result, err := Restore(ctx, newTestStorage(t), manifestPath(info.Path), RestoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to restore from manifest path: %v", err)
+	}
+	if result.Created != 1 {
+		t.Errorf("Expected 1 created task, got %+v", result)
+	}
+}
+
+// failingDeleteStorage fails every delete
+type failingDeleteStorage struct {
+	storage.Storage
+}
+
+func (s failingDeleteStorage) Delete(id string) error {
+	return errors.New("delete failed")
+}
+
+// failingDeleteTxStorage fails every delete made in a transaction
+type failingDeleteTxStorage struct {
+	storage.Storage
+}
+
+func (s failingDeleteTxStorage) WithTx(ctx context.Context, fn func(tx storage.Storage) error) error {
+	return storage.WithTx(ctx, s.Storage, func(tx storage.Storage) error {
+		return fn(failingDeleteStorage{tx})
+	})
+}
+
+func TestRestore_FailureMidway(t *testing.T) {
+	ctx := context.Background()
+	source := newTestStorage(t)
+	seed(t, source, "a", "b")
+
+	info, err := NewManager(t.TempDir()).Create(ctx, source)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+
+	t.Run("transaction leaves the store unchanged", func(t *testing.T) {
+		target := newTestStorage(t)
+		seed(t, target, "stale")
+
+		result, err := Restore(ctx, failingDeleteTxStorage{target}, info.Path, RestoreOptions{Prune: true})
+		if err == nil {
+			t.Fatal("Expected restore to fail")
+		}
+		if *result != (RestoreResult{}) {
+			t.Errorf("Expected no changes to be reported, got %+v", result)
+		}
+
+		tasks, err := target.GetAll()
+		if err != nil {
+			t.Fatalf("Failed to list tasks: %v", err)
+		}
+		if len(tasks) != 1 || tasks[0].ID != "stale" {
+			t.Errorf("Expected only the stale task to remain, got %d tasks", len(tasks))
+		}
+	})
+
+	t.Run("without transactions the partial result is reported", func(t *testing.T) {
+		target := newTestStorage(t)
+		seed(t, target, "stale")
+
+		result, err := Restore(ctx, failingDeleteStorage{target}, info.Path, RestoreOptions{Prune: true})
+		if err == nil {
+			t.Fatal("Expected restore to fail")
+		}
+		if result.Created != 2 || result.Deleted != 0 {
+			t.Errorf("Expected 2 created tasks and no deletions, got %+v", result)
+		}
+	})
+}
+
+func TestVerify_DetectsCorruption(t *testing.T) {
+	ctx := context.Background()
+	source := newTestStorage(t)
+	seed(t, source, "a", "b")
+
+	info, err := NewManager(t.TempDir()).Create(ctx, source)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if _, err := Verify(info.Path); err != nil {
+		t.Fatalf("Expected fresh backup to verify, got %v", err)
+	}
+
+	data, err := os.ReadFile(info.Path)
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	data[len(data)/2] ^= 0xff
+	if err := os.WriteFile(info.Path, data, 0644); err != nil {
+		t.Fatalf("Failed to corrupt backup: %v", err)
+	}
+
+	if _, err := Verify(info.Path); err == nil {
+		t.Error("Expected checksum mismatch, got nil")
+	}
+	if _, err := Restore(ctx, newTestStorage(t), info.Path, RestoreOptions{}); err == nil {
+		t.Error("Expected restore of a corrupt backup to fail")
+	}
+}
+
+func TestManager_Prune(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+	seed(t, store, "a")
+
+	now := time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC)
+	manager := NewManager(t.TempDir(), WithRetention(Retention{MaxCount: 3, MaxAge: 72 * time.Hour}))
+	manager.now = func() time.Time { return now }
+
+	// One backup a day for five days
+	for day := 0; day < 5; day++ {
+		now = time.Date(2024, 1, 10+day, 3, 0, 0, 0, time.UTC)
+		if _, err := manager.Create(ctx, store); err != nil {
+			t.Fatalf("Failed to create backup: %v", err)
+		}
+	}
+
+	removed, err := manager.Prune()
+	if err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("Expected 2 backups removed, got %d", len(removed))
+	}
+
+	infos, err := manager.List()
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(infos) != 3 || infos[0].Manifest.CreatedAt.Day() != 14 {
+		t.Errorf("Expected the 3 newest backups, got %+v", infos)
+	}
+
+	// Age limit removes everything but the newest backup
+	now = now.Add(30 * 24 * time.Hour)
+	if _, err := manager.Prune(); err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	infos, _ = manager.List()
+	if len(infos) != 1 {
+		t.Errorf("Expected the newest backup to be kept, got %d backups", len(infos))
+	}
+}
+
+func TestManager_List_SkipsManifestsNamingOtherFiles(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+	root := t.TempDir()
+	dir := filepath.Join(root, "backups")
+	outside := filepath.Join(root, "tasks.json")
+	if err := os.WriteFile(outside, []byte("[]"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	now := time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC)
+	manager := NewManager(dir, WithRetention(Retention{MaxCount: 1}))
+	manager.now = func() time.Time { return now }
+	var created []*Info
+	for day := 0; day < 2; day++ {
+		now = now.Add(24 * time.Hour)
+		info, err := manager.Create(ctx, store)
+		if err != nil {
+			t.Fatalf("Failed to create backup: %v", err)
+		}
+		created = append(created, info)
+	}
+
+	// Point the older manifest outside the backup directory
+	oldest := manifestPath(created[0].Path)
+	manifest := created[0].Manifest
+	manifest.File = "../tasks.json"
+	data, _ := json.Marshal(manifest)
+	if err := os.WriteFile(oldest, data, 0644); err != nil {
+		t.Fatalf("Failed to rewrite manifest: %v", err)
+	}
+
+	infos, err := manager.List()
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Path != created[1].Path {
+		t.Errorf("Expected only the untampered backup, got %+v", infos)
+	}
+	if _, err := manager.Prune(); err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("Expected the file outside the backup directory to survive pruning: %v", err)
+	}
+}
+
+func TestManager_UniqueNames(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	manager := NewManager(t.TempDir())
+	fixed := time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return fixed }
+
+	first, err := manager.Create(ctx, store)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	second, err := manager.Create(ctx, store)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if first.Path == second.Path {
+		t.Errorf("Expected distinct backup files, both were %s", first.Path)
+	}
+}
@@ -0,0 +1,173 @@
+// Package cleanup removes tasks that were completed longer ago than the
+// configured retention period, optionally archiving them first.
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"GoTask_Management/internal/backup"
+	"GoTask_Management/internal/task"
+)
+
+// Mode selects what happens to expired tasks
+type Mode string
+
+const (
+	// ModePurge deletes expired tasks
+	ModePurge Mode = "purge"
+	// ModeArchive writes expired tasks to a snapshot in the archive directory before deleting them
+	ModeArchive Mode = "archive"
+)
+
+// Config describes a cleanup policy
+type Config struct {
+	// After is how long a task stays after it was completed
+	After time.Duration
+	Mode  Mode
+	// ArchiveDir receives archive snapshots in ModeArchive
+	ArchiveDir string
+	// DryRun reports what would be removed without changing anything
+	DryRun bool
+}
+
+// Validate checks the configuration for errors
+func (c Config) Validate() error {
+	if c.After <= 0 {
+		return errors.New("cleanup retention must be positive")
+	}
+	switch c.Mode {
+	case ModePurge:
+	case ModeArchive:
+		if c.ArchiveDir == "" {
+			return errors.New("archive mode requires an archive directory")
+		}
+	default:
+		return fmt.Errorf("unknown cleanup mode %q (expected purge or archive)", c.Mode)
+	}
+	return nil
+}
+
+// Report describes the outcome of a cleanup run
+type Report struct {
+	Cutoff time.Time
+	Mode   Mode
+	DryRun bool
+	// TaskIDs lists the expired tasks; in a dry run nothing was removed
+	TaskIDs []string
+	Removed int
+	// Skipped lists expired tasks that changed after they were listed, such
+	// as tasks reopened meanwhile; they are kept, though in ModeArchive they
+	// are in the archive too
+	Skipped []string
+	// Backfilled counts done tasks that were given a completion time
+	Backfilled int
+	// Archive is the snapshot written in ModeArchive
+	Archive string
+}
+
+// Cleaner applies a cleanup policy through the task service
+type Cleaner struct {
+	service  *task.Service
+	config   Config
+	archiver *backup.Manager
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// Option configures optional Cleaner behaviour
+type Option func(*Cleaner)
+
+// WithLogger sets the logger used for cleanup runs
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Cleaner) {
+		c.logger = logger
+	}
+}
+
+// New creates a cleaner for the given policy
+func New(service *task.Service, config Config, opts ...Option) (*Cleaner, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := &Cleaner{
+		service: service,
+		config:  config,
+		logger:  slog.Default(),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if config.Mode == ModeArchive {
+		c.archiver = backup.NewManager(config.ArchiveDir, backup.WithLogger(c.logger))
+	}
+
+	return c, nil
+}
+
+// Run removes tasks completed before the retention cutoff. Done tasks without
+// a completion time are stamped with the current time first, so they expire
+// one retention period from now.
+func (c *Cleaner) Run(ctx context.Context) (*Report, error) {
+	report := &Report{
+		Cutoff: c.now().Add(-c.config.After),
+		Mode:   c.config.Mode,
+		DryRun: c.config.DryRun,
+	}
+
+	if !c.config.DryRun {
+		backfilled, err := c.service.BackfillCompletedAt(ctx)
+		report.Backfilled = backfilled
+		if err != nil {
+			return report, err
+		}
+	}
+
+	expired, err := c.service.ListCompletedBefore(ctx, report.Cutoff)
+	if err != nil {
+		return report, fmt.Errorf("failed to list completed tasks: %w", err)
+	}
+	for _, t := range expired {
+		report.TaskIDs = append(report.TaskIDs, t.ID)
+	}
+
+	if c.config.DryRun {
+		c.logger.InfoContext(ctx, "cleanup dry run", "mode", c.config.Mode, "cutoff", report.Cutoff, "would_remove", len(expired), "task_ids", report.TaskIDs)
+		return report, nil
+	}
+	if len(expired) == 0 {
+		return report, nil
+	}
+
+	if c.archiver != nil {
+		info, err := c.archiver.CreateFrom(ctx, expired)
+		if err != nil {
+			return report, fmt.Errorf("failed to archive tasks, nothing was removed: %w", err)
+		}
+		report.Archive = info.Path
+	}
+
+	// Only delete tasks as they were listed, so that changes made meanwhile are kept
+	for _, t := range expired {
+		err := c.service.DeleteTaskVersion(ctx, t.ID, t.Version)
+		if errors.Is(err, task.ErrVersionConflict) {
+			c.logger.InfoContext(ctx, "task changed since it was listed, keeping it", "task_id", t.ID)
+			report.Skipped = append(report.Skipped, t.ID)
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to remove task %s: %w", t.ID, err)
+		}
+		report.Removed++
+	}
+
+	c.logger.InfoContext(ctx, "cleanup completed", "mode", c.config.Mode, "cutoff", report.Cutoff, "removed", report.Removed, "skipped", len(report.Skipped), "archive", report.Archive)
+	return report, nil
+}
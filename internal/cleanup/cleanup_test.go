@@ -0,0 +1,192 @@
+package cleanup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/backup"
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/task"
+)
+
+// newSeededService returns a service over a JSON store holding one task
+// completed 40 days ago, one 5 days ago, one legacy done task and one pending task
+func newSeededService(t *testing.T) (*task.Service, storage.Storage) {
+	t.Helper()
+
+	store := storage.NewMemoryStorage()
+
+	old := time.Now().AddDate(0, 0, -40)
+	recent := time.Now().AddDate(0, 0, -5)
+	tasks := []*models.Task{
+		{ID: "old", Title: "Old", Done: true, CreatedAt: old, CompletedAt: &old},
+		{ID: "recent", Title: "Recent", Done: true, CreatedAt: old, CompletedAt: &recent},
+		{ID: "legacy", Title: "Legacy", Done: true, CreatedAt: old},
+		{ID: "pending", Title: "Pending", CreatedAt: old},
+	}
+	for _, tk := range tasks {
+		if err := store.Create(tk); err != nil {
+			t.Fatalf("Failed to seed task: %v", err)
+		}
+	}
+
+	return task.NewService(store), store
+}
+
+func remainingIDs(t *testing.T, store storage.Storage) map[string]bool {
+	t.Helper()
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, tk := range tasks {
+		ids[tk.ID] = true
+	}
+	return ids
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"purge", Config{After: time.Hour, Mode: ModePurge}, false},
+		{"archive", Config{After: time.Hour, Mode: ModeArchive, ArchiveDir: "archive"}, false},
+		{"archive without dir", Config{After: time.Hour, Mode: ModeArchive}, true},
+		{"zero retention", Config{Mode: ModePurge}, true},
+		{"unknown mode", Config{After: time.Hour, Mode: "shred"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCleaner_Purge(t *testing.T) {
+	service, store := newSeededService(t)
+
+	cleaner, err := New(service, Config{After: 30 * 24 * time.Hour, Mode: ModePurge})
+	if err != nil {
+		t.Fatalf("Failed to create cleaner: %v", err)
+	}
+
+	report, err := cleaner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if report.Removed != 1 || report.Backfilled != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	ids := remainingIDs(t, store)
+	if ids["old"] || !ids["recent"] || !ids["legacy"] || !ids["pending"] {
+		t.Errorf("Expected only the old task to be removed, remaining: %v", ids)
+	}
+}
+
+// reopeningStorage reopens the task with id the first time it is read by
+// ID, as another client editing it during a cleanup run would
+type reopeningStorage struct {
+	storage.Storage
+	id       string
+	reopened bool
+}
+
+func (r *reopeningStorage) GetByID(id string) (*models.Task, error) {
+	if id == r.id && !r.reopened {
+		r.reopened = true
+		tk, err := r.Storage.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		tk.Done, tk.CompletedAt, tk.Version = false, nil, tk.Version+1
+		if err := r.Storage.Update(tk); err != nil {
+			return nil, err
+		}
+	}
+	return r.Storage.GetByID(id)
+}
+
+func TestCleaner_KeepsTasksChangedDuringRun(t *testing.T) {
+	_, store := newSeededService(t)
+	service := task.NewService(&reopeningStorage{Storage: store, id: "old"})
+
+	cleaner, err := New(service, Config{After: 30 * 24 * time.Hour, Mode: ModePurge})
+	if err != nil {
+		t.Fatalf("Failed to create cleaner: %v", err)
+	}
+
+	report, err := cleaner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if report.Removed != 0 || len(report.Skipped) != 1 || report.Skipped[0] != "old" {
+		t.Errorf("Expected the reopened task to be skipped, got %+v", report)
+	}
+	if !remainingIDs(t, store)["old"] {
+		t.Error("Expected the reopened task to be kept")
+	}
+}
+
+func TestCleaner_DryRun(t *testing.T) {
+	service, store := newSeededService(t)
+
+	cleaner, err := New(service, Config{After: 30 * 24 * time.Hour, Mode: ModePurge, DryRun: true})
+	if err != nil {
+		t.Fatalf("Failed to create cleaner: %v", err)
+	}
+
+	report, err := cleaner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if len(report.TaskIDs) != 1 || report.TaskIDs[0] != "old" || report.Removed != 0 {
+		t.Errorf("Unexpected dry run report: %+v", report)
+	}
+	if len(remainingIDs(t, store)) != 4 {
+		t.Error("Expected dry run to leave all tasks in place")
+	}
+
+	legacy, _ := store.GetByID("legacy")
+	if legacy.CompletedAt != nil {
+		t.Error("Expected dry run not to backfill completion times")
+	}
+}
+
+func TestCleaner_Archive(t *testing.T) {
+	service, store := newSeededService(t)
+	archiveDir := t.TempDir()
+
+	cleaner, err := New(service, Config{After: 30 * 24 * time.Hour, Mode: ModeArchive, ArchiveDir: archiveDir})
+	if err != nil {
+		t.Fatalf("Failed to create cleaner: %v", err)
+	}
+
+	report, err := cleaner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if report.Archive == "" || report.Removed != 1 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if remainingIDs(t, store)["old"] {
+		t.Error("Expected archived task to be removed")
+	}
+
+	archived, err := backup.ReadTasks(report.Archive)
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	if len(archived) != 1 || archived[0].ID != "old" || archived[0].CompletedAt == nil {
+		t.Errorf("Unexpected archive contents: %+v", archived)
+	}
+}
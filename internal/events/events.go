@@ -0,0 +1,300 @@
+// Package events is an in-process bus for task lifecycle events. Publishers
+// do not know their subscribers; each subscriber runs synchronously or from
+// its own bounded queue, and a panicking subscriber does not affect others.
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"GoTask_Management/internal/models"
+)
+
+// Type names a task lifecycle event
+type Type string
+
+const (
+	TaskCreated   Type = "task.created"
+	TaskUpdated   Type = "task.updated"
+	TaskCompleted Type = "task.completed"
+	TaskDeleted   Type = "task.deleted"
+)
+
+// Types lists every task event type
+var Types = []Type{TaskCreated, TaskUpdated, TaskCompleted, TaskDeleted}
+
+// Valid reports whether t is a known event type
+func (t Type) Valid() bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event describes one change to a task. Before is nil for creations and After
+// is nil for deletions.
+type Event struct {
+	// ID is assigned by the bus and increases with every published event
+	ID         uint64
+	Type       Type
+	TaskID     string
+	Before     *models.Task
+	After      *models.Task
+	OccurredAt time.Time
+}
+
+// Task returns the task as it is after the change, or as it was before a deletion
+func (e Event) Task() *models.Task {
+	if e.After != nil {
+		return e.After
+	}
+	return e.Before
+}
+
+// Handler receives published events
+type Handler func(ctx context.Context, event Event)
+
+// DefaultQueueSize is the queue length of async subscribers created with a size of zero
+const DefaultQueueSize = 256
+
+// Bus delivers published events to subscribers
+type Bus struct {
+	logger *slog.Logger
+	seq    atomic.Uint64
+
+	mu     sync.RWMutex
+	subs   []*subscription
+	closed bool
+}
+
+// Option configures optional Bus behaviour
+type Option func(*Bus)
+
+// WithLogger sets the logger used for dropped events and handler panics
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *Bus) {
+		b.logger = logger
+	}
+}
+
+// NewBus creates an empty bus
+func NewBus(opts ...Option) *Bus {
+	b := &Bus{logger: slog.Default()}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+type subscription struct {
+	name    string
+	handler Handler
+	types   map[Type]bool
+
+	// queue is nil for synchronous subscribers
+	queueSize int
+	queue     chan queued
+	done      chan struct{}
+
+	dropped atomic.Int64
+	panics  atomic.Int64
+}
+
+type queued struct {
+	ctx   context.Context
+	event Event
+}
+
+// SubscribeOption configures a subscription
+type SubscribeOption func(*subscription)
+
+// Async delivers events from a queue of the given size on a dedicated
+// goroutine. Events published while the queue is full are dropped.
+func Async(queueSize int) SubscribeOption {
+	return func(s *subscription) {
+		if queueSize <= 0 {
+			queueSize = DefaultQueueSize
+		}
+		s.queueSize = queueSize
+	}
+}
+
+// OfType restricts a subscription to the given event types
+func OfType(types ...Type) SubscribeOption {
+	return func(s *subscription) {
+		s.types = make(map[Type]bool, len(types))
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+}
+
+func (s *subscription) wants(t Type) bool {
+	return s.types == nil || s.types[t]
+}
+
+// Subscribe registers handler under name, used in logs and Stats. Without
+// Async the handler runs on the publisher's goroutine before Publish returns.
+// The returned function removes the subscription, waiting for an async
+// subscriber to finish its queue.
+func (b *Bus) Subscribe(name string, handler Handler, opts ...SubscribeOption) func() {
+	s := &subscription{name: name, handler: handler}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return func() {}
+	}
+	if s.queueSize > 0 {
+		s.queue = make(chan queued, s.queueSize)
+		s.done = make(chan struct{})
+		go b.run(s)
+	}
+	b.subs = append(b.subs, s)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.unsubscribe(s) })
+	}
+}
+
+func (b *Bus) unsubscribe(s *subscription) {
+	b.mu.Lock()
+	removed := false
+	for i, sub := range b.subs {
+		if sub == s {
+			b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	if removed && s.queue != nil {
+		close(s.queue)
+	}
+	b.mu.Unlock()
+
+	if removed && s.done != nil {
+		<-s.done
+	}
+}
+
+// Publish stamps the event with an ID and, if unset, the current time, then
+// hands it to every matching subscriber. Async subscribers receive a context
+// that keeps the publisher's values but not its cancellation.
+func (b *Bus) Publish(ctx context.Context, event Event) Event {
+	event.ID = b.seq.Add(1)
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	var direct []*subscription
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return event
+	}
+	for _, s := range b.subs {
+		if !s.wants(event.Type) {
+			continue
+		}
+		if s.queue == nil {
+			direct = append(direct, s)
+			continue
+		}
+		select {
+		case s.queue <- queued{ctx: context.WithoutCancel(ctx), event: event}:
+		default:
+			s.dropped.Add(1)
+			b.logger.WarnContext(ctx, "event dropped, subscriber queue full", "subscriber", s.name, "event", event.Type, "task_id", event.TaskID)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, s := range direct {
+		b.call(ctx, s, event)
+	}
+	return event
+}
+
+func (b *Bus) run(s *subscription) {
+	defer close(s.done)
+	for item := range s.queue {
+		b.call(item.ctx, s, item.event)
+	}
+}
+
+// call runs a handler, recovering and logging a panic
+func (b *Bus) call(ctx context.Context, s *subscription, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.panics.Add(1)
+			b.logger.ErrorContext(ctx, "event subscriber panicked", "subscriber", s.name, "event", event.Type, "task_id", event.TaskID, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+		}
+	}()
+	s.handler(ctx, event)
+}
+
+// Close stops accepting events and waits for async subscribers to drain their queues
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	subs := b.subs
+	b.subs = nil
+	for _, s := range subs {
+		if s.queue != nil {
+			close(s.queue)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		if s.done != nil {
+			<-s.done
+		}
+	}
+}
+
+// SubscriberStats reports the state of one subscription
+type SubscriberStats struct {
+	Name  string `json:"name"`
+	Async bool   `json:"async"`
+	// Queued is the number of events waiting in an async subscriber's queue
+	Queued  int   `json:"queued"`
+	Dropped int64 `json:"dropped"`
+	Panics  int64 `json:"panics"`
+}
+
+// Stats reports every current subscription in subscription order
+func (b *Bus) Stats() []SubscriberStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stats := make([]SubscriberStats, 0, len(b.subs))
+	for _, s := range b.subs {
+		stats = append(stats, SubscriberStats{
+			Name:    s.name,
+			Async:   s.queue != nil,
+			Queued:  len(s.queue),
+			Dropped: s.dropped.Load(),
+			Panics:  s.panics.Load(),
+		})
+	}
+	return stats
+}
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+)
+
+func taskEvent(eventType Type, id string) Event {
+	return Event{Type: eventType, TaskID: id, After: &models.Task{ID: id}}
+}
+
+func TestBus_SyncDelivery(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+
+	all := Record(t, bus)
+	completed := Record(t, bus, OfType(TaskCompleted))
+
+	first := bus.Publish(context.Background(), taskEvent(TaskCreated, "t1"))
+	second := bus.Publish(context.Background(), taskEvent(TaskCompleted, "t1"))
+
+	all.AssertTypes(t, TaskCreated, TaskCompleted)
+	completed.AssertTypes(t, TaskCompleted)
+	if second.ID <= first.ID || first.OccurredAt.IsZero() {
+		t.Errorf("Expected increasing IDs and a timestamp, got %+v and %+v", first, second)
+	}
+	if got := all.Events()[1]; got.ID != second.ID {
+		t.Errorf("Expected subscribers to see the assigned ID, got %d", got.ID)
+	}
+}
+
+func TestBus_AsyncDelivery(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+
+	type ctxKey struct{}
+	var mu sync.Mutex
+	var values []interface{}
+	bus.Subscribe("ctx", func(ctx context.Context, e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		values = append(values, ctx.Value(ctxKey{}))
+		if ctx.Err() != nil {
+			t.Error("Expected async handlers to outlive the publisher's context")
+		}
+	}, Async(10))
+	rec := Record(t, bus, Async(10))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request-1"))
+	bus.Publish(ctx, taskEvent(TaskCreated, "t1"))
+	cancel()
+	bus.Publish(context.Background(), taskEvent(TaskDeleted, "t1"))
+
+	rec.WaitFor(t, 2, time.Second)
+	rec.AssertTypes(t, TaskCreated, TaskDeleted)
+
+	bus.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(values) != 2 || values[0] != "request-1" {
+		t.Errorf("Expected context values to be kept, got %v", values)
+	}
+}
+
+func TestBus_BoundedQueueDrops(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	bus.Subscribe("slow", func(ctx context.Context, e Event) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	}, Async(2))
+
+	bus.Publish(context.Background(), taskEvent(TaskCreated, "t0"))
+	<-started // the worker holds t0; the queue is empty again
+	for i := 0; i < 5; i++ {
+		bus.Publish(context.Background(), taskEvent(TaskUpdated, "t1"))
+	}
+
+	stats := bus.Stats()
+	if len(stats) != 1 || stats[0].Queued != 2 || stats[0].Dropped != 3 {
+		t.Errorf("Expected 2 queued and 3 dropped, got %+v", stats)
+	}
+	close(release)
+}
+
+func TestBus_PanicIsolation(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+
+	bus.Subscribe("sync-panic", func(ctx context.Context, e Event) { panic("boom") })
+	bus.Subscribe("async-panic", func(ctx context.Context, e Event) { panic("boom") }, Async(1))
+	syncRec := Record(t, bus)
+	asyncRec := Record(t, bus, Async(10))
+
+	bus.Publish(context.Background(), taskEvent(TaskCreated, "t1"))
+	bus.Publish(context.Background(), taskEvent(TaskUpdated, "t1"))
+
+	syncRec.AssertTypes(t, TaskCreated, TaskUpdated)
+	asyncRec.WaitFor(t, 2, time.Second)
+
+	// The async worker keeps running after a panic
+	deadline := time.Now().Add(time.Second)
+	for {
+		stats := bus.Stats()
+		if stats[0].Panics == 2 && stats[1].Panics+stats[1].Dropped == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected panics to be counted, got %+v", stats)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBus_UnsubscribeAndClose(t *testing.T) {
+	bus := NewBus()
+	rec := NewRecorder()
+	unsubscribe := bus.Subscribe("rec", rec.Handle, Async(10))
+
+	bus.Publish(context.Background(), taskEvent(TaskCreated, "t1"))
+	unsubscribe()
+	unsubscribe()
+	rec.AssertTypes(t, TaskCreated)
+
+	bus.Publish(context.Background(), taskEvent(TaskUpdated, "t1"))
+	rec.AssertTypes(t, TaskCreated)
+
+	other := NewRecorder()
+	bus.Subscribe("other", other.Handle, Async(10))
+	bus.Publish(context.Background(), taskEvent(TaskUpdated, "t1"))
+	bus.Close()
+	other.AssertTypes(t, TaskUpdated)
+
+	bus.Publish(context.Background(), taskEvent(TaskDeleted, "t1"))
+	bus.Subscribe("late", other.Handle)()
+	other.AssertTypes(t, TaskUpdated)
+}
+
+func TestEvent_Task(t *testing.T) {
+	before := &models.Task{ID: "t1", Title: "Before"}
+	after := &models.Task{ID: "t1", Title: "After"}
+
+	if got := (Event{Before: before, After: after}).Task(); got != after {
+		t.Errorf("Expected the after snapshot, got %+v", got)
+	}
+	if got := (Event{Type: TaskDeleted, Before: before}).Task(); got != before {
+		t.Errorf("Expected the before snapshot for deletions, got %+v", got)
+	}
+	if !TaskCompleted.Valid() || Type("task.exploded").Valid() {
+		t.Error("Unexpected Valid() result")
+	}
+}
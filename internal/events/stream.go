@@ -0,0 +1,177 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultReplaySize is the number of events a Stream keeps for replay
+	DefaultReplaySize = 1000
+	// listenerBuffer is how many events a listener may fall behind before it is dropped
+	listenerBuffer = 64
+)
+
+// StreamEvent is an event with its position in a Stream
+type StreamEvent struct {
+	Event
+	// Cursor identifies the event for resuming, e.g. as an SSE Last-Event-ID
+	Cursor string
+}
+
+// Stream keeps the most recent events for replay and fans new ones out to
+// listeners. Cursors include the process start time, so a cursor from before
+// a restart is recognised as unknown instead of matching an unrelated event.
+type Stream struct {
+	epoch       string
+	unsubscribe func()
+
+	mu        sync.Mutex
+	seq       uint64
+	buf       []StreamEvent
+	next      int
+	full      bool
+	listeners map[*Listener]struct{}
+	closed    bool
+}
+
+// NewStream subscribes a stream to bus, keeping the last size events
+func NewStream(bus *Bus, size int) *Stream {
+	if size <= 0 {
+		size = DefaultReplaySize
+	}
+	s := &Stream{
+		epoch:     strconv.FormatInt(time.Now().UnixNano(), 36),
+		buf:       make([]StreamEvent, size),
+		listeners: make(map[*Listener]struct{}),
+	}
+	s.unsubscribe = bus.Subscribe("stream", s.publish)
+	return s
+}
+
+// publish numbers the event, stores it and hands it to listeners. Listeners
+// whose buffer is full are closed; they can resume from their last cursor.
+func (s *Stream) publish(ctx context.Context, event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	s.seq++
+	entry := StreamEvent{Event: event, Cursor: s.cursor(s.seq)}
+	s.buf[s.next] = entry
+	s.next = (s.next + 1) % len(s.buf)
+	if s.next == 0 {
+		s.full = true
+	}
+
+	for l := range s.listeners {
+		select {
+		case l.ch <- entry:
+		default:
+			s.drop(l)
+		}
+	}
+}
+
+func (s *Stream) cursor(seq uint64) string {
+	return fmt.Sprintf("%s-%d", s.epoch, seq)
+}
+
+// parseCursor returns the sequence number of a cursor from this stream
+func (s *Stream) parseCursor(cursor string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(cursor, "-")
+	if !ok || epoch != s.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil || n > s.seq {
+		return 0, false
+	}
+	return n, true
+}
+
+// oldest returns the sequence number of the oldest buffered event
+func (s *Stream) oldest() uint64 {
+	size := uint64(s.next)
+	if s.full {
+		size = uint64(len(s.buf))
+	}
+	return s.seq - size + 1
+}
+
+// Listener receives events published after it was created
+type Listener struct {
+	stream *Stream
+	ch     chan StreamEvent
+	// C is closed when the listener falls behind or the stream closes
+	C <-chan StreamEvent
+	// Start is the cursor of the last event published before the listener
+	// was created; clients that must reload their state resume from here
+	Start string
+}
+
+// Listen registers a listener. When after is set, the events published after
+// that cursor are returned for replay; complete is false if they are no
+// longer buffered or the cursor is unknown, and the client should reload its
+// state. An empty after replays nothing.
+func (s *Stream) Listen(after string) (l *Listener, replay []StreamEvent, complete bool) {
+	ch := make(chan StreamEvent, listenerBuffer)
+	l = &Listener{stream: s, ch: ch, C: ch}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		close(ch)
+		return l, nil, false
+	}
+	s.listeners[l] = struct{}{}
+	l.Start = s.cursor(s.seq)
+
+	if after == "" {
+		return l, nil, true
+	}
+	seq, ok := s.parseCursor(after)
+	if !ok || seq+1 < s.oldest() {
+		return l, nil, false
+	}
+
+	for n := seq + 1; n <= s.seq; n++ {
+		i := (s.next - int(s.seq-n) - 1 + len(s.buf)) % len(s.buf)
+		replay = append(replay, s.buf[i])
+	}
+	return l, replay, true
+}
+
+// Close stops the listener
+func (l *Listener) Close() {
+	l.stream.mu.Lock()
+	defer l.stream.mu.Unlock()
+	l.stream.drop(l)
+}
+
+// drop removes and closes a listener; callers hold mu
+func (s *Stream) drop(l *Listener) {
+	if _, ok := s.listeners[l]; ok {
+		delete(s.listeners, l)
+		close(l.ch)
+	}
+}
+
+// Close unsubscribes from the bus and closes every listener
+func (s *Stream) Close() {
+	s.unsubscribe()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for l := range s.listeners {
+		s.drop(l)
+	}
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, l *Listener) StreamEvent {
+	t.Helper()
+	select {
+	case e, ok := <-l.C:
+		if !ok {
+			t.Fatal("Listener closed unexpectedly")
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+	return StreamEvent{}
+}
+
+func TestStream_LiveAndReplay(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+	stream := NewStream(bus, 3)
+	defer stream.Close()
+
+	live, replay, complete := stream.Listen("")
+	defer live.Close()
+	if len(replay) != 0 || !complete {
+		t.Fatalf("Expected an empty, complete start, got %d events", len(replay))
+	}
+
+	var cursors []string
+	for i := 0; i < 5; i++ {
+		bus.Publish(context.Background(), taskEvent(TaskUpdated, "t1"))
+		cursors = append(cursors, receive(t, live).Cursor)
+	}
+
+	// The buffer holds the last three events (cursors 2..4)
+	l, replay, complete := stream.Listen(cursors[1])
+	defer l.Close()
+	if !complete || len(replay) != 3 || replay[0].Cursor != cursors[2] || replay[2].Cursor != cursors[4] {
+		t.Errorf("Expected events 3-5, got complete=%v %v", complete, replay)
+	}
+
+	l2, replay, complete := stream.Listen(cursors[4])
+	defer l2.Close()
+	if !complete || len(replay) != 0 {
+		t.Errorf("Expected nothing to replay from the latest cursor, got %d", len(replay))
+	}
+
+	for _, cursor := range []string{cursors[0], "other-3", "garbage", stream.cursor(99)} {
+		l, replay, complete := stream.Listen(cursor)
+		l.Close()
+		if complete || replay != nil {
+			t.Errorf("Listen(%q): expected an incomplete replay", cursor)
+		}
+	}
+}
+
+func TestStream_SlowListenerDropped(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+	stream := NewStream(bus, 0)
+	defer stream.Close()
+
+	slow, _, _ := stream.Listen("")
+	for i := 0; i < listenerBuffer+1; i++ {
+		bus.Publish(context.Background(), taskEvent(TaskUpdated, "t1"))
+	}
+
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != listenerBuffer {
+		t.Errorf("Expected %d buffered events before the listener closed, got %d", listenerBuffer, n)
+	}
+	slow.Close()
+}
+
+func TestStream_Close(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+	stream := NewStream(bus, 10)
+
+	l, _, _ := stream.Listen("")
+	stream.Close()
+	if _, ok := <-l.C; ok {
+		t.Error("Expected the listener to be closed")
+	}
+	l.Close()
+
+	late, _, complete := stream.Listen("")
+	if _, ok := <-late.C; ok || complete {
+		t.Error("Expected listeners of a closed stream to be closed")
+	}
+	if len(bus.Stats()) != 0 {
+		t.Error("Expected the stream to unsubscribe from the bus")
+	}
+}
@@ -0,0 +1,109 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Recorder collects events for assertions in tests. Subscribe its Handle
+// method to a bus, or pass it wherever a Handler is expected.
+type Recorder struct {
+	mu      sync.Mutex
+	events  []Event
+	updated chan struct{}
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{updated: make(chan struct{}, 1)}
+}
+
+// Record subscribes a new recorder to bus and removes it when the test ends
+func Record(t testing.TB, bus *Bus, opts ...SubscribeOption) *Recorder {
+	t.Helper()
+	r := NewRecorder()
+	t.Cleanup(bus.Subscribe("recorder", r.Handle, opts...))
+	return r
+}
+
+// Handle records an event
+func (r *Recorder) Handle(ctx context.Context, event Event) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+
+	select {
+	case r.updated <- struct{}{}:
+	default:
+	}
+}
+
+// Events returns the recorded events in order
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+// Types returns the types of the recorded events in order
+func (r *Recorder) Types() []Type {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]Type, len(r.events))
+	for i, e := range r.events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+// Reset forgets all recorded events
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = nil
+}
+
+// WaitFor waits until at least n events were recorded, for async subscriptions
+func (r *Recorder) WaitFor(t testing.TB, n int, timeout time.Duration) []Event {
+	t.Helper()
+
+	deadline := time.After(timeout)
+	for {
+		if events := r.Events(); len(events) >= n {
+			return events
+		}
+		select {
+		case <-r.updated:
+		case <-deadline:
+			t.Fatalf("Timed out waiting for %d events, got %d: %v", n, len(r.Events()), r.Types())
+			return nil
+		}
+	}
+}
+
+// AssertTypes fails the test unless exactly the given event types were recorded, in order
+func (r *Recorder) AssertTypes(t testing.TB, want ...Type) {
+	t.Helper()
+
+	got := r.Types()
+	if len(got) != len(want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected events %v, got %v", want, got)
+			return
+		}
+	}
+}
+
+// AssertNone fails the test if any event was recorded
+func (r *Recorder) AssertNone(t testing.TB) {
+	t.Helper()
+	if got := r.Types(); len(got) != 0 {
+		t.Errorf("Expected no events, got %v", got)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package health
+
+import "errors"
+
+// FreeDiskSpace is not supported on this platform
+func FreeDiskSpace(path string) (uint64, error) {
+	return 0, errors.New("disk space check is not supported on this platform")
+}
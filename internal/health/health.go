@@ -0,0 +1,172 @@
+// Package health runs readiness checks against the application's dependencies.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status values reported for checks and overall health
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+)
+
+// CheckFunc reports whether a dependency is usable. It should honour ctx but
+// the Checker enforces the timeout even when it does not.
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of a single check
+type Result struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of running all checks
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Healthy reports whether every check passed
+func (r Report) Healthy() bool {
+	return r.Status == StatusHealthy
+}
+
+// Checker runs named checks concurrently, each bounded by a timeout
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]CheckFunc
+}
+
+// NewChecker creates a checker; timeout defaults to 2 seconds when not positive
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Checker{timeout: timeout, checks: make(map[string]CheckFunc)}
+}
+
+// Register adds or replaces the check with the given name
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Has reports whether a check with the given name is registered
+func (c *Checker) Has(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.checks[name]
+	return ok
+}
+
+// Names returns the registered check names in sorted order
+func (c *Checker) Names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run executes all checks concurrently and aggregates their results
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]CheckFunc, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusHealthy, Checks: make(map[string]Result, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+			result := c.runOne(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusHealthy {
+				report.Status = StatusUnhealthy
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) runOne(ctx context.Context, check CheckFunc) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+		errCh <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", c.timeout)
+	}
+
+	result := Result{Status: StatusHealthy, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// HeartbeatCheck fails when the heartbeat returned by last is older than maxAge
+func HeartbeatCheck(last func() time.Time, maxAge time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		beat := last()
+		if beat.IsZero() {
+			return fmt.Errorf("no heartbeat recorded")
+		}
+		if age := time.Since(beat); age > maxAge {
+			return fmt.Errorf("last heartbeat %s ago exceeds %s", age.Round(time.Second), maxAge)
+		}
+		return nil
+	}
+}
+
+// DiskSpaceCheck fails when the filesystem holding path has less than minFree bytes available
+func DiskSpaceCheck(path string, minFree uint64) CheckFunc {
+	return func(ctx context.Context) error {
+		free, err := FreeDiskSpace(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat filesystem: %w", err)
+		}
+		if free < minFree {
+			return fmt.Errorf("only %d MB free, need at least %d MB", free>>20, minFree>>20)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChecker_Run(t *testing.T) {
+	checker := NewChecker(50 * time.Millisecond)
+	checker.Register("ok", func(ctx context.Context) error { return nil })
+	checker.Register("failing", func(ctx context.Context) error { return errors.New("boom") })
+	checker.Register("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	checker.Register("panicking", func(ctx context.Context) error { panic("oops") })
+
+	start := time.Now()
+	report := checker.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected checks to run concurrently within the timeout, took %s", elapsed)
+	}
+
+	if report.Healthy() {
+		t.Error("Expected report to be unhealthy")
+	}
+	if got := report.Checks["ok"].Status; got != StatusHealthy {
+		t.Errorf("Expected ok check to be healthy, got %s", got)
+	}
+	if got := report.Checks["failing"].Error; got != "boom" {
+		t.Errorf("Expected failing check error 'boom', got %q", got)
+	}
+	for _, name := range []string{"slow", "panicking"} {
+		if got := report.Checks[name].Status; got != StatusUnhealthy {
+			t.Errorf("Expected %s check to be unhealthy, got %s", name, got)
+		}
+	}
+}
+
+func TestHeartbeatCheck(t *testing.T) {
+	ctx := context.Background()
+
+	var last time.Time
+	check := HeartbeatCheck(func() time.Time { return last }, time.Minute)
+
+	if err := check(ctx); err == nil {
+		t.Error("Expected error before the first heartbeat")
+	}
+
+	last = time.Now().Add(-2 * time.Minute)
+	if err := check(ctx); err == nil {
+		t.Error("Expected error for a stale heartbeat")
+	}
+
+	last = time.Now()
+	if err := check(ctx); err != nil {
+		t.Errorf("Expected fresh heartbeat to pass, got %v", err)
+	}
+}
+
+func TestDiskSpaceCheck(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	if _, err := FreeDiskSpace(dir); err != nil {
+		t.Skipf("Disk space is not available on this platform: %v", err)
+	}
+
+	if err := DiskSpaceCheck(dir, 1)(ctx); err != nil {
+		t.Errorf("Expected check to pass with a 1 byte minimum, got %v", err)
+	}
+	if err := DiskSpaceCheck(dir, 1<<62)(ctx); err == nil {
+		t.Error("Expected check to fail with an impossible minimum")
+	}
+}
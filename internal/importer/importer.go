@@ -0,0 +1,159 @@
+// Package importer loads tasks from a file into storage, keeping their IDs,
+// with the storage's batch operations so that large files cost a few
+// writes rather than one per task.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+)
+
+// DefaultBatchSize is how many tasks are written together when
+// Options.BatchSize is not set
+const DefaultBatchSize = 500
+
+// Options controls an import
+type Options struct {
+	// BatchSize is how many tasks are written per storage batch
+	BatchSize int
+	// Update overwrites existing tasks with the same ID; otherwise they are skipped
+	Update bool
+	// Atomic imports every task in one transaction, or none when a write fails
+	Atomic bool
+}
+
+// Result counts what an import did
+type Result struct {
+	Created int
+	Updated int
+	Skipped int
+}
+
+// Read parses tasks from r, either a JSON array as written by the JSON
+// storage or one JSON task per line as in backups
+func Read(r io.Reader) ([]*models.Task, error) {
+	reader := bufio.NewReader(r)
+	first, err := peekNonSpace(reader)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	var tasks []*models.Task
+	if first == '[' {
+		if err := json.NewDecoder(reader).Decode(&tasks); err != nil {
+			return nil, fmt.Errorf("invalid task list: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var task models.Task
+			if err := json.Unmarshal(scanner.Bytes(), &task); err != nil {
+				return nil, fmt.Errorf("invalid task on line %d: %w", line, err)
+			}
+			tasks = append(tasks, &task)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read tasks: %w", err)
+		}
+	}
+
+	for i, task := range tasks {
+		if task == nil || task.ID == "" {
+			return nil, fmt.Errorf("task %d has no ID", i+1)
+		}
+	}
+	return tasks, nil
+}
+
+// Import writes tasks to store. Tasks whose ID is already stored, or
+// appears earlier in tasks, are updated with Options.Update and skipped
+// otherwise. Without Options.Atomic each batch is written on its own, so a
+// failed import keeps the batches written before it, as counted in the
+// returned Result.
+func Import(ctx context.Context, store storage.Storage, tasks []*models.Task, opts Options) (*Result, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	store = storage.WithContext(ctx, store)
+
+	existing, err := store.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current tasks: %w", err)
+	}
+	known := make(map[string]bool, len(existing)+len(tasks))
+	for _, task := range existing {
+		known[task.ID] = true
+	}
+
+	var creates, updates []*models.Task
+	result := &Result{}
+	for _, task := range tasks {
+		switch {
+		case !known[task.ID]:
+			known[task.ID] = true
+			creates = append(creates, task)
+		case opts.Update:
+			updates = append(updates, task)
+		default:
+			result.Skipped++
+		}
+	}
+
+	if !opts.Atomic {
+		return result, write(ctx, store, creates, updates, opts.BatchSize, result)
+	}
+	err = storage.WithTx(ctx, store, func(tx storage.Storage) error {
+		result.Created, result.Updated = 0, 0
+		return write(ctx, tx, creates, updates, opts.BatchSize, result)
+	})
+	if err != nil {
+		result.Created, result.Updated = 0, 0
+	}
+	return result, err
+}
+
+// write creates and then updates tasks in batches of size, counting them in result
+func write(ctx context.Context, store storage.Storage, creates, updates []*models.Task, size int, result *Result) error {
+	for start := 0; start < len(creates); start += size {
+		batch := creates[start:min(start+size, len(creates))]
+		if err := storage.CreateMany(ctx, store, batch); err != nil {
+			return fmt.Errorf("failed to create tasks: %w", err)
+		}
+		result.Created += len(batch)
+	}
+	for start := 0; start < len(updates); start += size {
+		batch := updates[start:min(start+size, len(updates))]
+		if err := storage.UpdateMany(ctx, store, batch); err != nil {
+			return fmt.Errorf("failed to update tasks: %w", err)
+		}
+		result.Updated += len(batch)
+	}
+	return nil
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte unread
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, r.UnreadByte()
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+)
+
+func newTask(id, title string) *models.Task {
+	return &models.Task{ID: id, Title: title, CreatedAt: time.Now(), Version: 1}
+}
+
+func TestRead(t *testing.T) {
+	t.Run("reads a JSON array", func(t *testing.T) {
+		tasks, err := Read(strings.NewReader(` [{"id": "a", "title": "A"}, {"id": "b", "title": "B"}]`))
+		if err != nil || len(tasks) != 2 || tasks[1].Title != "B" {
+			t.Fatalf("Expected two tasks, got %+v, %v", tasks, err)
+		}
+	})
+
+	t.Run("reads one task per line", func(t *testing.T) {
+		tasks, err := Read(strings.NewReader("{\"id\": \"a\", \"title\": \"A\"}\n\n{\"id\": \"b\", \"title\": \"B\"}\n"))
+		if err != nil || len(tasks) != 2 || tasks[0].ID != "a" {
+			t.Fatalf("Expected two tasks, got %+v, %v", tasks, err)
+		}
+	})
+
+	t.Run("reads an empty file", func(t *testing.T) {
+		if tasks, err := Read(strings.NewReader("\n")); err != nil || len(tasks) != 0 {
+			t.Errorf("Expected no tasks, got %+v, %v", tasks, err)
+		}
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		for name, input := range map[string]string{
+			"malformed array": `[{"id": "a"`,
+			"malformed line":  "{\"id\": \"a\"}\nnot json\n",
+			"missing ID":      `[{"title": "No ID"}]`,
+		} {
+			if _, err := Read(strings.NewReader(input)); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates tasks in batches", func(t *testing.T) {
+		store := storage.NewMemoryStorage()
+		tasks := make([]*models.Task, 25)
+		for i := range tasks {
+			tasks[i] = newTask(fmt.Sprintf("t%02d", i), "Imported")
+		}
+
+		result, err := Import(ctx, store, tasks, Options{BatchSize: 10})
+		if err != nil || result.Created != 25 {
+			t.Fatalf("Expected 25 created, got %+v, %v", result, err)
+		}
+		if stored, _ := store.GetAll(); len(stored) != 25 {
+			t.Errorf("Expected 25 stored tasks, got %d", len(stored))
+		}
+	})
+
+	t.Run("skips or updates existing tasks", func(t *testing.T) {
+		store := storage.NewMemoryStorage()
+		store.Create(newTask("e1", "Existing"))
+		tasks := []*models.Task{newTask("e1", "Imported"), newTask("n1", "New"), newTask("n1", "New again")}
+
+		result, err := Import(ctx, store, tasks, Options{})
+		if err != nil || result.Created != 1 || result.Skipped != 2 {
+			t.Fatalf("Expected 1 created and 2 skipped, got %+v, %v", result, err)
+		}
+		if task, _ := store.GetByID("e1"); task.Title != "Existing" {
+			t.Errorf("Expected e1 to be kept, got %q", task.Title)
+		}
+
+		result, err = Import(ctx, store, tasks, Options{Update: true})
+		if err != nil || result.Updated != 3 {
+			t.Fatalf("Expected 3 updated, got %+v, %v", result, err)
+		}
+		if task, _ := store.GetByID("n1"); task.Title != "New again" {
+			t.Errorf("Expected the last n1 in the file to win, got %q", task.Title)
+		}
+	})
+
+	t.Run("atomic import writes nothing when a batch fails", func(t *testing.T) {
+		store := &failingStorage{MemoryStorage: storage.NewMemoryStorage(), failAfter: 1}
+		tasks := []*models.Task{newTask("a1", "One"), newTask("a2", "Two"), newTask("a3", "Three")}
+
+		result, err := Import(ctx, store, tasks, Options{BatchSize: 2, Atomic: true})
+		if err == nil || result.Created != 0 {
+			t.Fatalf("Expected the import to fail with nothing created, got %+v, %v", result, err)
+		}
+		if stored, _ := store.GetAll(); len(stored) != 0 {
+			t.Errorf("Expected no stored tasks, got %d", len(stored))
+		}
+
+		store.failAfter, store.batches = 1, 0
+		result, err = Import(ctx, store, tasks, Options{BatchSize: 2})
+		if err == nil || result.Created != 2 {
+			t.Fatalf("Expected the first batch to be kept, got %+v, %v", result, err)
+		}
+	})
+}
+
+// failingStorage fails every CreateMany after the first failAfter, including
+// those in transactions
+type failingStorage struct {
+	*storage.MemoryStorage
+	failAfter int
+	batches   int
+}
+
+func (f *failingStorage) CreateMany(tasks []*models.Task) error {
+	return f.createMany(f.MemoryStorage, tasks)
+}
+
+func (f *failingStorage) createMany(store storage.Batcher, tasks []*models.Task) error {
+	f.batches++
+	if f.batches > f.failAfter {
+		return errors.New("disk full")
+	}
+	return store.CreateMany(tasks)
+}
+
+func (f *failingStorage) WithTx(ctx context.Context, fn func(tx storage.Storage) error) error {
+	return f.MemoryStorage.WithTx(ctx, func(tx storage.Storage) error {
+		return fn(&failingTx{Storage: tx, parent: f})
+	})
+}
+
+type failingTx struct {
+	storage.Storage
+	parent *failingStorage
+}
+
+func (f *failingTx) CreateMany(tasks []*models.Task) error {
+	return f.parent.createMany(f.Storage.(storage.Batcher), tasks)
+}
+
+func (f *failingTx) UpdateMany(tasks []*models.Task) error {
+	return f.Storage.(storage.Batcher).UpdateMany(tasks)
+}
+
+func (f *failingTx) DeleteMany(ids []string) error {
+	return f.Storage.(storage.Batcher).DeleteMany(ids)
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"GoTask_Management/internal/tracing"
+)
+
+// contextHandler adds the request ID and trace identifiers carried by the
+// record's context, so that every *Context logging call is correlated
+type contextHandler struct {
+	slog.Handler
+}
+
+// WithContextAttrs wraps handler so records include request_id, trace_id and span_id from context
+func WithContextAttrs(handler slog.Handler) slog.Handler {
+	return contextHandler{Handler: handler}
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := tracing.RequestIDFromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := tracing.SpanFromContext(ctx); span != nil {
+		record.AddAttrs(slog.String("trace_id", span.TraceID()), slog.String("span_id", span.SpanID()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,108 @@
+// Package logging builds the application's structured logger from configuration.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Config mirrors the logging block of the configuration file
+type Config struct {
+	Level  string // debug, info, warn, error
+	Format string // text, json
+	Output string // stdout, stderr, file
+
+	// File output settings, used when Output is "file"
+	FilePath   string
+	MaxSize    int // megabytes before the file is rotated
+	MaxBackups int // number of rotated files to keep
+	MaxAge     int // days to keep rotated files
+	Compress   bool
+}
+
+// New creates a logger from config. Records logged with a context include the
+// request ID and trace identifiers it carries. The returned closer releases the log file
+// and must be closed on shutdown; it is a no-op for stdout and stderr.
+func New(config Config) (*slog.Logger, io.Closer, error) {
+	level, err := ParseLevel(config.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	writer, closer, err := openOutput(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(config.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(writer, options)
+	case "json":
+		handler = slog.NewJSONHandler(writer, options)
+	default:
+		closer.Close()
+		return nil, nil, fmt.Errorf("unsupported log format: %s", config.Format)
+	}
+
+	return slog.New(WithContextAttrs(handler)), closer, nil
+}
+
+// ParseLevel converts a configured level name into a slog.Level
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unsupported log level: %s", level)
+	}
+}
+
+// Discard returns a logger that drops every record, which is handy in tests
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+func openOutput(config Config) (io.Writer, io.Closer, error) {
+	switch strings.ToLower(config.Output) {
+	case "", "stdout":
+		return os.Stdout, nopCloser{}, nil
+	case "stderr":
+		return os.Stderr, nopCloser{}, nil
+	case "file":
+		if config.FilePath == "" {
+			return nil, nil, fmt.Errorf("file_path is required for file log output")
+		}
+		if err := os.MkdirAll(filepath.Dir(config.FilePath), 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+		rotator := &lumberjack.Logger{
+			Filename:   config.FilePath,
+			MaxSize:    config.MaxSize,
+			MaxBackups: config.MaxBackups,
+			MaxAge:     config.MaxAge,
+			Compress:   config.Compress,
+		}
+		return rotator, rotator, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported log output: %s", config.Output)
+	}
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"GoTask_Management/internal/tracing"
+)
+
+func TestParseLevel(t *testing.T) {
+	testCases := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"":        slog.LevelInfo,
+		"INFO":    slog.LevelInfo,
+		"warn":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+	}
+
+	for input, expected := range testCases {
+		level, err := ParseLevel(input)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", input, err)
+		}
+		if level != expected {
+			t.Errorf("Expected %v for %q, got %v", expected, input, level)
+		}
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("writes JSON records to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logs", "gotask.log")
+		logger, closer, err := New(Config{Level: "info", Format: "json", Output: "file", FilePath: path, MaxSize: 1})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.Debug("hidden")
+		logger.Info("task created", "task_id", "task_1")
+		if err := closer.Close(); err != nil {
+			t.Fatalf("Failed to close logger: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read log file: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != 1 {
+			t.Fatalf("Expected 1 record (debug filtered), got %d: %s", len(lines), data)
+		}
+
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+			t.Fatalf("Expected JSON record, got %q: %v", lines[0], err)
+		}
+		if record["msg"] != "task created" || record["task_id"] != "task_1" || record["level"] != "INFO" {
+			t.Errorf("Unexpected record: %v", record)
+		}
+	})
+
+	t.Run("rejects invalid configuration", func(t *testing.T) {
+		invalid := []Config{
+			{Level: "loud"},
+			{Format: "xml"},
+			{Output: "syslog"},
+			{Output: "file"},
+		}
+		for _, config := range invalid {
+			if _, _, err := New(config); err == nil {
+				t.Errorf("Expected error for %+v", config)
+			}
+		}
+	})
+
+	t.Run("defaults to text on stdout", func(t *testing.T) {
+		logger, closer, err := New(Config{})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		defer closer.Close()
+
+		handler, ok := logger.Handler().(contextHandler)
+		if !ok {
+			t.Fatalf("Expected context handler, got %T", logger.Handler())
+		}
+		if _, ok := handler.Handler.(*slog.TextHandler); !ok {
+			t.Errorf("Expected text handler, got %T", handler.Handler)
+		}
+	})
+}
+
+func TestContextAttrs(t *testing.T) {
+	var out strings.Builder
+	logger := slog.New(WithContextAttrs(slog.NewJSONHandler(&out, nil)))
+
+	tracer := tracing.NewTracer("test", nil)
+	ctx := tracing.ContextWithRequestID(context.Background(), "req-42")
+	ctx, span := tracer.Start(ctx, "operation")
+	defer span.End()
+
+	logger.With("component", "test").InfoContext(ctx, "correlated")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(out.String()), &record); err != nil {
+		t.Fatalf("Expected JSON record, got %q: %v", out.String(), err)
+	}
+	if record["request_id"] != "req-42" {
+		t.Errorf("Expected request_id req-42, got %v", record["request_id"])
+	}
+	if record["trace_id"] != span.TraceID() || record["span_id"] != span.SpanID() {
+		t.Errorf("Expected trace identifiers from span, got %v", record)
+	}
+	if record["component"] != "test" {
+		t.Errorf("Expected attributes from With to be kept, got %v", record)
+	}
+}
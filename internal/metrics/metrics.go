@@ -0,0 +1,355 @@
+// Package metrics implements a small set of Prometheus-compatible collectors
+// (counters, gauges and histograms) and renders them in the Prometheus text
+// exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are latency buckets in seconds suitable for HTTP and storage calls
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type metricKind string
+
+const (
+	kindCounter   metricKind = "counter"
+	kindGauge     metricKind = "gauge"
+	kindHistogram metricKind = "histogram"
+)
+
+// collector is implemented by every metric family kept in a Registry
+type collector interface {
+	describe() (name, help string, kind metricKind)
+	write(w io.Writer)
+}
+
+// Registry holds metric families and renders them for scraping
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// NewCounterVec returns the counter family with the given name, creating it if needed
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := r.getOrRegister(name, kindCounter, func() collector {
+		return &CounterVec{family: newFamily(name, help, labels)}
+	})
+	return c.(*CounterVec)
+}
+
+// NewGaugeVec returns the gauge family with the given name, creating it if needed
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	c := r.getOrRegister(name, kindGauge, func() collector {
+		return &GaugeVec{family: newFamily(name, help, labels)}
+	})
+	return c.(*GaugeVec)
+}
+
+// NewHistogramVec returns the histogram family with the given name, creating it if needed.
+// DefaultBuckets are used when buckets is empty.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	c := r.getOrRegister(name, kindHistogram, func() collector {
+		return &HistogramVec{family: newFamily(name, help, labels), buckets: sorted}
+	})
+	return c.(*HistogramVec)
+}
+
+func (r *Registry) getOrRegister(name string, kind metricKind, create func() collector) collector {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.collectors[name]; ok {
+		if _, _, existingKind := existing.describe(); existingKind != kind {
+			panic(fmt.Sprintf("metrics: %s already registered as %s", name, existingKind))
+		}
+		return existing
+	}
+
+	c := create()
+	r.collectors[name] = c
+	return c
+}
+
+// WriteText renders all metric families in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	collectors := make([]collector, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		collectors = append(collectors, r.collectors[name])
+	}
+	r.mu.RUnlock()
+
+	for _, c := range collectors {
+		name, help, kind := c.describe()
+		fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(help))
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+		c.write(w)
+	}
+}
+
+// Handler returns an HTTP handler that serves the registry for scraping
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		w.WriteHeader(http.StatusOK)
+		r.WriteText(w)
+	})
+}
+
+// family stores the series of one metric keyed by their label values
+type family struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64  // counters and gauges
+	counts      []uint64 // histogram bucket counts (non-cumulative)
+	sum         float64  // histogram sum
+	count       uint64   // histogram observation count
+}
+
+func newFamily(name, help string, labels []string) family {
+	return family{
+		name:   name,
+		help:   help,
+		labels: labels,
+		series: make(map[string]*series),
+	}
+}
+
+func (f *family) describe(kind metricKind) (string, string, metricKind) {
+	return f.name, f.help, kind
+}
+
+// lookup returns the series for the given label values; callers must hold f.mu
+func (f *family) lookup(labelValues []string, buckets int) *series {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if buckets > 0 {
+			s.counts = make([]uint64, buckets)
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// sortedSeries returns a snapshot of the series ordered by label values; callers must hold f.mu
+func (f *family) sortedSeries() []*series {
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]*series, 0, len(keys))
+	for _, key := range keys {
+		s := *f.series[key]
+		s.counts = append([]uint64(nil), s.counts...)
+		out = append(out, &s)
+	}
+	return out
+}
+
+func (f *family) formatLabels(values []string, extraName, extraValue string) string {
+	if len(f.labels) == 0 && extraName == "" {
+		return ""
+	}
+
+	parts := make([]string, 0, len(f.labels)+1)
+	for i, label := range f.labels {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, label, escapeLabelValue(values[i])))
+	}
+	if extraName != "" {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, extraName, extraValue))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels
+type CounterVec struct {
+	family
+}
+
+// Inc increments the counter for the given label values by one
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by delta, which must not be negative
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lookup(labelValues, 0).value += delta
+}
+
+// Value returns the current value for the given label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lookup(labelValues, 0).value
+}
+
+func (c *CounterVec) describe() (string, string, metricKind) { return c.family.describe(kindCounter) }
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	all := c.sortedSeries()
+	c.mu.Unlock()
+
+	for _, s := range all {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.formatLabels(s.labelValues, "", ""), formatFloat(s.value))
+	}
+}
+
+// GaugeVec is a value that can go up and down, partitioned by labels
+type GaugeVec struct {
+	family
+}
+
+// Set sets the gauge for the given label values
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lookup(labelValues, 0).value = value
+}
+
+// Add adds delta (which may be negative) to the gauge for the given label values
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lookup(labelValues, 0).value += delta
+}
+
+// Value returns the current value for the given label values
+func (g *GaugeVec) Value(labelValues ...string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lookup(labelValues, 0).value
+}
+
+func (g *GaugeVec) describe() (string, string, metricKind) { return g.family.describe(kindGauge) }
+
+func (g *GaugeVec) write(w io.Writer) {
+	g.mu.Lock()
+	all := g.sortedSeries()
+	g.mu.Unlock()
+
+	for _, s := range all {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, g.formatLabels(s.labelValues, "", ""), formatFloat(s.value))
+	}
+}
+
+// HistogramVec samples observations into cumulative buckets, partitioned by labels
+type HistogramVec struct {
+	family
+	buckets []float64
+}
+
+// Observe records a single observation for the given label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.lookup(labelValues, len(h.buckets))
+	for i, upper := range h.buckets {
+		if value <= upper {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// Count returns the number of observations for the given label values
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lookup(labelValues, len(h.buckets)).count
+}
+
+func (h *HistogramVec) describe() (string, string, metricKind) {
+	return h.family.describe(kindHistogram)
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	all := h.sortedSeries()
+	h.mu.Unlock()
+
+	for _, s := range all {
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.formatLabels(s.labelValues, "le", formatFloat(upper)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.formatLabels(s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.formatLabels(s.labelValues, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.formatLabels(s.labelValues, "", ""), s.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+func escapeLabelValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCounterVec(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("test_requests_total", "Total test requests.", "method", "status")
+
+	counter.Inc("GET", "200")
+	counter.Inc("GET", "200")
+	counter.Add(3, "POST", "201")
+
+	if got := counter.Value("GET", "200"); got != 2 {
+		t.Errorf("Expected 2, got %v", got)
+	}
+	if got := counter.Value("POST", "201"); got != 3 {
+		t.Errorf("Expected 3, got %v", got)
+	}
+
+	t.Run("rejects negative increments", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic for negative increment")
+			}
+		}()
+		counter.Add(-1, "GET", "200")
+	})
+
+	t.Run("rejects wrong label count", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic for wrong label count")
+			}
+		}()
+		counter.Inc("GET")
+	})
+}
+
+func TestGaugeVec(t *testing.T) {
+	registry := NewRegistry()
+	gauge := registry.NewGaugeVec("test_tasks", "Tasks by state.", "state")
+
+	gauge.Set(10, "total")
+	gauge.Add(-3, "total")
+
+	if got := gauge.Value("total"); got != 7 {
+		t.Errorf("Expected 7, got %v", got)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	registry := NewRegistry()
+	histogram := registry.NewHistogramVec("test_duration_seconds", "Durations.", []float64{0.1, 1}, "op")
+
+	histogram.Observe(0.05, "read")
+	histogram.Observe(0.5, "read")
+	histogram.Observe(5, "read")
+
+	if got := histogram.Count("read"); got != 3 {
+		t.Errorf("Expected 3 observations, got %d", got)
+	}
+
+	var out strings.Builder
+	registry.WriteText(&out)
+	text := out.String()
+
+	expected := []string{
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{op="read",le="0.1"} 1`,
+		`test_duration_seconds_bucket{op="read",le="1"} 2`,
+		`test_duration_seconds_bucket{op="read",le="+Inf"} 3`,
+		`test_duration_seconds_sum{op="read"} 5.55`,
+		`test_duration_seconds_count{op="read"} 3`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, text)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("returns existing family for same name", func(t *testing.T) {
+		registry := NewRegistry()
+		first := registry.NewCounterVec("test_total", "Help.", "a")
+		second := registry.NewCounterVec("test_total", "Help.", "a")
+		if first != second {
+			t.Error("Expected the same counter to be returned")
+		}
+	})
+
+	t.Run("panics on kind conflict", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.NewCounterVec("test_conflict", "Help.")
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic for conflicting kinds")
+			}
+		}()
+		registry.NewGaugeVec("test_conflict", "Help.")
+	})
+
+	t.Run("escapes label values", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.NewCounterVec("test_escape_total", "Help.", "path").Inc("a\"b\\c\nd")
+
+		var out strings.Builder
+		registry.WriteText(&out)
+		if !strings.Contains(out.String(), `test_escape_total{path="a\"b\\c\nd"} 1`) {
+			t.Errorf("Expected escaped label value, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("serves text exposition format", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.NewGaugeVec("test_up", "Whether the test is up.").Set(1)
+
+		rr := httptest.NewRecorder()
+		registry.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != ContentType {
+			t.Errorf("Expected content type %s, got %s", ContentType, got)
+		}
+		if !strings.Contains(rr.Body.String(), "# HELP test_up Whether the test is up.\n# TYPE test_up gauge\ntest_up 1\n") {
+			t.Errorf("Unexpected body:\n%s", rr.Body.String())
+		}
+	})
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		registry := NewRegistry()
+		counter := registry.NewCounterVec("test_concurrent_total", "Help.", "worker")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					counter.Inc("w")
+					registry.WriteText(&strings.Builder{})
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got := counter.Value("w"); got != 1000 {
+			t.Errorf("Expected 1000, got %v", got)
+		}
+	})
+}
@@ -5,11 +5,14 @@ import (
 )
 
 type Task struct {
-	ID        string     `json:"id" gorm:"primaryKey;type:varchar(255)"`
-	Title     string     `json:"title" gorm:"not null;type:text"`
-	Done      bool       `json:"done" gorm:"default:false"`
-	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	DueDate   *time.Time `json:"due_date,omitempty" gorm:"index"`
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	Title       string     `json:"title" gorm:"not null;type:text"`
+	Done        bool       `json:"done" gorm:"default:false"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	DueDate     *time.Time `json:"due_date,omitempty" gorm:"index"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" gorm:"index"`
+	// Version is incremented on every change, for optimistic concurrency checks
+	Version int64 `json:"version" gorm:"not null;default:0"`
 }
 
 type TaskFilter struct {
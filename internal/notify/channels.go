@@ -0,0 +1,222 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoTask_Management/internal/models"
+)
+
+// SMTPConfig configures the email channel
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	// Timeout bounds each send, from connecting to QUIT
+	Timeout time.Duration
+}
+
+// SMTPChannel sends notifications as plain-text email
+type SMTPChannel struct {
+	config SMTPConfig
+}
+
+// NewSMTPChannel creates an email channel. Authentication is used when a
+// username is configured; net/smtp only sends credentials over TLS or to localhost.
+func NewSMTPChannel(config SMTPConfig) (*SMTPChannel, error) {
+	if config.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	if config.From == "" || len(config.To) == 0 {
+		return nil, errors.New("smtp from and to addresses are required")
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &SMTPChannel{config: config}, nil
+}
+
+func (c *SMTPChannel) Name() string {
+	return "email"
+}
+
+// Send delivers the notification like smtp.SendMail, upgrading to TLS when
+// the server offers STARTTLS, but gives up when ctx is done or the timeout
+// passes so that a hung server cannot block the notification job
+func (c *SMTPChannel) Send(ctx context.Context, n Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	err := c.send(ctx, n)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return fmt.Errorf("failed to send email: %w", ctxErr)
+	}
+	return err
+}
+
+func (c *SMTPChannel) send(ctx context.Context, n Notification) error {
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Closing the connection unblocks any read or write when ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, c.config.Host)
+	if err != nil {
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.config.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if c.config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support authentication")
+		}
+		if err := client.Auth(smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(c.config.From); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	for _, to := range c.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(c.message(n)); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	return client.Quit()
+}
+
+func (c *SMTPChannel) message(n Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", c.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(c.config.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", sanitizeHeader(n.Subject())))
+	fmt.Fprintf(&b, "Date: %s\r\n", n.At.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(n.Body(), "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// sanitizeHeader keeps user-controlled titles from injecting extra headers
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// WebhookConfig configures the generic HTTP webhook channel
+type WebhookConfig struct {
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// WebhookChannel posts notifications as JSON
+type WebhookChannel struct {
+	config WebhookConfig
+	client *http.Client
+}
+
+// WebhookPayload is the JSON body posted by WebhookChannel
+type WebhookPayload struct {
+	Type          string      `json:"type"`
+	Subject       string      `json:"subject"`
+	OffsetSeconds int64       `json:"offset_seconds,omitempty"`
+	Task          models.Task `json:"task"`
+	SentAt        time.Time   `json:"sent_at"`
+}
+
+// NewWebhookChannel creates a webhook channel
+func NewWebhookChannel(config WebhookConfig) (*WebhookChannel, error) {
+	if config.URL == "" {
+		return nil, errors.New("webhook url is required")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+	return &WebhookChannel{config: config, client: client}, nil
+}
+
+func (c *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(WebhookPayload{
+		Type:          "task." + string(n.Kind),
+		Subject:       n.Subject(),
+		OffsetSeconds: int64(n.Offset / time.Second),
+		Task:          n.Task,
+		SentAt:        n.At.UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range c.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
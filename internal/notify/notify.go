@@ -0,0 +1,247 @@
+// Package notify sends due-date reminders and overdue notices for tasks
+// through pluggable channels such as email and webhooks.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/task"
+)
+
+// Kind identifies the type of notification
+type Kind string
+
+const (
+	// KindReminder is sent ahead of a task's due date
+	KindReminder Kind = "reminder"
+	// KindOverdue is sent once a task's due date has passed
+	KindOverdue Kind = "overdue"
+)
+
+// Notification is a single message about a task
+type Notification struct {
+	Kind Kind
+	Task models.Task
+	// Offset is the reminder offset before the due date; zero for overdue notices
+	Offset time.Duration
+	// At is when the notification was generated
+	At time.Time
+}
+
+// Subject returns a one-line summary of the notification
+func (n Notification) Subject() string {
+	if n.Kind == KindOverdue {
+		return fmt.Sprintf("Task overdue: %s", n.Task.Title)
+	}
+	return fmt.Sprintf("Task due in %s: %s", formatOffset(n.Offset), n.Task.Title)
+}
+
+// Body returns a plain-text description of the notification
+func (n Notification) Body() string {
+	due := ""
+	if n.Task.DueDate != nil {
+		due = n.Task.DueDate.Format(time.RFC1123)
+	}
+	return fmt.Sprintf("%s\n\nTask: %s\nID: %s\nDue: %s\n", n.Subject(), n.Task.Title, n.Task.ID, due)
+}
+
+// Channel delivers notifications to one destination
+type Channel interface {
+	// Name identifies the channel in logs and de-duplication keys
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// Config controls which notifications are generated
+type Config struct {
+	// Offsets before the due date at which reminders are sent, e.g. 24h and 1h
+	Offsets []time.Duration
+	// Overdue enables a notice once the due date has passed
+	Overdue bool
+}
+
+// dedupRetention is how long sent notifications are remembered. A task that
+// stays overdue longer than this gets a fresh overdue notice.
+const dedupRetention = 90 * 24 * time.Hour
+
+// Notifier scans tasks and sends each due reminder once per channel. Each
+// notification is claimed in the dedup store before it is sent, so notifiers
+// sharing a store, such as servers sharing a database, send it once. A
+// notifier that stops between the claim and the send loses that notification.
+type Notifier struct {
+	service  *task.Service
+	channels []Channel
+	config   Config
+	dedup    storage.NotificationStore
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// Option configures optional Notifier behaviour
+type Option func(*Notifier)
+
+// WithLogger sets the logger used for notification runs
+func WithLogger(logger *slog.Logger) Option {
+	return func(n *Notifier) {
+		n.logger = logger
+	}
+}
+
+// WithDedupStore sets where sent notifications are recorded; the default
+// keeps them in memory
+func WithDedupStore(store storage.NotificationStore) Option {
+	return func(n *Notifier) {
+		n.dedup = store
+	}
+}
+
+// New creates a notifier sending through channels
+func New(service *task.Service, config Config, channels []Channel, opts ...Option) *Notifier {
+	offsets := make([]time.Duration, 0, len(config.Offsets))
+	for _, offset := range config.Offsets {
+		if offset > 0 {
+			offsets = append(offsets, offset)
+		}
+	}
+	// Smallest offset first so the nearest reminder wins
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	config.Offsets = offsets
+
+	n := &Notifier{
+		service:  service,
+		channels: channels,
+		config:   config,
+		dedup:    storage.NewMemoryNotificationStore(),
+		logger:   slog.Default(),
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Run sends every notification that is due and has not been sent yet. A
+// failed channel is retried on the next run; other channels are unaffected.
+func (n *Notifier) Run(ctx context.Context) (int, error) {
+	tasks, err := n.service.ListTasks(ctx, "undone")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	now := n.now()
+	sent := 0
+	var errs []error
+	for _, t := range tasks {
+		notification, skipped, ok := n.pending(t, now)
+		if !ok {
+			continue
+		}
+
+		for _, channel := range n.channels {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+
+			key := dedupKey(channel, notification)
+			err := n.dedup.ClaimNotification(&storage.NotificationRecord{Key: key, SentAt: now})
+			if errors.Is(err, storage.ErrNotificationClaimed) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			if err := channel.Send(ctx, notification); err != nil {
+				n.logger.WarnContext(ctx, "failed to send notification", "channel", channel.Name(), "task_id", t.ID, "kind", notification.Kind, "error", err)
+				errs = append(errs, fmt.Errorf("%s: %w", channel.Name(), err))
+				// Release the claim so the next run retries
+				if err := n.dedup.DeleteNotification(key); err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			sent++
+			n.logger.InfoContext(ctx, "notification sent", "channel", channel.Name(), "task_id", t.ID, "kind", notification.Kind, "offset", notification.Offset)
+
+			// Larger offsets that were missed, e.g. while the server was down,
+			// are marked as sent so they do not fire after a nearer reminder
+			for _, offset := range skipped {
+				missed := notification
+				missed.Kind, missed.Offset = KindReminder, offset
+				err := n.dedup.ClaimNotification(&storage.NotificationRecord{Key: dedupKey(channel, missed), SentAt: now})
+				if err != nil && !errors.Is(err, storage.ErrNotificationClaimed) {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	if _, err := n.dedup.DeleteNotificationsBefore(now.Add(-dedupRetention)); err != nil {
+		errs = append(errs, err)
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// pending returns the notification due for t at now, together with the
+// larger reminder offsets it supersedes
+func (n *Notifier) pending(t *models.Task, now time.Time) (Notification, []time.Duration, bool) {
+	if t.Done || t.DueDate == nil {
+		return Notification{}, nil, false
+	}
+	due := *t.DueDate
+
+	if !now.Before(due) {
+		if !n.config.Overdue {
+			return Notification{}, nil, false
+		}
+		return Notification{Kind: KindOverdue, Task: *t, At: now}, n.config.Offsets, true
+	}
+
+	for i, offset := range n.config.Offsets {
+		if !now.Before(due.Add(-offset)) {
+			return Notification{Kind: KindReminder, Task: *t, Offset: offset, At: now}, n.config.Offsets[i+1:], true
+		}
+	}
+	return Notification{}, nil, false
+}
+
+// dedupKey identifies a notification per channel. The due date is part of the
+// key so rescheduling a task re-arms its reminders.
+func dedupKey(channel Channel, n Notification) string {
+	due := int64(0)
+	if n.Task.DueDate != nil {
+		due = n.Task.DueDate.Unix()
+	}
+	return fmt.Sprintf("%s|%s|%s|%d|%d", channel.Name(), n.Task.ID, n.Kind, int64(n.Offset/time.Second), due)
+}
+
+func formatOffset(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		days := int(d / (24 * time.Hour))
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	case d%time.Hour == 0:
+		hours := int(d / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	default:
+		return d.String()
+	}
+}
@@ -0,0 +1,435 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/task"
+)
+
+// recordingChannel records notifications and optionally fails
+type recordingChannel struct {
+	name string
+	fail bool
+	sent []Notification
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Send(ctx context.Context, n Notification) error {
+	if c.fail {
+		return errors.New("channel unavailable")
+	}
+	c.sent = append(c.sent, n)
+	return nil
+}
+
+func newTestService(t *testing.T, tasks ...*models.Task) *task.Service {
+	t.Helper()
+
+	store := storage.NewMemoryStorage()
+
+	for _, tk := range tasks {
+		if err := store.Create(tk); err != nil {
+			t.Fatalf("Failed to seed task: %v", err)
+		}
+	}
+	return task.NewService(store)
+}
+
+func at(t time.Time) *time.Time { return &t }
+
+func TestNotifier_RemindersSentOnce(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestService(t,
+		&models.Task{ID: "soon", Title: "Soon", DueDate: at(now.Add(30 * time.Minute))},
+		&models.Task{ID: "tomorrow", Title: "Tomorrow", DueDate: at(now.Add(20 * time.Hour))},
+		&models.Task{ID: "later", Title: "Later", DueDate: at(now.Add(72 * time.Hour))},
+		&models.Task{ID: "late", Title: "Late", DueDate: at(now.Add(-time.Hour))},
+		&models.Task{ID: "done", Title: "Done", Done: true, DueDate: at(now.Add(-time.Hour))},
+		&models.Task{ID: "undated", Title: "Undated"},
+	)
+
+	channel := &recordingChannel{name: "test"}
+	n := New(service, Config{Offsets: []time.Duration{24 * time.Hour, time.Hour}, Overdue: true}, []Channel{channel})
+	n.now = func() time.Time { return now }
+
+	sent, err := n.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if sent != 3 {
+		t.Fatalf("Run() sent = %d, want 3", sent)
+	}
+
+	got := make(map[string]Notification)
+	for _, notification := range channel.sent {
+		got[notification.Task.ID] = notification
+	}
+	if got["soon"].Kind != KindReminder || got["soon"].Offset != time.Hour {
+		t.Errorf("soon: got %s/%s, want 1h reminder", got["soon"].Kind, got["soon"].Offset)
+	}
+	if got["tomorrow"].Kind != KindReminder || got["tomorrow"].Offset != 24*time.Hour {
+		t.Errorf("tomorrow: got %s/%s, want 24h reminder", got["tomorrow"].Kind, got["tomorrow"].Offset)
+	}
+	if got["late"].Kind != KindOverdue {
+		t.Errorf("late: got %s, want overdue", got["late"].Kind)
+	}
+
+	sent, err = n.Run(context.Background())
+	if err != nil || sent != 0 {
+		t.Fatalf("second Run() = %d, %v, want 0, nil", sent, err)
+	}
+}
+
+func TestNotifier_SkipsSupersededReminders(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestService(t, &models.Task{ID: "t1", Title: "Task", DueDate: at(now.Add(30 * time.Minute))})
+
+	channel := &recordingChannel{name: "test"}
+	n := New(service, Config{Offsets: []time.Duration{time.Hour, 24 * time.Hour}, Overdue: true}, []Channel{channel})
+	n.now = func() time.Time { return now }
+
+	if _, err := n.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(channel.sent) != 1 || channel.sent[0].Offset != time.Hour {
+		t.Fatalf("expected only the 1h reminder, got %+v", channel.sent)
+	}
+
+	// Once overdue, the notice is sent but no earlier reminders fire
+	now = now.Add(time.Hour)
+	if _, err := n.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(channel.sent) != 2 || channel.sent[1].Kind != KindOverdue {
+		t.Fatalf("expected an overdue notice, got %+v", channel.sent)
+	}
+}
+
+func TestNotifier_RescheduleRearms(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestService(t, &models.Task{ID: "t1", Title: "Task", DueDate: at(now.Add(30 * time.Minute))})
+
+	channel := &recordingChannel{name: "test"}
+	n := New(service, Config{Offsets: []time.Duration{time.Hour}}, []Channel{channel})
+	n.now = func() time.Time { return now }
+
+	if _, err := n.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if _, err := service.UpdateTask(context.Background(), "t1", "Task", false, at(now.Add(45*time.Minute))); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if _, err := n.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(channel.sent) != 2 {
+		t.Fatalf("expected a new reminder after rescheduling, got %d", len(channel.sent))
+	}
+}
+
+func TestNotifier_FailedChannelRetried(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestService(t, &models.Task{ID: "t1", Title: "Task", DueDate: at(now.Add(-time.Minute))})
+
+	good := &recordingChannel{name: "good"}
+	bad := &recordingChannel{name: "bad", fail: true}
+	n := New(service, Config{Overdue: true}, []Channel{bad, good})
+	n.now = func() time.Time { return now }
+
+	if _, err := n.Run(context.Background()); err == nil {
+		t.Fatal("expected an error from the failing channel")
+	}
+	if len(good.sent) != 1 {
+		t.Fatalf("healthy channel should still deliver, got %d", len(good.sent))
+	}
+
+	bad.fail = false
+	sent, err := n.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if sent != 1 || len(bad.sent) != 1 || len(good.sent) != 1 {
+		t.Fatalf("expected only the failed channel to retry, sent=%d bad=%d good=%d", sent, len(bad.sent), len(good.sent))
+	}
+}
+
+func TestNotifier_SharedStoreSendsOnce(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestService(t, &models.Task{ID: "t1", Title: "Task", DueDate: at(now.Add(-time.Minute))})
+
+	// Two servers sharing a database, each with its own channel of the same name
+	dedup := storage.NewMemoryNotificationStore()
+	channels := []*recordingChannel{{name: "test"}, {name: "test"}}
+	var wg sync.WaitGroup
+	for _, channel := range channels {
+		n := New(service, Config{Overdue: true}, []Channel{channel}, WithDedupStore(dedup))
+		n.now = func() time.Time { return now }
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := n.Run(context.Background()); err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if total := len(channels[0].sent) + len(channels[1].sent); total != 1 {
+		t.Fatalf("expected the notice to be sent once, got %d", total)
+	}
+}
+
+func TestNotifier_RecordsSentInStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestService(t, &models.Task{ID: "t1", Title: "Task", DueDate: at(now.Add(-time.Minute))})
+
+	run := func() []Notification {
+		t.Helper()
+		store, err := storage.NewJSONStorage(path)
+		if err != nil {
+			t.Fatalf("NewJSONStorage() error = %v", err)
+		}
+		channel := &recordingChannel{name: "test"}
+		n := New(service, Config{Overdue: true}, []Channel{channel}, WithDedupStore(store))
+		n.now = func() time.Time { return now }
+		if _, err := n.Run(context.Background()); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return channel.sent
+	}
+
+	if sent := run(); len(sent) != 1 {
+		t.Fatalf("expected the overdue notice, got %d", len(sent))
+	}
+	// A restarted server reads the record back
+	if sent := run(); len(sent) != 0 {
+		t.Fatalf("expected no notice after a restart, got %d", len(sent))
+	}
+
+	// Records older than the retention are pruned at the end of a run,
+	// re-arming the notice for the next one
+	now = now.Add(dedupRetention + time.Hour)
+	run()
+	if sent := run(); len(sent) != 1 {
+		t.Fatalf("expected a fresh notice once the record was pruned, got %d", len(sent))
+	}
+}
+
+// smtpStandIn is a minimal SMTP server that accepts every message
+type smtpStandIn struct {
+	addr string
+	mu   sync.Mutex
+	msgs []string
+}
+
+func startSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpStandIn{addr: ln.Addr().String()}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply("354 end with .")
+			var msg strings.Builder
+			for {
+				data, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if data == ".\r\n" {
+					break
+				}
+				msg.WriteString(data)
+			}
+			s.mu.Lock()
+			s.msgs = append(s.msgs, msg.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *smtpStandIn) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.msgs...)
+}
+
+func TestSMTPChannel_Send(t *testing.T) {
+	server := startSMTPStandIn(t)
+	host, port, _ := net.SplitHostPort(server.addr)
+	portNum, _ := strconv.Atoi(port)
+
+	channel, err := NewSMTPChannel(SMTPConfig{Host: host, Port: portNum, From: "tasks@example.com", To: []string{"me@example.com"}})
+	if err != nil {
+		t.Fatalf("NewSMTPChannel() error = %v", err)
+	}
+
+	due := time.Now().Add(time.Hour)
+	n := Notification{Kind: KindReminder, Offset: time.Hour, At: time.Now(), Task: models.Task{ID: "t1", Title: "Pay rent\r\nBcc: evil@example.com", DueDate: &due}}
+	if err := channel.Send(context.Background(), n); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	msgs := server.messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	if !strings.Contains(msgs[0], "Subject: Task due in 1 hour: Pay rent  Bcc: evil@example.com\r\n") {
+		t.Errorf("unexpected message headers:\n%s", msgs[0])
+	}
+	headers, _, _ := strings.Cut(msgs[0], "\r\n\r\n")
+	if strings.Contains(headers, "\r\nBcc:") {
+		t.Error("task title injected a header")
+	}
+}
+
+func TestSMTPChannel_EncodesNonASCIISubjects(t *testing.T) {
+	server := startSMTPStandIn(t)
+	host, port, _ := net.SplitHostPort(server.addr)
+	portNum, _ := strconv.Atoi(port)
+
+	channel, _ := NewSMTPChannel(SMTPConfig{Host: host, Port: portNum, From: "tasks@example.com", To: []string{"me@example.com"}})
+	n := Notification{Kind: KindOverdue, At: time.Now(), Task: models.Task{ID: "t1", Title: "Café 📅"}}
+	if err := channel.Send(context.Background(), n); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	msgs := server.messages()
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "Subject: =?UTF-8?q?") {
+		t.Errorf("expected an RFC 2047 encoded subject, got:\n%v", msgs)
+	}
+}
+
+func TestSMTPChannel_GivesUpOnHungServers(t *testing.T) {
+	// Accepts connections but never sends a greeting
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	channel, _ := NewSMTPChannel(SMTPConfig{Host: host, Port: portNum, From: "tasks@example.com", To: []string{"me@example.com"}, Timeout: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = channel.Send(ctx, Notification{Kind: KindOverdue, At: time.Now(), Task: models.Task{ID: "t1", Title: "Hung"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() error = %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Send() took %v despite the context deadline", elapsed)
+	}
+
+	channel, _ = NewSMTPChannel(SMTPConfig{Host: host, Port: portNum, From: "tasks@example.com", To: []string{"me@example.com"}, Timeout: 100 * time.Millisecond})
+	if err := channel.Send(context.Background(), Notification{Kind: KindOverdue, At: time.Now(), Task: models.Task{ID: "t1", Title: "Hung"}}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() error = %v, want the configured timeout to apply", err)
+	}
+}
+
+func TestWebhookChannel_Send(t *testing.T) {
+	var payload WebhookPayload
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	channel, err := NewWebhookChannel(WebhookConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}})
+	if err != nil {
+		t.Fatalf("NewWebhookChannel() error = %v", err)
+	}
+
+	n := Notification{Kind: KindOverdue, At: time.Now(), Task: models.Task{ID: "t1", Title: "Task"}}
+	if err := channel.Send(context.Background(), n); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if payload.Type != "task.overdue" || payload.Task.ID != "t1" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want configured header", auth)
+	}
+}
+
+func TestWebhookChannel_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	channel, _ := NewWebhookChannel(WebhookConfig{URL: server.URL})
+	if err := channel.Send(context.Background(), Notification{Kind: KindOverdue}); err == nil {
+		t.Fatal("expected an error for a non-2xx response")
+	}
+}
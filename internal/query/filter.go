@@ -0,0 +1,232 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoTask_Management/internal/models"
+)
+
+// Field is the task attribute a Condition tests
+type Field int
+
+const (
+	FieldTitle Field = iota
+	FieldID
+	FieldStatus
+	FieldDue
+	FieldCreated
+	FieldCompleted
+)
+
+// fields maps the field names of the language to fields
+var fields = map[string]Field{
+	"title":     FieldTitle,
+	"id":        FieldID,
+	"status":    FieldStatus,
+	"due":       FieldDue,
+	"created":   FieldCreated,
+	"completed": FieldCompleted,
+}
+
+// fieldNames lists the fields in error messages
+const fieldNames = "status, due, created, completed, title, id"
+
+// IsTime reports whether f is one of the optional or required task times
+func (f Field) IsTime() bool {
+	return f == FieldDue || f == FieldCreated || f == FieldCompleted
+}
+
+// Filter is a validated query, ready to be checked against tasks or
+// translated into a storage query. Tasks match when they match every
+// condition.
+type Filter struct {
+	Conditions []Condition
+}
+
+// Condition is one validated term
+type Condition struct {
+	Field Field
+	Not   bool
+	// Text is the lower case text the title must contain, or the exact ID
+	Text string
+	// Done is the status tested by FieldStatus
+	Done bool
+	// Unset matches tasks without the time, as in due:none
+	Unset bool
+	// Since and Until bound a time to [Since, Until) for tasks that have it.
+	// A zero bound is open.
+	Since, Until time.Time
+}
+
+// ParseFilter parses and compiles a query; see Compile
+func ParseFilter(input string, now time.Time) (*Filter, error) {
+	q, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	return q.Compile(now)
+}
+
+// Compile validates the fields and values of q and returns the filter it
+// stands for. Relative dates such as "today" and "+3d" are resolved against
+// now, and dates are days in now's location.
+func (q *Query) Compile(now time.Time) (*Filter, error) {
+	filter := &Filter{}
+	for _, term := range q.Terms {
+		cond, err := compileTerm(term, now)
+		if err != nil {
+			return nil, err
+		}
+		filter.Conditions = append(filter.Conditions, cond)
+	}
+	return filter, nil
+}
+
+func compileTerm(term Term, now time.Time) (Condition, error) {
+	fail := func(format string, args ...any) (Condition, error) {
+		return Condition{}, &Error{Pos: term.Pos, Msg: fmt.Sprintf(format, args...)}
+	}
+	if term.Field == "" {
+		return Condition{Field: FieldTitle, Not: term.Negated, Text: strings.ToLower(term.Value)}, nil
+	}
+
+	name := strings.ToLower(term.Field)
+	field, ok := fields[name]
+	if !ok {
+		return fail("unknown field %q (fields: %s)", term.Field, fieldNames)
+	}
+	cond := Condition{Field: field, Not: term.Negated}
+	if !field.IsTime() && term.Op != OpEq {
+		return fail("%s does not support %s", name, term.Op)
+	}
+
+	switch field {
+	case FieldTitle:
+		cond.Text = strings.ToLower(term.Value)
+	case FieldID:
+		cond.Text = term.Value
+	case FieldStatus:
+		switch strings.ToLower(term.Value) {
+		case "open", "undone", "pending":
+			cond.Done = false
+		case "done", "closed", "completed":
+			cond.Done = true
+		default:
+			return fail("invalid status %q (expected open or done)", term.Value)
+		}
+	default:
+		if strings.EqualFold(term.Value, "none") {
+			if term.Op != OpEq {
+				return fail("%s:none does not support %s", name, term.Op)
+			}
+			cond.Unset = true
+			return cond, nil
+		}
+		start, end, err := parseTime(term.Value, now)
+		if err != nil {
+			return fail("invalid %s %q: %v", name, term.Value, err)
+		}
+		switch term.Op {
+		case OpEq:
+			cond.Since, cond.Until = start, end
+		case OpLt:
+			cond.Until = start
+		case OpLe:
+			cond.Until = end
+		case OpGt:
+			cond.Since = end
+		case OpGe:
+			cond.Since = start
+		}
+	}
+	return cond, nil
+}
+
+// parseTime returns the span [start, end) named by value: a whole day for
+// dates and relative days, and a single instant for RFC 3339 times
+func parseTime(value string, now time.Time) (time.Time, time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, t.Add(time.Nanosecond), nil
+	}
+
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	day := func(offset int) (time.Time, time.Time, error) {
+		start := today.AddDate(0, 0, offset)
+		return start, start.AddDate(0, 0, 1), nil
+	}
+	switch strings.ToLower(value) {
+	case "today":
+		return day(0)
+	case "tomorrow":
+		return day(1)
+	case "yesterday":
+		return day(-1)
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, value, loc); err == nil {
+		return t, t.AddDate(0, 0, 1), nil
+	}
+
+	// Relative days and weeks: +3d, -1w
+	if len(value) >= 3 && (value[0] == '+' || value[0] == '-') {
+		unit := 0
+		switch value[len(value)-1] {
+		case 'd':
+			unit = 1
+		case 'w':
+			unit = 7
+		}
+		n, err := strconv.Atoi(value[1 : len(value)-1])
+		if unit > 0 && err == nil && n >= 0 && n <= 100000 {
+			if value[0] == '-' {
+				n = -n
+			}
+			return day(n * unit)
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("expected YYYY-MM-DD, an RFC 3339 time, today, tomorrow, yesterday or +Nd/-Nw")
+}
+
+// Match reports whether task matches every condition
+func (f *Filter) Match(task *models.Task) bool {
+	for _, cond := range f.Conditions {
+		if !cond.Match(task) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether task matches the condition
+func (c Condition) Match(task *models.Task) bool {
+	return c.match(task) != c.Not
+}
+
+func (c Condition) match(task *models.Task) bool {
+	switch c.Field {
+	case FieldTitle:
+		return strings.Contains(strings.ToLower(task.Title), c.Text)
+	case FieldID:
+		return task.ID == c.Text
+	case FieldStatus:
+		return task.Done == c.Done
+	}
+
+	var t *time.Time
+	switch c.Field {
+	case FieldDue:
+		t = task.DueDate
+	case FieldCompleted:
+		t = task.CompletedAt
+	case FieldCreated:
+		t = &task.CreatedAt
+	}
+	if c.Unset {
+		return t == nil
+	}
+	return t != nil && (c.Since.IsZero() || !t.Before(c.Since)) && (c.Until.IsZero() || t.Before(c.Until))
+}
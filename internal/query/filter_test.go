@@ -0,0 +1,133 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+)
+
+func TestCompile(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*3600)
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, loc)
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, loc) }
+
+	tests := []struct {
+		input string
+		want  Condition
+	}{
+		{input: "Login", want: Condition{Field: FieldTitle, Text: "login"}},
+		{input: "-title:Draft", want: Condition{Field: FieldTitle, Not: true, Text: "draft"}},
+		{input: "id:Task_1", want: Condition{Field: FieldID, Text: "Task_1"}},
+		{input: "status:open", want: Condition{Field: FieldStatus, Done: false}},
+		{input: "STATUS:Done", want: Condition{Field: FieldStatus, Done: true}},
+		{input: "due:none", want: Condition{Field: FieldDue, Unset: true}},
+		{input: "due:2026-10-20", want: Condition{Field: FieldDue, Since: day(20), Until: day(21)}},
+		{input: "due<2026-10-20", want: Condition{Field: FieldDue, Until: day(20)}},
+		{input: "due<=2026-10-20", want: Condition{Field: FieldDue, Until: day(21)}},
+		{input: "due>2026-10-20", want: Condition{Field: FieldDue, Since: day(21)}},
+		{input: "due>=2026-10-20", want: Condition{Field: FieldDue, Since: day(20)}},
+		{input: "due<today", want: Condition{Field: FieldDue, Until: day(18)}},
+		{input: "due<=tomorrow", want: Condition{Field: FieldDue, Until: day(20)}},
+		{input: "completed>=yesterday", want: Condition{Field: FieldCompleted, Since: day(17)}},
+		{input: "due<=+1w", want: Condition{Field: FieldDue, Until: day(26)}},
+		{input: "created>=-3d", want: Condition{Field: FieldCreated, Since: day(15)}},
+		{
+			input: "created<2026-10-01T12:00:00Z",
+			want:  Condition{Field: FieldCreated, Until: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			filter, err := ParseFilter(tt.input, now)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) failed: %v", tt.input, err)
+			}
+			if len(filter.Conditions) != 1 {
+				t.Fatalf("Expected one condition, got %+v", filter.Conditions)
+			}
+			got := filter.Conditions[0]
+			if got.Field != tt.want.Field || got.Not != tt.want.Not || got.Text != tt.want.Text ||
+				got.Done != tt.want.Done || got.Unset != tt.want.Unset ||
+				!got.Since.Equal(tt.want.Since) || !got.Until.Equal(tt.want.Until) {
+				t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{input: "status:open priority>=high", pos: 12, msg: `unknown field "priority"`},
+		{input: "status:blocked", pos: 0, msg: `invalid status "blocked"`},
+		{input: "status>open", pos: 0, msg: "status does not support >"},
+		{input: "title<b", pos: 0, msg: "title does not support <"},
+		{input: "open -due:soon", pos: 5, msg: `invalid due "soon"`},
+		{input: "due<none", pos: 0, msg: "due:none does not support <"},
+		{input: "due:2026-02-30", pos: 0, msg: "invalid due"},
+		{input: "due<+3m", pos: 0, msg: "invalid due"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseFilter(tt.input, time.Now())
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("ParseFilter(%q) = %v, want an *Error", tt.input, err)
+			}
+			if qerr.Pos != tt.pos || !strings.Contains(qerr.Msg, tt.msg) {
+				t.Errorf("ParseFilter(%q) = %v, want %q at %d", tt.input, err, tt.msg, tt.pos)
+			}
+		})
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	due := now.Add(48 * time.Hour)
+	completed := now.Add(-time.Hour)
+	open := &models.Task{ID: "open", Title: "Fix the Login bug", CreatedAt: now.AddDate(0, 0, -10), DueDate: &due}
+	done := &models.Task{ID: "done", Title: "Write release notes", Done: true, CreatedAt: now, CompletedAt: &completed}
+
+	tests := []struct {
+		input string
+		open  bool
+		done  bool
+	}{
+		{input: "", open: true, done: true},
+		{input: "status:open", open: true},
+		{input: "-status:open", done: true},
+		{input: `"login bug"`, open: true},
+		{input: "login -bug", open: false},
+		{input: "due<=+2d", open: true},
+		{input: "due<+2d", open: false},
+		{input: "-due<+2d", open: true, done: true},
+		{input: "due:none", done: true},
+		{input: "-due:none status:open", open: true},
+		{input: "completed:today", done: true},
+		{input: "created<-1w", open: true},
+		{input: "id:done notes", done: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			filter, err := ParseFilter(tt.input, now)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) failed: %v", tt.input, err)
+			}
+			if got := filter.Match(open); got != tt.open {
+				t.Errorf("Match(open) = %v, want %v", got, tt.open)
+			}
+			if got := filter.Match(done); got != tt.done {
+				t.Errorf("Match(done) = %v, want %v", got, tt.done)
+			}
+		})
+	}
+}
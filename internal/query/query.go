@@ -0,0 +1,278 @@
+// Package query parses the task query language used to filter task lists,
+// e.g. `status:open due<2026-11-01 -title:draft "login bug"`.
+//
+// A query is a list of terms separated by spaces, all of which must match.
+// A term is either text, which must appear in the title, or a field, an
+// operator and a value. Text and values with spaces are written in double
+// quotes, with \" and \\ for a quote and a backslash. A term starting with
+// "-" is negated.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Op is the operator of a field term
+type Op int
+
+const (
+	OpEq Op = iota // ":" or "="
+	OpLt           // "<"
+	OpLe           // "<="
+	OpGt           // ">"
+	OpGe           // ">="
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpLt:
+		return "<"
+	case OpLe:
+		return "<="
+	case OpGt:
+		return ">"
+	case OpGe:
+		return ">="
+	}
+	return ":"
+}
+
+// Query is a parsed query: tasks match when they match every term
+type Query struct {
+	Terms []Term
+}
+
+// Term is one condition of a query
+type Term struct {
+	// Pos is the byte offset of the term in the query, or -1 for terms that
+	// were not parsed from it
+	Pos     int
+	Negated bool
+	// Field is empty for text terms
+	Field string
+	Op    Op
+	Value string
+}
+
+// Error is a syntax or validation error in a query
+type Error struct {
+	// Pos is the byte offset of the error in the query, or -1 when unknown
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	if e.Pos < 0 {
+		return "invalid query: " + e.Msg
+	}
+	return fmt.Sprintf("invalid query at position %d: %s", e.Pos, e.Msg)
+}
+
+// Parse parses a query without checking its fields and values; see Compile
+func Parse(input string) (*Query, error) {
+	p := &parser{input: input}
+	q := &Query{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) {
+			return q, nil
+		}
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		q.Terms = append(q.Terms, term)
+	}
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) peek() rune {
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return r
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.input) || unicode.IsSpace(p.peek())
+}
+
+func (p *parser) term() (Term, error) {
+	term := Term{Pos: p.pos}
+	if p.input[p.pos] == '-' {
+		p.pos++
+		if p.atEnd() {
+			return term, &Error{Pos: term.Pos, Msg: `expected a term after "-"`}
+		}
+		term.Negated = true
+	}
+
+	if p.input[p.pos] == '"' {
+		text, err := p.quoted()
+		term.Value = text
+		return term, err
+	}
+
+	start := p.pos
+	word := p.word(func(r rune) bool { return !isOpChar(r) })
+	if p.pos >= len(p.input) || !isOpChar(p.peek()) {
+		term.Value = word
+		return term, nil
+	}
+
+	if !isFieldName(word) {
+		return term, &Error{Pos: start, Msg: fmt.Sprintf("invalid field name %q", word)}
+	}
+	term.Field = word
+	opPos := p.pos
+	switch {
+	case strings.HasPrefix(p.input[p.pos:], "<="):
+		term.Op, p.pos = OpLe, p.pos+2
+	case strings.HasPrefix(p.input[p.pos:], ">="):
+		term.Op, p.pos = OpGe, p.pos+2
+	case p.input[p.pos] == '<':
+		term.Op, p.pos = OpLt, p.pos+1
+	case p.input[p.pos] == '>':
+		term.Op, p.pos = OpGt, p.pos+1
+	case p.input[p.pos] == ':' || p.input[p.pos] == '=':
+		term.Op, p.pos = OpEq, p.pos+1
+	default:
+		return term, &Error{Pos: opPos, Msg: fmt.Sprintf("unknown operator %q", p.input[p.pos:p.pos+1])}
+	}
+
+	if p.atEnd() {
+		return term, &Error{Pos: p.pos, Msg: fmt.Sprintf("missing value for %s", term.Field)}
+	}
+	if p.input[p.pos] == '"' {
+		value, err := p.quoted()
+		term.Value = value
+		return term, err
+	}
+	term.Value = p.word(func(rune) bool { return true })
+	return term, nil
+}
+
+// word reads up to the next space or quote, or the first rune not accepted by ok
+func (p *parser) word(ok func(rune) bool) string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if unicode.IsSpace(r) || r == '"' || !ok(r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.input[start:p.pos]
+}
+
+// quoted reads a string in double quotes, where \" and \\ stand for a quote
+// and a backslash and other backslashes are kept
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			if b.Len() == 0 {
+				return "", &Error{Pos: start, Msg: "empty quoted string"}
+			}
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.input) && (p.input[p.pos+1] == '"' || p.input[p.pos+1] == '\\'):
+			b.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", &Error{Pos: start, Msg: "unterminated quoted string"}
+}
+
+func isOpChar(r rune) bool {
+	return strings.ContainsRune(":=<>!", r)
+}
+
+func isFieldName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// WithStatus adds a term requiring status to q, unless status is empty, and
+// returns q. It combines a query with a separate status filter.
+func (q *Query) WithStatus(status string) *Query {
+	if status != "" {
+		q.Terms = append(q.Terms, Term{Pos: -1, Field: "status", Op: OpEq, Value: status})
+	}
+	return q
+}
+
+// String formats the query so that Parse returns the same terms
+func (q *Query) String() string {
+	terms := make([]string, len(q.Terms))
+	for i, term := range q.Terms {
+		terms[i] = term.String()
+	}
+	return strings.Join(terms, " ")
+}
+
+func (t Term) String() string {
+	var b strings.Builder
+	if t.Negated {
+		b.WriteByte('-')
+	}
+	if t.Field == "" {
+		b.WriteString(quoteIf(t.Value, func(s string) bool {
+			return strings.HasPrefix(s, "-") || strings.ContainsFunc(s, isOpChar)
+		}))
+		return b.String()
+	}
+	b.WriteString(t.Field)
+	b.WriteString(t.Op.String())
+	b.WriteString(quoteIf(t.Value, func(s string) bool {
+		// A leading "=" would be read as part of the operator
+		return strings.HasPrefix(s, "=")
+	}))
+	return b.String()
+}
+
+// quoteIf quotes s when it is empty, has spaces or quotes, or needsQuotes
+func quoteIf(s string, needsQuotes func(string) bool) string {
+	if s != "" && !strings.ContainsFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == '"' }) && !needsQuotes(s) {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Term
+	}{
+		{
+			name:  "empty",
+			input: "  ",
+			want:  nil,
+		},
+		{
+			name:  "fields, negation and phrases",
+			input: `status:open due<2026-11-01 -title:draft "login bug"`,
+			want: []Term{
+				{Pos: 0, Field: "status", Op: OpEq, Value: "open"},
+				{Pos: 12, Field: "due", Op: OpLt, Value: "2026-11-01"},
+				{Pos: 27, Negated: true, Field: "title", Op: OpEq, Value: "draft"},
+				{Pos: 40, Value: "login bug"},
+			},
+		},
+		{
+			name:  "operators",
+			input: "due<=today due>=+3d created>2026-01-01 completed=none",
+			want: []Term{
+				{Pos: 0, Field: "due", Op: OpLe, Value: "today"},
+				{Pos: 11, Field: "due", Op: OpGe, Value: "+3d"},
+				{Pos: 20, Field: "created", Op: OpGt, Value: "2026-01-01"},
+				{Pos: 39, Field: "completed", Op: OpEq, Value: "none"},
+			},
+		},
+		{
+			name:  "values may contain operators",
+			input: `created>2026-01-01T09:30:00Z title:"a \"quoted\" \\ title" -"-x"`,
+			want: []Term{
+				{Pos: 0, Field: "created", Op: OpGt, Value: "2026-01-01T09:30:00Z"},
+				{Pos: 29, Field: "title", Op: OpEq, Value: `a "quoted" \ title`},
+				{Pos: 59, Negated: true, Value: "-x"},
+			},
+		},
+		{
+			name:  "unicode text",
+			input: "café 日本",
+			want:  []Term{{Pos: 0, Value: "café"}, {Pos: 7, Value: "日本"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(q.Terms, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, q.Terms, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+	}{
+		{input: `status:open "login`, pos: 12},
+		{input: `""`, pos: 0},
+		{input: `- open`, pos: 0},
+		{input: `due<`, pos: 4},
+		{input: `due: today`, pos: 4},
+		{input: `:open`, pos: 0},
+		{input: `due.date:today`, pos: 0},
+		{input: `due!today`, pos: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("Parse(%q) = %v, want an *Error", tt.input, err)
+			}
+			if qerr.Pos != tt.pos {
+				t.Errorf("Parse(%q) failed at %d, want %d: %v", tt.input, qerr.Pos, tt.pos, err)
+			}
+		})
+	}
+}
+
+func TestQuery_String(t *testing.T) {
+	q := &Query{Terms: []Term{
+		{Field: "status", Op: OpEq, Value: "open"},
+		{Field: "due", Op: OpLe, Value: "=x"},
+		{Negated: true, Field: "title", Op: OpEq, Value: `say "hi"`},
+		{Value: "-dash"},
+		{Value: "a:b"},
+	}}
+	want := `status:open due<="=x" -title:"say \"hi\"" "-dash" "a:b"`
+	if got := q.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
+
+func TestQuery_WithStatus(t *testing.T) {
+	q, err := Parse("tag:backend")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if got := q.WithStatus("").String(); got != "tag:backend" {
+		t.Errorf("WithStatus(\"\") = %s, want tag:backend", got)
+	}
+	if got := q.WithStatus("done").String(); got != "tag:backend status:done" {
+		t.Errorf("WithStatus(done) = %s, want tag:backend status:done", got)
+	}
+	if pos := q.Terms[1].Pos; pos != -1 {
+		t.Errorf("Expected the status term to have no position, got %d", pos)
+	}
+}
+
+// FuzzParse checks that Parse never panics and that formatting a parsed
+// query gives a query that parses to the same terms
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		`status:open priority>=high due<2026-11-01 tag:backend -tag:blocked "login bug"`,
+		`-"-x" title:"a \"b\" \\" due<==x`,
+		`created>2026-01-01T09:30:00Z completed:none`,
+		"café -日本 \"\\",
+		`a:"" -`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		q, err := Parse(input)
+		if err != nil {
+			var qerr *Error
+			if !errors.As(err, &qerr) || qerr.Pos < 0 || qerr.Pos > len(input) {
+				t.Fatalf("Parse(%q) returned %v", input, err)
+			}
+			return
+		}
+
+		formatted := q.String()
+		again, err := Parse(formatted)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed on the formatted query %q: %v", input, formatted, err)
+		}
+		if len(again.Terms) != len(q.Terms) {
+			t.Fatalf("Parse(%q) has %d terms, its formatted query %q has %d", input, len(q.Terms), formatted, len(again.Terms))
+		}
+		for i := range q.Terms {
+			want, got := q.Terms[i], again.Terms[i]
+			want.Pos, got.Pos = 0, 0
+			if got != want {
+				t.Fatalf("Parse(%q) term %d is %+v, after formatting as %q it is %+v", input, i, want, formatted, got)
+			}
+		}
+	})
+}
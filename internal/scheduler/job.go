@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrJobNotFound is returned when no job is registered under a name
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobRunning is returned when triggering a job that has not finished its previous run
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Trigger values recorded in a job's run history
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// historySize is the number of runs kept per job
+const historySize = 20
+
+// Schedule computes when a job runs next
+type Schedule interface {
+	// Next returns the next activation time strictly after t
+	Next(t time.Time) time.Time
+}
+
+// Every returns a schedule that fires at a fixed interval
+func Every(interval time.Duration) Schedule {
+	return cron.Every(interval)
+}
+
+// ParseSchedule accepts a Go duration ("5m"), a descriptor ("@hourly", "@every 5m")
+// or a standard five-field cron expression ("0 3 * * *")
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("schedule is empty")
+	}
+
+	if interval, err := time.ParseDuration(spec); err == nil {
+		if interval <= 0 {
+			return nil, fmt.Errorf("interval must be positive: %s", spec)
+		}
+		return Every(interval), nil
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// RetryPolicy controls how failed runs are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per run; values below 1 mean a single attempt
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for each further retry
+	Backoff time.Duration
+}
+
+// Job is a unit of work run by the scheduler
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Spec is the human-readable form of Schedule reported in job status
+	Spec string
+	// Timeout bounds a single attempt; zero means no timeout
+	Timeout time.Duration
+	Retry   RetryPolicy
+	// Run performs the work and should return promptly once ctx is done
+	Run func(ctx context.Context) error
+}
+
+// JobConfig overrides the schedule, timeout and retry policy of a registered job
+type JobConfig struct {
+	Schedule     string        `mapstructure:"schedule"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+}
+
+// apply returns job with the non-zero fields of cfg applied
+func (cfg JobConfig) apply(job Job) (Job, error) {
+	if cfg.Schedule != "" {
+		schedule, err := ParseSchedule(cfg.Schedule)
+		if err != nil {
+			return job, err
+		}
+		job.Schedule = schedule
+		job.Spec = cfg.Schedule
+	}
+	if cfg.Timeout > 0 {
+		job.Timeout = cfg.Timeout
+	}
+	if cfg.MaxAttempts > 0 {
+		job.Retry.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.RetryBackoff > 0 {
+		job.Retry.Backoff = cfg.RetryBackoff
+	}
+	return job, nil
+}
+
+// RunRecord describes a single run of a job, including its retries
+type RunRecord struct {
+	Trigger    string    `json:"trigger"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// JobStatus is a snapshot of a job's configuration and run history
+type JobStatus struct {
+	Name           string      `json:"name"`
+	Schedule       string      `json:"schedule"`
+	Timeout        string      `json:"timeout,omitempty"`
+	MaxAttempts    int         `json:"max_attempts"`
+	Running        bool        `json:"running"`
+	NextRun        *time.Time  `json:"next_run,omitempty"`
+	LastRun        *time.Time  `json:"last_run,omitempty"`
+	LastSuccess    *time.Time  `json:"last_success,omitempty"`
+	LastError      string      `json:"last_error,omitempty"`
+	LastErrorAt    *time.Time  `json:"last_error_at,omitempty"`
+	LastDurationMs int64       `json:"last_duration_ms"`
+	History        []RunRecord `json:"history"`
+}
+
+// jobState tracks a registered job; all fields are guarded by Scheduler.mu
+type jobState struct {
+	job     Job
+	next    time.Time
+	running bool
+	history []RunRecord // newest first
+
+	lastSuccess time.Time
+	lastError   string
+	lastErrorAt time.Time
+}
+
+func (st *jobState) record(run RunRecord) {
+	st.history = append([]RunRecord{run}, st.history...)
+	if len(st.history) > historySize {
+		st.history = st.history[:historySize]
+	}
+
+	if run.Error == "" {
+		st.lastSuccess = run.FinishedAt
+	} else {
+		st.lastError = run.Error
+		st.lastErrorAt = run.FinishedAt
+	}
+}
+
+func (st *jobState) status() JobStatus {
+	status := JobStatus{
+		Name:        st.job.Name,
+		Schedule:    st.job.Spec,
+		MaxAttempts: max(st.job.Retry.MaxAttempts, 1),
+		Running:     st.running,
+		LastError:   st.lastError,
+		History:     append([]RunRecord{}, st.history...),
+	}
+	if st.job.Timeout > 0 {
+		status.Timeout = st.job.Timeout.String()
+	}
+	if !st.next.IsZero() {
+		status.NextRun = timePtr(st.next)
+	}
+	if len(st.history) > 0 {
+		status.LastRun = timePtr(st.history[0].StartedAt)
+		status.LastDurationMs = st.history[0].DurationMs
+	}
+	if !st.lastSuccess.IsZero() {
+		status.LastSuccess = timePtr(st.lastSuccess)
+	}
+	if !st.lastErrorAt.IsZero() {
+		status.LastErrorAt = timePtr(st.lastErrorAt)
+	}
+	return status
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -1,60 +1,404 @@
 package scheduler
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"GoTask_Management/internal/metrics"
 	"GoTask_Management/internal/task"
 )
 
+// SummaryJobName is the name of the built-in job that logs and publishes the task summary
+const SummaryJobName = "task_summary"
+
 type Scheduler struct {
 	taskService *task.Service
 	interval    int // in seconds
-	ticker      *time.Ticker
-	done        chan bool
+	done        chan struct{}
+	wake        chan struct{}
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	started     bool
+	taskGauge   *metrics.GaugeVec
+	jobRuns     *metrics.CounterVec
+	jobDuration *metrics.HistogramVec
+	logger      *slog.Logger
+	heartbeat   atomic.Int64 // unix nanoseconds of the last loop iteration
+	jobConfig   map[string]JobConfig
+
+	mu   sync.Mutex
+	jobs map[string]*jobState
+}
+
+// Option configures optional Scheduler behaviour
+type Option func(*Scheduler)
+
+// WithLogger sets the logger used for scheduler runs
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// WithMetrics publishes the task summary as gauges and job outcomes as
+// counters and histograms in registry
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(s *Scheduler) {
+		s.taskGauge = registry.NewGaugeVec(
+			"gotask_tasks",
+			"Number of tasks by state (total, done, overdue) as of the last scheduler run.",
+			"state",
+		)
+		s.jobRuns = registry.NewCounterVec(
+			"gotask_scheduler_job_runs_total",
+			"Total number of scheduler job runs by job and result.",
+			"job", "result",
+		)
+		s.jobDuration = registry.NewHistogramVec(
+			"gotask_scheduler_job_duration_seconds",
+			"Scheduler job run duration in seconds, including retries.",
+			metrics.DefaultBuckets,
+			"job",
+		)
+	}
 }
 
-func New(taskService *task.Service, interval int) *Scheduler {
-	return &Scheduler{
+// WithJobConfig overrides the schedule, timeout and retry policy of jobs by name
+// as they are registered
+func WithJobConfig(config map[string]JobConfig) Option {
+	return func(s *Scheduler) {
+		s.jobConfig = config
+	}
+}
+
+func New(taskService *task.Service, interval int, opts ...Option) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
 		taskService: taskService,
 		interval:    interval,
-		done:        make(chan bool),
+		done:        make(chan struct{}),
+		wake:        make(chan struct{}, 1),
+		ctx:         ctx,
+		cancel:      cancel,
+		logger:      slog.Default(),
+		jobs:        make(map[string]*jobState),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	summary := Job{
+		Name:     SummaryJobName,
+		Schedule: Every(s.Interval()),
+		Spec:     fmt.Sprintf("@every %s", s.Interval()),
+		Timeout:  time.Minute,
+		Run:      s.publishSummary,
+	}
+	if err := s.Register(summary); err != nil {
+		// Only an invalid override can fail here; fall back to the interval
+		s.logger.Error("invalid job configuration, using defaults", "job", SummaryJobName, "error", err)
+		s.jobs[SummaryJobName] = &jobState{job: summary, next: summary.Schedule.Next(time.Now())}
+	}
+
+	return s
+}
+
+// Register adds a job, applying any override from WithJobConfig. Jobs may be
+// registered before or after Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" {
+		return errors.New("job name is required")
+	}
+	if job.Run == nil {
+		return fmt.Errorf("job %s has no run function", job.Name)
+	}
+
+	job, err := s.jobConfig[job.Name].apply(job)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if job.Schedule == nil {
+		return fmt.Errorf("job %s has no schedule", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %s is already registered", job.Name)
 	}
+	s.jobs[job.Name] = &jobState{job: job, next: job.Schedule.Next(time.Now())}
+	s.notify()
+	return nil
 }
 
 func (s *Scheduler) Start() {
-	s.ticker = time.NewTicker(time.Duration(s.interval) * time.Second)
+	s.mu.Lock()
+	s.started = true
+	// Publish gauges right away instead of waiting a full interval
+	if s.taskGauge != nil {
+		s.jobs[SummaryJobName].next = time.Now()
+	}
+	s.mu.Unlock()
+
+	s.beat()
+	s.wg.Add(1)
+	go s.loop()
+
+	s.logger.Info("scheduler started", "interval_seconds", s.interval, "jobs", len(s.Jobs()))
+}
+
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	started := s.started
+	s.started = false
+	s.mu.Unlock()
+	if !started {
+		return
+	}
+
+	close(s.done)
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("scheduler stopped")
+}
+
+// Interval returns the time between scheduler runs
+func (s *Scheduler) Interval() time.Duration {
+	return time.Duration(s.interval) * time.Second
+}
 
-	go func() {
-		for {
+// LastHeartbeat returns when the scheduler loop last woke up, or the zero time
+// if it has not been started
+func (s *Scheduler) LastHeartbeat() time.Time {
+	nanos := s.heartbeat.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// Jobs returns the status of every registered job sorted by name
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, st := range s.jobs {
+		statuses = append(statuses, st.status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Job returns the status of the named job
+func (s *Scheduler) Job(name string) (JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.jobs[name]
+	if !ok {
+		return JobStatus{}, ErrJobNotFound
+	}
+	return st.status(), nil
+}
+
+// Trigger starts a run of the named job in the background without affecting its schedule
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.jobs[name]
+	if !ok {
+		return ErrJobNotFound
+	}
+	if st.running {
+		return ErrJobRunning
+	}
+
+	st.running = true
+	s.wg.Add(1)
+	go s.execute(st, TriggerManual)
+	return nil
+}
+
+func (s *Scheduler) beat() {
+	s.heartbeat.Store(time.Now().UnixNano())
+}
+
+// notify wakes the loop so it picks up schedule changes; callers hold s.mu
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		s.beat()
+		wait := time.Until(s.dispatchDue(time.Now()))
+		// Wake at least once per interval so the heartbeat stays fresh
+		if limit := s.Interval(); limit > 0 && wait > limit {
+			wait = limit
+		}
+
+		if !timer.Stop() {
 			select {
-			case <-s.ticker.C:
-				s.performBackup()
-			case <-s.done:
-				return
+			case <-timer.C:
+			default:
 			}
 		}
-	}()
+		timer.Reset(wait)
 
-	log.Printf("Scheduler started with interval: %d seconds", s.interval)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+	}
 }
 
-func (s *Scheduler) Stop() {
-	s.ticker.Stop()
-	s.done <- true
-	log.Println("Scheduler stopped")
+// dispatchDue starts every job whose next run is at or before now and returns
+// the earliest upcoming run
+func (s *Scheduler) dispatchDue(now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var earliest time.Time
+	for _, st := range s.jobs {
+		if !st.next.After(now) {
+			st.next = st.job.Schedule.Next(now)
+			if st.running {
+				s.logger.Warn("skipping job run, previous run still in progress", "job", st.job.Name)
+			} else {
+				st.running = true
+				s.wg.Add(1)
+				go s.execute(st, TriggerSchedule)
+			}
+		}
+		if earliest.IsZero() || st.next.Before(earliest) {
+			earliest = st.next
+		}
+	}
+
+	if earliest.IsZero() {
+		return now.Add(s.Interval())
+	}
+	return earliest
 }
 
-func (s *Scheduler) performBackup() {
-	total, done, overdue, err := s.taskService.GetTasksSummary()
+// execute runs a job with its retry policy and records the outcome
+func (s *Scheduler) execute(st *jobState, trigger string) {
+	defer s.wg.Done()
+
+	job := st.job
+	logger := s.logger.With("job", job.Name, "trigger", trigger)
+	logger.Debug("job started")
+
+	start := time.Now()
+	attempts := max(job.Retry.MaxAttempts, 1)
+	backoff := job.Retry.Backoff
+
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		err = s.attempt(job)
+		if err == nil || attempt >= attempts {
+			break
+		}
+
+		logger.Warn("job attempt failed, retrying", "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+		}
+		if s.ctx.Err() != nil {
+			break
+		}
+		backoff *= 2
+	}
+
+	finished := time.Now()
+	run := RunRecord{
+		Trigger:    trigger,
+		StartedAt:  start,
+		FinishedAt: finished,
+		DurationMs: finished.Sub(start).Milliseconds(),
+		Attempts:   attempt,
+	}
+
+	result := "success"
 	if err != nil {
-		log.Printf("Error getting task summary: %v", err)
-		return
+		run.Error = err.Error()
+		result = "error"
+		logger.Error("job failed", "attempts", attempt, "duration", finished.Sub(start), "error", err)
+	} else {
+		logger.Info("job completed", "attempts", attempt, "duration", finished.Sub(start))
+	}
+
+	if s.jobRuns != nil {
+		s.jobRuns.Inc(job.Name, result)
+		s.jobDuration.Observe(finished.Sub(start).Seconds(), job.Name)
+	}
+
+	s.mu.Lock()
+	st.running = false
+	st.record(run)
+	s.mu.Unlock()
+}
+
+// attempt runs a job once within its timeout, converting panics into errors
+func (s *Scheduler) attempt(job Job) (err error) {
+	ctx := s.ctx
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
 	}
 
-	log.Printf("📊 Task Summary - Total: %d | Done: %d | Overdue: %d", total, done, overdue)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
 
-	// Here you could add additional backup logic
-	// For example, creating a backup file with timestamp
-	// or sending data to a remote backup service
+	if err := job.Run(ctx); err != nil {
+		if ctxErr := ctx.Err(); errors.Is(ctxErr, context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s: %w", job.Timeout, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// publishSummary logs the task summary and updates the task gauges
+func (s *Scheduler) publishSummary(ctx context.Context) error {
+	total, done, overdue, err := s.taskService.GetTasksSummary(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get task summary: %w", err)
+	}
+
+	s.logger.Info("task summary", "total", total, "done", done, "overdue", overdue)
+
+	if s.taskGauge != nil {
+		s.taskGauge.Set(float64(total), "total")
+		s.taskGauge.Set(float64(done), "done")
+		s.taskGauge.Set(float64(overdue), "overdue")
+	}
+	return nil
 }
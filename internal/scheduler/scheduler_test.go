@@ -0,0 +1,292 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/metrics"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/task"
+)
+
+func newTestService(t *testing.T) *task.Service {
+	t.Helper()
+
+	store := storage.NewMemoryStorage()
+
+	return task.NewService(store)
+}
+
+func TestScheduler_TaskGauges(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t)
+
+	past := time.Now().Add(-48 * time.Hour)
+	if _, err := service.CreateTask(ctx, "Overdue", &past); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	done, err := service.CreateTask(ctx, "Done", nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if err := service.MarkTaskDone(ctx, done.ID, true); err != nil {
+		t.Fatalf("Failed to mark task done: %v", err)
+	}
+
+	registry := metrics.NewRegistry()
+	sched := New(service, 60, WithMetrics(registry))
+	if err := sched.publishSummary(ctx); err != nil {
+		t.Fatalf("Failed to publish summary: %v", err)
+	}
+
+	expected := map[string]float64{"total": 2, "done": 1, "overdue": 1}
+	for state, want := range expected {
+		if got := sched.taskGauge.Value(state); got != want {
+			t.Errorf("Expected %s gauge %v, got %v", state, want, got)
+		}
+	}
+}
+
+func TestScheduler_Heartbeat(t *testing.T) {
+	sched := New(newTestService(t), 60)
+
+	if !sched.LastHeartbeat().IsZero() {
+		t.Error("Expected no heartbeat before Start")
+	}
+
+	sched.Start()
+	defer sched.Stop()
+
+	if beat := sched.LastHeartbeat(); time.Since(beat) > time.Second {
+		t.Errorf("Expected a fresh heartbeat after Start, got %v", beat)
+	}
+	if sched.Interval() != time.Minute {
+		t.Errorf("Expected interval 1m, got %s", sched.Interval())
+	}
+}
+
+// everySchedule fires at sub-second intervals, which cron schedules cannot express
+type everySchedule time.Duration
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// waitForRuns polls until the named job has recorded n runs
+func waitForRuns(t *testing.T, sched *Scheduler, name string, n int) JobStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := sched.Job(name)
+		if err != nil {
+			t.Fatalf("Failed to get job status: %v", err)
+		}
+		if len(status.History) >= n && !status.Running {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d runs of %s", n, name)
+	return JobStatus{}
+}
+
+func TestScheduler_Register(t *testing.T) {
+	sched := New(newTestService(t), 60)
+	noop := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name string
+		job  Job
+	}{
+		{"missing name", Job{Schedule: Every(time.Minute), Run: noop}},
+		{"missing run", Job{Name: "a", Schedule: Every(time.Minute)}},
+		{"missing schedule", Job{Name: "b", Run: noop}},
+		{"duplicate", Job{Name: SummaryJobName, Schedule: Every(time.Minute), Run: noop}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := sched.Register(tt.job); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+
+	names := []string{}
+	for _, status := range sched.Jobs() {
+		names = append(names, status.Name)
+	}
+	if len(names) != 1 || names[0] != SummaryJobName {
+		t.Errorf("Expected only the built-in job, got %v", names)
+	}
+}
+
+func TestScheduler_JobConfigOverride(t *testing.T) {
+	sched := New(newTestService(t), 60, WithJobConfig(map[string]JobConfig{
+		"nightly": {Schedule: "0 3 * * *", Timeout: time.Second, MaxAttempts: 3},
+	}))
+
+	err := sched.Register(Job{
+		Name:     "nightly",
+		Schedule: Every(time.Hour),
+		Spec:     "1h",
+		Run:      func(ctx context.Context) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("Failed to register job: %v", err)
+	}
+
+	status, _ := sched.Job("nightly")
+	if status.Schedule != "0 3 * * *" || status.Timeout != "1s" || status.MaxAttempts != 3 {
+		t.Errorf("Expected overrides to apply, got %+v", status)
+	}
+	if status.NextRun == nil || status.NextRun.Hour() != 3 {
+		t.Errorf("Expected next run at 03:00, got %v", status.NextRun)
+	}
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	sched := New(newTestService(t), 60)
+	release := make(chan struct{})
+
+	err := sched.Register(Job{
+		Name:     "manual",
+		Schedule: Every(time.Hour),
+		Run: func(ctx context.Context) error {
+			<-release
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to register job: %v", err)
+	}
+
+	if err := sched.Trigger("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+	if err := sched.Trigger("manual"); err != nil {
+		t.Fatalf("Failed to trigger job: %v", err)
+	}
+	if err := sched.Trigger("manual"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("Expected ErrJobRunning, got %v", err)
+	}
+	close(release)
+
+	status := waitForRuns(t, sched, "manual", 1)
+	run := status.History[0]
+	if run.Trigger != TriggerManual || run.Error != "" || run.Attempts != 1 {
+		t.Errorf("Unexpected run record: %+v", run)
+	}
+	if status.LastSuccess == nil {
+		t.Error("Expected last success to be recorded")
+	}
+}
+
+func TestScheduler_RetryAndTimeout(t *testing.T) {
+	sched := New(newTestService(t), 60)
+
+	var calls atomic.Int32
+	err := sched.Register(Job{
+		Name:     "flaky",
+		Schedule: Every(time.Hour),
+		Retry:    RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+		Run: func(ctx context.Context) error {
+			if calls.Add(1) < 3 {
+				return errors.New("transient")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to register job: %v", err)
+	}
+	err = sched.Register(Job{
+		Name:     "slow",
+		Schedule: Every(time.Hour),
+		Timeout:  10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to register job: %v", err)
+	}
+
+	_ = sched.Trigger("flaky")
+	_ = sched.Trigger("slow")
+
+	flaky := waitForRuns(t, sched, "flaky", 1)
+	if run := flaky.History[0]; run.Attempts != 3 || run.Error != "" {
+		t.Errorf("Expected success on the third attempt, got %+v", run)
+	}
+
+	slow := waitForRuns(t, sched, "slow", 1)
+	if slow.LastError == "" || slow.LastSuccess != nil {
+		t.Errorf("Expected timeout error, got %+v", slow)
+	}
+}
+
+func TestScheduler_RunsOnSchedule(t *testing.T) {
+	sched := New(newTestService(t), 60)
+
+	var calls atomic.Int32
+	err := sched.Register(Job{
+		Name:     "frequent",
+		Schedule: everySchedule(10 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to register job: %v", err)
+	}
+
+	sched.Start()
+	status := waitForRuns(t, sched, "frequent", 2)
+	sched.Stop()
+
+	if status.History[0].Trigger != TriggerSchedule {
+		t.Errorf("Expected scheduled trigger, got %s", status.History[0].Trigger)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		spec    string
+		want    time.Time
+		wantErr bool
+	}{
+		{spec: "5m", want: base.Add(5 * time.Minute)},
+		{spec: "@every 1h", want: base.Add(time.Hour)},
+		{spec: "0 3 * * *", want: time.Date(2024, 1, 16, 3, 0, 0, 0, time.UTC)},
+		{spec: "@daily", want: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "", wantErr: true},
+		{spec: "-5m", wantErr: true},
+		{spec: "not a schedule", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := schedule.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Expected next %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"GoTask_Management/internal/models"
+)
+
+// Batcher is implemented by storages with a native way to write many tasks
+// at once, such as multi-row inserts. Each method is all or nothing: when
+// one task cannot be written, none are, and the error is a *BatchError when
+// the failing task is known.
+type Batcher interface {
+	// CreateMany creates tasks, failing if any already exists
+	CreateMany(tasks []*models.Task) error
+	// UpdateMany replaces tasks, failing if any does not exist
+	UpdateMany(tasks []*models.Task) error
+	// DeleteMany deletes the tasks with ids, failing if any does not exist
+	DeleteMany(ids []string) error
+}
+
+// gormBatchRows is how many tasks the PostgreSQL and MySQL storages insert
+// per statement in CreateMany
+const gormBatchRows = 500
+
+// BatchError reports the item of a batch that failed
+type BatchError struct {
+	// Index is the position of the item in the batch
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// CreateMany creates tasks all or nothing, natively when store is a Batcher
+// and otherwise one by one in a transaction
+func CreateMany(ctx context.Context, store Storage, tasks []*models.Task) error {
+	if batcher, ok := store.(Batcher); ok {
+		return batcher.CreateMany(tasks)
+	}
+	return WithTx(ctx, store, func(tx Storage) error {
+		if batcher, ok := tx.(Batcher); ok {
+			return batcher.CreateMany(tasks)
+		}
+		for i, task := range tasks {
+			if err := tx.Create(task); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateMany replaces tasks all or nothing, see CreateMany
+func UpdateMany(ctx context.Context, store Storage, tasks []*models.Task) error {
+	if batcher, ok := store.(Batcher); ok {
+		return batcher.UpdateMany(tasks)
+	}
+	return WithTx(ctx, store, func(tx Storage) error {
+		if batcher, ok := tx.(Batcher); ok {
+			return batcher.UpdateMany(tasks)
+		}
+		for i, task := range tasks {
+			if err := tx.Update(task); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteMany deletes the tasks with ids all or nothing, see CreateMany
+func DeleteMany(ctx context.Context, store Storage, ids []string) error {
+	if batcher, ok := store.(Batcher); ok {
+		return batcher.DeleteMany(ids)
+	}
+	return WithTx(ctx, store, func(tx Storage) error {
+		if batcher, ok := tx.(Batcher); ok {
+			return batcher.DeleteMany(ids)
+		}
+		for i, id := range ids {
+			if err := tx.Delete(id); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"GoTask_Management/internal/metrics"
+	"GoTask_Management/internal/models"
+)
+
+func TestBatch(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	jsonStorage := func(name string) *JSONStorage {
+		store, err := NewJSONStorage(helper.TempFilePath(name))
+		helper.AssertNoError(err, "creating JSON storage")
+		return store
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		testBatchCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		testBatchCompliance(t, jsonStorage("batch.json"))
+	})
+
+	t.Run("wal", func(t *testing.T) {
+		store, err := NewWALStorage(WALConfig{Path: helper.TempFilePath("batch_wal.json")})
+		helper.AssertNoError(err, "creating WAL storage")
+		defer store.Close()
+		testBatchCompliance(t, store)
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStorage(BoltConfig{Path: helper.TempFilePath("batch.db")})
+		helper.AssertNoError(err, "creating Bolt storage")
+		defer store.Close()
+		testBatchCompliance(t, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("batch.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+		testBatchCompliance(t, store)
+	})
+
+	t.Run("decorators", func(t *testing.T) {
+		var store Storage = jsonStorage("batch_decorated.json")
+		store = NewCachedStorage(store, CacheConfig{})
+		store = NewInstrumentedStorage(store, metrics.NewRegistry())
+		store = NewTracingStorage(store, nil)
+		testBatchCompliance(t, store)
+	})
+
+	t.Run("sqlite inserts more rows than fit in one statement", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("batch_large.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+
+		tasks := make([]*models.Task, 2*sqliteBatchRows+7)
+		for i := range tasks {
+			tasks[i] = helper.CreateSampleTask(fmt.Sprintf("large_%d", i), "Large")
+		}
+		helper.AssertNoError(store.CreateMany(tasks), "creating tasks")
+		if all, _ := store.GetAll(); len(all) != len(tasks) {
+			t.Errorf("Expected %d tasks, got %d", len(tasks), len(all))
+		}
+	})
+
+	t.Run("cache is dropped after a batch", func(t *testing.T) {
+		store := NewCachedStorage(jsonStorage("batch_cached.json"), CacheConfig{})
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("b1", "Before")), "creating task")
+		store.GetByID("b1")
+		store.GetAll()
+
+		helper.AssertNoError(store.UpdateMany([]*models.Task{helper.CreateSampleTask("b1", "After")}), "updating tasks")
+		if task, _ := store.GetByID("b1"); task == nil || task.Title != "After" {
+			t.Errorf("Expected the updated task, got %+v", task)
+		}
+	})
+
+	t.Run("unsupported storage", func(t *testing.T) {
+		store := struct{ Storage }{NewMemoryStorage()}
+		err := CreateMany(context.Background(), store, []*models.Task{helper.CreateSampleTask("u1", "Unsupported")})
+		if !errors.Is(err, ErrTxUnsupported) {
+			t.Errorf("Expected ErrTxUnsupported, got %v", err)
+		}
+	})
+}
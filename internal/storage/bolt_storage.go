@@ -0,0 +1,648 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+
+	bolt "go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
+)
+
+var (
+	boltTasksBucket   = []byte("tasks")
+	boltDueDateBucket = []byte("idx_due_date")
+	boltDoneBucket    = []byte("idx_done")
+	// boltIdempotencyBucket holds idempotency records as JSON by key
+	boltIdempotencyBucket = []byte("idempotency")
+	// boltViewsBucket holds saved view records as JSON by ID
+	boltViewsBucket = []byte("views")
+	// boltWebhookSubscriptionsBucket and boltWebhookDeliveriesBucket hold
+	// webhook records as JSON by ID
+	boltWebhookSubscriptionsBucket = []byte("webhook_subscriptions")
+	boltWebhookDeliveriesBucket    = []byte("webhook_deliveries")
+	// boltNotificationsBucket holds sent notification records as JSON by key
+	boltNotificationsBucket = []byte("notifications")
+)
+
+// BoltConfig holds the configuration for BoltStorage
+type BoltConfig struct {
+	// Path is the database file, created if missing
+	Path string
+	// LockTimeout is how long opening waits for another process to close
+	// the database before failing with ErrLockTimeout
+	LockTimeout time.Duration
+}
+
+// BoltStorage keeps tasks in an embedded bbolt database: a single file
+// holding a B+tree, written in pure Go. Tasks are stored as JSON by ID, and
+// every write updates the due date and done index buckets in the same
+// transaction, so the indexes never disagree with the tasks.
+//
+// bbolt locks the file for as long as it is open, so only one process can
+// use the database at a time.
+type BoltStorage struct {
+	db *bolt.DB
+	// tx is the transaction operations join, inside WithTx
+	tx *bolt.Tx
+}
+
+// NewBoltStorage opens or creates the database at config.Path
+func NewBoltStorage(config BoltConfig) (*BoltStorage, error) {
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = DefaultLockTimeout
+	}
+	db, err := bolt.Open(config.Path, 0644, &bolt.Options{Timeout: config.LockTimeout})
+	if errors.Is(err, bolterrors.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s is held by another process after %s", ErrLockTimeout, config.Path, config.LockTimeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltTasksBucket, boltDueDateBucket, boltDoneBucket, boltIdempotencyBucket, boltViewsBucket,
+			boltWebhookSubscriptionsBucket, boltWebhookDeliveriesBucket, boltNotificationsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create buckets: %w", err)
+	}
+
+	return &BoltStorage{db: db}, nil
+}
+
+// Create implements Storage interface
+func (bs *BoltStorage) Create(task *models.Task) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		if tx.Bucket(boltTasksBucket).Get([]byte(task.ID)) != nil {
+			return fmt.Errorf("task already exists")
+		}
+		return putBoltTask(tx, task)
+	})
+}
+
+// GetAll implements Storage interface, returning tasks in ID order
+func (bs *BoltStorage) GetAll() ([]*models.Task, error) {
+	tasks := make([]*models.Task, 0)
+	err := bs.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltTasksBucket).ForEach(func(_, data []byte) error {
+			task, err := decodeBoltTask(data)
+			if err != nil {
+				return err
+			}
+			tasks = append(tasks, task)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetByID implements Storage interface
+func (bs *BoltStorage) GetByID(id string) (*models.Task, error) {
+	var task *models.Task
+	err := bs.view(func(tx *bolt.Tx) error {
+		var err error
+		task, err = getBoltTask(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Update implements Storage interface
+func (bs *BoltStorage) Update(task *models.Task) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		old, err := getBoltTask(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if err := deleteBoltIndexes(tx, old); err != nil {
+			return err
+		}
+		return putBoltTask(tx, task)
+	})
+}
+
+// Delete implements Storage interface
+func (bs *BoltStorage) Delete(id string) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		old, err := getBoltTask(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteBoltIndexes(tx, old); err != nil {
+			return err
+		}
+		return tx.Bucket(boltTasksBucket).Delete([]byte(id))
+	})
+}
+
+// Find implements Finder. A status condition is served from the done index,
+// otherwise a due date range from the due date index, otherwise all tasks
+// are scanned; every candidate is then checked against the whole filter.
+func (bs *BoltStorage) Find(filter *query.Filter) ([]*models.Task, error) {
+	var status, due *query.Condition
+	for i, cond := range filter.Conditions {
+		switch {
+		case cond.Not || cond.Unset:
+		case cond.Field == query.FieldStatus && status == nil:
+			status = &filter.Conditions[i]
+		case cond.Field == query.FieldDue && due == nil && boltIndexable(cond.Since) && boltIndexable(cond.Until):
+			due = &filter.Conditions[i]
+		}
+	}
+
+	var tasks []*models.Task
+	collect := func(task *models.Task) {
+		if filter.Match(task) {
+			tasks = append(tasks, task)
+		}
+	}
+	err := bs.view(func(tx *bolt.Tx) error {
+		switch {
+		case status != nil:
+			prefix := boltDoneKey(status.Done, "")
+			cursor := tx.Bucket(boltDoneBucket).Cursor()
+			for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+				task, err := getBoltTask(tx, string(key[len(prefix):]))
+				if err != nil {
+					return err
+				}
+				collect(task)
+			}
+		case due != nil:
+			cursor := tx.Bucket(boltDueDateBucket).Cursor()
+			key, _ := cursor.First()
+			if !due.Since.IsZero() {
+				key, _ = cursor.Seek(boltDueKey(due.Since, ""))
+			}
+			for ; key != nil; key, _ = cursor.Next() {
+				at, id := decodeBoltDueKey(key)
+				if !due.Until.IsZero() && !at.Before(due.Until) {
+					break
+				}
+				task, err := getBoltTask(tx, id)
+				if err != nil {
+					return err
+				}
+				collect(task)
+			}
+			// Keep the ID order of GetAll
+			sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+		default:
+			return tx.Bucket(boltTasksBucket).ForEach(func(_, data []byte) error {
+				task, err := decodeBoltTask(data)
+				if err != nil {
+					return err
+				}
+				collect(task)
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// WithTx implements Transactor with a read-write bbolt transaction, which
+// also keeps other writers waiting until fn returns
+func (bs *BoltStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	if bs.tx != nil {
+		return fn(bs)
+	}
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		if err := fn(&BoltStorage{db: bs.db, tx: tx}); err != nil {
+			return err
+		}
+		return ctx.Err()
+	})
+}
+
+// update runs fn in the joined transaction or a new read-write one
+func (bs *BoltStorage) update(fn func(tx *bolt.Tx) error) error {
+	if bs.tx != nil {
+		return fn(bs.tx)
+	}
+	return bs.db.Update(fn)
+}
+
+// view runs fn in the joined transaction or a new read-only one
+func (bs *BoltStorage) view(fn func(tx *bolt.Tx) error) error {
+	if bs.tx != nil {
+		return fn(bs.tx)
+	}
+	return bs.db.View(fn)
+}
+
+// Close implements Storage interface
+func (bs *BoltStorage) Close() error {
+	return bs.db.Close()
+}
+
+// HealthCheck verifies that the database can still be read
+func (bs *BoltStorage) HealthCheck() error {
+	return bs.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(boltTasksBucket) == nil {
+			return fmt.Errorf("tasks bucket is missing")
+		}
+		return nil
+	})
+}
+
+func getBoltTask(tx *bolt.Tx, id string) (*models.Task, error) {
+	data := tx.Bucket(boltTasksBucket).Get([]byte(id))
+	if data == nil {
+		return nil, fmt.Errorf("task not found")
+	}
+	return decodeBoltTask(data)
+}
+
+// decodeBoltTask parses a stored task. The data is only valid during the
+// transaction, which unmarshalling does not retain.
+func decodeBoltTask(data []byte) (*models.Task, error) {
+	var task models.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("failed to decode task: %w", err)
+	}
+	return &task, nil
+}
+
+// putBoltTask stores task and adds its index entries
+func putBoltTask(tx *bolt.Tx, task *models.Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(boltTasksBucket).Put([]byte(task.ID), data); err != nil {
+		return err
+	}
+	if task.DueDate != nil {
+		if err := tx.Bucket(boltDueDateBucket).Put(boltDueKey(*task.DueDate, task.ID), nil); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(boltDoneBucket).Put(boltDoneKey(task.Done, task.ID), nil)
+}
+
+// deleteBoltIndexes removes the index entries of the stored task
+func deleteBoltIndexes(tx *bolt.Tx, task *models.Task) error {
+	if task.DueDate != nil {
+		if err := tx.Bucket(boltDueDateBucket).Delete(boltDueKey(*task.DueDate, task.ID)); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(boltDoneBucket).Delete(boltDoneKey(task.Done, task.ID))
+}
+
+// The due dates that Unix nanoseconds can hold, from 1677 to 2262
+var (
+	boltMinDue = time.Unix(0, math.MinInt64)
+	boltMaxDue = time.Unix(0, math.MaxInt64)
+)
+
+// boltDueKey orders index entries by due date: the Unix nanoseconds with the
+// sign bit flipped, big-endian so that byte order is time order, then the ID.
+// Dates beyond the nanosecond range saturate at its ends, which keeps the
+// order, and Find checks every candidate against the filter.
+func boltDueKey(due time.Time, id string) []byte {
+	var nanos int64
+	switch {
+	case due.Before(boltMinDue):
+		nanos = math.MinInt64
+	case due.After(boltMaxDue):
+		nanos = math.MaxInt64
+	default:
+		nanos = due.UnixNano()
+	}
+	key := make([]byte, 8, 8+len(id))
+	binary.BigEndian.PutUint64(key, uint64(nanos)^(1<<63))
+	return append(key, id...)
+}
+
+func decodeBoltDueKey(key []byte) (time.Time, string) {
+	nanos := int64(binary.BigEndian.Uint64(key[:8]) ^ (1 << 63))
+	return time.Unix(0, nanos), string(key[8:])
+}
+
+// boltIndexable reports whether t is zero or strictly within the times that
+// due date index keys can hold, so that saturated keys fall outside a range
+// bounded by t
+func boltIndexable(t time.Time) bool {
+	return t.IsZero() || t.Year() > 1677 && t.Year() < 2262
+}
+
+// boltDoneKey prefixes the ID with 1 for done tasks and 0 for the rest
+func boltDoneKey(done bool, id string) []byte {
+	flag := byte('0')
+	if done {
+		flag = '1'
+	}
+	return append([]byte{flag, '/'}, id...)
+}
+
+// CreateIdempotencyRecord implements IdempotencyStore
+func (bs *BoltStorage) CreateIdempotencyRecord(record *IdempotencyRecord) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		existing, err := getBoltIdempotencyRecord(tx, record.Key)
+		if err != nil {
+			return err
+		}
+		if existing != nil && !existing.Expired(record.CreatedAt) {
+			return ErrIdempotencyKeyExists
+		}
+		return putBoltIdempotencyRecord(tx, record)
+	})
+}
+
+// GetIdempotencyRecord implements IdempotencyStore
+func (bs *BoltStorage) GetIdempotencyRecord(key string) (*IdempotencyRecord, error) {
+	var record *IdempotencyRecord
+	err := bs.view(func(tx *bolt.Tx) error {
+		var err error
+		record, err = getBoltIdempotencyRecord(tx, key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.Expired(time.Now()) {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	return record, nil
+}
+
+// SaveIdempotencyRecord implements IdempotencyStore
+func (bs *BoltStorage) SaveIdempotencyRecord(record *IdempotencyRecord) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		return putBoltIdempotencyRecord(tx, record)
+	})
+}
+
+// DeleteIdempotencyRecord implements IdempotencyStore
+func (bs *BoltStorage) DeleteIdempotencyRecord(key string) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltIdempotencyBucket).Delete([]byte(key))
+	})
+}
+
+// DeleteExpiredIdempotencyRecords implements IdempotencyStore
+func (bs *BoltStorage) DeleteExpiredIdempotencyRecords(now time.Time) (int, error) {
+	removed := 0
+	err := bs.update(func(tx *bolt.Tx) error {
+		removed = 0
+		var expired [][]byte
+		err := tx.Bucket(boltIdempotencyBucket).ForEach(func(key, data []byte) error {
+			var record IdempotencyRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("invalid idempotency record %q: %w", key, err)
+			}
+			if record.Expired(now) {
+				// Keys are only valid until the transaction ends
+				expired = append(expired, bytes.Clone(key))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range expired {
+			if err := tx.Bucket(boltIdempotencyBucket).Delete(key); err != nil {
+				return err
+			}
+		}
+		removed = len(expired)
+		return nil
+	})
+	return removed, err
+}
+
+// getBoltIdempotencyRecord returns the record for key, expired or not, or nil
+func getBoltIdempotencyRecord(tx *bolt.Tx, key string) (*IdempotencyRecord, error) {
+	data := tx.Bucket(boltIdempotencyBucket).Get([]byte(key))
+	if data == nil {
+		return nil, nil
+	}
+	var record IdempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("invalid idempotency record %q: %w", key, err)
+	}
+	return &record, nil
+}
+
+func putBoltIdempotencyRecord(tx *bolt.Tx, record *IdempotencyRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+	return tx.Bucket(boltIdempotencyBucket).Put([]byte(record.Key), data)
+}
+
+// ListViews implements ViewStore
+func (bs *BoltStorage) ListViews() ([]*ViewRecord, error) {
+	var records []*ViewRecord
+	err := bs.view(func(tx *bolt.Tx) error {
+		records = nil
+		return tx.Bucket(boltViewsBucket).ForEach(func(id, data []byte) error {
+			var record ViewRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("invalid view record %q: %w", id, err)
+			}
+			records = append(records, &record)
+			return nil
+		})
+	})
+	return records, err
+}
+
+// SaveView implements ViewStore
+func (bs *BoltStorage) SaveView(record *ViewRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode view record: %w", err)
+	}
+	return bs.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltViewsBucket).Put([]byte(record.ID), data)
+	})
+}
+
+// DeleteView implements ViewStore
+func (bs *BoltStorage) DeleteView(id string) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltViewsBucket).Delete([]byte(id))
+	})
+}
+
+// ListWebhookSubscriptions implements WebhookStore
+func (bs *BoltStorage) ListWebhookSubscriptions() ([]*WebhookSubscriptionRecord, error) {
+	var records []*WebhookSubscriptionRecord
+	err := bs.view(func(tx *bolt.Tx) error {
+		records = make([]*WebhookSubscriptionRecord, 0)
+		return tx.Bucket(boltWebhookSubscriptionsBucket).ForEach(func(id, data []byte) error {
+			var record WebhookSubscriptionRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("invalid webhook subscription record %q: %w", id, err)
+			}
+			records = append(records, &record)
+			return nil
+		})
+	})
+	return records, err
+}
+
+// SaveWebhookSubscription implements WebhookStore
+func (bs *BoltStorage) SaveWebhookSubscription(record *WebhookSubscriptionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook subscription record: %w", err)
+	}
+	return bs.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltWebhookSubscriptionsBucket).Put([]byte(record.ID), data)
+	})
+}
+
+// DeleteWebhookSubscription implements WebhookStore
+func (bs *BoltStorage) DeleteWebhookSubscription(id string) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		deliveries, err := boltWebhookDeliveries(tx, id)
+		if err != nil {
+			return err
+		}
+		bucket := tx.Bucket(boltWebhookDeliveriesBucket)
+		for _, record := range deliveries {
+			if err := bucket.Delete([]byte(record.ID)); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(boltWebhookSubscriptionsBucket).Delete([]byte(id))
+	})
+}
+
+// ListWebhookDeliveries implements WebhookStore
+func (bs *BoltStorage) ListWebhookDeliveries(subscriptionID string) ([]*WebhookDeliveryRecord, error) {
+	var records []*WebhookDeliveryRecord
+	err := bs.view(func(tx *bolt.Tx) error {
+		var err error
+		records, err = boltWebhookDeliveries(tx, subscriptionID)
+		return err
+	})
+	return records, err
+}
+
+// SaveWebhookDelivery implements WebhookStore
+func (bs *BoltStorage) SaveWebhookDelivery(record *WebhookDeliveryRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook delivery record: %w", err)
+	}
+	return bs.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltWebhookDeliveriesBucket).Put([]byte(record.ID), data)
+	})
+}
+
+// DeleteWebhookDeliveries implements WebhookStore
+func (bs *BoltStorage) DeleteWebhookDeliveries(ids []string) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltWebhookDeliveriesBucket)
+		for _, id := range ids {
+			if err := bucket.Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// boltWebhookDeliveries returns the delivery records for subscriptionID, or
+// every record when it is empty
+func boltWebhookDeliveries(tx *bolt.Tx, subscriptionID string) ([]*WebhookDeliveryRecord, error) {
+	records := make([]*WebhookDeliveryRecord, 0)
+	err := tx.Bucket(boltWebhookDeliveriesBucket).ForEach(func(id, data []byte) error {
+		var record WebhookDeliveryRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("invalid webhook delivery record %q: %w", id, err)
+		}
+		if subscriptionID == "" || record.SubscriptionID == subscriptionID {
+			records = append(records, &record)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// ClaimNotification implements NotificationStore
+func (bs *BoltStorage) ClaimNotification(record *NotificationRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification record: %w", err)
+	}
+	return bs.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltNotificationsBucket)
+		if bucket.Get([]byte(record.Key)) != nil {
+			return ErrNotificationClaimed
+		}
+		return bucket.Put([]byte(record.Key), data)
+	})
+}
+
+// DeleteNotification implements NotificationStore
+func (bs *BoltStorage) DeleteNotification(key string) error {
+	return bs.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltNotificationsBucket).Delete([]byte(key))
+	})
+}
+
+// DeleteNotificationsBefore implements NotificationStore
+func (bs *BoltStorage) DeleteNotificationsBefore(before time.Time) (int, error) {
+	removed := 0
+	err := bs.update(func(tx *bolt.Tx) error {
+		var old [][]byte
+		err := tx.Bucket(boltNotificationsBucket).ForEach(func(key, data []byte) error {
+			var record NotificationRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("invalid notification record %q: %w", key, err)
+			}
+			if record.SentAt.Before(before) {
+				// Keys are only valid until the transaction ends
+				old = append(old, bytes.Clone(key))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range old {
+			if err := tx.Bucket(boltNotificationsBucket).Delete(key); err != nil {
+				return err
+			}
+		}
+		removed = len(old)
+		return nil
+	})
+	return removed, err
+}
+
+// Verify that BoltStorage implements Storage interface
+var _ Storage = (*BoltStorage)(nil)
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+)
+
+func TestBoltStorage(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	open := func(path string) *BoltStorage {
+		t.Helper()
+		store, err := NewBoltStorage(BoltConfig{Path: path})
+		if err != nil {
+			t.Fatalf("Failed to open Bolt storage: %v", err)
+		}
+		return store
+	}
+	ids := func(tasks []*models.Task) []string {
+		result := make([]string, len(tasks))
+		for i, task := range tasks {
+			result[i] = task.ID
+		}
+		return result
+	}
+	find := func(store *BoltStorage, conditions ...query.Condition) []*models.Task {
+		t.Helper()
+		tasks, err := store.Find(&query.Filter{Conditions: conditions})
+		helper.AssertNoError(err, "finding tasks")
+		return tasks
+	}
+	dueBefore := func(until time.Time) query.Condition {
+		return query.Condition{Field: query.FieldDue, Until: until}
+	}
+	status := func(done bool) query.Condition {
+		return query.Condition{Field: query.FieldStatus, Done: done}
+	}
+
+	t.Run("passes storage compliance", func(t *testing.T) {
+		store := open(helper.TempFilePath("bolt_compliance.db"))
+		defer store.Close()
+		testStorageCompliance(t, store)
+	})
+
+	t.Run("persists across reopening", func(t *testing.T) {
+		path := helper.TempFilePath("bolt_reopen.db")
+		store := open(path)
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("p1", "Persisted")), "creating task")
+		helper.AssertNoError(store.Close(), "closing")
+
+		reopened := open(path)
+		defer reopened.Close()
+		if task, err := reopened.GetByID("p1"); err != nil || task.Title != "Persisted" {
+			t.Errorf("Expected the stored task, got %+v, %v", task, err)
+		}
+		helper.AssertError(reopened.Create(helper.CreateSampleTask("p1", "Duplicate")), true, "creating a duplicate")
+	})
+
+	t.Run("maintains the indexes", func(t *testing.T) {
+		store := open(helper.TempFilePath("bolt_indexes.db"))
+		defer store.Close()
+
+		now := time.Now()
+		due := func(d time.Duration) *time.Time {
+			at := now.Add(d)
+			return &at
+		}
+		tasks := []*models.Task{
+			{ID: "i1", Title: "Later", CreatedAt: now, DueDate: due(48 * time.Hour)},
+			{ID: "i2", Title: "Overdue", CreatedAt: now, DueDate: due(-time.Hour)},
+			{ID: "i3", Title: "No due date", CreatedAt: now, Done: true},
+			{ID: "i4", Title: "Soon", CreatedAt: now, DueDate: due(time.Hour)},
+		}
+		for _, task := range tasks {
+			helper.AssertNoError(store.Create(task), "creating task")
+		}
+
+		dueSoon := find(store, dueBefore(now.Add(24*time.Hour)))
+		if got := ids(dueSoon); len(got) != 2 || got[0] != "i2" || got[1] != "i4" {
+			t.Errorf("Expected i2 and i4 by due date, got %v", got)
+		}
+		if got := ids(find(store, status(true))); len(got) != 1 || got[0] != "i3" {
+			t.Errorf("Expected only i3 to be done, got %v", got)
+		}
+
+		// Updates move index entries and deletes remove them
+		tasks[3].DueDate = due(72 * time.Hour)
+		tasks[3].Done = true
+		helper.AssertNoError(store.Update(tasks[3]), "updating task")
+		helper.AssertNoError(store.Delete("i2"), "deleting task")
+
+		if dueSoon := find(store, dueBefore(now.Add(24*time.Hour))); len(dueSoon) != 0 {
+			t.Errorf("Expected no tasks due soon, got %v", ids(dueSoon))
+		}
+		if got := ids(find(store, dueBefore(now.Add(100*time.Hour)))); len(got) != 2 || got[0] != "i1" || got[1] != "i4" {
+			t.Errorf("Expected i1 then i4, got %v", got)
+		}
+		if got := ids(find(store, status(false))); len(got) != 1 || got[0] != "i1" {
+			t.Errorf("Expected only i1 to be undone, got %v", got)
+		}
+	})
+
+	t.Run("indexes due dates beyond the nanosecond range", func(t *testing.T) {
+		store := open(helper.TempFilePath("bolt_far_dates.db"))
+		defer store.Close()
+
+		at := func(year int) *time.Time {
+			t := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+			return &t
+		}
+		for _, task := range []*models.Task{
+			{ID: "ancient", Title: "Ancient", DueDate: at(1500)},
+			{ID: "now", Title: "Now", DueDate: at(2026)},
+			{ID: "distant", Title: "Distant", DueDate: at(2500)},
+		} {
+			helper.AssertNoError(store.Create(task), "creating task")
+		}
+
+		if got := ids(find(store, dueBefore(*at(2000)))); len(got) != 1 || got[0] != "ancient" {
+			t.Errorf("Expected only the ancient task before 2000, got %v", got)
+		}
+		if got := ids(find(store, query.Condition{Field: query.FieldDue, Since: *at(2100)})); len(got) != 1 || got[0] != "distant" {
+			t.Errorf("Expected only the distant task after 2100, got %v", got)
+		}
+
+		helper.AssertNoError(store.Delete("distant"), "deleting task")
+		helper.AssertNoError(store.Delete("ancient"), "deleting task")
+		if got := ids(find(store, dueBefore(*at(2200)))); len(got) != 1 || got[0] != "now" {
+			t.Errorf("Expected the index entries of deleted tasks to be removed, got %v", got)
+		}
+	})
+
+	t.Run("rolls back failed writes", func(t *testing.T) {
+		store := open(helper.TempFilePath("bolt_rollback.db"))
+		defer store.Close()
+
+		helper.AssertError(store.Update(helper.CreateSampleTask("missing", "Missing")), true, "updating a missing task")
+		helper.AssertError(store.Delete("missing"), true, "deleting a missing task")
+		if undone := find(store, status(false)); len(undone) != 0 {
+			t.Errorf("Expected no index entries from failed writes, got %v", ids(undone))
+		}
+	})
+
+	t.Run("times out while another instance has the file open", func(t *testing.T) {
+		path := helper.TempFilePath("bolt_locked.db")
+		store := open(path)
+		defer store.Close()
+
+		_, err := NewBoltStorage(BoltConfig{Path: path, LockTimeout: 50 * time.Millisecond})
+		if !errors.Is(err, ErrLockTimeout) {
+			t.Errorf("Expected ErrLockTimeout, got %v", err)
+		}
+	})
+}
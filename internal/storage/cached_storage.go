@@ -0,0 +1,473 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+)
+
+const (
+	// DefaultCacheTTL is how long cached results are served when CacheConfig.TTL is zero
+	DefaultCacheTTL = 30 * time.Second
+	// DefaultCacheMaxEntries bounds the cached tasks when CacheConfig.MaxEntries is zero
+	DefaultCacheMaxEntries = 1000
+)
+
+// CacheConfig holds the settings for CachedStorage
+type CacheConfig struct {
+	// TTL bounds how stale a result may be when another process changes
+	// the storage and no change feed is watched
+	TTL time.Duration
+	// MaxEntries bounds the number of tasks cached by ID; the least recently
+	// used are evicted first
+	MaxEntries int
+}
+
+// CachedStorage decorates a Storage with an in-memory cache of GetByID,
+// GetAll and Find results. Writes go to the wrapped storage and invalidate the
+// affected results, so the next read returns the task as stored. Tasks are
+// copied in and out, so callers may modify the tasks they receive.
+type CachedStorage struct {
+	next  Storage
+	cache *taskCache
+}
+
+// NewCachedStorage wraps next with a cache configured by config
+func NewCachedStorage(next Storage, config CacheConfig) *CachedStorage {
+	if config.TTL <= 0 {
+		config.TTL = DefaultCacheTTL
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultCacheMaxEntries
+	}
+	return &CachedStorage{
+		next: next,
+		cache: &taskCache{
+			ttl:        config.TTL,
+			maxEntries: config.MaxEntries,
+			byID:       make(map[string]*list.Element),
+			lru:        list.New(),
+		},
+	}
+}
+
+// Create implements Storage interface
+func (cs *CachedStorage) Create(task *models.Task) error {
+	err := cs.next.Create(task)
+	cs.cache.invalidate(task.ID)
+	return err
+}
+
+// GetAll implements Storage interface
+func (cs *CachedStorage) GetAll() ([]*models.Task, error) {
+	if tasks, ok := cs.cache.all(); ok {
+		return tasks, nil
+	}
+
+	generation := cs.cache.generation()
+	tasks, err := cs.next.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	cs.cache.storeAll(generation, tasks)
+	return copyTasks(tasks), nil
+}
+
+// GetByID implements Storage interface
+func (cs *CachedStorage) GetByID(id string) (*models.Task, error) {
+	if task, ok := cs.cache.get(id); ok {
+		return task, nil
+	}
+
+	generation := cs.cache.generation()
+	task, err := cs.next.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	cs.cache.store(generation, task)
+	return copyTask(task), nil
+}
+
+// Update implements Storage interface
+func (cs *CachedStorage) Update(task *models.Task) error {
+	err := cs.next.Update(task)
+	cs.cache.invalidate(task.ID)
+	return err
+}
+
+// Delete implements Storage interface
+func (cs *CachedStorage) Delete(id string) error {
+	err := cs.next.Delete(id)
+	cs.cache.invalidate(id)
+	return err
+}
+
+// UpdateIfVersion implements Versioner, natively when the wrapped storage
+// does. The version is checked against the wrapped storage, never the cache.
+func (cs *CachedStorage) UpdateIfVersion(task *models.Task, expected int64) error {
+	err := UpdateIfVersion(context.Background(), cs.next, task, expected)
+	cs.cache.invalidate(task.ID)
+	return err
+}
+
+// DeleteIfVersion implements Versioner, see UpdateIfVersion
+func (cs *CachedStorage) DeleteIfVersion(id string, expected int64) error {
+	err := DeleteIfVersion(context.Background(), cs.next, id, expected)
+	cs.cache.invalidate(id)
+	return err
+}
+
+// GetFresh implements FreshReader by reading the task from the wrapped
+// storage and caching the result
+func (cs *CachedStorage) GetFresh(id string) (*models.Task, error) {
+	generation := cs.cache.generation()
+	task, err := GetFresh(cs.next, id)
+	if err != nil {
+		return nil, err
+	}
+	cs.cache.store(generation, task)
+	return copyTask(task), nil
+}
+
+// Search implements Searcher, natively when the wrapped storage does. Results
+// are not cached.
+func (cs *CachedStorage) Search(query string, limit int) ([]SearchResult, error) {
+	return Search(cs.next, query, limit)
+}
+
+// Find implements Finder, natively when the wrapped storage does, with the
+// results cached by filter, and otherwise by filtering the cached task list
+func (cs *CachedStorage) Find(filter *query.Filter) ([]*models.Task, error) {
+	if _, ok := cs.next.(Finder); !ok {
+		tasks, err := cs.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		return matchTasks(tasks, filter), nil
+	}
+
+	key := filterKey(filter)
+	if tasks, ok := cs.cache.found(key); ok {
+		return tasks, nil
+	}
+
+	generation := cs.cache.generation()
+	tasks, err := Find(cs.next, filter)
+	if err != nil {
+		return nil, err
+	}
+	cs.cache.storeFound(generation, key, tasks)
+	return copyTasks(tasks), nil
+}
+
+// filterKey identifies the conditions of filter, with relative dates
+// already resolved
+func filterKey(filter *query.Filter) string {
+	var b strings.Builder
+	for _, c := range filter.Conditions {
+		fmt.Fprintf(&b, "%d|%t|%q|%t|%t|%d|%d;", c.Field, c.Not, c.Text, c.Done, c.Unset, c.Since.UnixNano(), c.Until.UnixNano())
+	}
+	return b.String()
+}
+
+// CreateMany implements Batcher, natively when the wrapped storage does, and
+// drops the whole cache rather than each task
+func (cs *CachedStorage) CreateMany(tasks []*models.Task) error {
+	defer cs.cache.clear()
+	return CreateMany(context.Background(), cs.next, tasks)
+}
+
+// UpdateMany implements Batcher, see CreateMany
+func (cs *CachedStorage) UpdateMany(tasks []*models.Task) error {
+	defer cs.cache.clear()
+	return UpdateMany(context.Background(), cs.next, tasks)
+}
+
+// DeleteMany implements Batcher, see CreateMany
+func (cs *CachedStorage) DeleteMany(ids []string) error {
+	defer cs.cache.clear()
+	return DeleteMany(context.Background(), cs.next, ids)
+}
+
+// WithTx implements Transactor when the wrapped storage does. Operations in
+// the transaction bypass the cache, so that uncommitted tasks are never
+// cached, and the whole cache is dropped afterwards.
+func (cs *CachedStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	defer cs.cache.clear()
+	return WithTx(ctx, cs.next, fn)
+}
+
+// Close implements Storage interface
+func (cs *CachedStorage) Close() error {
+	return cs.next.Close()
+}
+
+// HealthCheck forwards to the wrapped storage when it supports health checks
+func (cs *CachedStorage) HealthCheck() error {
+	if healthChecker, ok := cs.next.(interface{ HealthCheck() error }); ok {
+		return healthChecker.HealthCheck()
+	}
+	return nil
+}
+
+// Watch implements Watcher when the wrapped storage does, invalidating
+// cached tasks as changes are reported so that changes made by other
+// processes are seen before the TTL expires. AsWatcher returns the cache only
+// when the wrapped storage can be watched.
+func (cs *CachedStorage) Watch(ctx context.Context) (<-chan Change, error) {
+	watcher, ok := AsWatcher(cs.next)
+	if !ok {
+		return nil, fmt.Errorf("storage does not support watching")
+	}
+	changes, err := watcher.Watch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	forwarded := make(chan Change)
+	go func() {
+		defer close(forwarded)
+		for change := range changes {
+			cs.cache.invalidate(change.TaskID)
+			if !sendChange(ctx, forwarded, change) {
+				return
+			}
+		}
+	}()
+	return forwarded, nil
+}
+
+func (cs *CachedStorage) canWatch() bool {
+	_, ok := AsWatcher(cs.next)
+	return ok
+}
+
+// Invalidate drops every cached result
+func (cs *CachedStorage) Invalidate() {
+	cs.cache.clear()
+}
+
+// WithContext implements ContextBinder by binding ctx to the wrapped storage;
+// the bound storage shares the cache
+func (cs *CachedStorage) WithContext(ctx context.Context) Storage {
+	bound := *cs
+	bound.next = WithContext(ctx, cs.next)
+	return &bound
+}
+
+// Unwrap returns the decorated storage
+func (cs *CachedStorage) Unwrap() Storage {
+	return cs.next
+}
+
+// taskCache holds the cached results shared by a CachedStorage and the
+// storages bound from it
+type taskCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu    sync.Mutex
+	byID  map[string]*list.Element
+	lru   *list.List // of *cacheEntry, most recently used first
+	tasks []*models.Task
+	// listExpires is zero when tasks, the GetAll result, is not cached
+	listExpires time.Time
+	// finds holds Find results by filterKey; any write drops them all
+	finds map[string]cacheFind
+	// gen increases on every write, so a read that raced with a write does
+	// not cache what it read
+	gen uint64
+}
+
+type cacheEntry struct {
+	task    *models.Task
+	expires time.Time
+}
+
+type cacheFind struct {
+	tasks   []*models.Task
+	expires time.Time
+}
+
+// cacheMaxFinds bounds the number of cached Find results
+const cacheMaxFinds = 100
+
+func (c *taskCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+func (c *taskCache) get(id string) (*models.Task, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.byID[id]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.byID, id)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return copyTask(entry.task), true
+}
+
+func (c *taskCache) all() ([]*models.Task, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.listExpires.IsZero() || time.Now().After(c.listExpires) {
+		return nil, false
+	}
+	return copyTasks(c.tasks), true
+}
+
+func (c *taskCache) found(key string) ([]*models.Task, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.finds[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.finds, key)
+		return nil, false
+	}
+	return copyTasks(entry.tasks), true
+}
+
+// storeFound caches the result of Find unless a write happened since
+// generation was read. When full, expired results are dropped, and every
+// result if none had expired.
+func (c *taskCache) storeFound(generation uint64, key string, tasks []*models.Task) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.gen {
+		return
+	}
+
+	now := time.Now()
+	if len(c.finds) >= cacheMaxFinds {
+		for k, entry := range c.finds {
+			if now.After(entry.expires) {
+				delete(c.finds, k)
+			}
+		}
+		if len(c.finds) >= cacheMaxFinds {
+			clear(c.finds)
+		}
+	}
+	if c.finds == nil {
+		c.finds = make(map[string]cacheFind)
+	}
+	c.finds[key] = cacheFind{tasks: copyTasks(tasks), expires: now.Add(c.ttl)}
+}
+
+// store caches task unless a write happened since generation was read
+func (c *taskCache) store(generation uint64, task *models.Task) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.gen {
+		c.put(task)
+	}
+}
+
+// storeAll caches the result of GetAll unless a write happened since
+// generation was read
+func (c *taskCache) storeAll(generation uint64, tasks []*models.Task) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.gen {
+		c.tasks = copyTasks(tasks)
+		c.listExpires = time.Now().Add(c.ttl)
+	}
+}
+
+// invalidate drops id and the GetAll result after a write. It runs even when
+// the write failed, since the stored state is then unknown.
+func (c *taskCache) invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.tasks, c.listExpires = nil, time.Time{}
+	clear(c.finds)
+	c.remove(id)
+}
+
+func (c *taskCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.tasks, c.listExpires = nil, time.Time{}
+	clear(c.finds)
+	clear(c.byID)
+	c.lru.Init()
+}
+
+// put caches a copy of task, evicting the least recently used entry when
+// full. Callers hold c.mu.
+func (c *taskCache) put(task *models.Task) {
+	c.remove(task.ID)
+	entry := &cacheEntry{task: copyTask(task), expires: time.Now().Add(c.ttl)}
+	c.byID[task.ID] = c.lru.PushFront(entry)
+
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.byID, oldest.Value.(*cacheEntry).task.ID)
+	}
+}
+
+// remove drops id from the cache. Callers hold c.mu.
+func (c *taskCache) remove(id string) {
+	if elem, ok := c.byID[id]; ok {
+		c.lru.Remove(elem)
+		delete(c.byID, id)
+	}
+}
+
+// copyTask returns a deep copy of task, so neither copy sees changes made
+// through the other, including to its dates
+func copyTask(task *models.Task) *models.Task {
+	if task == nil {
+		return nil
+	}
+	clone := *task
+	if task.DueDate != nil {
+		dueDate := *task.DueDate
+		clone.DueDate = &dueDate
+	}
+	if task.CompletedAt != nil {
+		completedAt := *task.CompletedAt
+		clone.CompletedAt = &completedAt
+	}
+	return &clone
+}
+
+// copyTasks copies tasks, keeping a nil slice nil
+func copyTasks(tasks []*models.Task) []*models.Task {
+	if tasks == nil {
+		return nil
+	}
+	clones := make([]*models.Task, len(tasks))
+	for i, task := range tasks {
+		clones[i] = copyTask(task)
+	}
+	return clones
+}
+
+// Verify that CachedStorage implements Storage interface
+var _ Storage = (*CachedStorage)(nil)
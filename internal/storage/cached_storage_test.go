@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+)
+
+// countingStorage counts the reads that reach the wrapped storage
+type countingStorage struct {
+	Storage
+	getAll  atomic.Int64
+	getByID atomic.Int64
+}
+
+func (cs *countingStorage) GetAll() ([]*models.Task, error) {
+	cs.getAll.Add(1)
+	return cs.Storage.GetAll()
+}
+
+func (cs *countingStorage) GetByID(id string) (*models.Task, error) {
+	cs.getByID.Add(1)
+	return cs.Storage.GetByID(id)
+}
+
+// findingStorage is a Finder counting the Find calls that reach it
+type findingStorage struct {
+	Storage
+	finds atomic.Int64
+}
+
+func (fs *findingStorage) Find(filter *query.Filter) ([]*models.Task, error) {
+	fs.finds.Add(1)
+	tasks, err := fs.Storage.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return matchTasks(tasks, filter), nil
+}
+
+func TestCachedStorage(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	newCached := func(name string, config CacheConfig) (*CachedStorage, *countingStorage) {
+		inner, err := NewJSONStorage(helper.TempFilePath(name))
+		helper.AssertNoError(err, "creating JSON storage")
+		counting := &countingStorage{Storage: inner}
+		return NewCachedStorage(counting, config), counting
+	}
+
+	t.Run("passes storage compliance", func(t *testing.T) {
+		store, _ := newCached("cached_compliance.json", CacheConfig{})
+		testStorageCompliance(t, store)
+	})
+
+	t.Run("serves repeated reads from memory", func(t *testing.T) {
+		store, counting := newCached("cached_reads.json", CacheConfig{})
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("c1", "Cached")), "creating task")
+
+		for range 3 {
+			_, err := store.GetByID("c1")
+			helper.AssertNoError(err, "getting task")
+			_, err = store.GetAll()
+			helper.AssertNoError(err, "listing tasks")
+		}
+		if got := counting.getByID.Load(); got != 1 {
+			t.Errorf("Expected 1 backend GetByID, got %d", got)
+		}
+		if got := counting.getAll.Load(); got != 1 {
+			t.Errorf("Expected 1 backend GetAll, got %d", got)
+		}
+
+		// Misses are not cached
+		for range 2 {
+			_, err := store.GetByID("missing")
+			helper.AssertError(err, true, "getting missing task")
+		}
+		if got := counting.getByID.Load(); got != 3 {
+			t.Errorf("Expected missing tasks to be looked up each time, got %d lookups", got)
+		}
+	})
+
+	t.Run("writes invalidate", func(t *testing.T) {
+		store, _ := newCached("cached_writes.json", CacheConfig{})
+		task := helper.CreateSampleTask("w1", "Before")
+		helper.AssertNoError(store.Create(task), "creating task")
+		store.GetByID("w1")
+		store.GetAll()
+
+		task.Title = "After"
+		helper.AssertNoError(store.Update(task), "updating task")
+		got, err := store.GetByID("w1")
+		helper.AssertNoError(err, "getting task")
+		if got.Title != "After" {
+			t.Errorf("Expected the updated title, got %q", got.Title)
+		}
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("w2", "Second")), "creating task")
+		if tasks, _ := store.GetAll(); len(tasks) != 2 {
+			t.Errorf("Expected 2 tasks after a create, got %d", len(tasks))
+		}
+
+		helper.AssertNoError(store.Delete("w1"), "deleting task")
+		_, err = store.GetByID("w1")
+		helper.AssertError(err, true, "getting deleted task")
+	})
+
+	t.Run("returns copies", func(t *testing.T) {
+		store, _ := newCached("cached_copies.json", CacheConfig{})
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("p1", "Original")), "creating task")
+
+		got, _ := store.GetByID("p1")
+		got.Title = "Mutated"
+		tasks, _ := store.GetAll()
+		tasks[0].Title = "Mutated"
+
+		if got, _ := store.GetByID("p1"); got.Title != "Original" {
+			t.Errorf("Expected the cached task to be unaffected, got %q", got.Title)
+		}
+		if tasks, _ := store.GetAll(); tasks[0].Title != "Original" {
+			t.Errorf("Expected the cached list to be unaffected, got %q", tasks[0].Title)
+		}
+	})
+
+	t.Run("expires entries after the TTL", func(t *testing.T) {
+		store, counting := newCached("cached_ttl.json", CacheConfig{TTL: 20 * time.Millisecond})
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("t1", "Short lived")), "creating task")
+
+		store.GetByID("t1")
+		store.GetAll()
+		time.Sleep(40 * time.Millisecond)
+		store.GetByID("t1")
+		store.GetAll()
+
+		if counting.getByID.Load() != 2 || counting.getAll.Load() != 2 {
+			t.Errorf("Expected expired results to be reloaded, got %d and %d loads", counting.getByID.Load(), counting.getAll.Load())
+		}
+	})
+
+	t.Run("evicts the least recently used", func(t *testing.T) {
+		store, counting := newCached("cached_lru.json", CacheConfig{MaxEntries: 2})
+		for _, id := range []string{"l1", "l2", "l3"} {
+			helper.AssertNoError(store.Create(helper.CreateSampleTask(id, id)), "creating task")
+		}
+
+		store.GetByID("l1")
+		store.GetByID("l2")
+		store.GetByID("l1")
+		store.GetByID("l3") // evicts l2
+		before := counting.getByID.Load()
+
+		store.GetByID("l1")
+		store.GetByID("l3")
+		if got := counting.getByID.Load() - before; got != 0 {
+			t.Errorf("Expected l1 and l3 to be cached, got %d backend reads", got)
+		}
+		store.GetByID("l2")
+		if got := counting.getByID.Load() - before; got != 1 {
+			t.Errorf("Expected l2 to have been evicted, got %d backend reads", got)
+		}
+	})
+
+	t.Run("change feed invalidates", func(t *testing.T) {
+		path := helper.TempFilePath("cached_feed.json")
+		inner, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+		store := NewCachedStorage(inner, CacheConfig{TTL: time.Hour})
+		external, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+
+		task := helper.CreateSampleTask("f1", "Before")
+		helper.AssertNoError(store.Create(task), "creating task")
+		store.GetByID("f1")
+
+		if _, ok := AsWatcher(NewTracingStorage(store, nil)); !ok {
+			t.Fatal("Expected the cache to be found as a watcher")
+		}
+		changes, err := store.Watch(t.Context())
+		helper.AssertNoError(err, "watching")
+
+		task.Title = "Changed elsewhere"
+		helper.AssertNoError(external.Update(task), "updating task")
+		select {
+		case <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the change")
+		}
+		if got, _ := store.GetByID("f1"); got.Title != "Changed elsewhere" {
+			t.Errorf("Expected the external change after invalidation, got %q", got.Title)
+		}
+	})
+
+	t.Run("is a watcher only when the wrapped storage is", func(t *testing.T) {
+		if _, ok := AsWatcher(NewCachedStorage(NewMemoryStorage(), CacheConfig{})); ok {
+			t.Error("Expected a cache over memory storage not to be a watcher")
+		}
+	})
+
+	t.Run("caches native find results by filter", func(t *testing.T) {
+		finding := &findingStorage{Storage: NewMemoryStorage()}
+		store := NewCachedStorage(finding, CacheConfig{})
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("n1", "Native")), "creating task")
+		now := time.Now()
+		open, _ := query.ParseFilter("status:open due:none", now)
+		done, _ := query.ParseFilter("status:done", now)
+
+		for range 3 {
+			tasks, err := store.Find(open)
+			helper.AssertNoError(err, "finding tasks")
+			if len(tasks) != 1 {
+				t.Fatalf("Expected 1 open task, got %d", len(tasks))
+			}
+			tasks[0].Title = "Mutated"
+		}
+		store.Find(done)
+		if got := finding.finds.Load(); got != 2 {
+			t.Errorf("Expected 1 backend Find per filter, got %d", got)
+		}
+
+		// Same conditions compiled again share the entry; writes drop it
+		again, _ := query.ParseFilter("status:open due:none", now)
+		if tasks, _ := store.Find(again); tasks[0].Title != "Native" || finding.finds.Load() != 2 {
+			t.Errorf("Expected an unmodified cached result, got %q after %d finds", tasks[0].Title, finding.finds.Load())
+		}
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("n2", "Second")), "creating task")
+		if tasks, _ := store.Find(open); len(tasks) != 2 || finding.finds.Load() != 3 {
+			t.Errorf("Expected a fresh result after a write, got %d tasks", len(tasks))
+		}
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		store, _ := newCached("cached_concurrent.json", CacheConfig{MaxEntries: 5})
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				id := fmt.Sprintf("r%d", i)
+				task := helper.CreateSampleTask(id, "Concurrent")
+				if err := store.Create(task); err != nil {
+					t.Errorf("Failed to create task: %v", err)
+					return
+				}
+				for j := range 20 {
+					task.Title = fmt.Sprintf("Title %d", j)
+					store.Update(task)
+					store.GetByID(id)
+					store.GetAll()
+				}
+				if got, _ := store.GetByID(id); got.Title != "Title 19" {
+					t.Errorf("Expected the last write to be read back, got %q", got.Title)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
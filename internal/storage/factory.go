@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
 	"time"
@@ -16,12 +17,21 @@ const (
 	StorageTypePostgreSQL StorageType = "postgres"
 	StorageTypeMySQL      StorageType = "mysql"
 	StorageTypeMongoDB    StorageType = "mongodb"
+	StorageTypeWAL        StorageType = "wal"
+	StorageTypeBolt       StorageType = "bolt"
+	StorageTypeMemory     StorageType = "memory"
 )
 
 // StorageConfig holds configuration for all storage types
 type StorageConfig struct {
 	Type     StorageType
-	FilePath string // For JSON and SQLite
+	FilePath string // For JSON, SQLite, WAL and Bolt
+	// LockTimeout bounds the wait for another process to release a JSON,
+	// WAL or Bolt file; DefaultLockTimeout is used when zero
+	LockTimeout time.Duration
+	// SnapshotPath is where memory storage writes its tasks on Close;
+	// nothing is written when empty
+	SnapshotPath string
 
 	// Database connection settings
 	Host     string
@@ -44,6 +54,9 @@ type StorageConfig struct {
 	Collection     string
 	ConnectTimeout time.Duration
 	QueryTimeout   time.Duration
+
+	// Logger receives backend logs; slog.Default() is used when nil
+	Logger *slog.Logger
 }
 
 // NewStorageFromEnv creates a storage instance based on environment variables
@@ -116,6 +129,14 @@ func LoadConfigFromEnv() (*StorageConfig, error) {
 	}
 	config.QueryTimeout = queryTimeout
 
+	lockTimeoutStr := getEnvOrDefault("STORAGE_LOCK_TIMEOUT", DefaultLockTimeout.String())
+	lockTimeout, err := time.ParseDuration(lockTimeoutStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid STORAGE_LOCK_TIMEOUT: %w", err)
+	}
+	config.LockTimeout = lockTimeout
+	config.SnapshotPath = os.Getenv("STORAGE_SNAPSHOT_PATH")
+
 	return config, nil
 }
 
@@ -123,11 +144,20 @@ func LoadConfigFromEnv() (*StorageConfig, error) {
 func NewStorage(config *StorageConfig) (Storage, error) {
 	switch config.Type {
 	case StorageTypeJSON:
-		return NewJSONStorage(config.FilePath)
+		return NewJSONStorage(config.FilePath, WithLockTimeout(config.LockTimeout))
 
 	case StorageTypeSQLite:
 		return NewSQLiteStorage(config.FilePath)
 
+	case StorageTypeWAL:
+		return NewWALStorage(WALConfig{Path: config.FilePath, LockTimeout: config.LockTimeout, Logger: config.Logger})
+
+	case StorageTypeBolt:
+		return NewBoltStorage(BoltConfig{Path: config.FilePath, LockTimeout: config.LockTimeout})
+
+	case StorageTypeMemory:
+		return NewMemoryStorage(WithSnapshotPath(config.SnapshotPath)), nil
+
 	case StorageTypePostgreSQL:
 		pgConfig := PostgreSQLConfig{
 			Host:     config.Host,
@@ -137,6 +167,7 @@ func NewStorage(config *StorageConfig) (Storage, error) {
 			DBName:   config.DBName,
 			SSLMode:  config.SSLMode,
 			TimeZone: config.TimeZone,
+			Logger:   config.Logger,
 		}
 		return NewPostgreSQLStorage(pgConfig)
 
@@ -150,6 +181,7 @@ func NewStorage(config *StorageConfig) (Storage, error) {
 			Charset:   config.Charset,
 			ParseTime: config.ParseTime,
 			Loc:       config.Loc,
+			Logger:    config.Logger,
 		}
 		return NewMySQLStorage(mysqlConfig)
 
@@ -160,6 +192,7 @@ func NewStorage(config *StorageConfig) (Storage, error) {
 			Collection:     config.Collection,
 			ConnectTimeout: config.ConnectTimeout,
 			QueryTimeout:   config.QueryTimeout,
+			Logger:         config.Logger,
 		}
 		return NewMongoDBStorage(mongoConfig)
 
@@ -179,7 +212,7 @@ func getEnvOrDefault(key, defaultValue string) string {
 // ValidateConfig validates the storage configuration
 func ValidateConfig(config *StorageConfig) error {
 	switch config.Type {
-	case StorageTypeJSON, StorageTypeSQLite:
+	case StorageTypeJSON, StorageTypeSQLite, StorageTypeWAL, StorageTypeBolt:
 		if config.FilePath == "" {
 			return fmt.Errorf("file path is required for %s storage", config.Type)
 		}
@@ -209,6 +242,9 @@ func ValidateConfig(config *StorageConfig) error {
 			return fmt.Errorf("collection name is required for MongoDB storage")
 		}
 
+	case StorageTypeMemory:
+		// Nothing is required; the snapshot path is optional
+
 	default:
 		return fmt.Errorf("unsupported storage type: %s", config.Type)
 	}
@@ -224,6 +260,9 @@ func GetSupportedStorageTypes() []StorageType {
 		StorageTypePostgreSQL,
 		StorageTypeMySQL,
 		StorageTypeMongoDB,
+		StorageTypeWAL,
+		StorageTypeBolt,
+		StorageTypeMemory,
 	}
 }
 
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -14,6 +15,7 @@ func TestLoadConfigFromEnv(t *testing.T) {
 		"DB_PASSWORD", "DB_NAME", "POSTGRES_SSL_MODE", "POSTGRES_TIMEZONE",
 		"MYSQL_CHARSET", "MYSQL_PARSE_TIME", "MYSQL_LOC", "MONGODB_URI",
 		"MONGODB_COLLECTION", "MONGODB_CONNECT_TIMEOUT", "MONGODB_QUERY_TIMEOUT",
+		"STORAGE_LOCK_TIMEOUT", "STORAGE_SNAPSHOT_PATH",
 	}
 	
 	for _, envVar := range envVars {
@@ -200,6 +202,26 @@ func TestLoadConfigFromEnv(t *testing.T) {
 			t.Error("Expected error for invalid timeout, got nil")
 		}
 	})
+
+	t.Run("LockTimeout", func(t *testing.T) {
+		// Clean previous env vars
+		for _, envVar := range envVars {
+			os.Unsetenv(envVar)
+		}
+
+		config, err := LoadConfigFromEnv()
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if config.LockTimeout != DefaultLockTimeout {
+			t.Errorf("Expected default lock timeout %v, got %v", DefaultLockTimeout, config.LockTimeout)
+		}
+
+		os.Setenv("STORAGE_LOCK_TIMEOUT", "invalid")
+		if _, err := LoadConfigFromEnv(); err == nil {
+			t.Error("Expected error for invalid lock timeout, got nil")
+		}
+	})
 }
 
 func TestNewStorage(t *testing.T) {
@@ -219,8 +241,9 @@ func TestNewStorage(t *testing.T) {
 			t.Error("Expected storage instance, got nil")
 		}
 
-		// Clean up test file
+		// Clean up test files
 		os.Remove("test_tasks.json")
+		os.Remove("test_tasks.json.lock")
 	})
 
 	t.Run("SQLiteStorage", func(t *testing.T) {
@@ -243,6 +266,59 @@ func TestNewStorage(t *testing.T) {
 		os.Remove("test_tasks.db")
 	})
 
+	t.Run("WALStorage", func(t *testing.T) {
+		config := &StorageConfig{
+			Type:     StorageTypeWAL,
+			FilePath: filepath.Join(t.TempDir(), "tasks.json"),
+		}
+
+		storage, err := NewStorage(config)
+		if err != nil {
+			t.Fatalf("Failed to create WAL storage: %v", err)
+		}
+		defer storage.Close()
+
+		if _, ok := storage.(*WALStorage); !ok {
+			t.Errorf("Expected a WALStorage, got %T", storage)
+		}
+	})
+
+	t.Run("BoltStorage", func(t *testing.T) {
+		config := &StorageConfig{
+			Type:     StorageTypeBolt,
+			FilePath: filepath.Join(t.TempDir(), "tasks.db"),
+		}
+
+		storage, err := NewStorage(config)
+		if err != nil {
+			t.Fatalf("Failed to create Bolt storage: %v", err)
+		}
+		defer storage.Close()
+
+		if _, ok := storage.(*BoltStorage); !ok {
+			t.Errorf("Expected a BoltStorage, got %T", storage)
+		}
+	})
+
+	t.Run("MemoryStorage", func(t *testing.T) {
+		config := &StorageConfig{
+			Type: StorageTypeMemory,
+		}
+		if err := ValidateConfig(config); err != nil {
+			t.Fatalf("Expected memory storage to need no settings, got %v", err)
+		}
+
+		storage, err := NewStorage(config)
+		if err != nil {
+			t.Fatalf("Failed to create memory storage: %v", err)
+		}
+		defer storage.Close()
+
+		if _, ok := storage.(*MemoryStorage); !ok {
+			t.Errorf("Expected a MemoryStorage, got %T", storage)
+		}
+	})
+
 	t.Run("UnsupportedType", func(t *testing.T) {
 		config := &StorageConfig{
 			Type: StorageType("unsupported"),
@@ -385,6 +461,9 @@ func TestGetSupportedStorageTypes(t *testing.T) {
 		StorageTypePostgreSQL,
 		StorageTypeMySQL,
 		StorageTypeMongoDB,
+		StorageTypeWAL,
+		StorageTypeBolt,
+		StorageTypeMemory,
 	}
 
 	if len(types) != len(expectedTypes) {
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultLockTimeout is how long file-based storages wait for another
+// process to release the storage file when no timeout is configured
+const DefaultLockTimeout = 5 * time.Second
+
+// lockRetryInterval is how often a held lock is retried
+const lockRetryInterval = 10 * time.Millisecond
+
+// ErrLockTimeout is returned when another process holds a storage file's lock
+// for longer than the lock timeout
+var ErrLockTimeout = errors.New("timed out waiting for storage file lock")
+
+// fileLock is an advisory lock shared by every process using a storage
+// file. It locks a separate path+".lock" file, because saves replace the
+// storage file itself. Each acquisition opens its own descriptor, so it also
+// excludes other storage instances in the same process.
+type fileLock struct {
+	path    string
+	timeout time.Duration
+}
+
+func newFileLock(path string, timeout time.Duration) *fileLock {
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	return &fileLock{path: path + ".lock", timeout: timeout}
+}
+
+// acquire takes the lock, shared by readers or exclusive for a writer, and
+// returns the function that releases it
+func (l *fileLock) acquire(exclusive bool) (func(), error) {
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(l.timeout)
+	for {
+		locked, err := tryLockFile(f, exclusive)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", l.path, err)
+		}
+		if locked {
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s is held by another process after %s", ErrLockTimeout, l.path, l.timeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// sameFileState reports whether a and b describe the same, unmodified file.
+// Storages use it to detect writes by other processes since they last read a
+// file.
+func sameFileState(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return os.SameFile(a, b) && a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+// readSidecar decodes the JSON file at path, which file-based storages keep
+// next to their storage file, into v. v is left as it is while the file does
+// not exist. Callers hold the storage's file lock.
+func readSidecar(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeSidecar replaces the JSON file at path with v via a temporary file
+// created with perm. Callers hold the storage's file lock.
+func writeSidecar(path string, v any, perm os.FileMode) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tempFile, path)
+}
@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !windows
+
+package storage
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// tryLockFile fails: file locking is not implemented on this platform, and
+// storage files shared without it could be corrupted by concurrent writers
+func tryLockFile(f *os.File, exclusive bool) (bool, error) {
+	return false, fmt.Errorf("file locking is not supported on %s", runtime.GOOS)
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// openLockTestStorage opens the file-based storage named by storageType
+func openLockTestStorage(storageType, path string, timeout time.Duration) (Storage, error) {
+	if storageType == string(StorageTypeWAL) {
+		return NewWALStorage(WALConfig{Path: path, CompactEvery: 10, LockTimeout: timeout})
+	}
+	return NewJSONStorage(path, WithLockTimeout(timeout))
+}
+
+// TestHelperProcessWriter is run as a separate process by
+// TestFileLock_MultiProcess; it creates tasks through its own storage
+func TestHelperProcessWriter(t *testing.T) {
+	path := os.Getenv("GOTASK_LOCK_TEST_PATH")
+	if path == "" {
+		t.Skip("only run as a helper process")
+	}
+	writer := os.Getenv("GOTASK_LOCK_TEST_WRITER")
+	count, _ := strconv.Atoi(os.Getenv("GOTASK_LOCK_TEST_COUNT"))
+
+	store, err := openLockTestStorage(os.Getenv("GOTASK_LOCK_TEST_TYPE"), path, 30*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	defer store.Close()
+
+	helper := NewTestHelper(t)
+	for i := range count {
+		task := helper.CreateSampleTask(fmt.Sprintf("%s-%d", writer, i), "Written by "+writer)
+		if err := store.Create(task); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+}
+
+func TestFileLock_MultiProcess(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("file locking is only supported on linux and darwin")
+	}
+	if testing.Short() {
+		t.Skip("skipping multi-process test in short mode")
+	}
+
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	const writers, perWriter = 4, 25
+	for _, storageType := range []StorageType{StorageTypeJSON, StorageTypeWAL} {
+		t.Run(string(storageType), func(t *testing.T) {
+			path := helper.TempFilePath(fmt.Sprintf("multi_process_%s.json", storageType))
+
+			var wg sync.WaitGroup
+			for w := range writers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcessWriter$")
+					cmd.Env = append(os.Environ(),
+						"GOTASK_LOCK_TEST_PATH="+path,
+						"GOTASK_LOCK_TEST_TYPE="+string(storageType),
+						fmt.Sprintf("GOTASK_LOCK_TEST_WRITER=w%d", w),
+						fmt.Sprintf("GOTASK_LOCK_TEST_COUNT=%d", perWriter),
+					)
+					if output, err := cmd.CombinedOutput(); err != nil {
+						t.Errorf("Writer %d failed: %v\n%s", w, err, output)
+					}
+				}()
+			}
+			wg.Wait()
+
+			store, err := openLockTestStorage(string(storageType), path, 0)
+			helper.AssertNoError(err, "opening storage")
+			defer store.Close()
+			tasks, err := store.GetAll()
+			helper.AssertNoError(err, "listing tasks")
+			if len(tasks) != writers*perWriter {
+				t.Errorf("Expected %d tasks, got %d: writes were lost", writers*perWriter, len(tasks))
+			}
+		})
+	}
+}
+
+func TestFileLock_Timeout(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("file locking is only supported on linux and darwin")
+	}
+
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	for _, storageType := range []StorageType{StorageTypeJSON, StorageTypeWAL} {
+		t.Run(string(storageType), func(t *testing.T) {
+			path := helper.TempFilePath(fmt.Sprintf("lock_timeout_%s.json", storageType))
+			store, err := openLockTestStorage(string(storageType), path, 50*time.Millisecond)
+			helper.AssertNoError(err, "opening storage")
+			defer store.Close()
+
+			unlock, err := newFileLock(path, 0).acquire(true)
+			helper.AssertNoError(err, "holding the lock")
+			start := time.Now()
+			err = store.Create(helper.CreateSampleTask("blocked", "Blocked"))
+			if !errors.Is(err, ErrLockTimeout) {
+				t.Errorf("Expected ErrLockTimeout, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Expected to give up after the lock timeout, waited %s", elapsed)
+			}
+			if _, err := store.GetAll(); !errors.Is(err, ErrLockTimeout) {
+				t.Errorf("Expected readers to wait for a writer, got %v", err)
+			}
+
+			unlock()
+			helper.AssertNoError(store.Create(helper.CreateSampleTask("unblocked", "Unblocked")), "creating task after release")
+		})
+	}
+}
+
+func TestFileLock_ExternalModification(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	for _, storageType := range []StorageType{StorageTypeJSON, StorageTypeWAL} {
+		t.Run(string(storageType), func(t *testing.T) {
+			path := helper.TempFilePath(fmt.Sprintf("external_%s.json", storageType))
+			first, err := openLockTestStorage(string(storageType), path, 0)
+			helper.AssertNoError(err, "opening first storage")
+			defer first.Close()
+			second, err := openLockTestStorage(string(storageType), path, 0)
+			helper.AssertNoError(err, "opening second storage")
+			defer second.Close()
+
+			task := helper.CreateSampleTask("e1", "Created by first")
+			helper.AssertNoError(first.Create(task), "creating task")
+			if _, err := first.GetAll(); err != nil {
+				t.Fatalf("Failed to list tasks: %v", err)
+			}
+
+			// The second storage sees the first's write and builds on it
+			task.Title = "Updated by second"
+			helper.AssertNoError(second.Update(task), "updating task through the second storage")
+			for i := range 12 {
+				helper.AssertNoError(second.Create(helper.CreateSampleTask(fmt.Sprintf("e%d", i+2), "More")), "creating task")
+			}
+
+			// The first sees the second's writes, including across a WAL compaction
+			got, err := first.GetByID("e1")
+			helper.AssertNoError(err, "getting task")
+			if got.Title != "Updated by second" {
+				t.Errorf("Expected the other storage's update, got %q", got.Title)
+			}
+			if tasks, _ := first.GetAll(); len(tasks) != 13 {
+				t.Errorf("Expected 13 tasks, got %d", len(tasks))
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+
+	"gorm.io/gorm"
+)
+
+// Finder is implemented by storages that can select tasks matching a query
+// filter themselves, e.g. with a WHERE clause or an index
+type Finder interface {
+	// Find returns the tasks matching filter, in the order of GetAll
+	Find(filter *query.Filter) ([]*models.Task, error)
+}
+
+// Find returns the tasks of store matching filter, in the order of GetAll.
+// Finders select the tasks natively; other storages are filtered in memory.
+func Find(store Storage, filter *query.Filter) ([]*models.Task, error) {
+	if finder, ok := store.(Finder); ok {
+		return finder.Find(filter)
+	}
+	tasks, err := store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return matchTasks(tasks, filter), nil
+}
+
+// matchTasks returns the tasks matching filter, keeping their order
+func matchTasks(tasks []*models.Task, filter *query.Filter) []*models.Task {
+	matched := make([]*models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if filter.Match(task) {
+			matched = append(matched, task)
+		}
+	}
+	return matched
+}
+
+// sqlTimeColumns names the columns of time fields in the SQL backends
+var sqlTimeColumns = map[query.Field]string{
+	query.FieldDue:       "due_date",
+	query.FieldCreated:   "created_at",
+	query.FieldCompleted: "completed_at",
+}
+
+// sqlWhere translates conditions into a WHERE clause for the tasks table,
+// with ? placeholders for args. It returns an empty clause for no conditions.
+func sqlWhere(conditions []query.Condition) (string, []any) {
+	var clauses []string
+	var args []any
+	for _, cond := range conditions {
+		var clause string
+		switch cond.Field {
+		case query.FieldTitle:
+			// ! escapes LIKE wildcards; backslash would need escaping itself in MySQL
+			clause = "LOWER(title) LIKE ? ESCAPE '!'"
+			args = append(args, "%"+likeEscaper.Replace(cond.Text)+"%")
+		case query.FieldID:
+			clause = "id = ?"
+			args = append(args, cond.Text)
+		case query.FieldStatus:
+			clause = "done = ?"
+			args = append(args, cond.Done)
+		default:
+			column := sqlTimeColumns[cond.Field]
+			if cond.Unset {
+				clause = column + " IS NULL"
+				break
+			}
+			// Checking for NULL keeps negated conditions true for tasks without the time
+			parts := []string{column + " IS NOT NULL"}
+			if !cond.Since.IsZero() {
+				parts = append(parts, column+" >= ?")
+				args = append(args, cond.Since)
+			}
+			if !cond.Until.IsZero() {
+				parts = append(parts, column+" < ?")
+				args = append(args, cond.Until)
+			}
+			clause = strings.Join(parts, " AND ")
+		}
+		if cond.Not {
+			clause = "NOT (" + clause + ")"
+		} else {
+			clause = "(" + clause + ")"
+		}
+		clauses = append(clauses, clause)
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// findGormTasks implements Finder for PostgreSQL and MySQL
+func findGormTasks(db *gorm.DB, filter *query.Filter) ([]*models.Task, error) {
+	db = db.Order("created_at DESC")
+	if where, args := sqlWhere(filter.Conditions); where != "" {
+		db = db.Where(where, args...)
+	}
+	var tasks []*models.Task
+	if err := db.Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to find tasks: %w", err)
+	}
+	return tasks, nil
+}
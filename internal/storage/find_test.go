@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"testing"
+
+	"GoTask_Management/internal/metrics"
+)
+
+func TestFind(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	t.Run("memory", func(t *testing.T) {
+		testFindCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		store, err := NewJSONStorage(helper.TempFilePath("find.json"))
+		helper.AssertNoError(err, "creating JSON storage")
+		testFindCompliance(t, store)
+	})
+
+	t.Run("wal", func(t *testing.T) {
+		store, err := NewWALStorage(WALConfig{Path: helper.TempFilePath("find_wal.json")})
+		helper.AssertNoError(err, "creating WAL storage")
+		defer store.Close()
+		testFindCompliance(t, store)
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStorage(BoltConfig{Path: helper.TempFilePath("find.db")})
+		helper.AssertNoError(err, "creating Bolt storage")
+		defer store.Close()
+		testFindCompliance(t, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("find.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+		testFindCompliance(t, store)
+	})
+
+	t.Run("decorators", func(t *testing.T) {
+		var store Storage = NewMemoryStorage()
+		store = NewCachedStorage(store, CacheConfig{})
+		store = NewInstrumentedStorage(store, metrics.NewRegistry())
+		store = NewTracingStorage(store, nil)
+		testFindCompliance(t, store)
+	})
+
+	t.Run("decorated finder", func(t *testing.T) {
+		sqlite, err := NewSQLiteStorage(helper.TempFilePath("find_decorated.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer sqlite.Close()
+		testFindCompliance(t, NewTracingStorage(NewCachedStorage(sqlite, CacheConfig{}), nil))
+	})
+}
@@ -0,0 +1,307 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRecord is the stored outcome of a request sent with an
+// idempotency key, replayed when the request is retried
+type IdempotencyRecord struct {
+	Key string `json:"key" gorm:"primaryKey;size:255" bson:"_id"`
+	// RequestHash identifies the request the key was first used with
+	RequestHash string `json:"request_hash" gorm:"size:64;not null" bson:"request_hash"`
+	// StatusCode is 0 while the request is still being processed
+	StatusCode  int       `json:"status_code" gorm:"not null;default:0" bson:"status_code"`
+	ContentType string    `json:"content_type,omitempty" gorm:"size:255" bson:"content_type,omitempty"`
+	Body        []byte    `json:"body,omitempty" bson:"body,omitempty"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null" bson:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index" bson:"expires_at"`
+}
+
+// TableName keeps the SQL table name independent of the type name
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_records"
+}
+
+// Expired reports whether the record no longer holds its key at now
+func (r *IdempotencyRecord) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+var (
+	// ErrIdempotencyKeyExists is returned by CreateIdempotencyRecord when an
+	// unexpired record already holds the key
+	ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+	// ErrIdempotencyRecordNotFound is returned for keys with no unexpired record
+	ErrIdempotencyRecordNotFound = errors.New("idempotency record not found")
+)
+
+// IdempotencyStore is implemented by storages that can keep idempotency
+// records next to the tasks, so that retried requests are recognised by
+// every server sharing the database
+type IdempotencyStore interface {
+	// CreateIdempotencyRecord stores record, replacing an expired record with
+	// the same key, or returns ErrIdempotencyKeyExists. Expiry is judged at
+	// record.CreatedAt.
+	CreateIdempotencyRecord(record *IdempotencyRecord) error
+	// GetIdempotencyRecord returns the unexpired record for key
+	GetIdempotencyRecord(key string) (*IdempotencyRecord, error)
+	// SaveIdempotencyRecord inserts or replaces the record with record.Key
+	SaveIdempotencyRecord(record *IdempotencyRecord) error
+	// DeleteIdempotencyRecord removes the record for key, if any
+	DeleteIdempotencyRecord(key string) error
+	// DeleteExpiredIdempotencyRecords removes records expired at now and
+	// returns how many were removed
+	DeleteExpiredIdempotencyRecords(now time.Time) (int, error)
+}
+
+// AsIdempotencyStore returns the IdempotencyStore behind store, looking
+// through decorators that expose the storage they wrap
+func AsIdempotencyStore(store Storage) (IdempotencyStore, bool) {
+	for store != nil {
+		if s, ok := store.(IdempotencyStore); ok {
+			return s, true
+		}
+		unwrapper, ok := store.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		store = unwrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// MemoryIdempotencyStore keeps idempotency records in memory for
+// MemoryStorage; records are lost on restart and not shared between
+// processes.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore creates an empty MemoryIdempotencyStore
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]*IdempotencyRecord)}
+}
+
+// CreateIdempotencyRecord implements IdempotencyStore
+func (s *MemoryIdempotencyStore) CreateIdempotencyRecord(record *IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[record.Key]; ok && !existing.Expired(record.CreatedAt) {
+		return ErrIdempotencyKeyExists
+	}
+	s.records[record.Key] = copyIdempotencyRecord(record)
+	return nil
+}
+
+// GetIdempotencyRecord implements IdempotencyStore
+func (s *MemoryIdempotencyStore) GetIdempotencyRecord(key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok || record.Expired(time.Now()) {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	return copyIdempotencyRecord(record), nil
+}
+
+// SaveIdempotencyRecord implements IdempotencyStore
+func (s *MemoryIdempotencyStore) SaveIdempotencyRecord(record *IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.Key] = copyIdempotencyRecord(record)
+	return nil
+}
+
+// DeleteIdempotencyRecord implements IdempotencyStore
+func (s *MemoryIdempotencyStore) DeleteIdempotencyRecord(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// DeleteExpiredIdempotencyRecords implements IdempotencyStore
+func (s *MemoryIdempotencyStore) DeleteExpiredIdempotencyRecords(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for key, record := range s.records {
+		if record.Expired(now) {
+			delete(s.records, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// fileIdempotencyStore keeps idempotency records for the JSON and WAL
+// storages in a file next to the storage file, path+".idempotency". Every
+// operation reads and rewrites it under the storage's file lock, so processes
+// sharing the storage file share the records too.
+type fileIdempotencyStore struct {
+	recordsPath string
+	recordsLock *fileLock
+}
+
+func newFileIdempotencyStore(path string, timeout time.Duration) *fileIdempotencyStore {
+	return &fileIdempotencyStore{recordsPath: path + ".idempotency", recordsLock: newFileLock(path, timeout)}
+}
+
+// CreateIdempotencyRecord implements IdempotencyStore
+func (s *fileIdempotencyStore) CreateIdempotencyRecord(record *IdempotencyRecord) error {
+	return s.updateRecords(func(records map[string]*IdempotencyRecord) error {
+		if existing, ok := records[record.Key]; ok && !existing.Expired(record.CreatedAt) {
+			return ErrIdempotencyKeyExists
+		}
+		records[record.Key] = record
+		return nil
+	})
+}
+
+// GetIdempotencyRecord implements IdempotencyStore
+func (s *fileIdempotencyStore) GetIdempotencyRecord(key string) (*IdempotencyRecord, error) {
+	unlock, err := s.recordsLock.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	records, err := s.loadRecords()
+	if err != nil {
+		return nil, err
+	}
+	record, ok := records[key]
+	if !ok || record.Expired(time.Now()) {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	return record, nil
+}
+
+// SaveIdempotencyRecord implements IdempotencyStore
+func (s *fileIdempotencyStore) SaveIdempotencyRecord(record *IdempotencyRecord) error {
+	return s.updateRecords(func(records map[string]*IdempotencyRecord) error {
+		records[record.Key] = record
+		return nil
+	})
+}
+
+// DeleteIdempotencyRecord implements IdempotencyStore
+func (s *fileIdempotencyStore) DeleteIdempotencyRecord(key string) error {
+	return s.updateRecords(func(records map[string]*IdempotencyRecord) error {
+		delete(records, key)
+		return nil
+	})
+}
+
+// DeleteExpiredIdempotencyRecords implements IdempotencyStore
+func (s *fileIdempotencyStore) DeleteExpiredIdempotencyRecords(now time.Time) (int, error) {
+	removed := 0
+	err := s.updateRecords(func(records map[string]*IdempotencyRecord) error {
+		for key, record := range records {
+			if record.Expired(now) {
+				delete(records, key)
+				removed++
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// updateRecords runs fn on the records under the exclusive file lock and saves
+// them if it succeeds
+func (s *fileIdempotencyStore) updateRecords(fn func(records map[string]*IdempotencyRecord) error) error {
+	unlock, err := s.recordsLock.acquire(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := s.loadRecords()
+	if err != nil {
+		return err
+	}
+	if err := fn(records); err != nil {
+		return err
+	}
+	if err := writeSidecar(s.recordsPath, records, 0644); err != nil {
+		return fmt.Errorf("failed to save idempotency records: %w", err)
+	}
+	return nil
+}
+
+// loadRecords reads the records; callers hold the file lock
+func (s *fileIdempotencyStore) loadRecords() (map[string]*IdempotencyRecord, error) {
+	records := make(map[string]*IdempotencyRecord)
+	if err := readSidecar(s.recordsPath, &records); err != nil {
+		return nil, fmt.Errorf("failed to read idempotency records: %w", err)
+	}
+	return records, nil
+}
+
+func copyIdempotencyRecord(record *IdempotencyRecord) *IdempotencyRecord {
+	copied := *record
+	copied.Body = append([]byte(nil), record.Body...)
+	return &copied
+}
+
+// The gorm helpers below implement IdempotencyStore for PostgreSQL and
+// MySQL. Conditions on the key go through the primary key of the model
+// value rather than SQL text, because key is a reserved word in MySQL.
+
+func createGormIdempotencyRecord(db *gorm.DB, record *IdempotencyRecord) error {
+	err := db.Where("expires_at <= ?", record.CreatedAt).Delete(&IdempotencyRecord{Key: record.Key}).Error
+	if err != nil {
+		return fmt.Errorf("failed to replace expired idempotency record: %w", err)
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create idempotency record: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+func getGormIdempotencyRecord(db *gorm.DB, key string) (*IdempotencyRecord, error) {
+	record := &IdempotencyRecord{Key: key}
+	err := db.Where("expires_at > ?", time.Now()).Take(record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+	return record, nil
+}
+
+func saveGormIdempotencyRecord(db *gorm.DB, record *IdempotencyRecord) error {
+	if err := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(record).Error; err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+	return nil
+}
+
+func deleteGormIdempotencyRecord(db *gorm.DB, key string) error {
+	if err := db.Delete(&IdempotencyRecord{Key: key}).Error; err != nil {
+		return fmt.Errorf("failed to delete idempotency record: %w", err)
+	}
+	return nil
+}
+
+func deleteExpiredGormIdempotencyRecords(db *gorm.DB, now time.Time) (int, error) {
+	result := db.Where("expires_at <= ?", now).Delete(&IdempotencyRecord{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency records: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
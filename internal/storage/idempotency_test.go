@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/metrics"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	t.Run("memory", func(t *testing.T) {
+		testIdempotencyCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		store, err := NewJSONStorage(helper.TempFilePath("idempotency.json"))
+		helper.AssertNoError(err, "creating JSON storage")
+		testIdempotencyCompliance(t, store)
+	})
+
+	t.Run("wal", func(t *testing.T) {
+		store, err := NewWALStorage(WALConfig{Path: helper.TempFilePath("idempotency_wal.json")})
+		helper.AssertNoError(err, "creating WAL storage")
+		defer store.Close()
+		testIdempotencyCompliance(t, store)
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStorage(BoltConfig{Path: helper.TempFilePath("idempotency.db")})
+		helper.AssertNoError(err, "creating Bolt storage")
+		defer store.Close()
+		testIdempotencyCompliance(t, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("idempotency.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+		testIdempotencyCompliance(t, store)
+	})
+
+	t.Run("found through decorators", func(t *testing.T) {
+		memory := NewMemoryStorage()
+		var store Storage = NewCachedStorage(memory, CacheConfig{})
+		store = NewInstrumentedStorage(store, metrics.NewRegistry())
+		store = NewTracingStorage(store, nil)
+
+		found, ok := AsIdempotencyStore(store)
+		if !ok || found != IdempotencyStore(memory) {
+			t.Fatalf("Expected the wrapped MemoryStorage, got %T", found)
+		}
+	})
+
+	t.Run("shared by file storages on the same file", func(t *testing.T) {
+		path := helper.TempFilePath("idempotency_shared.json")
+		first, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+		second, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+
+		now := time.Now()
+		record := &IdempotencyRecord{Key: "shared", RequestHash: "hash", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+		helper.AssertNoError(first.CreateIdempotencyRecord(record), "creating record")
+		if err := second.CreateIdempotencyRecord(record); !errors.Is(err, ErrIdempotencyKeyExists) {
+			t.Errorf("Expected ErrIdempotencyKeyExists from the other storage, got %v", err)
+		}
+		if _, err := second.GetIdempotencyRecord("shared"); err != nil {
+			t.Errorf("Expected the other storage to read the record, got %v", err)
+		}
+	})
+}
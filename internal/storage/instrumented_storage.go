@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"GoTask_Management/internal/metrics"
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+)
+
+// InstrumentedStorage decorates a Storage with latency and error metrics
+type InstrumentedStorage struct {
+	next     Storage
+	duration *metrics.HistogramVec
+	errors   *metrics.CounterVec
+}
+
+// NewInstrumentedStorage wraps next so that every operation is recorded in registry
+func NewInstrumentedStorage(next Storage, registry *metrics.Registry) *InstrumentedStorage {
+	return &InstrumentedStorage{
+		next: next,
+		duration: registry.NewHistogramVec(
+			"gotask_storage_operation_duration_seconds",
+			"Storage operation latency in seconds by operation.",
+			metrics.DefaultBuckets,
+			"operation",
+		),
+		errors: registry.NewCounterVec(
+			"gotask_storage_operation_errors_total",
+			"Total number of failed storage operations by operation.",
+			"operation",
+		),
+	}
+}
+
+// observe records the outcome of an operation that started at start
+func (is *InstrumentedStorage) observe(operation string, start time.Time, err error) {
+	is.duration.Observe(time.Since(start).Seconds(), operation)
+	if err != nil {
+		is.errors.Inc(operation)
+	}
+}
+
+// Create implements Storage interface
+func (is *InstrumentedStorage) Create(task *models.Task) error {
+	start := time.Now()
+	err := is.next.Create(task)
+	is.observe("create", start, err)
+	return err
+}
+
+// GetAll implements Storage interface
+func (is *InstrumentedStorage) GetAll() ([]*models.Task, error) {
+	start := time.Now()
+	tasks, err := is.next.GetAll()
+	is.observe("get_all", start, err)
+	return tasks, err
+}
+
+// GetByID implements Storage interface
+func (is *InstrumentedStorage) GetByID(id string) (*models.Task, error) {
+	start := time.Now()
+	task, err := is.next.GetByID(id)
+	is.observe("get_by_id", start, err)
+	return task, err
+}
+
+// Update implements Storage interface
+func (is *InstrumentedStorage) Update(task *models.Task) error {
+	start := time.Now()
+	err := is.next.Update(task)
+	is.observe("update", start, err)
+	return err
+}
+
+// Delete implements Storage interface
+func (is *InstrumentedStorage) Delete(id string) error {
+	start := time.Now()
+	err := is.next.Delete(id)
+	is.observe("delete", start, err)
+	return err
+}
+
+// UpdateIfVersion implements Versioner, natively when the wrapped storage does
+func (is *InstrumentedStorage) UpdateIfVersion(task *models.Task, expected int64) error {
+	start := time.Now()
+	err := UpdateIfVersion(context.Background(), is.next, task, expected)
+	is.observe("update_if_version", start, err)
+	return err
+}
+
+// DeleteIfVersion implements Versioner, natively when the wrapped storage does
+func (is *InstrumentedStorage) DeleteIfVersion(id string, expected int64) error {
+	start := time.Now()
+	err := DeleteIfVersion(context.Background(), is.next, id, expected)
+	is.observe("delete_if_version", start, err)
+	return err
+}
+
+// GetFresh implements FreshReader, reading past a cache in the wrapped storage
+func (is *InstrumentedStorage) GetFresh(id string) (*models.Task, error) {
+	start := time.Now()
+	task, err := GetFresh(is.next, id)
+	is.observe("get_fresh", start, err)
+	return task, err
+}
+
+// Search implements Searcher, natively when the wrapped storage does
+func (is *InstrumentedStorage) Search(query string, limit int) ([]SearchResult, error) {
+	start := time.Now()
+	results, err := Search(is.next, query, limit)
+	is.observe("search", start, err)
+	return results, err
+}
+
+// Find implements Finder, natively when the wrapped storage does
+func (is *InstrumentedStorage) Find(filter *query.Filter) ([]*models.Task, error) {
+	start := time.Now()
+	tasks, err := Find(is.next, filter)
+	is.observe("find", start, err)
+	return tasks, err
+}
+
+// CreateMany implements Batcher, natively when the wrapped storage does
+func (is *InstrumentedStorage) CreateMany(tasks []*models.Task) error {
+	start := time.Now()
+	err := CreateMany(context.Background(), is.next, tasks)
+	is.observe("create_many", start, err)
+	return err
+}
+
+// UpdateMany implements Batcher, natively when the wrapped storage does
+func (is *InstrumentedStorage) UpdateMany(tasks []*models.Task) error {
+	start := time.Now()
+	err := UpdateMany(context.Background(), is.next, tasks)
+	is.observe("update_many", start, err)
+	return err
+}
+
+// DeleteMany implements Batcher, natively when the wrapped storage does
+func (is *InstrumentedStorage) DeleteMany(ids []string) error {
+	start := time.Now()
+	err := DeleteMany(context.Background(), is.next, ids)
+	is.observe("delete_many", start, err)
+	return err
+}
+
+// WithTx implements Transactor when the wrapped storage does, recording the
+// transaction as a whole and each operation inside it
+func (is *InstrumentedStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	start := time.Now()
+	err := WithTx(ctx, is.next, func(tx Storage) error {
+		bound := *is
+		bound.next = tx
+		return fn(&bound)
+	})
+	is.observe("transaction", start, err)
+	return err
+}
+
+// Close implements Storage interface
+func (is *InstrumentedStorage) Close() error {
+	return is.next.Close()
+}
+
+// HealthCheck forwards to the wrapped storage when it supports health checks
+func (is *InstrumentedStorage) HealthCheck() error {
+	start := time.Now()
+	var err error
+	if healthChecker, ok := is.next.(interface{ HealthCheck() error }); ok {
+		err = healthChecker.HealthCheck()
+	}
+	is.observe("health_check", start, err)
+	return err
+}
+
+// WithContext implements ContextBinder by binding ctx to the wrapped storage
+func (is *InstrumentedStorage) WithContext(ctx context.Context) Storage {
+	bound := *is
+	bound.next = WithContext(ctx, is.next)
+	return &bound
+}
+
+// Unwrap returns the decorated storage
+func (is *InstrumentedStorage) Unwrap() Storage {
+	return is.next
+}
+
+// Verify that InstrumentedStorage implements Storage interface
+var _ Storage = (*InstrumentedStorage)(nil)
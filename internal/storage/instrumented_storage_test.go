@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"GoTask_Management/internal/metrics"
+)
+
+func TestInstrumentedStorage(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	t.Run("passes storage compliance", func(t *testing.T) {
+		inner, err := NewJSONStorage(helper.TempFilePath("instrumented_compliance.json"))
+		helper.AssertNoError(err, "creating JSON storage")
+
+		testStorageCompliance(t, NewInstrumentedStorage(inner, metrics.NewRegistry()))
+	})
+
+	t.Run("records latency and errors per operation", func(t *testing.T) {
+		inner, err := NewJSONStorage(helper.TempFilePath("instrumented_metrics.json"))
+		helper.AssertNoError(err, "creating JSON storage")
+
+		registry := metrics.NewRegistry()
+		store := NewInstrumentedStorage(inner, registry)
+
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("m1", "Metrics")), "creating task")
+		_, err = store.GetByID("m1")
+		helper.AssertNoError(err, "getting task")
+		_, err = store.GetByID("missing")
+		helper.AssertError(err, true, "getting missing task")
+		helper.AssertError(store.Delete("missing"), true, "deleting missing task")
+
+		if got := store.duration.Count("get_by_id"); got != 2 {
+			t.Errorf("Expected 2 get_by_id observations, got %d", got)
+		}
+		if got := store.errors.Value("get_by_id"); got != 1 {
+			t.Errorf("Expected 1 get_by_id error, got %v", got)
+		}
+		if got := store.errors.Value("create"); got != 0 {
+			t.Errorf("Expected no create errors, got %v", got)
+		}
+
+		var out strings.Builder
+		registry.WriteText(&out)
+		if !strings.Contains(out.String(), `gotask_storage_operation_errors_total{operation="delete"} 1`) {
+			t.Errorf("Expected delete error in exposition, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("forwards health checks", func(t *testing.T) {
+		inner, err := NewSQLiteStorage(helper.TempFilePath("instrumented_health.db"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		store := NewInstrumentedStorage(inner, metrics.NewRegistry())
+		defer store.Close()
+
+		helper.AssertNoError(store.HealthCheck(), "health check")
+	})
+}
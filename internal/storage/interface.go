@@ -1,6 +1,11 @@
 package storage
 
-import "GoTask_Management/internal/models"
+import (
+	"context"
+	"errors"
+
+	"GoTask_Management/internal/models"
+)
 
 type Storage interface {
 	Create(task *models.Task) error
@@ -10,3 +15,38 @@ type Storage interface {
 	Delete(id string) error
 	Close() error
 }
+
+// ContextBinder is implemented by storages, typically decorators, that can
+// attach a request context to their operations for tracing and logging
+type ContextBinder interface {
+	WithContext(ctx context.Context) Storage
+}
+
+// WithContext binds ctx to store when it supports it and otherwise returns store unchanged
+func WithContext(ctx context.Context, store Storage) Storage {
+	if binder, ok := store.(ContextBinder); ok {
+		return binder.WithContext(ctx)
+	}
+	return store
+}
+
+// Transactor is implemented by storages that can group writes into a unit of
+// work. WithTx calls fn with a Storage for the transaction: when fn returns
+// nil all of its writes take effect together, and when it returns an error
+// none do. fn must use only tx until it returns, must not close it, and may be
+// called again if the backend retries the transaction.
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(tx Storage) error) error
+}
+
+// ErrTxUnsupported is returned by WithTx for storages that are not Transactors,
+// or that cannot run transactions where they are deployed
+var ErrTxUnsupported = errors.New("storage does not support transactions")
+
+// WithTx runs fn in a transaction on store, see Transactor
+func WithTx(ctx context.Context, store Storage, fn func(tx Storage) error) error {
+	if transactor, ok := store.(Transactor); ok {
+		return transactor.WithTx(ctx, fn)
+	}
+	return ErrTxUnsupported
+}
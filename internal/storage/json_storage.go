@@ -1,23 +1,75 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"GoTask_Management/internal/models"
+
+	"github.com/fsnotify/fsnotify"
 )
 
+// jsonWatchSettle is how long Watch waits for writes to the file to settle
+// before reloading it
+const jsonWatchSettle = 50 * time.Millisecond
+
+// JSONStorage keeps tasks in a JSON file, and idempotency records, saved
+// views, webhooks and sent notifications in files next to it
 type JSONStorage struct {
-	filepath string
-	mu       sync.RWMutex
+	*fileIdempotencyStore
+	*fileViewStore
+	*fileWebhookStore
+	*fileNotificationStore
+
+	filepath    string
+	lockTimeout time.Duration
+	// mu orders operations within this process; the file lock orders them
+	// across processes sharing the file, such as the server and the CLI
+	mu sync.RWMutex
+
+	// cached holds the tasks last read or written, while the file still has
+	// the identity, modification time and size in cachedInfo
+	cacheMu    sync.Mutex
+	cached     []*models.Task
+	cachedInfo os.FileInfo
+	// index is the search index over cached, built by the first Search
+	// after the cache changes
+	index *searchIndex
 }
 
-func NewJSONStorage(filepath string) (*JSONStorage, error) {
+// JSONOption configures optional JSONStorage behaviour
+type JSONOption func(*JSONStorage)
+
+// WithLockTimeout sets how long operations wait for another process to
+// release the file before failing with ErrLockTimeout
+func WithLockTimeout(timeout time.Duration) JSONOption {
+	return func(js *JSONStorage) {
+		js.lockTimeout = timeout
+	}
+}
+
+func NewJSONStorage(filepath string, opts ...JSONOption) (*JSONStorage, error) {
 	js := &JSONStorage{
 		filepath: filepath,
 	}
+	for _, opt := range opts {
+		opt(js)
+	}
+	js.fileIdempotencyStore = newFileIdempotencyStore(filepath, js.lockTimeout)
+	js.fileViewStore = newFileViewStore(filepath, js.lockTimeout)
+	js.fileWebhookStore = newFileWebhookStore(filepath, js.lockTimeout)
+	js.fileNotificationStore = newFileNotificationStore(filepath, js.lockTimeout)
+	unlock, err := js.lockFile(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	// Create file if it doesn't exist
 	if _, err := os.Stat(filepath); os.IsNotExist(err) {
@@ -32,6 +84,11 @@ func NewJSONStorage(filepath string) (*JSONStorage, error) {
 func (js *JSONStorage) Create(task *models.Task) error {
 	js.mu.Lock()
 	defer js.mu.Unlock()
+	unlock, err := js.lockFile(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	tasks, err := js.load()
 	if err != nil {
@@ -45,6 +102,11 @@ func (js *JSONStorage) Create(task *models.Task) error {
 func (js *JSONStorage) GetAll() ([]*models.Task, error) {
 	js.mu.RLock()
 	defer js.mu.RUnlock()
+	unlock, err := js.lockFile(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	return js.load()
 }
@@ -52,6 +114,11 @@ func (js *JSONStorage) GetAll() ([]*models.Task, error) {
 func (js *JSONStorage) GetByID(id string) (*models.Task, error) {
 	js.mu.RLock()
 	defer js.mu.RUnlock()
+	unlock, err := js.lockFile(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	tasks, err := js.load()
 	if err != nil {
@@ -70,6 +137,11 @@ func (js *JSONStorage) GetByID(id string) (*models.Task, error) {
 func (js *JSONStorage) Update(task *models.Task) error {
 	js.mu.Lock()
 	defer js.mu.Unlock()
+	unlock, err := js.lockFile(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	tasks, err := js.load()
 	if err != nil {
@@ -89,6 +161,11 @@ func (js *JSONStorage) Update(task *models.Task) error {
 func (js *JSONStorage) Delete(id string) error {
 	js.mu.Lock()
 	defer js.mu.Unlock()
+	unlock, err := js.lockFile(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	tasks, err := js.load()
 	if err != nil {
@@ -112,11 +189,140 @@ func (js *JSONStorage) Delete(id string) error {
 	return js.save(filtered)
 }
 
+// WithTx implements Transactor as a copy-on-write batch: fn works on the
+// tasks in memory, and they are saved in one write when it succeeds. The file
+// stays locked until then.
+func (js *JSONStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	unlock, err := js.lockFile(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tasks, err := js.load()
+	if err != nil {
+		return err
+	}
+	batch := newMemoryBatch(tasks)
+	if err := fn(batch); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return js.save(batch.tasks)
+}
+
+// Search implements Searcher with an in-process inverted index, kept until
+// the file changes
+func (js *JSONStorage) Search(query string, limit int) ([]SearchResult, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+	unlock, err := js.lockFile(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Refreshes the cache if the file changed
+	if _, err := js.load(); err != nil {
+		return nil, err
+	}
+
+	js.cacheMu.Lock()
+	if js.index == nil {
+		js.index = newSearchIndex(js.cached)
+	}
+	index := js.index
+	js.cacheMu.Unlock()
+	return index.search(query, limit), nil
+}
+
 func (js *JSONStorage) Close() error {
 	return nil
 }
 
+// Watch reports changes to the file, whether written by this storage or by
+// another process, by diffing the file against its previous contents
+func (js *JSONStorage) Watch(ctx context.Context) (<-chan Change, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// Watch the directory, since saves replace the file rather than write to it
+	if err := watcher.Add(filepath.Dir(js.filepath)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	tasks, err := js.GetAll()
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	snapshot := indexTasks(tasks)
+
+	changes := make(chan Change)
+	go func() {
+		defer close(changes)
+		defer watcher.Close()
+
+		settle := time.NewTimer(0)
+		<-settle.C
+		name := filepath.Clean(js.filepath)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == name && event.Op&(fsnotify.Create|fsnotify.Write) != 0 {
+					settle.Reset(jsonWatchSettle)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("error watching task file", "path", js.filepath, "error", err)
+			case <-settle.C:
+				tasks, err := js.GetAll()
+				if err != nil {
+					// Likely a partial write by another tool; the next write retriggers
+					slog.Warn("failed to reload task file", "path", js.filepath, "error", err)
+					continue
+				}
+				current := indexTasks(tasks)
+				for _, change := range diffTasks(snapshot, current) {
+					if !sendChange(ctx, changes, change) {
+						return
+					}
+				}
+				snapshot = current
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// lockFile takes the lock shared with other processes using the file
+func (js *JSONStorage) lockFile(exclusive bool) (func(), error) {
+	return newFileLock(js.filepath, js.lockTimeout).acquire(exclusive)
+}
+
+// load reads the tasks, reusing the last parsed contents unless the file was
+// replaced or modified since. Callers hold the file lock.
 func (js *JSONStorage) load() ([]*models.Task, error) {
+	info, err := os.Stat(js.filepath)
+	if err != nil {
+		return nil, err
+	}
+	if tasks, ok := js.fromCache(info); ok {
+		return tasks, nil
+	}
+
 	data, err := os.ReadFile(js.filepath)
 	if err != nil {
 		return nil, err
@@ -127,9 +333,27 @@ func (js *JSONStorage) load() ([]*models.Task, error) {
 		return nil, err
 	}
 
+	js.setCache(tasks, info)
 	return tasks, nil
 }
 
+func (js *JSONStorage) fromCache(info os.FileInfo) ([]*models.Task, bool) {
+	js.cacheMu.Lock()
+	defer js.cacheMu.Unlock()
+
+	if js.cachedInfo == nil || !sameFileState(info, js.cachedInfo) {
+		return nil, false
+	}
+	return copyTasks(js.cached), true
+}
+
+func (js *JSONStorage) setCache(tasks []*models.Task, info os.FileInfo) {
+	js.cacheMu.Lock()
+	defer js.cacheMu.Unlock()
+
+	js.cached, js.cachedInfo, js.index = copyTasks(tasks), info, nil
+}
+
 func (js *JSONStorage) save(tasks []*models.Task) error {
 	data, err := json.MarshalIndent(tasks, "", "  ")
 	if err != nil {
@@ -143,5 +367,12 @@ func (js *JSONStorage) save(tasks []*models.Task) error {
 	}
 
 	// Rename temp file to actual file
-	return os.Rename(tempFile, js.filepath)
+	if err := os.Rename(tempFile, js.filepath); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(js.filepath); err == nil {
+		js.setCache(tasks, info)
+	}
+	return nil
 }
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// loggerOrDefault returns logger, falling back to the process-wide default
+func loggerOrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+// gormSlowThreshold is how long a query takes before it is logged as slow
+const gormSlowThreshold = 200 * time.Millisecond
+
+// newGormLogger routes GORM's logs through logger. Failed queries are logged
+// at error level and slow queries at warn level, so both show at the default
+// info level; every SQL statement is traced at debug level.
+func newGormLogger(logger *slog.Logger) gormlogger.Interface {
+	level := gormlogger.Warn
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		level = gormlogger.Info
+	}
+	return &gormLogger{logger: logger.With("component", "gorm"), level: level}
+}
+
+// gormLogger implements GORM's logger interface on top of slog
+type gormLogger struct {
+	logger *slog.Logger
+	level  gormlogger.LogLevel
+}
+
+// LogMode implements gormlogger.Interface
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info implements gormlogger.Interface
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn implements gormlogger.Interface
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error implements gormlogger.Interface
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace implements gormlogger.Interface, logging a finished query by outcome
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	attrs := func() []slog.Attr {
+		sql, rows := fc()
+		return []slog.Attr{slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("duration", elapsed)}
+	}
+	switch {
+	case err != nil && !errors.Is(err, gormlogger.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		l.logger.LogAttrs(ctx, slog.LevelError, "query failed", append(attrs(), slog.String("error", err.Error()))...)
+	case elapsed > gormSlowThreshold && l.level >= gormlogger.Warn:
+		l.logger.LogAttrs(ctx, slog.LevelWarn, "slow query", append(attrs(), slog.Duration("threshold", gormSlowThreshold))...)
+	case l.level >= gormlogger.Info:
+		l.logger.LogAttrs(ctx, slog.LevelDebug, "query", attrs()...)
+	}
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestGormLogger(t *testing.T) {
+	query := func() (string, int64) { return "SELECT * FROM tasks", 3 }
+
+	t.Run("logs slow queries and errors at info level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := newGormLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+		logger.Trace(context.Background(), time.Now(), query, nil)
+		if buf.Len() != 0 {
+			t.Errorf("Expected fast queries not to be logged at info level, got %s", buf.String())
+		}
+
+		logger.Trace(context.Background(), time.Now().Add(-time.Second), query, nil)
+		if out := buf.String(); !strings.Contains(out, `"level":"WARN"`) || !strings.Contains(out, `"msg":"slow query"`) ||
+			!strings.Contains(out, `"sql":"SELECT * FROM tasks"`) {
+			t.Errorf("Expected a slow query warning, got %s", out)
+		}
+
+		buf.Reset()
+		logger.Trace(context.Background(), time.Now(), query, errors.New("connection reset"))
+		if out := buf.String(); !strings.Contains(out, `"level":"ERROR"`) || !strings.Contains(out, "connection reset") {
+			t.Errorf("Expected a query error, got %s", out)
+		}
+
+		buf.Reset()
+		logger.Trace(context.Background(), time.Now(), query, gormlogger.ErrRecordNotFound)
+		if buf.Len() != 0 {
+			t.Errorf("Expected missing records not to be logged, got %s", buf.String())
+		}
+	})
+
+	t.Run("traces every query at debug level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := newGormLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+		logger.Trace(context.Background(), time.Now(), query, nil)
+		if out := buf.String(); !strings.Contains(out, `"level":"DEBUG"`) || !strings.Contains(out, `"rows":3`) {
+			t.Errorf("Expected a debug query trace, got %s", out)
+		}
+	})
+
+	t.Run("silent mode logs nothing", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := newGormLogger(slog.New(slog.NewJSONHandler(&buf, nil))).LogMode(gormlogger.Silent)
+
+		logger.Trace(context.Background(), time.Now().Add(-time.Second), query, errors.New("boom"))
+		if buf.Len() != 0 {
+			t.Errorf("Expected no output, got %s", buf.String())
+		}
+	})
+}
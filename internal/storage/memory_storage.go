@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"GoTask_Management/internal/models"
+)
+
+// MemoryStorage keeps tasks in memory only, for tests and ephemeral runs.
+// Tasks are copied in and out, so callers cannot change stored tasks through
+// the pointers they pass or receive. GetAll returns tasks in creation order.
+// Idempotency records, saved views, webhooks and sent notifications are kept
+// alongside the tasks but not snapshotted.
+type MemoryStorage struct {
+	*MemoryIdempotencyStore
+	*MemoryViewStore
+	*MemoryWebhookStore
+	*MemoryNotificationStore
+
+	snapshotPath string
+
+	mu    sync.RWMutex
+	tasks []*models.Task
+	index map[string]int // task ID to position in tasks
+}
+
+// MemoryOption configures optional MemoryStorage behaviour
+type MemoryOption func(*MemoryStorage)
+
+// WithSnapshotPath makes Close write the tasks to path, in the JSONStorage
+// file format, so an ephemeral run can be inspected or opened with the json
+// storage afterwards. The snapshot is write-only: NewMemoryStorage always
+// starts empty and never reads it back.
+func WithSnapshotPath(path string) MemoryOption {
+	return func(ms *MemoryStorage) {
+		ms.snapshotPath = path
+	}
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage(opts ...MemoryOption) *MemoryStorage {
+	ms := &MemoryStorage{
+		MemoryIdempotencyStore:  NewMemoryIdempotencyStore(),
+		MemoryViewStore:         NewMemoryViewStore(),
+		MemoryWebhookStore:      NewMemoryWebhookStore(),
+		MemoryNotificationStore: NewMemoryNotificationStore(),
+		index:                   make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(ms)
+	}
+	return ms
+}
+
+// newMemoryBatch returns a MemoryStorage holding copies of tasks, for
+// storages that run transactions as a copy-on-write batch
+func newMemoryBatch(tasks []*models.Task) *MemoryStorage {
+	batch := NewMemoryStorage()
+	for _, task := range tasks {
+		batch.index[task.ID] = len(batch.tasks)
+		batch.tasks = append(batch.tasks, copyTask(task))
+	}
+	return batch
+}
+
+// Create implements Storage interface
+func (ms *MemoryStorage) Create(task *models.Task) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.index[task.ID]; exists {
+		return fmt.Errorf("task already exists")
+	}
+	ms.index[task.ID] = len(ms.tasks)
+	ms.tasks = append(ms.tasks, copyTask(task))
+	return nil
+}
+
+// GetAll implements Storage interface
+func (ms *MemoryStorage) GetAll() ([]*models.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	tasks := make([]*models.Task, 0, len(ms.tasks))
+	for _, task := range ms.tasks {
+		tasks = append(tasks, copyTask(task))
+	}
+	return tasks, nil
+}
+
+// GetByID implements Storage interface
+func (ms *MemoryStorage) GetByID(id string) (*models.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	i, ok := ms.index[id]
+	if !ok {
+		return nil, fmt.Errorf("task not found")
+	}
+	return copyTask(ms.tasks[i]), nil
+}
+
+// Update implements Storage interface
+func (ms *MemoryStorage) Update(task *models.Task) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	i, ok := ms.index[task.ID]
+	if !ok {
+		return fmt.Errorf("task not found")
+	}
+	ms.tasks[i] = copyTask(task)
+	return nil
+}
+
+// Delete implements Storage interface
+func (ms *MemoryStorage) Delete(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	i, ok := ms.index[id]
+	if !ok {
+		return fmt.Errorf("task not found")
+	}
+	ms.tasks = append(ms.tasks[:i], ms.tasks[i+1:]...)
+	delete(ms.index, id)
+	for j := i; j < len(ms.tasks); j++ {
+		ms.index[ms.tasks[j].ID] = j
+	}
+	return nil
+}
+
+// CreateMany implements Batcher
+func (ms *MemoryStorage) CreateMany(tasks []*models.Task) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	seen := make(map[string]bool, len(tasks))
+	for i, task := range tasks {
+		if _, exists := ms.index[task.ID]; exists || seen[task.ID] {
+			return &BatchError{Index: i, Err: fmt.Errorf("task already exists")}
+		}
+		seen[task.ID] = true
+	}
+	for _, task := range tasks {
+		ms.index[task.ID] = len(ms.tasks)
+		ms.tasks = append(ms.tasks, copyTask(task))
+	}
+	return nil
+}
+
+// UpdateMany implements Batcher
+func (ms *MemoryStorage) UpdateMany(tasks []*models.Task) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i, task := range tasks {
+		if _, ok := ms.index[task.ID]; !ok {
+			return &BatchError{Index: i, Err: fmt.Errorf("task not found")}
+		}
+	}
+	for _, task := range tasks {
+		ms.tasks[ms.index[task.ID]] = copyTask(task)
+	}
+	return nil
+}
+
+// DeleteMany implements Batcher, compacting the tasks once for the batch
+func (ms *MemoryStorage) DeleteMany(ids []string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	deleted := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if _, ok := ms.index[id]; !ok || deleted[id] {
+			return &BatchError{Index: i, Err: fmt.Errorf("task not found")}
+		}
+		deleted[id] = true
+	}
+
+	kept := ms.tasks[:0]
+	for _, task := range ms.tasks {
+		if deleted[task.ID] {
+			delete(ms.index, task.ID)
+			continue
+		}
+		ms.index[task.ID] = len(kept)
+		kept = append(kept, task)
+	}
+	clear(ms.tasks[len(kept):])
+	ms.tasks = kept
+	return nil
+}
+
+// WithTx implements Transactor. fn works on a copy of the tasks, which
+// replaces them when fn succeeds; other operations wait until then.
+func (ms *MemoryStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	batch := newMemoryBatch(ms.tasks)
+	if err := fn(batch); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ms.tasks, ms.index = batch.tasks, batch.index
+	return nil
+}
+
+// Close implements Storage interface, writing the snapshot when one is
+// configured. The tasks stay available, so Close may be called again.
+func (ms *MemoryStorage) Close() error {
+	if ms.snapshotPath == "" {
+		return nil
+	}
+
+	ms.mu.RLock()
+	tasks := ms.tasks
+	if tasks == nil {
+		tasks = make([]*models.Task, 0)
+	}
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	ms.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tempFile := ms.snapshotPath + ".tmp"
+	if err := writeFileSync(tempFile, data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tempFile, ms.snapshotPath); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return syncDir(filepath.Dir(ms.snapshotPath))
+}
+
+// Verify that MemoryStorage implements Storage interface
+var _ Storage = (*MemoryStorage)(nil)
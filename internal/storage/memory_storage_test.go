@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	t.Run("passes storage compliance", func(t *testing.T) {
+		testStorageCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("guards stored tasks", func(t *testing.T) {
+		store := NewMemoryStorage()
+		due := time.Now().Add(time.Hour)
+		task := helper.CreateSampleTask("g1", "Original")
+		taskDue := due
+		task.DueDate = &taskDue
+		helper.AssertNoError(store.Create(task), "creating task")
+
+		task.Title = "Mutated after create"
+		*task.DueDate = due.Add(time.Hour)
+		got, _ := store.GetByID("g1")
+		got.Title = "Mutated after get"
+		*got.DueDate = due.Add(2 * time.Hour)
+		tasks, _ := store.GetAll()
+		tasks[0].Title = "Mutated after list"
+
+		got, _ = store.GetByID("g1")
+		if got.Title != "Original" || !got.DueDate.Equal(due) {
+			t.Errorf("Expected the stored task to be unaffected, got %q due %v", got.Title, got.DueDate)
+		}
+		helper.AssertError(store.Create(helper.CreateSampleTask("g1", "Duplicate")), true, "creating a duplicate")
+	})
+
+	t.Run("lists in creation order", func(t *testing.T) {
+		store := NewMemoryStorage()
+		for _, id := range []string{"o1", "o2", "o3", "o4"} {
+			helper.AssertNoError(store.Create(helper.CreateSampleTask(id, id)), "creating task")
+		}
+		helper.AssertNoError(store.Delete("o2"), "deleting task")
+		helper.AssertNoError(store.Update(helper.CreateSampleTask("o3", "Renamed")), "updating task")
+
+		tasks, _ := store.GetAll()
+		if len(tasks) != 3 || tasks[0].ID != "o1" || tasks[1].ID != "o3" || tasks[2].ID != "o4" {
+			t.Errorf("Expected o1, o3, o4, got %+v", tasks)
+		}
+		if task, _ := store.GetByID("o4"); task == nil || task.ID != "o4" {
+			t.Errorf("Expected o4 to be found after a delete, got %+v", task)
+		}
+	})
+
+	t.Run("writes a snapshot on close", func(t *testing.T) {
+		path := helper.TempFilePath("memory_snapshot.json")
+		store := NewMemoryStorage(WithSnapshotPath(path))
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("s1", "Snapshotted")), "creating task")
+		helper.AssertNoError(store.Close(), "closing")
+
+		snapshot, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "opening snapshot")
+		if task, err := snapshot.GetByID("s1"); err != nil || task.Title != "Snapshotted" {
+			t.Errorf("Expected the task in the snapshot, got %+v, %v", task, err)
+		}
+
+		empty := helper.TempFilePath("memory_empty.json")
+		helper.AssertNoError(NewMemoryStorage(WithSnapshotPath(empty)).Close(), "closing empty storage")
+		if data, _ := os.ReadFile(empty); string(data) != "[]" {
+			t.Errorf("Expected an empty JSON array, got %q", data)
+		}
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		store := NewMemoryStorage()
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				task := helper.CreateSampleTask(fmt.Sprintf("c%d", i), "Concurrent")
+				if err := store.Create(task); err != nil {
+					t.Errorf("Failed to create task: %v", err)
+					return
+				}
+				for j := range 20 {
+					task.Title = fmt.Sprintf("Title %d", j)
+					store.Update(task)
+					store.GetAll()
+				}
+				if i%2 == 0 {
+					store.Delete(task.ID)
+				}
+			}()
+		}
+		wg.Wait()
+		if tasks, _ := store.GetAll(); len(tasks) != 4 {
+			t.Errorf("Expected 4 tasks to remain, got %d", len(tasks))
+		}
+	})
+}
@@ -2,11 +2,15 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"regexp"
+	"strings"
 	"time"
 
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -18,7 +22,19 @@ type MongoDBStorage struct {
 	client     *mongo.Client
 	database   *mongo.Database
 	collection *mongo.Collection
+	// idempotency holds idempotency records, expired by a TTL index
+	idempotency *mongo.Collection
+	views       *mongo.Collection
+	// webhookSubscriptions and webhookDeliveries hold webhook records
+	webhookSubscriptions *mongo.Collection
+	webhookDeliveries    *mongo.Collection
+	// notifications holds sent notification records by key
+	notifications *mongo.Collection
+	// standalone is set when the server is neither a replica set member nor
+	// a mongos router, so it cannot run transactions
+	standalone bool
 	ctx        context.Context
+	logger     *slog.Logger
 }
 
 // MongoDBConfig holds the configuration for MongoDB connection
@@ -28,6 +44,7 @@ type MongoDBConfig struct {
 	Collection     string
 	ConnectTimeout time.Duration
 	QueryTimeout   time.Duration
+	Logger         *slog.Logger
 }
 
 // NewMongoDBStorage creates a new MongoDB storage instance
@@ -55,10 +72,25 @@ func NewMongoDBStorage(config MongoDBConfig) (*MongoDBStorage, error) {
 	collection := database.Collection(config.Collection)
 
 	storage := &MongoDBStorage{
-		client:     client,
-		database:   database,
-		collection: collection,
-		ctx:        context.Background(),
+		client:               client,
+		database:             database,
+		collection:           collection,
+		idempotency:          database.Collection(config.Collection + "_idempotency"),
+		views:                database.Collection(config.Collection + "_views"),
+		webhookSubscriptions: database.Collection(config.Collection + "_webhook_subscriptions"),
+		webhookDeliveries:    database.Collection(config.Collection + "_webhook_deliveries"),
+		notifications:        database.Collection(config.Collection + "_notifications"),
+		ctx:                  context.Background(),
+		logger:               loggerOrDefault(config.Logger),
+	}
+
+	standalone, err := isStandalone(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MongoDB topology: %w", err)
+	}
+	storage.standalone = standalone
+	if standalone {
+		storage.logger.Warn("MongoDB server is standalone, transactions and batches are unavailable; run it as a replica set to enable them")
 	}
 
 	// Create indexes
@@ -66,10 +98,24 @@ func NewMongoDBStorage(config MongoDBConfig) (*MongoDBStorage, error) {
 		return nil, fmt.Errorf("failed to create indexes: %w", err)
 	}
 
-	log.Println("✅ MongoDB storage initialized successfully")
+	storage.logger.Info("MongoDB storage initialized", "database", config.Database, "collection", config.Collection)
 	return storage, nil
 }
 
+// isStandalone reports whether the server behind database is a standalone
+// mongod, which rejects transactions. Replica set members report a set name
+// and mongos routers report "isdbgrid".
+func isStandalone(ctx context.Context, database *mongo.Database) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := database.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, err
+	}
+	return hello.SetName == "" && hello.Msg != "isdbgrid", nil
+}
+
 // createIndexes creates necessary indexes for optimal performance
 func (ms *MongoDBStorage) createIndexes() error {
 	ctx, cancel := context.WithTimeout(ms.ctx, 10*time.Second)
@@ -104,9 +150,34 @@ func (ms *MongoDBStorage) createIndexes() error {
 		},
 	}
 
-	indexes := []mongo.IndexModel{idIndex, createdAtIndex, dueDateIndex, doneIndex, compoundIndex}
+	// Create text index for Search; scripts/mongo-init.js creates the same one
+	titleTextIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "title", Value: "text"}},
+	}
+
+	indexes := []mongo.IndexModel{idIndex, createdAtIndex, dueDateIndex, doneIndex, compoundIndex, titleTextIndex}
+
+	if _, err := ms.collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		return err
+	}
+
+	// MongoDB removes idempotency records once expires_at has passed; the
+	// removal runs about once a minute, so reads still check expiry
+	ttlIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	if _, err := ms.idempotency.Indexes().CreateOne(ctx, ttlIndex); err != nil {
+		return err
+	}
+
+	subscriptionIndex := mongo.IndexModel{Keys: bson.D{{Key: "subscription_id", Value: 1}}}
+	if _, err := ms.webhookDeliveries.Indexes().CreateOne(ctx, subscriptionIndex); err != nil {
+		return err
+	}
 
-	_, err := ms.collection.Indexes().CreateMany(ctx, indexes)
+	sentAtIndex := mongo.IndexModel{Keys: bson.D{{Key: "sent_at", Value: 1}}}
+	_, err := ms.notifications.Indexes().CreateOne(ctx, sentAtIndex)
 	return err
 }
 
@@ -135,7 +206,7 @@ func (ms *MongoDBStorage) GetAll() ([]*models.Task, error) {
 	}
 	defer cursor.Close(ctx)
 
-	var tasks []*models.Task
+	tasks := make([]*models.Task, 0)
 	if err := cursor.All(ctx, &tasks); err != nil {
 		return nil, fmt.Errorf("failed to decode tasks: %w", err)
 	}
@@ -200,6 +271,113 @@ func (ms *MongoDBStorage) Delete(id string) error {
 	return nil
 }
 
+// UpdateIfVersion implements Versioner by matching the version in the
+// update filter
+func (ms *MongoDBStorage) UpdateIfVersion(task *models.Task, expected int64) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "id", Value: task.ID}, {Key: "version", Value: expected}}
+	result, err := ms.collection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: task}})
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return versionMismatch(ms, task.ID, expected)
+	}
+	return nil
+}
+
+// DeleteIfVersion implements Versioner by matching the version in the
+// delete filter
+func (ms *MongoDBStorage) DeleteIfVersion(id string, expected int64) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "id", Value: id}, {Key: "version", Value: expected}}
+	result, err := ms.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return versionMismatch(ms, id, expected)
+	}
+	return nil
+}
+
+// CreateMany implements Batcher with a single InsertMany in a transaction,
+// so like WithTx it needs a replica set or sharded cluster
+func (ms *MongoDBStorage) CreateMany(tasks []*models.Task) error {
+	return ms.WithTx(ms.ctx, func(tx Storage) error {
+		txStorage := tx.(*MongoDBStorage)
+		ctx, cancel := context.WithTimeout(txStorage.ctx, 30*time.Second)
+		defer cancel()
+
+		documents := make([]interface{}, len(tasks))
+		for i, task := range tasks {
+			documents[i] = task
+		}
+		_, err := txStorage.collection.InsertMany(ctx, documents)
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+			return &BatchError{Index: bulkErr.WriteErrors[0].Index, Err: fmt.Errorf("failed to create task: %w", err)}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create tasks: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateMany implements Batcher in a transaction
+func (ms *MongoDBStorage) UpdateMany(tasks []*models.Task) error {
+	return ms.WithTx(ms.ctx, func(tx Storage) error {
+		for i, task := range tasks {
+			if err := tx.Update(task); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteMany implements Batcher in a transaction
+func (ms *MongoDBStorage) DeleteMany(ids []string) error {
+	return ms.WithTx(ms.ctx, func(tx Storage) error {
+		for i, id := range ids {
+			if err := tx.Delete(id); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// WithTx implements Transactor with a multi-document transaction in a
+// session, which needs a replica set or sharded cluster. On a standalone
+// server it returns an error wrapping ErrTxUnsupported. The driver retries
+// fn on transient transaction errors.
+func (ms *MongoDBStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	if mongo.SessionFromContext(ms.ctx) != nil {
+		return fn(ms)
+	}
+	if ms.standalone {
+		return fmt.Errorf("%w: MongoDB transactions require a replica set or sharded cluster", ErrTxUnsupported)
+	}
+	session, err := ms.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		tx := *ms
+		tx.ctx = sessCtx
+		return nil, fn(&tx)
+	})
+	return err
+}
+
 // Close implements Storage interface
 func (ms *MongoDBStorage) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -298,6 +476,336 @@ func (ms *MongoDBStorage) GetOverdueTasksCount() (int64, error) {
 	return count, nil
 }
 
+// Search implements Searcher with the text index, ranking by text score.
+// MongoDB stems words but does not match prefixes.
+func (ms *MongoDBStorage) Search(query string, limit int) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ms.ctx, 10*time.Second)
+	defer cancel()
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}}).
+		SetLimit(int64(limit))
+	cursor, err := ms.collection.Find(ctx, bson.M{"$text": bson.M{"$search": strings.Join(terms, " ")}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tasks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []SearchResult
+	for cursor.Next(ctx) {
+		var doc struct {
+			models.Task `bson:",inline"`
+			Score       float64 `bson:"score"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode task: %w", err)
+		}
+		task := doc.Task
+		results = append(results, SearchResult{Task: &task, Score: doc.Score, Matches: matchRanges(task.Title, terms)})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search tasks: %w", err)
+	}
+	sortSearchResults(results)
+	return results, nil
+}
+
+// Find implements Finder, selecting tasks by status, ID and title in the
+// query and checking times in Go. Task has no bson tags, so its times are
+// stored as createdat, duedate and completedat rather than under the names
+// the indexes use.
+func (ms *MongoDBStorage) Find(filter *query.Filter) ([]*models.Task, error) {
+	ctx, cancel := context.WithTimeout(ms.ctx, 10*time.Second)
+	defer cancel()
+
+	conditions := bson.A{}
+	for _, cond := range filter.Conditions {
+		var c bson.D
+		switch cond.Field {
+		case query.FieldTitle:
+			c = bson.D{{Key: "title", Value: bson.D{{Key: "$regex", Value: regexp.QuoteMeta(cond.Text)}, {Key: "$options", Value: "i"}}}}
+		case query.FieldID:
+			c = bson.D{{Key: "id", Value: cond.Text}}
+		case query.FieldStatus:
+			c = bson.D{{Key: "done", Value: cond.Done}}
+		default:
+			continue
+		}
+		if cond.Not {
+			c = bson.D{{Key: "$nor", Value: bson.A{c}}}
+		}
+		conditions = append(conditions, c)
+	}
+	where := bson.D{}
+	if len(conditions) > 0 {
+		where = bson.D{{Key: "$and", Value: conditions}}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := ms.collection.Find(ctx, where, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tasks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []*models.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode tasks: %w", err)
+	}
+	return matchTasks(tasks, filter), nil
+}
+
+// CreateIdempotencyRecord implements IdempotencyStore
+func (ms *MongoDBStorage) CreateIdempotencyRecord(record *IdempotencyRecord) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	expired := bson.M{"_id": record.Key, "expires_at": bson.M{"$lte": record.CreatedAt}}
+	if _, err := ms.idempotency.DeleteOne(ctx, expired); err != nil {
+		return fmt.Errorf("failed to replace expired idempotency record: %w", err)
+	}
+	_, err := ms.idempotency.InsertOne(ctx, record)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrIdempotencyKeyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency record: %w", err)
+	}
+	return nil
+}
+
+// GetIdempotencyRecord implements IdempotencyStore
+func (ms *MongoDBStorage) GetIdempotencyRecord(key string) (*IdempotencyRecord, error) {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	var record IdempotencyRecord
+	filter := bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}
+	err := ms.idempotency.FindOne(ctx, filter).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+	return &record, nil
+}
+
+// SaveIdempotencyRecord implements IdempotencyStore
+func (ms *MongoDBStorage) SaveIdempotencyRecord(record *IdempotencyRecord) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := ms.idempotency.ReplaceOne(ctx, bson.M{"_id": record.Key}, record, opts); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+	return nil
+}
+
+// DeleteIdempotencyRecord implements IdempotencyStore
+func (ms *MongoDBStorage) DeleteIdempotencyRecord(key string) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := ms.idempotency.DeleteOne(ctx, bson.M{"_id": key}); err != nil {
+		return fmt.Errorf("failed to delete idempotency record: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyRecords implements IdempotencyStore. The TTL
+// index does the same in the background.
+func (ms *MongoDBStorage) DeleteExpiredIdempotencyRecords(now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ms.ctx, 30*time.Second)
+	defer cancel()
+
+	result, err := ms.idempotency.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lte": now}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency records: %w", err)
+	}
+	return int(result.DeletedCount), nil
+}
+
+// ListViews implements ViewStore
+func (ms *MongoDBStorage) ListViews() ([]*ViewRecord, error) {
+	ctx, cancel := context.WithTimeout(ms.ctx, 10*time.Second)
+	defer cancel()
+
+	cursor, err := ms.views.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []*ViewRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode views: %w", err)
+	}
+	return records, nil
+}
+
+// SaveView implements ViewStore
+func (ms *MongoDBStorage) SaveView(record *ViewRecord) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := ms.views.ReplaceOne(ctx, bson.M{"_id": record.ID}, record, opts); err != nil {
+		return fmt.Errorf("failed to save view: %w", err)
+	}
+	return nil
+}
+
+// DeleteView implements ViewStore
+func (ms *MongoDBStorage) DeleteView(id string) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := ms.views.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookSubscriptions implements WebhookStore
+func (ms *MongoDBStorage) ListWebhookSubscriptions() ([]*WebhookSubscriptionRecord, error) {
+	ctx, cancel := context.WithTimeout(ms.ctx, 10*time.Second)
+	defer cancel()
+
+	cursor, err := ms.webhookSubscriptions.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	records := make([]*WebhookSubscriptionRecord, 0)
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook subscriptions: %w", err)
+	}
+	return records, nil
+}
+
+// SaveWebhookSubscription implements WebhookStore
+func (ms *MongoDBStorage) SaveWebhookSubscription(record *WebhookSubscriptionRecord) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := ms.webhookSubscriptions.ReplaceOne(ctx, bson.M{"_id": record.ID}, record, opts); err != nil {
+		return fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhookSubscription implements WebhookStore. The deliveries are
+// removed first, so a failure leaves the subscription in place.
+func (ms *MongoDBStorage) DeleteWebhookSubscription(id string) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := ms.webhookDeliveries.DeleteMany(ctx, bson.M{"subscription_id": id}); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	if _, err := ms.webhookSubscriptions.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries implements WebhookStore
+func (ms *MongoDBStorage) ListWebhookDeliveries(subscriptionID string) ([]*WebhookDeliveryRecord, error) {
+	ctx, cancel := context.WithTimeout(ms.ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if subscriptionID != "" {
+		filter["subscription_id"] = subscriptionID
+	}
+	cursor, err := ms.webhookDeliveries.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	records := make([]*WebhookDeliveryRecord, 0)
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook deliveries: %w", err)
+	}
+	return records, nil
+}
+
+// SaveWebhookDelivery implements WebhookStore
+func (ms *MongoDBStorage) SaveWebhookDelivery(record *WebhookDeliveryRecord) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := ms.webhookDeliveries.ReplaceOne(ctx, bson.M{"_id": record.ID}, record, opts); err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhookDeliveries implements WebhookStore
+func (ms *MongoDBStorage) DeleteWebhookDeliveries(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ms.ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := ms.webhookDeliveries.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// ClaimNotification implements NotificationStore
+func (ms *MongoDBStorage) ClaimNotification(record *NotificationRecord) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := ms.notifications.InsertOne(ctx, record)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrNotificationClaimed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to claim notification: %w", err)
+	}
+	return nil
+}
+
+// DeleteNotification implements NotificationStore
+func (ms *MongoDBStorage) DeleteNotification(key string) error {
+	ctx, cancel := context.WithTimeout(ms.ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := ms.notifications.DeleteOne(ctx, bson.M{"_id": key}); err != nil {
+		return fmt.Errorf("failed to delete notification: %w", err)
+	}
+	return nil
+}
+
+// DeleteNotificationsBefore implements NotificationStore
+func (ms *MongoDBStorage) DeleteNotificationsBefore(before time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ms.ctx, 30*time.Second)
+	defer cancel()
+
+	result, err := ms.notifications.DeleteMany(ctx, bson.M{"sent_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete notifications: %w", err)
+	}
+	return int(result.DeletedCount), nil
+}
+
 // HealthCheck performs a health check on the database connection
 func (ms *MongoDBStorage) HealthCheck() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -305,6 +813,125 @@ func (ms *MongoDBStorage) HealthCheck() error {
 	return ms.client.Ping(ctx, nil)
 }
 
+// mongoChange is the part of a change stream event Watch uses
+type mongoChange struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID bson.RawValue `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *models.Task `bson:"fullDocument"`
+}
+
+// Watch reports changes made by any client of the collection through a
+// change stream, which needs a replica set or sharded cluster
+func (ms *MongoDBStorage) Watch(ctx context.Context) (<-chan Change, error) {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	stream, err := ms.collection.Watch(ctx, mongo.Pipeline{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open change stream: %w", err)
+	}
+
+	// Delete events only carry the document key, so map keys to task IDs
+	taskIDs, err := ms.taskIDsByKey(ctx)
+	if err != nil {
+		stream.Close(context.Background())
+		return nil, err
+	}
+
+	changes := make(chan Change)
+	go func() {
+		defer close(changes)
+		for {
+			ms.receive(ctx, stream, taskIDs, changes)
+			err := stream.Err()
+			stream.Close(context.Background())
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				ms.logger.Warn("task change stream was invalidated")
+				return
+			}
+			ms.logger.Warn("lost task change stream, resuming", "error", err)
+
+			opts.SetResumeAfter(stream.ResumeToken())
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(watchRetryDelay):
+				}
+				if stream, err = ms.collection.Watch(ctx, mongo.Pipeline{}, opts); err == nil {
+					break
+				}
+				ms.logger.Warn("failed to resume task change stream", "error", err)
+			}
+		}
+	}()
+	return changes, nil
+}
+
+func (ms *MongoDBStorage) taskIDsByKey(ctx context.Context) (map[string]string, error) {
+	opts := options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}, {Key: "id", Value: 1}})
+	cursor, err := ms.collection.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task keys: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	taskIDs := make(map[string]string)
+	for cursor.Next(ctx) {
+		var doc struct {
+			Key    bson.RawValue `bson:"_id"`
+			TaskID string        `bson:"id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode task key: %w", err)
+		}
+		taskIDs[doc.Key.String()] = doc.TaskID
+	}
+	return taskIDs, cursor.Err()
+}
+
+// receive forwards stream events until the stream or ctx ends
+func (ms *MongoDBStorage) receive(ctx context.Context, stream *mongo.ChangeStream, taskIDs map[string]string, changes chan<- Change) {
+	for stream.Next(ctx) {
+		var event mongoChange
+		if err := stream.Decode(&event); err != nil {
+			ms.logger.Warn("ignoring undecodable task change", "error", err)
+			continue
+		}
+		key := event.DocumentKey.ID.String()
+
+		var change Change
+		switch event.OperationType {
+		case "insert", "update", "replace":
+			if event.FullDocument == nil {
+				// Deleted since; its own event follows
+				continue
+			}
+			taskIDs[key] = event.FullDocument.ID
+			change = Change{Op: ChangeUpdate, TaskID: event.FullDocument.ID, Task: event.FullDocument}
+			if event.OperationType == "insert" {
+				change.Op = ChangeCreate
+			}
+		case "delete":
+			taskID, ok := taskIDs[key]
+			if !ok {
+				continue
+			}
+			delete(taskIDs, key)
+			change = Change{Op: ChangeDelete, TaskID: taskID}
+		default:
+			continue
+		}
+
+		if !sendChange(ctx, changes, change) {
+			return
+		}
+	}
+}
+
 // GetCollection returns the underlying MongoDB collection (for advanced operations)
 func (ms *MongoDBStorage) GetCollection() *mongo.Collection {
 	return ms.collection
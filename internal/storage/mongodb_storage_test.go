@@ -6,7 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"GoTask_Management/internal/models"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -35,6 +34,43 @@ func TestMongoDBStorage(t *testing.T) {
 
 	// Run storage compliance tests
 	testStorageCompliance(t, storage)
+
+	t.Run("Idempotency", func(t *testing.T) {
+		testIdempotencyCompliance(t, storage)
+	})
+
+	t.Run("Views", func(t *testing.T) {
+		testViewStoreCompliance(t, storage)
+	})
+
+	t.Run("Webhooks", func(t *testing.T) {
+		testWebhookStoreCompliance(t, storage)
+	})
+
+	t.Run("Notifications", func(t *testing.T) {
+		testNotificationStoreCompliance(t, storage)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		testSearchCompliance(t, storage)
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		testFindCompliance(t, storage)
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		// Transactions and batches need a replica set or a sharded cluster
+		var hello bson.M
+		if err := storage.database.RunCommand(context.Background(), bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+			t.Fatalf("Failed to run hello: %v", err)
+		}
+		if hello["setName"] == nil && hello["msg"] != "isdbgrid" {
+			t.Skip("MongoDB transaction test skipped: server is standalone")
+		}
+		testTransactionCompliance(t, storage)
+		testBatchCompliance(t, storage)
+	})
 }
 
 func TestMongoDBStorage_AdditionalMethods(t *testing.T) {
@@ -1,19 +1,23 @@
 package storage
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 // MySQLStorage implements the Storage interface using MySQL with GORM
 type MySQLStorage struct {
-	db *gorm.DB
+	db     *gorm.DB
+	logger *slog.Logger
 }
 
 // MySQLConfig holds the configuration for MySQL connection
@@ -26,16 +30,18 @@ type MySQLConfig struct {
 	Charset  string
 	ParseTime bool
 	Loc      string
+	Logger   *slog.Logger
 }
 
 // NewMySQLStorage creates a new MySQL storage instance
 func NewMySQLStorage(config MySQLConfig) (*MySQLStorage, error) {
+	logger := loggerOrDefault(config.Logger)
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=%s",
 		config.User, config.Password, config.Host, config.Port, config.DBName,
 		config.Charset, config.ParseTime, config.Loc)
 
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: newGormLogger(logger),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MySQL: %w", err)
@@ -52,20 +58,26 @@ func NewMySQLStorage(config MySQLConfig) (*MySQLStorage, error) {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	storage := &MySQLStorage{db: db}
+	storage := &MySQLStorage{db: db, logger: logger}
 
 	// Auto-migrate the schema
 	if err := storage.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	log.Println("✅ MySQL storage initialized successfully")
+	logger.Info("MySQL storage initialized", "host", config.Host, "database", config.DBName)
 	return storage, nil
 }
 
 // migrate runs database migrations
 func (ms *MySQLStorage) migrate() error {
-	return ms.db.AutoMigrate(&models.Task{})
+	if err := ms.db.AutoMigrate(&models.Task{}, &IdempotencyRecord{}, &ViewRecord{}, &WebhookSubscriptionRecord{}, &WebhookDeliveryRecord{}, &NotificationRecord{}); err != nil {
+		return err
+	}
+	if ms.db.Migrator().HasIndex(&models.Task{}, "idx_tasks_title_fulltext") {
+		return nil
+	}
+	return ms.db.Exec(`ALTER TABLE tasks ADD FULLTEXT INDEX idx_tasks_title_fulltext (title)`).Error
 }
 
 // Create implements Storage interface
@@ -121,6 +133,16 @@ func (ms *MySQLStorage) Delete(id string) error {
 	return nil
 }
 
+// UpdateIfVersion implements Versioner with a conditional UPDATE
+func (ms *MySQLStorage) UpdateIfVersion(task *models.Task, expected int64) error {
+	return updateGormTaskIfVersion(ms, ms.db, task, expected)
+}
+
+// DeleteIfVersion implements Versioner with a conditional DELETE
+func (ms *MySQLStorage) DeleteIfVersion(id string, expected int64) error {
+	return deleteGormTaskIfVersion(ms, ms.db, id, expected)
+}
+
 // Close implements Storage interface
 func (ms *MySQLStorage) Close() error {
 	sqlDB, err := ms.db.DB()
@@ -179,6 +201,124 @@ func (ms *MySQLStorage) GetOverdueTasksCount() (int64, error) {
 	return count, nil
 }
 
+// Search implements Searcher with the FULLTEXT index in boolean mode, where
+// tasks match any query word, long enough words also match as prefixes, and
+// MySQL's relevance orders the results
+func (ms *MySQLStorage) Search(query string, limit int) ([]SearchResult, error) {
+	var terms []string
+	for _, term := range searchTerms(query) {
+		if utf8.RuneCountInString(term) >= minPrefixLength {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	against := strings.Join(terms, " ")
+	var rows []gormSearchRow
+	err := ms.db.Raw(`SELECT *, MATCH (title) AGAINST (? IN BOOLEAN MODE) AS score
+		FROM tasks
+		WHERE MATCH (title) AGAINST (? IN BOOLEAN MODE)
+		ORDER BY score DESC, created_at DESC, id
+		LIMIT ?`, against, against, limit).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tasks: %w", err)
+	}
+	return searchResults(rows, query), nil
+}
+
+// Find implements Finder with a WHERE clause
+func (ms *MySQLStorage) Find(filter *query.Filter) ([]*models.Task, error) {
+	return findGormTasks(ms.db, filter)
+}
+
+// CreateIdempotencyRecord implements IdempotencyStore
+func (ms *MySQLStorage) CreateIdempotencyRecord(record *IdempotencyRecord) error {
+	return createGormIdempotencyRecord(ms.db, record)
+}
+
+// GetIdempotencyRecord implements IdempotencyStore
+func (ms *MySQLStorage) GetIdempotencyRecord(key string) (*IdempotencyRecord, error) {
+	return getGormIdempotencyRecord(ms.db, key)
+}
+
+// SaveIdempotencyRecord implements IdempotencyStore
+func (ms *MySQLStorage) SaveIdempotencyRecord(record *IdempotencyRecord) error {
+	return saveGormIdempotencyRecord(ms.db, record)
+}
+
+// DeleteIdempotencyRecord implements IdempotencyStore
+func (ms *MySQLStorage) DeleteIdempotencyRecord(key string) error {
+	return deleteGormIdempotencyRecord(ms.db, key)
+}
+
+// DeleteExpiredIdempotencyRecords implements IdempotencyStore
+func (ms *MySQLStorage) DeleteExpiredIdempotencyRecords(now time.Time) (int, error) {
+	return deleteExpiredGormIdempotencyRecords(ms.db, now)
+}
+
+// ListViews implements ViewStore
+func (ms *MySQLStorage) ListViews() ([]*ViewRecord, error) {
+	return listGormViews(ms.db)
+}
+
+// SaveView implements ViewStore
+func (ms *MySQLStorage) SaveView(record *ViewRecord) error {
+	return saveGormView(ms.db, record)
+}
+
+// DeleteView implements ViewStore
+func (ms *MySQLStorage) DeleteView(id string) error {
+	return deleteGormView(ms.db, id)
+}
+
+// ListWebhookSubscriptions implements WebhookStore
+func (ms *MySQLStorage) ListWebhookSubscriptions() ([]*WebhookSubscriptionRecord, error) {
+	return listGormWebhookSubscriptions(ms.db)
+}
+
+// SaveWebhookSubscription implements WebhookStore
+func (ms *MySQLStorage) SaveWebhookSubscription(record *WebhookSubscriptionRecord) error {
+	return saveGormWebhookSubscription(ms.db, record)
+}
+
+// DeleteWebhookSubscription implements WebhookStore
+func (ms *MySQLStorage) DeleteWebhookSubscription(id string) error {
+	return deleteGormWebhookSubscription(ms.db, id)
+}
+
+// ListWebhookDeliveries implements WebhookStore
+func (ms *MySQLStorage) ListWebhookDeliveries(subscriptionID string) ([]*WebhookDeliveryRecord, error) {
+	return listGormWebhookDeliveries(ms.db, subscriptionID)
+}
+
+// SaveWebhookDelivery implements WebhookStore
+func (ms *MySQLStorage) SaveWebhookDelivery(record *WebhookDeliveryRecord) error {
+	return saveGormWebhookDelivery(ms.db, record)
+}
+
+// DeleteWebhookDeliveries implements WebhookStore
+func (ms *MySQLStorage) DeleteWebhookDeliveries(ids []string) error {
+	return deleteGormWebhookDeliveries(ms.db, ids)
+}
+
+// ClaimNotification implements NotificationStore
+func (ms *MySQLStorage) ClaimNotification(record *NotificationRecord) error {
+	return claimGormNotification(ms.db, record)
+}
+
+// DeleteNotification implements NotificationStore
+func (ms *MySQLStorage) DeleteNotification(key string) error {
+	return deleteGormNotification(ms.db, key)
+}
+
+// DeleteNotificationsBefore implements NotificationStore
+func (ms *MySQLStorage) DeleteNotificationsBefore(before time.Time) (int, error) {
+	return deleteGormNotificationsBefore(ms.db, before)
+}
+
 // HealthCheck performs a health check on the database connection
 func (ms *MySQLStorage) HealthCheck() error {
 	sqlDB, err := ms.db.DB()
@@ -188,14 +328,113 @@ func (ms *MySQLStorage) HealthCheck() error {
 	return sqlDB.Ping()
 }
 
+// Watch reports changes made by any client of the database, polling a
+// change log that triggers append to
+func (ms *MySQLStorage) Watch(ctx context.Context) (<-chan Change, error) {
+	sqlDB, err := ms.db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	log := &changeLog{
+		db:     sqlDB,
+		setup:  ms.setupChangeLog,
+		prune:  `DELETE FROM task_changes WHERE changed_at < NOW() - INTERVAL 1 HOUR`,
+		get:    ms.GetByID,
+		logger: ms.logger,
+	}
+	return log.watch(ctx)
+}
+
+func (ms *MySQLStorage) setupChangeLog(ctx context.Context) error {
+	db := ms.db.WithContext(ctx)
+	err := db.Exec(`CREATE TABLE IF NOT EXISTS task_changes (
+		seq BIGINT AUTO_INCREMENT PRIMARY KEY,
+		task_id VARCHAR(255) NOT NULL,
+		op VARCHAR(16) NOT NULL,
+		changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to create change log: %w", err)
+	}
+
+	// CREATE TRIGGER IF NOT EXISTS needs MySQL 8.0.29, so check first
+	var existing []string
+	err = db.Raw(`SELECT TRIGGER_NAME FROM information_schema.TRIGGERS
+		WHERE TRIGGER_SCHEMA = DATABASE() AND EVENT_OBJECT_TABLE = 'tasks'`).Scan(&existing).Error
+	if err != nil {
+		return fmt.Errorf("failed to list triggers: %w", err)
+	}
+	installed := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		installed[name] = true
+	}
+
+	triggers := map[string]string{
+		"tasks_watch_insert": `CREATE TRIGGER tasks_watch_insert AFTER INSERT ON tasks FOR EACH ROW
+			INSERT INTO task_changes (task_id, op) VALUES (NEW.id, 'create')`,
+		"tasks_watch_update": `CREATE TRIGGER tasks_watch_update AFTER UPDATE ON tasks FOR EACH ROW
+			INSERT INTO task_changes (task_id, op) VALUES (NEW.id, 'update')`,
+		"tasks_watch_delete": `CREATE TRIGGER tasks_watch_delete AFTER DELETE ON tasks FOR EACH ROW
+			INSERT INTO task_changes (task_id, op) VALUES (OLD.id, 'delete')`,
+	}
+	for name, statement := range triggers {
+		if installed[name] {
+			continue
+		}
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to create trigger %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // GetDB returns the underlying GORM database instance (for advanced operations)
 func (ms *MySQLStorage) GetDB() *gorm.DB {
 	return ms.db
 }
 
-// BeginTransaction starts a new database transaction
-func (ms *MySQLStorage) BeginTransaction() *gorm.DB {
-	return ms.db.Begin()
+// CreateMany implements Batcher with multi-row inserts in one transaction
+func (ms *MySQLStorage) CreateMany(tasks []*models.Task) error {
+	return ms.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(tasks, gormBatchRows).Error; err != nil {
+			return fmt.Errorf("failed to create tasks: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateMany implements Batcher in one transaction
+func (ms *MySQLStorage) UpdateMany(tasks []*models.Task) error {
+	return ms.db.Transaction(func(tx *gorm.DB) error {
+		store := &MySQLStorage{db: tx, logger: ms.logger}
+		for i, task := range tasks {
+			if err := store.Update(task); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteMany implements Batcher in one transaction
+func (ms *MySQLStorage) DeleteMany(ids []string) error {
+	return ms.db.Transaction(func(tx *gorm.DB) error {
+		store := &MySQLStorage{db: tx, logger: ms.logger}
+		for i, id := range ids {
+			if err := store.Delete(id); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// WithTx implements Transactor with a database transaction. Calls from
+// inside fn become savepoints of the outer transaction.
+func (ms *MySQLStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	return ms.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&MySQLStorage{db: tx, logger: ms.logger})
+	})
 }
 
 // Verify that MySQLStorage implements Storage interface
@@ -5,7 +5,6 @@ import (
 	"testing"
 	"time"
 
-	"GoTask_Management/internal/models"
 )
 
 func TestMySQLStorage(t *testing.T) {
@@ -208,13 +207,35 @@ func TestMySQLStorage_AdditionalMethods(t *testing.T) {
 	})
 
 	t.Run("Transaction", func(t *testing.T) {
-		tx := storage.BeginTransaction()
-		if tx == nil {
-			t.Error("Failed to begin transaction")
-		}
+		testTransactionCompliance(t, storage)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		testBatchCompliance(t, storage)
+	})
+
+	t.Run("Idempotency", func(t *testing.T) {
+		testIdempotencyCompliance(t, storage)
+	})
+
+	t.Run("Views", func(t *testing.T) {
+		testViewStoreCompliance(t, storage)
+	})
+
+	t.Run("Webhooks", func(t *testing.T) {
+		testWebhookStoreCompliance(t, storage)
+	})
+
+	t.Run("Notifications", func(t *testing.T) {
+		testNotificationStoreCompliance(t, storage)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		testSearchCompliance(t, storage)
+	})
 
-		// Test rollback
-		tx.Rollback()
+	t.Run("Find", func(t *testing.T) {
+		testFindCompliance(t, storage)
 	})
 
 	t.Run("UTF8Support", func(t *testing.T) {
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRecord records that the notification identified by Key, a key
+// chosen by the notify package, was sent or is being sent
+type NotificationRecord struct {
+	Key    string    `json:"key" gorm:"primaryKey;size:255" bson:"_id"`
+	SentAt time.Time `json:"sent_at" gorm:"not null;index" bson:"sent_at"`
+}
+
+// TableName keeps the SQL table name independent of the type name
+func (NotificationRecord) TableName() string {
+	return "notification_records"
+}
+
+// ErrNotificationClaimed is returned by ClaimNotification when a record
+// already holds the key
+var ErrNotificationClaimed = errors.New("notification already claimed")
+
+// NotificationStore is implemented by storages that can record sent
+// notifications next to the tasks, so that servers sharing the database send
+// each notification once
+type NotificationStore interface {
+	// ClaimNotification stores record, or returns ErrNotificationClaimed if
+	// a record already holds its key
+	ClaimNotification(record *NotificationRecord) error
+	// DeleteNotification removes the record for key, if any
+	DeleteNotification(key string) error
+	// DeleteNotificationsBefore removes records sent before the given time
+	// and returns how many were removed
+	DeleteNotificationsBefore(before time.Time) (int, error)
+}
+
+// AsNotificationStore returns the NotificationStore behind store, looking
+// through decorators that expose the storage they wrap
+func AsNotificationStore(store Storage) (NotificationStore, bool) {
+	for store != nil {
+		if s, ok := store.(NotificationStore); ok {
+			return s, true
+		}
+		unwrapper, ok := store.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		store = unwrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// MemoryNotificationStore keeps notification records in memory for
+// MemoryStorage; records are lost on restart
+type MemoryNotificationStore struct {
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// NewMemoryNotificationStore creates an empty MemoryNotificationStore
+func NewMemoryNotificationStore() *MemoryNotificationStore {
+	return &MemoryNotificationStore{sent: make(map[string]time.Time)}
+}
+
+// ClaimNotification implements NotificationStore
+func (s *MemoryNotificationStore) ClaimNotification(record *NotificationRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return claimNotification(s.sent, record)
+}
+
+// DeleteNotification implements NotificationStore
+func (s *MemoryNotificationStore) DeleteNotification(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sent, key)
+	return nil
+}
+
+// DeleteNotificationsBefore implements NotificationStore
+func (s *MemoryNotificationStore) DeleteNotificationsBefore(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return deleteNotificationsBefore(s.sent, before), nil
+}
+
+func claimNotification(sent map[string]time.Time, record *NotificationRecord) error {
+	if _, ok := sent[record.Key]; ok {
+		return ErrNotificationClaimed
+	}
+	sent[record.Key] = record.SentAt
+	return nil
+}
+
+func deleteNotificationsBefore(sent map[string]time.Time, before time.Time) int {
+	removed := 0
+	for key, at := range sent {
+		if at.Before(before) {
+			delete(sent, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// fileNotificationStore keeps notification records for the JSON and WAL
+// storages in a file next to the storage file, path+".notifications", under
+// the storage's file lock
+type fileNotificationStore struct {
+	notificationsPath string
+	notificationsLock *fileLock
+}
+
+func newFileNotificationStore(path string, timeout time.Duration) *fileNotificationStore {
+	return &fileNotificationStore{notificationsPath: path + ".notifications", notificationsLock: newFileLock(path, timeout)}
+}
+
+// ClaimNotification implements NotificationStore
+func (s *fileNotificationStore) ClaimNotification(record *NotificationRecord) error {
+	return s.updateNotifications(func(sent map[string]time.Time) error {
+		return claimNotification(sent, record)
+	})
+}
+
+// DeleteNotification implements NotificationStore
+func (s *fileNotificationStore) DeleteNotification(key string) error {
+	return s.updateNotifications(func(sent map[string]time.Time) error {
+		delete(sent, key)
+		return nil
+	})
+}
+
+// DeleteNotificationsBefore implements NotificationStore
+func (s *fileNotificationStore) DeleteNotificationsBefore(before time.Time) (int, error) {
+	removed := 0
+	err := s.updateNotifications(func(sent map[string]time.Time) error {
+		removed = deleteNotificationsBefore(sent, before)
+		return nil
+	})
+	return removed, err
+}
+
+// updateNotifications runs fn on the records under the exclusive file lock
+// and saves them unless fn fails
+func (s *fileNotificationStore) updateNotifications(fn func(sent map[string]time.Time) error) error {
+	unlock, err := s.notificationsLock.acquire(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	sent := make(map[string]time.Time)
+	if err := readSidecar(s.notificationsPath, &sent); err != nil {
+		return fmt.Errorf("failed to read notifications: %w", err)
+	}
+	if err := fn(sent); err != nil {
+		return err
+	}
+	if err := writeSidecar(s.notificationsPath, sent, 0644); err != nil {
+		return fmt.Errorf("failed to save notifications: %w", err)
+	}
+	return nil
+}
+
+// The gorm helpers below implement NotificationStore for PostgreSQL and MySQL
+
+func claimGormNotification(db *gorm.DB, record *NotificationRecord) error {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return fmt.Errorf("failed to claim notification: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotificationClaimed
+	}
+	return nil
+}
+
+func deleteGormNotification(db *gorm.DB, key string) error {
+	if err := db.Delete(&NotificationRecord{Key: key}).Error; err != nil {
+		return fmt.Errorf("failed to delete notification: %w", err)
+	}
+	return nil
+}
+
+func deleteGormNotificationsBefore(db *gorm.DB, before time.Time) (int, error) {
+	result := db.Where("sent_at < ?", before).Delete(&NotificationRecord{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete notifications: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/metrics"
+)
+
+func TestNotificationStore(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	t.Run("memory", func(t *testing.T) {
+		testNotificationStoreCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		store, err := NewJSONStorage(helper.TempFilePath("notifications.json"))
+		helper.AssertNoError(err, "creating JSON storage")
+		testNotificationStoreCompliance(t, store)
+	})
+
+	t.Run("wal", func(t *testing.T) {
+		store, err := NewWALStorage(WALConfig{Path: helper.TempFilePath("notifications_wal.json")})
+		helper.AssertNoError(err, "creating WAL storage")
+		defer store.Close()
+		testNotificationStoreCompliance(t, store)
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStorage(BoltConfig{Path: helper.TempFilePath("notifications.db")})
+		helper.AssertNoError(err, "creating Bolt storage")
+		defer store.Close()
+		testNotificationStoreCompliance(t, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("notifications.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+		testNotificationStoreCompliance(t, store)
+	})
+
+	t.Run("found through decorators", func(t *testing.T) {
+		memory := NewMemoryStorage()
+		var store Storage = NewCachedStorage(memory, CacheConfig{})
+		store = NewInstrumentedStorage(store, metrics.NewRegistry())
+		store = NewTracingStorage(store, nil)
+
+		found, ok := AsNotificationStore(store)
+		if !ok || found != NotificationStore(memory) {
+			t.Fatalf("Expected the wrapped MemoryStorage, got %T", found)
+		}
+	})
+
+	t.Run("shared by file storages on the same file", func(t *testing.T) {
+		path := helper.TempFilePath("notifications_shared.json")
+		first, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+		second, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+
+		record := &NotificationRecord{Key: "shared", SentAt: time.Now()}
+		helper.AssertNoError(first.ClaimNotification(record), "claiming notification")
+		if err := second.ClaimNotification(record); !errors.Is(err, ErrNotificationClaimed) {
+			t.Errorf("Expected the other storage to see the claim, got %v", err)
+		}
+	})
+}
+
+// testNotificationStoreCompliance checks that a NotificationStore claims
+// each key once, releases deleted keys and prunes old records
+func testNotificationStoreCompliance(t *testing.T, store NotificationStore) {
+	// Keys are unique per run, for databases that outlive the test
+	prefix := fmt.Sprintf("notify_%d_", time.Now().UnixNano())
+	now := time.Now().Truncate(time.Millisecond)
+
+	sent := &NotificationRecord{Key: prefix + "sent", SentAt: now}
+	if err := store.ClaimNotification(sent); err != nil {
+		t.Fatalf("Failed to claim notification: %v", err)
+	}
+	if err := store.ClaimNotification(&NotificationRecord{Key: sent.Key, SentAt: now}); !errors.Is(err, ErrNotificationClaimed) {
+		t.Errorf("Expected ErrNotificationClaimed for a claimed key, got %v", err)
+	}
+
+	if err := store.DeleteNotification(sent.Key); err != nil {
+		t.Fatalf("Failed to delete notification: %v", err)
+	}
+	if err := store.ClaimNotification(sent); err != nil {
+		t.Errorf("Expected a deleted key to be claimable again, got %v", err)
+	}
+	if err := store.DeleteNotification(prefix + "missing"); err != nil {
+		t.Errorf("Expected deleting a missing key to succeed, got %v", err)
+	}
+
+	old := &NotificationRecord{Key: prefix + "old", SentAt: now.Add(-48 * time.Hour)}
+	if err := store.ClaimNotification(old); err != nil {
+		t.Fatalf("Failed to claim notification: %v", err)
+	}
+	removed, err := store.DeleteNotificationsBefore(now.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to delete old notifications: %v", err)
+	}
+	if removed < 1 {
+		t.Errorf("Expected the old record to be removed, got %d removed", removed)
+	}
+	if err := store.ClaimNotification(old); err != nil {
+		t.Errorf("Expected a pruned key to be claimable again, got %v", err)
+	}
+	if err := store.ClaimNotification(sent); !errors.Is(err, ErrNotificationClaimed) {
+		t.Errorf("Expected a recent record to survive pruning, got %v", err)
+	}
+
+	for _, key := range []string{sent.Key, old.Key} {
+		if err := store.DeleteNotification(key); err != nil {
+			t.Fatalf("Failed to delete notification: %v", err)
+		}
+	}
+}
@@ -1,20 +1,28 @@
 package storage
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
 
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 // PostgreSQLStorage implements the Storage interface using PostgreSQL with GORM
 type PostgreSQLStorage struct {
-	db *gorm.DB
+	db     *gorm.DB
+	logger *slog.Logger
 }
 
 // PostgreSQLConfig holds the configuration for PostgreSQL connection
@@ -26,15 +34,17 @@ type PostgreSQLConfig struct {
 	DBName   string
 	SSLMode  string
 	TimeZone string
+	Logger   *slog.Logger
 }
 
 // NewPostgreSQLStorage creates a new PostgreSQL storage instance
 func NewPostgreSQLStorage(config PostgreSQLConfig) (*PostgreSQLStorage, error) {
+	logger := loggerOrDefault(config.Logger)
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
 		config.Host, config.User, config.Password, config.DBName, config.Port, config.SSLMode, config.TimeZone)
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: newGormLogger(logger),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
@@ -50,20 +60,24 @@ func NewPostgreSQLStorage(config PostgreSQLConfig) (*PostgreSQLStorage, error) {
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 
-	storage := &PostgreSQLStorage{db: db}
+	storage := &PostgreSQLStorage{db: db, logger: logger}
 
 	// Auto-migrate the schema
 	if err := storage.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	log.Println("✅ PostgreSQL storage initialized successfully")
+	logger.Info("PostgreSQL storage initialized", "host", config.Host, "database", config.DBName)
 	return storage, nil
 }
 
 // migrate runs database migrations
 func (ps *PostgreSQLStorage) migrate() error {
-	return ps.db.AutoMigrate(&models.Task{})
+	if err := ps.db.AutoMigrate(&models.Task{}, &IdempotencyRecord{}, &ViewRecord{}, &WebhookSubscriptionRecord{}, &WebhookDeliveryRecord{}, &NotificationRecord{}); err != nil {
+		return err
+	}
+	// Search matches against this expression, so the index serves it without a stored column
+	return ps.db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_title_search ON tasks USING GIN (to_tsvector('english', title))`).Error
 }
 
 // Create implements Storage interface
@@ -119,6 +133,16 @@ func (ps *PostgreSQLStorage) Delete(id string) error {
 	return nil
 }
 
+// UpdateIfVersion implements Versioner with a conditional UPDATE
+func (ps *PostgreSQLStorage) UpdateIfVersion(task *models.Task, expected int64) error {
+	return updateGormTaskIfVersion(ps, ps.db, task, expected)
+}
+
+// DeleteIfVersion implements Versioner with a conditional DELETE
+func (ps *PostgreSQLStorage) DeleteIfVersion(id string, expected int64) error {
+	return deleteGormTaskIfVersion(ps, ps.db, id, expected)
+}
+
 // Close implements Storage interface
 func (ps *PostgreSQLStorage) Close() error {
 	sqlDB, err := ps.db.DB()
@@ -177,6 +201,124 @@ func (ps *PostgreSQLStorage) GetOverdueTasksCount() (int64, error) {
 	return count, nil
 }
 
+// Search implements Searcher with English text search, ranking by ts_rank
+// and highlighting with ts_headline. Query words are stemmed, and matched as
+// prefixes when long enough.
+func (ps *PostgreSQLStorage) Search(query string, limit int) ([]SearchResult, error) {
+	var terms []string
+	for _, term := range searchTerms(query) {
+		if utf8.RuneCountInString(term) >= minPrefixLength {
+			term += ":*"
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var rows []gormSearchRow
+	err := ps.db.Raw(`SELECT tasks.*, ts_rank(to_tsvector('english', title), q) AS score,
+			ts_headline('english', title, q, 'HighlightAll=true, StartSel=' || chr(2) || ', StopSel=' || chr(3)) AS highlight
+		FROM tasks, to_tsquery('english', ?) q
+		WHERE to_tsvector('english', title) @@ q
+		ORDER BY score DESC, created_at DESC, id
+		LIMIT ?`, strings.Join(terms, " | "), limit).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tasks: %w", err)
+	}
+	return searchResults(rows, query), nil
+}
+
+// Find implements Finder with a WHERE clause
+func (ps *PostgreSQLStorage) Find(filter *query.Filter) ([]*models.Task, error) {
+	return findGormTasks(ps.db, filter)
+}
+
+// CreateIdempotencyRecord implements IdempotencyStore
+func (ps *PostgreSQLStorage) CreateIdempotencyRecord(record *IdempotencyRecord) error {
+	return createGormIdempotencyRecord(ps.db, record)
+}
+
+// GetIdempotencyRecord implements IdempotencyStore
+func (ps *PostgreSQLStorage) GetIdempotencyRecord(key string) (*IdempotencyRecord, error) {
+	return getGormIdempotencyRecord(ps.db, key)
+}
+
+// SaveIdempotencyRecord implements IdempotencyStore
+func (ps *PostgreSQLStorage) SaveIdempotencyRecord(record *IdempotencyRecord) error {
+	return saveGormIdempotencyRecord(ps.db, record)
+}
+
+// DeleteIdempotencyRecord implements IdempotencyStore
+func (ps *PostgreSQLStorage) DeleteIdempotencyRecord(key string) error {
+	return deleteGormIdempotencyRecord(ps.db, key)
+}
+
+// DeleteExpiredIdempotencyRecords implements IdempotencyStore
+func (ps *PostgreSQLStorage) DeleteExpiredIdempotencyRecords(now time.Time) (int, error) {
+	return deleteExpiredGormIdempotencyRecords(ps.db, now)
+}
+
+// ListViews implements ViewStore
+func (ps *PostgreSQLStorage) ListViews() ([]*ViewRecord, error) {
+	return listGormViews(ps.db)
+}
+
+// SaveView implements ViewStore
+func (ps *PostgreSQLStorage) SaveView(record *ViewRecord) error {
+	return saveGormView(ps.db, record)
+}
+
+// DeleteView implements ViewStore
+func (ps *PostgreSQLStorage) DeleteView(id string) error {
+	return deleteGormView(ps.db, id)
+}
+
+// ListWebhookSubscriptions implements WebhookStore
+func (ps *PostgreSQLStorage) ListWebhookSubscriptions() ([]*WebhookSubscriptionRecord, error) {
+	return listGormWebhookSubscriptions(ps.db)
+}
+
+// SaveWebhookSubscription implements WebhookStore
+func (ps *PostgreSQLStorage) SaveWebhookSubscription(record *WebhookSubscriptionRecord) error {
+	return saveGormWebhookSubscription(ps.db, record)
+}
+
+// DeleteWebhookSubscription implements WebhookStore
+func (ps *PostgreSQLStorage) DeleteWebhookSubscription(id string) error {
+	return deleteGormWebhookSubscription(ps.db, id)
+}
+
+// ListWebhookDeliveries implements WebhookStore
+func (ps *PostgreSQLStorage) ListWebhookDeliveries(subscriptionID string) ([]*WebhookDeliveryRecord, error) {
+	return listGormWebhookDeliveries(ps.db, subscriptionID)
+}
+
+// SaveWebhookDelivery implements WebhookStore
+func (ps *PostgreSQLStorage) SaveWebhookDelivery(record *WebhookDeliveryRecord) error {
+	return saveGormWebhookDelivery(ps.db, record)
+}
+
+// DeleteWebhookDeliveries implements WebhookStore
+func (ps *PostgreSQLStorage) DeleteWebhookDeliveries(ids []string) error {
+	return deleteGormWebhookDeliveries(ps.db, ids)
+}
+
+// ClaimNotification implements NotificationStore
+func (ps *PostgreSQLStorage) ClaimNotification(record *NotificationRecord) error {
+	return claimGormNotification(ps.db, record)
+}
+
+// DeleteNotification implements NotificationStore
+func (ps *PostgreSQLStorage) DeleteNotification(key string) error {
+	return deleteGormNotification(ps.db, key)
+}
+
+// DeleteNotificationsBefore implements NotificationStore
+func (ps *PostgreSQLStorage) DeleteNotificationsBefore(before time.Time) (int, error) {
+	return deleteGormNotificationsBefore(ps.db, before)
+}
+
 // HealthCheck performs a health check on the database connection
 func (ps *PostgreSQLStorage) HealthCheck() error {
 	sqlDB, err := ps.db.DB()
@@ -186,14 +328,177 @@ func (ps *PostgreSQLStorage) HealthCheck() error {
 	return sqlDB.Ping()
 }
 
+// postgresWatchChannel is the notification channel task triggers publish to
+const postgresWatchChannel = "gotask_task_changes"
+
+// Watch reports changes made by any client of the database, listening for
+// notifications that a trigger on the tasks table sends. Changes made while
+// the listening connection is being re-established are missed.
+func (ps *PostgreSQLStorage) Watch(ctx context.Context) (<-chan Change, error) {
+	if err := ps.setupNotifications(ctx); err != nil {
+		return nil, err
+	}
+	conn, err := ps.listenConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan Change)
+	go func() {
+		defer close(changes)
+		for {
+			err := ps.receive(ctx, conn, changes)
+			conn.Close()
+			if ctx.Err() != nil {
+				return
+			}
+			ps.logger.Warn("lost task change notifications, reconnecting", "error", err)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(watchRetryDelay):
+				}
+				if conn, err = ps.listenConn(ctx); err == nil {
+					break
+				}
+				ps.logger.Warn("failed to listen for task changes", "error", err)
+			}
+		}
+	}()
+	return changes, nil
+}
+
+func (ps *PostgreSQLStorage) setupNotifications(ctx context.Context) error {
+	err := ps.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`CREATE OR REPLACE FUNCTION gotask_notify_task_change() RETURNS trigger AS $$
+		BEGIN
+			IF TG_OP = 'DELETE' THEN
+				PERFORM pg_notify('` + postgresWatchChannel + `', json_build_object('op', 'delete', 'id', OLD.id)::text);
+				RETURN OLD;
+			END IF;
+			PERFORM pg_notify('` + postgresWatchChannel + `', json_build_object(
+				'op', CASE TG_OP WHEN 'INSERT' THEN 'create' ELSE 'update' END, 'id', NEW.id)::text);
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql`).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec(`DROP TRIGGER IF EXISTS tasks_watch ON tasks`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`CREATE TRIGGER tasks_watch AFTER INSERT OR UPDATE OR DELETE ON tasks
+			FOR EACH ROW EXECUTE FUNCTION gotask_notify_task_change()`).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to install change trigger: %w", err)
+	}
+	return nil
+}
+
+// listenConn takes a connection out of the pool and subscribes it to task notifications
+func (ps *PostgreSQLStorage) listenConn(ctx context.Context) (*sql.Conn, error) {
+	sqlDB, err := ps.db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a connection: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "LISTEN "+postgresWatchChannel); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to listen for task changes: %w", err)
+	}
+	return conn, nil
+}
+
+// receive forwards notifications from conn until ctx is done or the
+// connection fails. The connection is always discarded afterwards, since it
+// is still listening.
+func (ps *PostgreSQLStorage) receive(ctx context.Context, conn *sql.Conn, changes chan<- Change) error {
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("%w: unexpected connection type %T", driver.ErrBadConn, driverConn)
+		}
+		for {
+			notification, err := stdlibConn.Conn().WaitForNotification(ctx)
+			if err != nil {
+				return fmt.Errorf("%w: %v", driver.ErrBadConn, err)
+			}
+
+			var payload struct {
+				Op ChangeOp `json:"op"`
+				ID string   `json:"id"`
+			}
+			if err := json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+				ps.logger.Warn("ignoring malformed task notification", "payload", notification.Payload)
+				continue
+			}
+			change := Change{Op: payload.Op, TaskID: payload.ID}
+			if payload.Op != ChangeDelete {
+				if change.Task, err = ps.GetByID(payload.ID); err != nil {
+					// Deleted since; its own notification follows
+					continue
+				}
+			}
+			if !sendChange(ctx, changes, change) {
+				return driver.ErrBadConn
+			}
+		}
+	})
+}
+
 // GetDB returns the underlying GORM database instance (for advanced operations)
 func (ps *PostgreSQLStorage) GetDB() *gorm.DB {
 	return ps.db
 }
 
-// BeginTransaction starts a new database transaction
-func (ps *PostgreSQLStorage) BeginTransaction() *gorm.DB {
-	return ps.db.Begin()
+// CreateMany implements Batcher with multi-row inserts in one transaction
+func (ps *PostgreSQLStorage) CreateMany(tasks []*models.Task) error {
+	return ps.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(tasks, gormBatchRows).Error; err != nil {
+			return fmt.Errorf("failed to create tasks: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateMany implements Batcher in one transaction
+func (ps *PostgreSQLStorage) UpdateMany(tasks []*models.Task) error {
+	return ps.db.Transaction(func(tx *gorm.DB) error {
+		store := &PostgreSQLStorage{db: tx, logger: ps.logger}
+		for i, task := range tasks {
+			if err := store.Update(task); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteMany implements Batcher in one transaction
+func (ps *PostgreSQLStorage) DeleteMany(ids []string) error {
+	return ps.db.Transaction(func(tx *gorm.DB) error {
+		store := &PostgreSQLStorage{db: tx, logger: ps.logger}
+		for i, id := range ids {
+			if err := store.Delete(id); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// WithTx implements Transactor with a database transaction. Calls from
+// inside fn become savepoints of the outer transaction.
+func (ps *PostgreSQLStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	return ps.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&PostgreSQLStorage{db: tx, logger: ps.logger})
+	})
 }
 
 // Verify that PostgreSQLStorage implements Storage interface
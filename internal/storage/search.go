@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"GoTask_Management/internal/models"
+)
+
+const (
+	// DefaultSearchLimit is how many results Search returns when limit is not positive
+	DefaultSearchLimit = 20
+
+	// minPrefixLength is the shortest query term that also matches longer
+	// words starting with it, so that "doc" finds "documentation"
+	minPrefixLength = 3
+	// prefixWeight discounts prefix matches against whole-word matches
+	prefixWeight = 0.5
+
+	// BM25 parameters: term frequency saturation and title length normalisation
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// highlightStart and highlightEnd delimit matches in highlighted text
+	// returned by databases, before it is turned into TextRanges
+	highlightStart = "\x02"
+	highlightEnd   = "\x03"
+)
+
+// TextRange is a byte range [Start, End) of a string
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SearchResult is a task whose title matched a search
+type SearchResult struct {
+	Task *models.Task
+	// Score orders results by relevance, higher first. Scores depend on the
+	// backend and compare only within one search.
+	Score float64
+	// Matches are the parts of Task.Title that matched, in order
+	Matches []TextRange
+}
+
+// Highlight returns the title with each match between open and close. When
+// escape is not nil, it is applied to the text outside the markers, e.g. to
+// escape HTML.
+func (r SearchResult) Highlight(open, close string, escape func(string) string) string {
+	if escape == nil {
+		escape = func(s string) string { return s }
+	}
+	title := r.Task.Title
+	var b strings.Builder
+	last := 0
+	for _, m := range r.Matches {
+		if m.Start < last || m.End > len(title) || m.Start >= m.End {
+			continue
+		}
+		b.WriteString(escape(title[last:m.Start]))
+		b.WriteString(open)
+		b.WriteString(escape(title[m.Start:m.End]))
+		b.WriteString(close)
+		last = m.End
+	}
+	b.WriteString(escape(title[last:]))
+	return b.String()
+}
+
+// Searcher is implemented by storages with a native full-text search on
+// task titles
+type Searcher interface {
+	// Search returns up to limit tasks matching any word of query, most
+	// relevant first
+	Search(query string, limit int) ([]SearchResult, error)
+}
+
+// Search returns up to limit tasks whose titles match any word of query,
+// most relevant first. Searchers search natively; other storages are
+// searched with an inverted index built from GetAll for this one query.
+func Search(store Storage, query string, limit int) ([]SearchResult, error) {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if len(searchTerms(query)) == 0 {
+		return nil, nil
+	}
+	if searcher, ok := store.(Searcher); ok {
+		return searcher.Search(query, limit)
+	}
+	tasks, err := store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return newSearchIndex(tasks).search(query, limit), nil
+}
+
+// word is a word of a text with its byte range
+type word struct {
+	text string // lower case
+	TextRange
+}
+
+// tokenize splits text into lower case words of letters and digits
+func tokenize(text string) []word {
+	var words []word
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			words = append(words, word{strings.ToLower(text[start:i]), TextRange{start, i}})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, word{strings.ToLower(text[start:]), TextRange{start, len(text)}})
+	}
+	return words
+}
+
+// searchTerms returns the distinct words of query, in order
+func searchTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, w := range tokenize(query) {
+		if !seen[w.text] {
+			seen[w.text] = true
+			terms = append(terms, w.text)
+		}
+	}
+	return terms
+}
+
+// termWeight is how well word matches term: 1 for the same word,
+// prefixWeight when term is a long enough prefix of it, and 0 otherwise
+func termWeight(word, term string) float64 {
+	switch {
+	case word == term:
+		return 1
+	case utf8.RuneCountInString(term) >= minPrefixLength && strings.HasPrefix(word, term):
+		return prefixWeight
+	}
+	return 0
+}
+
+// matchRanges returns the words of title that match any of terms
+func matchRanges(title string, terms []string) []TextRange {
+	var ranges []TextRange
+	for _, w := range tokenize(title) {
+		for _, term := range terms {
+			if termWeight(w.text, term) > 0 {
+				ranges = append(ranges, w.TextRange)
+				break
+			}
+		}
+	}
+	return ranges
+}
+
+// parseHighlighted turns text with matches between highlightStart and
+// highlightEnd, as returned by a database, into ranges of title. It returns
+// false when the text is not title with markers added.
+func parseHighlighted(title, highlighted string) ([]TextRange, bool) {
+	var ranges []TextRange
+	var plain strings.Builder
+	start := -1
+	for i := 0; i < len(highlighted); i++ {
+		switch highlighted[i] {
+		case highlightStart[0]:
+			start = plain.Len()
+		case highlightEnd[0]:
+			if start >= 0 && plain.Len() > start {
+				ranges = append(ranges, TextRange{start, plain.Len()})
+			}
+			start = -1
+		default:
+			plain.WriteByte(highlighted[i])
+		}
+	}
+	return ranges, plain.String() == title
+}
+
+// highlightMatches returns the ranges of title marked in highlighted, or
+// falls back to matching query's words when the database changed the text
+func highlightMatches(title, highlighted, query string) []TextRange {
+	if ranges, ok := parseHighlighted(title, highlighted); ok && len(ranges) > 0 {
+		return ranges
+	}
+	return matchRanges(title, searchTerms(query))
+}
+
+// searchIndex is an inverted index over task titles, ranking with BM25
+type searchIndex struct {
+	tasks []*models.Task
+	// postings maps each word to the tasks containing it and how often
+	postings map[string]map[int]int
+	// words holds the keys of postings in order, for prefix lookups
+	words     []string
+	lengths   []int
+	avgLength float64
+}
+
+func newSearchIndex(tasks []*models.Task) *searchIndex {
+	idx := &searchIndex{
+		tasks:    tasks,
+		postings: make(map[string]map[int]int),
+		lengths:  make([]int, len(tasks)),
+	}
+	total := 0
+	for i, task := range tasks {
+		words := tokenize(task.Title)
+		idx.lengths[i] = len(words)
+		total += len(words)
+		for _, w := range words {
+			if idx.postings[w.text] == nil {
+				idx.postings[w.text] = make(map[int]int)
+				idx.words = append(idx.words, w.text)
+			}
+			idx.postings[w.text][i]++
+		}
+	}
+	sort.Strings(idx.words)
+	if len(tasks) > 0 {
+		idx.avgLength = float64(total) / float64(len(tasks))
+	}
+	return idx
+}
+
+// search ranks the tasks matching any word of query and returns up to limit
+// of them, as copies
+func (idx *searchIndex) search(query string, limit int) []SearchResult {
+	terms := searchTerms(query)
+	scores := make(map[int]float64)
+	for _, term := range terms {
+		// Weighted frequency of the term in each task, counting words it prefixes
+		freqs := make(map[int]float64)
+		for i := sort.SearchStrings(idx.words, term); i < len(idx.words); i++ {
+			weight := termWeight(idx.words[i], term)
+			if weight == 0 {
+				break
+			}
+			for doc, n := range idx.postings[idx.words[i]] {
+				freqs[doc] += weight * float64(n)
+			}
+		}
+
+		n := float64(len(idx.tasks))
+		idf := math.Log(1 + (n-float64(len(freqs))+0.5)/(float64(len(freqs))+0.5))
+		for doc, tf := range freqs {
+			norm := 1 - bm25B + bm25B*float64(idx.lengths[doc])/idx.avgLength
+			scores[doc] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for doc, score := range scores {
+		task := idx.tasks[doc]
+		results = append(results, SearchResult{Task: task, Score: score, Matches: matchRanges(task.Title, terms)})
+	}
+	sortSearchResults(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	for i := range results {
+		results[i].Task = copyTask(results[i].Task)
+	}
+	return results
+}
+
+// gormSearchRow is a task with the relevance and highlighted title computed
+// by a SQL search
+type gormSearchRow struct {
+	models.Task `gorm:"embedded"`
+	Score       float64
+	Highlight   string
+}
+
+// searchResults converts rows of a SQL search into results
+func searchResults(rows []gormSearchRow, query string) []SearchResult {
+	results := make([]SearchResult, len(rows))
+	for i := range rows {
+		task := rows[i].Task
+		results[i] = SearchResult{Task: &task, Score: rows[i].Score, Matches: highlightMatches(task.Title, rows[i].Highlight, query)}
+	}
+	return results
+}
+
+// sortSearchResults orders results by score, then newest first, then by ID
+func sortSearchResults(results []SearchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.Task.CreatedAt.Equal(b.Task.CreatedAt) {
+			return a.Task.CreatedAt.After(b.Task.CreatedAt)
+		}
+		return a.Task.ID < b.Task.ID
+	})
+}
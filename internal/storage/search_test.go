@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"html"
+	"testing"
+
+	"GoTask_Management/internal/metrics"
+	"GoTask_Management/internal/models"
+)
+
+func TestSearch(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	jsonStorage := func(name string) *JSONStorage {
+		store, err := NewJSONStorage(helper.TempFilePath(name))
+		helper.AssertNoError(err, "creating JSON storage")
+		return store
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		testSearchCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		testSearchCompliance(t, jsonStorage("search.json"))
+	})
+
+	t.Run("wal", func(t *testing.T) {
+		store, err := NewWALStorage(WALConfig{Path: helper.TempFilePath("search_wal.json")})
+		helper.AssertNoError(err, "creating WAL storage")
+		defer store.Close()
+		testSearchCompliance(t, store)
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStorage(BoltConfig{Path: helper.TempFilePath("search.db")})
+		helper.AssertNoError(err, "creating Bolt storage")
+		defer store.Close()
+		testSearchCompliance(t, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("search.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+		testSearchCompliance(t, store)
+	})
+
+	t.Run("decorators", func(t *testing.T) {
+		var store Storage = jsonStorage("search_decorated.json")
+		store = NewCachedStorage(store, CacheConfig{})
+		store = NewInstrumentedStorage(store, metrics.NewRegistry())
+		store = NewTracingStorage(store, nil)
+		testSearchCompliance(t, store)
+	})
+
+	t.Run("prefixes match longer words", func(t *testing.T) {
+		sqlite, err := NewSQLiteStorage(helper.TempFilePath("search_prefix.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer sqlite.Close()
+
+		for name, store := range map[string]Storage{"memory": NewMemoryStorage(), "sqlite": sqlite} {
+			helper.AssertNoError(store.Create(helper.CreateSampleTask("p1", "Update documentation")), "creating task")
+			helper.AssertNoError(store.Create(helper.CreateSampleTask("p2", "Review the docs")), "creating task")
+			helper.AssertNoError(store.Create(helper.CreateSampleTask("p3", "Do laundry")), "creating task")
+
+			results, err := Search(store, "docs", 10)
+			if err != nil || len(results) != 1 || results[0].Task.ID != "p2" {
+				t.Errorf("%s: expected only the whole word to match docs, got %+v, %v", name, results, err)
+			}
+			results, err = Search(store, "doc", 10)
+			if err != nil || len(results) != 2 {
+				t.Fatalf("%s: expected doc to match two tasks, got %+v, %v", name, results, err)
+			}
+			if got := results[0].Highlight("[", "]", nil); got != "Update [documentation]" && got != "Review the [docs]" {
+				t.Errorf("%s: unexpected highlight %q", name, got)
+			}
+			if results, _ := Search(store, "do", 10); len(results) != 1 || results[0].Task.ID != "p3" {
+				t.Errorf("%s: expected short terms to match whole words only, got %+v", name, results)
+			}
+		}
+	})
+
+	t.Run("json index follows other processes", func(t *testing.T) {
+		path := helper.TempFilePath("search_shared.json")
+		first, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+		second, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+
+		helper.AssertNoError(first.Create(helper.CreateSampleTask("s1", "Shared notebook")), "creating task")
+		if results, _ := Search(second, "notebook", 10); len(results) != 1 {
+			t.Fatalf("Expected one result, got %d", len(results))
+		}
+		helper.AssertNoError(first.Create(helper.CreateSampleTask("s2", "Another notebook")), "creating task")
+		if results, _ := Search(second, "notebook", 10); len(results) != 2 {
+			t.Errorf("Expected the index to be rebuilt after an external write, got %d results", len(results))
+		}
+	})
+}
+
+func TestSearchResult_Highlight(t *testing.T) {
+	result := SearchResult{
+		Task:    &models.Task{Title: "Fix <b>bold</b> & café"},
+		Matches: matchRanges("Fix <b>bold</b> & café", searchTerms("BOLD Café")),
+	}
+	if got := result.Highlight("<mark>", "</mark>", html.EscapeString); got != "Fix &lt;b&gt;<mark>bold</mark>&lt;/b&gt; &amp; <mark>café</mark>" {
+		t.Errorf("Unexpected highlight %q", got)
+	}
+
+	ranges, ok := parseHighlighted("Ship the release", "Ship the \x02release\x03")
+	if !ok || len(ranges) != 1 || ranges[0] != (TextRange{9, 16}) {
+		t.Errorf("Expected the marked word, got %v, %v", ranges, ok)
+	}
+	if _, ok := parseHighlighted("Ship the release", "Ship \x02release\x03"); ok {
+		t.Error("Expected changed text to be rejected")
+	}
+}
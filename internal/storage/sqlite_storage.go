@@ -1,15 +1,25 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
 
 	_ "modernc.org/sqlite"
 )
 
 type SQLiteStorage struct {
 	db *sql.DB
+	// tx is the transaction operations join, inside WithTx
+	tx *sql.Tx
+	// pollInterval overrides how often Watch polls for changes
+	pollInterval time.Duration
 }
 
 func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
@@ -25,25 +35,150 @@ func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
         title TEXT NOT NULL,
         done BOOLEAN DEFAULT 0,
         created_at DATETIME NOT NULL,
-        due_date DATETIME
+        due_date DATETIME,
+        completed_at DATETIME,
+        version INTEGER NOT NULL DEFAULT 0
     );`
 
 	if _, err := db.Exec(createTableSQL); err != nil {
 		return nil, err
 	}
 
+	if err := migrateSQLite(db); err != nil {
+		return nil, err
+	}
+
+	// Times are Unix nanoseconds so that expiry compares numerically
+	if _, err := db.Exec(`
+    CREATE TABLE IF NOT EXISTS idempotency_records (
+        key TEXT PRIMARY KEY,
+        request_hash TEXT NOT NULL,
+        status_code INTEGER NOT NULL DEFAULT 0,
+        content_type TEXT NOT NULL DEFAULT '',
+        body BLOB,
+        created_at INTEGER NOT NULL,
+        expires_at INTEGER NOT NULL
+    );`); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(`
+    CREATE TABLE IF NOT EXISTS saved_views (
+        id TEXT PRIMARY KEY,
+        data BLOB NOT NULL,
+        created_at INTEGER NOT NULL
+    );`); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(`
+    CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+        id TEXT PRIMARY KEY,
+        data BLOB NOT NULL,
+        created_at INTEGER NOT NULL
+    );
+    CREATE TABLE IF NOT EXISTS webhook_deliveries (
+        id TEXT PRIMARY KEY,
+        subscription_id TEXT NOT NULL,
+        pending BOOLEAN NOT NULL,
+        data BLOB NOT NULL,
+        created_at INTEGER NOT NULL
+    );
+    CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries(subscription_id);`); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(`
+    CREATE TABLE IF NOT EXISTS notification_records (
+        key TEXT PRIMARY KEY,
+        sent_at INTEGER NOT NULL
+    );
+    CREATE INDEX IF NOT EXISTS idx_notification_records_sent_at ON notification_records(sent_at);`); err != nil {
+		return nil, err
+	}
+
+	if err := setupSQLiteSearch(db); err != nil {
+		return nil, err
+	}
+
 	return &SQLiteStorage{db: db}, nil
 }
 
+// setupSQLiteSearch creates the FTS5 index on task titles, with triggers
+// that keep it in step with the tasks table, and fills it when it is new.
+// The index is keyed by task ID because rowids of a table without an
+// integer primary key may change on VACUUM.
+func setupSQLiteSearch(db *sql.DB) error {
+	var exists int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'tasks_fts'`).Scan(&exists); err != nil {
+		return err
+	}
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(id UNINDEXED, title, tokenize = 'unicode61 remove_diacritics 2')`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+			INSERT INTO tasks_fts (id, title) VALUES (NEW.id, NEW.title);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF title ON tasks BEGIN
+			UPDATE tasks_fts SET title = NEW.title WHERE id = OLD.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+			DELETE FROM tasks_fts WHERE id = OLD.id;
+		END`,
+	}
+	if exists == 0 {
+		statements = append(statements, `INSERT INTO tasks_fts (id, title) SELECT id, title FROM tasks`)
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateSQLite adds columns introduced after the table was first created
+func migrateSQLite(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('tasks')`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	if !columns["completed_at"] {
+		if _, err := db.Exec(`ALTER TABLE tasks ADD COLUMN completed_at DATETIME`); err != nil {
+			return err
+		}
+	}
+	if !columns["version"] {
+		if _, err := db.Exec(`ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *SQLiteStorage) Create(task *models.Task) error {
-	query := `INSERT INTO tasks (id, title, done, created_at, due_date) VALUES (?, ?, ?, ?, ?)`
-	_, err := s.db.Exec(query, task.ID, task.Title, task.Done, task.CreatedAt, task.DueDate)
+	query := `INSERT INTO tasks (id, title, done, created_at, due_date, completed_at, version) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.conn().Exec(query, task.ID, task.Title, task.Done, task.CreatedAt, task.DueDate, task.CompletedAt, task.Version)
 	return err
 }
 
 func (s *SQLiteStorage) GetAll() ([]*models.Task, error) {
-	query := `SELECT id, title, done, created_at, due_date FROM tasks`
-	rows, err := s.db.Query(query)
+	query := `SELECT id, title, done, created_at, due_date, completed_at, version FROM tasks`
+	rows, err := s.conn().Query(query)
 	if err != nil {
 		return nil, err
 	}
@@ -57,9 +192,9 @@ func (s *SQLiteStorage) GetAll() ([]*models.Task, error) {
 	var tasks []*models.Task
 	for rows.Next() {
 		task := &models.Task{}
-		var dueDate sql.NullTime
+		var dueDate, completedAt sql.NullTime
 
-		err := rows.Scan(&task.ID, &task.Title, &task.Done, &task.CreatedAt, &dueDate)
+		err := rows.Scan(&task.ID, &task.Title, &task.Done, &task.CreatedAt, &dueDate, &completedAt, &task.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -67,6 +202,9 @@ func (s *SQLiteStorage) GetAll() ([]*models.Task, error) {
 		if dueDate.Valid {
 			task.DueDate = &dueDate.Time
 		}
+		if completedAt.Valid {
+			task.CompletedAt = &completedAt.Time
+		}
 
 		tasks = append(tasks, task)
 	}
@@ -75,13 +213,13 @@ func (s *SQLiteStorage) GetAll() ([]*models.Task, error) {
 }
 
 func (s *SQLiteStorage) GetByID(id string) (*models.Task, error) {
-	query := `SELECT id, title, done, created_at, due_date FROM tasks WHERE id = ?`
-	row := s.db.QueryRow(query, id)
+	query := `SELECT id, title, done, created_at, due_date, completed_at, version FROM tasks WHERE id = ?`
+	row := s.conn().QueryRow(query, id)
 
 	task := &models.Task{}
-	var dueDate sql.NullTime
+	var dueDate, completedAt sql.NullTime
 
-	err := row.Scan(&task.ID, &task.Title, &task.Done, &task.CreatedAt, &dueDate)
+	err := row.Scan(&task.ID, &task.Title, &task.Done, &task.CreatedAt, &dueDate, &completedAt, &task.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -89,13 +227,16 @@ func (s *SQLiteStorage) GetByID(id string) (*models.Task, error) {
 	if dueDate.Valid {
 		task.DueDate = &dueDate.Time
 	}
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
 
 	return task, nil
 }
 
 func (s *SQLiteStorage) Update(task *models.Task) error {
-	query := `UPDATE tasks SET title = ?, done = ?, due_date = ? WHERE id = ?`
-	result, err := s.db.Exec(query, task.Title, task.Done, task.DueDate, task.ID)
+	query := `UPDATE tasks SET title = ?, done = ?, due_date = ?, completed_at = ?, version = ? WHERE id = ?`
+	result, err := s.conn().Exec(query, task.Title, task.Done, task.DueDate, task.CompletedAt, task.Version, task.ID)
 	if err != nil {
 		return err
 	}
@@ -114,7 +255,7 @@ func (s *SQLiteStorage) Update(task *models.Task) error {
 
 func (s *SQLiteStorage) Delete(id string) error {
 	query := `DELETE FROM tasks WHERE id = ?`
-	result, err := s.db.Exec(query, id)
+	result, err := s.conn().Exec(query, id)
 	if err != nil {
 		return err
 	}
@@ -131,6 +272,481 @@ func (s *SQLiteStorage) Delete(id string) error {
 	return nil
 }
 
+// UpdateIfVersion implements Versioner with a conditional UPDATE
+func (s *SQLiteStorage) UpdateIfVersion(task *models.Task, expected int64) error {
+	query := `UPDATE tasks SET title = ?, done = ?, due_date = ?, completed_at = ?, version = ? WHERE id = ? AND version = ?`
+	result, err := s.conn().Exec(query, task.Title, task.Done, task.DueDate, task.CompletedAt, task.Version, task.ID, expected)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return versionMismatch(s, task.ID, expected)
+	}
+	return nil
+}
+
+// DeleteIfVersion implements Versioner with a conditional DELETE
+func (s *SQLiteStorage) DeleteIfVersion(id string, expected int64) error {
+	result, err := s.conn().Exec(`DELETE FROM tasks WHERE id = ? AND version = ?`, id, expected)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return versionMismatch(s, id, expected)
+	}
+	return nil
+}
+
+// Watch reports changes made through any connection to the database file,
+// polling a change log that triggers append to
+func (s *SQLiteStorage) Watch(ctx context.Context) (<-chan Change, error) {
+	log := &changeLog{
+		db:       s.db,
+		setup:    s.setupChangeLog,
+		prune:    `DELETE FROM task_changes WHERE changed_at < datetime('now', '-1 hour')`,
+		get:      s.GetByID,
+		interval: s.pollInterval,
+		logger:   slog.Default(),
+	}
+	return log.watch(ctx)
+}
+
+func (s *SQLiteStorage) setupChangeLog(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS task_changes (
+			seq INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id TEXT NOT NULL,
+			op TEXT NOT NULL,
+			changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_watch_insert AFTER INSERT ON tasks BEGIN
+			INSERT INTO task_changes (task_id, op) VALUES (NEW.id, 'create');
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_watch_update AFTER UPDATE ON tasks BEGIN
+			INSERT INTO task_changes (task_id, op) VALUES (NEW.id, 'update');
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_watch_delete AFTER DELETE ON tasks BEGIN
+			INSERT INTO task_changes (task_id, op) VALUES (OLD.id, 'delete');
+		END`,
+	}
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteBatchRows is how many tasks CreateMany inserts per statement, keeping
+// the bound parameters under SQLite's historical limit of 999
+const sqliteBatchRows = 100
+
+// CreateMany implements Batcher with multi-row inserts in one transaction
+func (s *SQLiteStorage) CreateMany(tasks []*models.Task) error {
+	return s.inTx(func(tx *SQLiteStorage) error {
+		for start := 0; start < len(tasks); start += sqliteBatchRows {
+			chunk := tasks[start:min(start+sqliteBatchRows, len(tasks))]
+			query := `INSERT INTO tasks (id, title, done, created_at, due_date, completed_at, version) VALUES ` +
+				strings.TrimSuffix(strings.Repeat(`(?, ?, ?, ?, ?, ?, ?), `, len(chunk)), ", ")
+			args := make([]any, 0, 7*len(chunk))
+			for _, task := range chunk {
+				args = append(args, task.ID, task.Title, task.Done, task.CreatedAt, task.DueDate, task.CompletedAt, task.Version)
+			}
+			if _, err := tx.conn().Exec(query, args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateMany implements Batcher in one transaction
+func (s *SQLiteStorage) UpdateMany(tasks []*models.Task) error {
+	return s.inTx(func(tx *SQLiteStorage) error {
+		for i, task := range tasks {
+			if err := tx.Update(task); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteMany implements Batcher in one transaction
+func (s *SQLiteStorage) DeleteMany(ids []string) error {
+	return s.inTx(func(tx *SQLiteStorage) error {
+		for i, id := range ids {
+			if err := tx.Delete(id); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// inTx runs fn in the joined transaction or a new one
+func (s *SQLiteStorage) inTx(fn func(tx *SQLiteStorage) error) error {
+	return s.WithTx(context.Background(), func(tx Storage) error {
+		return fn(tx.(*SQLiteStorage))
+	})
+}
+
+// WithTx implements Transactor with a database transaction
+func (s *SQLiteStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(&SQLiteStorage{db: s.db, tx: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// sqlQuerier is the part of *sql.DB and *sql.Tx the operations use
+type sqlQuerier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// conn returns the joined transaction, or the database outside WithTx
+func (s *SQLiteStorage) conn() sqlQuerier {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.db
+}
+
+// Search implements Searcher with the FTS5 index, ranking by BM25. Query
+// words are matched whole, and as prefixes when long enough.
+func (s *SQLiteStorage) Search(query string, limit int) ([]SearchResult, error) {
+	var match []string
+	for _, term := range searchTerms(query) {
+		phrase := `"` + term + `"`
+		if utf8.RuneCountInString(term) >= minPrefixLength {
+			phrase += "*"
+		}
+		match = append(match, phrase)
+	}
+	if len(match) == 0 {
+		return nil, nil
+	}
+
+	rows, err := s.conn().Query(`SELECT t.id, t.title, t.done, t.created_at, t.due_date, t.completed_at, t.version,
+			-bm25(tasks_fts), highlight(tasks_fts, 1, char(2), char(3))
+		FROM tasks_fts JOIN tasks t ON t.id = tasks_fts.id
+		WHERE tasks_fts MATCH ?
+		ORDER BY bm25(tasks_fts), t.created_at DESC, t.id
+		LIMIT ?`, strings.Join(match, " OR "), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		task := &models.Task{}
+		var dueDate, completedAt sql.NullTime
+		var score float64
+		var highlighted string
+		err := rows.Scan(&task.ID, &task.Title, &task.Done, &task.CreatedAt, &dueDate, &completedAt, &task.Version, &score, &highlighted)
+		if err != nil {
+			return nil, err
+		}
+		if dueDate.Valid {
+			task.DueDate = &dueDate.Time
+		}
+		if completedAt.Valid {
+			task.CompletedAt = &completedAt.Time
+		}
+		results = append(results, SearchResult{Task: task, Score: score, Matches: highlightMatches(task.Title, highlighted, query)})
+	}
+	return results, rows.Err()
+}
+
+// Find implements Finder, selecting tasks by status, ID and ASCII title text
+// in SQL and checking the rest in Go: times are stored as text with zone
+// names, which SQLite cannot compare, and its LOWER only folds ASCII
+func (s *SQLiteStorage) Find(filter *query.Filter) ([]*models.Task, error) {
+	var pushed []query.Condition
+	for _, cond := range filter.Conditions {
+		if !cond.Field.IsTime() && (cond.Field != query.FieldTitle || isASCII(cond.Text)) {
+			pushed = append(pushed, cond)
+		}
+	}
+
+	stmt := `SELECT id, title, done, created_at, due_date, completed_at, version FROM tasks`
+	where, args := sqlWhere(pushed)
+	if where != "" {
+		stmt += " WHERE " + where
+	}
+	rows, err := s.conn().Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*models.Task
+	for rows.Next() {
+		task := &models.Task{}
+		var dueDate, completedAt sql.NullTime
+		err := rows.Scan(&task.ID, &task.Title, &task.Done, &task.CreatedAt, &dueDate, &completedAt, &task.Version)
+		if err != nil {
+			return nil, err
+		}
+		if dueDate.Valid {
+			task.DueDate = &dueDate.Time
+		}
+		if completedAt.Valid {
+			task.CompletedAt = &completedAt.Time
+		}
+		if filter.Match(task) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, rows.Err()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateIdempotencyRecord implements IdempotencyStore. An expired record
+// with the key is replaced by the upsert; an unexpired one leaves no row
+// affected.
+func (s *SQLiteStorage) CreateIdempotencyRecord(record *IdempotencyRecord) error {
+	result, err := s.conn().Exec(`INSERT INTO idempotency_records (key, request_hash, status_code, content_type, body, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			request_hash = excluded.request_hash, status_code = excluded.status_code,
+			content_type = excluded.content_type, body = excluded.body,
+			created_at = excluded.created_at, expires_at = excluded.expires_at
+		WHERE idempotency_records.expires_at <= excluded.created_at`,
+		record.Key, record.RequestHash, record.StatusCode, record.ContentType, record.Body,
+		record.CreatedAt.UnixNano(), record.ExpiresAt.UnixNano())
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+// GetIdempotencyRecord implements IdempotencyStore
+func (s *SQLiteStorage) GetIdempotencyRecord(key string) (*IdempotencyRecord, error) {
+	record := &IdempotencyRecord{Key: key}
+	var createdAt, expiresAt int64
+	err := s.conn().QueryRow(`SELECT request_hash, status_code, content_type, body, created_at, expires_at
+		FROM idempotency_records WHERE key = ? AND expires_at > ?`, key, time.Now().UnixNano()).
+		Scan(&record.RequestHash, &record.StatusCode, &record.ContentType, &record.Body, &createdAt, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	record.CreatedAt, record.ExpiresAt = time.Unix(0, createdAt), time.Unix(0, expiresAt)
+	return record, nil
+}
+
+// SaveIdempotencyRecord implements IdempotencyStore
+func (s *SQLiteStorage) SaveIdempotencyRecord(record *IdempotencyRecord) error {
+	_, err := s.conn().Exec(`INSERT OR REPLACE INTO idempotency_records (key, request_hash, status_code, content_type, body, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		record.Key, record.RequestHash, record.StatusCode, record.ContentType, record.Body,
+		record.CreatedAt.UnixNano(), record.ExpiresAt.UnixNano())
+	return err
+}
+
+// DeleteIdempotencyRecord implements IdempotencyStore
+func (s *SQLiteStorage) DeleteIdempotencyRecord(key string) error {
+	_, err := s.conn().Exec(`DELETE FROM idempotency_records WHERE key = ?`, key)
+	return err
+}
+
+// DeleteExpiredIdempotencyRecords implements IdempotencyStore
+func (s *SQLiteStorage) DeleteExpiredIdempotencyRecords(now time.Time) (int, error) {
+	result, err := s.conn().Exec(`DELETE FROM idempotency_records WHERE expires_at <= ?`, now.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// ListViews implements ViewStore
+func (s *SQLiteStorage) ListViews() ([]*ViewRecord, error) {
+	rows, err := s.conn().Query(`SELECT id, data, created_at FROM saved_views`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*ViewRecord
+	for rows.Next() {
+		var record ViewRecord
+		var createdAt int64
+		if err := rows.Scan(&record.ID, &record.Data, &createdAt); err != nil {
+			return nil, err
+		}
+		record.CreatedAt = time.Unix(0, createdAt)
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
+
+// SaveView implements ViewStore
+func (s *SQLiteStorage) SaveView(record *ViewRecord) error {
+	_, err := s.conn().Exec(`INSERT OR REPLACE INTO saved_views (id, data, created_at) VALUES (?, ?, ?)`,
+		record.ID, record.Data, record.CreatedAt.UnixNano())
+	return err
+}
+
+// DeleteView implements ViewStore
+func (s *SQLiteStorage) DeleteView(id string) error {
+	_, err := s.conn().Exec(`DELETE FROM saved_views WHERE id = ?`, id)
+	return err
+}
+
+// ListWebhookSubscriptions implements WebhookStore
+func (s *SQLiteStorage) ListWebhookSubscriptions() ([]*WebhookSubscriptionRecord, error) {
+	rows, err := s.conn().Query(`SELECT id, data, created_at FROM webhook_subscriptions`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]*WebhookSubscriptionRecord, 0)
+	for rows.Next() {
+		var record WebhookSubscriptionRecord
+		var createdAt int64
+		if err := rows.Scan(&record.ID, &record.Data, &createdAt); err != nil {
+			return nil, err
+		}
+		record.CreatedAt = time.Unix(0, createdAt)
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
+
+// SaveWebhookSubscription implements WebhookStore
+func (s *SQLiteStorage) SaveWebhookSubscription(record *WebhookSubscriptionRecord) error {
+	_, err := s.conn().Exec(`INSERT OR REPLACE INTO webhook_subscriptions (id, data, created_at) VALUES (?, ?, ?)`,
+		record.ID, record.Data, record.CreatedAt.UnixNano())
+	return err
+}
+
+// DeleteWebhookSubscription implements WebhookStore
+func (s *SQLiteStorage) DeleteWebhookSubscription(id string) error {
+	return s.WithTx(context.Background(), func(tx Storage) error {
+		conn := tx.(*SQLiteStorage).conn()
+		if _, err := conn.Exec(`DELETE FROM webhook_deliveries WHERE subscription_id = ?`, id); err != nil {
+			return err
+		}
+		_, err := conn.Exec(`DELETE FROM webhook_subscriptions WHERE id = ?`, id)
+		return err
+	})
+}
+
+// ListWebhookDeliveries implements WebhookStore
+func (s *SQLiteStorage) ListWebhookDeliveries(subscriptionID string) ([]*WebhookDeliveryRecord, error) {
+	query := `SELECT id, subscription_id, pending, data, created_at FROM webhook_deliveries`
+	var args []any
+	if subscriptionID != "" {
+		query += ` WHERE subscription_id = ?`
+		args = append(args, subscriptionID)
+	}
+	rows, err := s.conn().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]*WebhookDeliveryRecord, 0)
+	for rows.Next() {
+		var record WebhookDeliveryRecord
+		var createdAt int64
+		if err := rows.Scan(&record.ID, &record.SubscriptionID, &record.Pending, &record.Data, &createdAt); err != nil {
+			return nil, err
+		}
+		record.CreatedAt = time.Unix(0, createdAt)
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
+
+// SaveWebhookDelivery implements WebhookStore
+func (s *SQLiteStorage) SaveWebhookDelivery(record *WebhookDeliveryRecord) error {
+	_, err := s.conn().Exec(`INSERT OR REPLACE INTO webhook_deliveries (id, subscription_id, pending, data, created_at) VALUES (?, ?, ?, ?, ?)`,
+		record.ID, record.SubscriptionID, record.Pending, record.Data, record.CreatedAt.UnixNano())
+	return err
+}
+
+// DeleteWebhookDeliveries implements WebhookStore
+func (s *SQLiteStorage) DeleteWebhookDeliveries(ids []string) error {
+	return s.WithTx(context.Background(), func(tx Storage) error {
+		conn := tx.(*SQLiteStorage).conn()
+		for _, id := range ids {
+			if _, err := conn.Exec(`DELETE FROM webhook_deliveries WHERE id = ?`, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ClaimNotification implements NotificationStore
+func (s *SQLiteStorage) ClaimNotification(record *NotificationRecord) error {
+	result, err := s.conn().Exec(`INSERT INTO notification_records (key, sent_at) VALUES (?, ?) ON CONFLICT (key) DO NOTHING`,
+		record.Key, record.SentAt.UnixNano())
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotificationClaimed
+	}
+	return nil
+}
+
+// DeleteNotification implements NotificationStore
+func (s *SQLiteStorage) DeleteNotification(key string) error {
+	_, err := s.conn().Exec(`DELETE FROM notification_records WHERE key = ?`, key)
+	return err
+}
+
+// DeleteNotificationsBefore implements NotificationStore
+func (s *SQLiteStorage) DeleteNotificationsBefore(before time.Time) (int, error) {
+	result, err := s.conn().Exec(`DELETE FROM notification_records WHERE sent_at < ?`, before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
 func (s *SQLiteStorage) Close() error {
 	return s.db.Close()
 }
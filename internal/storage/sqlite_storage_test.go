@@ -585,6 +585,57 @@ func TestSQLiteStorage_EdgeCases(t *testing.T) {
 	})
 }
 
+func TestSQLiteStorage_MigratesLegacySchema(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	dbPath := helper.TempFilePath("legacy.db")
+
+	// Create a database with the schema used before completed_at and version existed
+	db, err := sql.Open("sqlite", dbPath)
+	helper.AssertNoError(err, "opening legacy database")
+	_, err = db.Exec(`CREATE TABLE tasks (
+        id TEXT PRIMARY KEY,
+        title TEXT NOT NULL,
+        done BOOLEAN DEFAULT 0,
+        created_at DATETIME NOT NULL,
+        due_date DATETIME
+    )`)
+	helper.AssertNoError(err, "creating legacy table")
+	_, err = db.Exec(`INSERT INTO tasks (id, title, done, created_at) VALUES ('legacy', 'Legacy', 1, ?)`, time.Now())
+	helper.AssertNoError(err, "inserting legacy task")
+	db.Close()
+
+	storage, err := NewSQLiteStorage(dbPath)
+	helper.AssertNoError(err, "opening legacy database with migration")
+	defer storage.Close()
+
+	legacy, err := storage.GetByID("legacy")
+	helper.AssertNoError(err, "reading legacy task")
+	if legacy.CompletedAt != nil || legacy.Version != 0 {
+		t.Errorf("Expected legacy task without completion time at version 0, got %v %d", legacy.CompletedAt, legacy.Version)
+	}
+
+	completedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	legacy.CompletedAt = &completedAt
+	legacy.Version = 1
+	helper.AssertNoError(storage.Update(legacy), "updating legacy task")
+
+	updated, err := storage.GetByID("legacy")
+	helper.AssertNoError(err, "reading updated task")
+	if updated.CompletedAt == nil || !updated.CompletedAt.Equal(completedAt) {
+		t.Errorf("Expected completion time %v, got %v", completedAt, updated.CompletedAt)
+	}
+	if updated.Version != 1 {
+		t.Errorf("Expected version 1, got %d", updated.Version)
+	}
+
+	// Reopening must not try to add the column again
+	reopened, err := NewSQLiteStorage(dbPath)
+	helper.AssertNoError(err, "reopening migrated database")
+	reopened.Close()
+}
+
 // Helper function to calculate absolute duration
 func abs(d time.Duration) time.Duration {
 	if d < 0 {
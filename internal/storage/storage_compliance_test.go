@@ -1,14 +1,30 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
 )
 
 // testStorageCompliance runs a comprehensive test suite that all storage implementations should pass
 func testStorageCompliance(t *testing.T, storage Storage) {
+	t.Run("GetAllEmpty", func(t *testing.T) {
+		tasks, err := storage.GetAll()
+		if err != nil {
+			t.Fatalf("Failed to get all tasks: %v", err)
+		}
+		if tasks == nil {
+			t.Error("Expected an empty slice, got nil")
+		}
+	})
+
 	t.Run("Create", func(t *testing.T) {
 		task := &models.Task{
 			ID:        "test_create",
@@ -349,3 +365,419 @@ func testStorageCompliance(t *testing.T, storage Storage) {
 		}
 	})
 }
+
+// testTransactionCompliance checks the WithTx guarantees that every
+// Transactor should provide
+func testTransactionCompliance(t *testing.T, storage Storage) {
+	ctx := context.Background()
+	newTask := func(id, title string) *models.Task {
+		return &models.Task{ID: id, Title: title, CreatedAt: time.Now()}
+	}
+
+	t.Run("TxCommit", func(t *testing.T) {
+		if err := storage.Create(newTask("tx_commit_old", "Old")); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		err := WithTx(ctx, storage, func(tx Storage) error {
+			if err := tx.Create(newTask("tx_commit_new", "New")); err != nil {
+				return err
+			}
+			if err := tx.Update(newTask("tx_commit_old", "Updated")); err != nil {
+				return err
+			}
+			return tx.Delete("tx_commit_new")
+		})
+		if err != nil {
+			t.Fatalf("Failed to commit transaction: %v", err)
+		}
+
+		if task, err := storage.GetByID("tx_commit_old"); err != nil || task.Title != "Updated" {
+			t.Errorf("Expected the update to be committed, got %+v, %v", task, err)
+		}
+		if _, err := storage.GetByID("tx_commit_new"); err == nil {
+			t.Error("Expected the task created and deleted in the transaction to be gone")
+		}
+	})
+
+	t.Run("TxRollback", func(t *testing.T) {
+		if err := storage.Create(newTask("tx_rollback_old", "Old")); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		errAbort := errors.New("abort")
+		err := WithTx(ctx, storage, func(tx Storage) error {
+			if err := tx.Create(newTask("tx_rollback_new", "New")); err != nil {
+				return err
+			}
+			if err := tx.Update(newTask("tx_rollback_old", "Updated")); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("Expected the error from fn, got %v", err)
+		}
+
+		if task, err := storage.GetByID("tx_rollback_old"); err != nil || task.Title != "Old" {
+			t.Errorf("Expected the update to be rolled back, got %+v, %v", task, err)
+		}
+		if _, err := storage.GetByID("tx_rollback_new"); err == nil {
+			t.Error("Expected the created task to be rolled back")
+		}
+	})
+
+	t.Run("TxReadYourWrites", func(t *testing.T) {
+		err := WithTx(ctx, storage, func(tx Storage) error {
+			if err := tx.Create(newTask("tx_read", "Read")); err != nil {
+				return err
+			}
+			task, err := tx.GetByID("tx_read")
+			if err != nil {
+				return err
+			}
+			if task.Title != "Read" {
+				t.Errorf("Expected to read the created task, got %+v", task)
+			}
+
+			tasks, err := tx.GetAll()
+			if err != nil {
+				return err
+			}
+			for _, task := range tasks {
+				if task.ID == "tx_read" {
+					return nil
+				}
+			}
+			t.Error("Expected GetAll in the transaction to include the created task")
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to run transaction: %v", err)
+		}
+	})
+
+	t.Run("TxFailedOperation", func(t *testing.T) {
+		if err := storage.Create(newTask("tx_failed", "Existing")); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		err := WithTx(ctx, storage, func(tx Storage) error {
+			if err := tx.Create(newTask("tx_failed_new", "New")); err != nil {
+				return err
+			}
+			return tx.Delete("tx_missing")
+		})
+		if err == nil {
+			t.Fatal("Expected the failed delete to fail the transaction")
+		}
+		if _, err := storage.GetByID("tx_failed_new"); err == nil {
+			t.Error("Expected the transaction to be rolled back")
+		}
+	})
+}
+
+// testBatchCompliance checks that CreateMany, UpdateMany and DeleteMany write
+// all of their tasks or none
+func testBatchCompliance(t *testing.T, storage Storage) {
+	ctx := context.Background()
+	newTask := func(id, title string) *models.Task {
+		return &models.Task{ID: id, Title: title, CreatedAt: time.Now(), Version: 1}
+	}
+	assertFailedAt := func(t *testing.T, err error, index int) {
+		t.Helper()
+		if err == nil {
+			t.Fatal("Expected the batch to fail")
+		}
+		// Backends with multi-row inserts may not know which task failed
+		var batchErr *BatchError
+		if errors.As(err, &batchErr) && batchErr.Index != index {
+			t.Errorf("Expected item %d to fail, got %v", index, err)
+		}
+	}
+
+	t.Run("BatchCreate", func(t *testing.T) {
+		tasks := []*models.Task{newTask("batch_c1", "One"), newTask("batch_c2", "Two"), newTask("batch_c3", "Three")}
+		if err := CreateMany(ctx, storage, tasks); err != nil {
+			t.Fatalf("Failed to create tasks: %v", err)
+		}
+		for _, task := range tasks {
+			if got, err := storage.GetByID(task.ID); err != nil || got.Title != task.Title {
+				t.Errorf("Expected %s to be created, got %+v, %v", task.ID, got, err)
+			}
+		}
+
+		err := CreateMany(ctx, storage, []*models.Task{newTask("batch_c4", "Four"), newTask("batch_c1", "Duplicate")})
+		assertFailedAt(t, err, 1)
+		if _, err := storage.GetByID("batch_c4"); err == nil {
+			t.Error("Expected no task of the failed batch to be created")
+		}
+	})
+
+	t.Run("BatchUpdate", func(t *testing.T) {
+		if err := CreateMany(ctx, storage, []*models.Task{newTask("batch_u1", "One"), newTask("batch_u2", "Two")}); err != nil {
+			t.Fatalf("Failed to create tasks: %v", err)
+		}
+		if err := UpdateMany(ctx, storage, []*models.Task{newTask("batch_u1", "One updated"), newTask("batch_u2", "Two updated")}); err != nil {
+			t.Fatalf("Failed to update tasks: %v", err)
+		}
+		if got, _ := storage.GetByID("batch_u2"); got == nil || got.Title != "Two updated" {
+			t.Errorf("Expected batch_u2 to be updated, got %+v", got)
+		}
+
+		err := UpdateMany(ctx, storage, []*models.Task{newTask("batch_u1", "Again"), newTask("batch_missing", "Missing")})
+		assertFailedAt(t, err, 1)
+		if got, _ := storage.GetByID("batch_u1"); got == nil || got.Title != "One updated" {
+			t.Errorf("Expected batch_u1 to be unchanged, got %+v", got)
+		}
+	})
+
+	t.Run("BatchDelete", func(t *testing.T) {
+		if err := CreateMany(ctx, storage, []*models.Task{newTask("batch_d1", "One"), newTask("batch_d2", "Two"), newTask("batch_d3", "Three")}); err != nil {
+			t.Fatalf("Failed to create tasks: %v", err)
+		}
+		if err := DeleteMany(ctx, storage, []string{"batch_d1", "batch_d2"}); err != nil {
+			t.Fatalf("Failed to delete tasks: %v", err)
+		}
+		if _, err := storage.GetByID("batch_d2"); err == nil {
+			t.Error("Expected batch_d2 to be deleted")
+		}
+
+		err := DeleteMany(ctx, storage, []string{"batch_d3", "batch_d1"})
+		assertFailedAt(t, err, 1)
+		if _, err := storage.GetByID("batch_d3"); err != nil {
+			t.Errorf("Expected batch_d3 to be kept, got %v", err)
+		}
+	})
+}
+
+// testIdempotencyCompliance checks that an IdempotencyStore holds a key until
+// its record expires and replaces expired records
+func testIdempotencyCompliance(t *testing.T, store IdempotencyStore) {
+	// Keys are unique per run, for databases that outlive the test
+	prefix := fmt.Sprintf("idem_%d_", time.Now().UnixNano())
+	newRecord := func(key string, created time.Time, ttl time.Duration) *IdempotencyRecord {
+		return &IdempotencyRecord{Key: prefix + key, RequestHash: "hash_" + key, CreatedAt: created, ExpiresAt: created.Add(ttl)}
+	}
+
+	t.Run("IdempotencyCreate", func(t *testing.T) {
+		record := newRecord("create", time.Now(), time.Hour)
+		if err := store.CreateIdempotencyRecord(record); err != nil {
+			t.Fatalf("Failed to create record: %v", err)
+		}
+		got, err := store.GetIdempotencyRecord(record.Key)
+		if err != nil || got.RequestHash != record.RequestHash || got.StatusCode != 0 {
+			t.Fatalf("Expected the pending record, got %+v, %v", got, err)
+		}
+		if err := store.CreateIdempotencyRecord(newRecord("create", time.Now(), time.Hour)); !errors.Is(err, ErrIdempotencyKeyExists) {
+			t.Errorf("Expected ErrIdempotencyKeyExists, got %v", err)
+		}
+	})
+
+	t.Run("IdempotencySave", func(t *testing.T) {
+		record := newRecord("save", time.Now(), time.Minute)
+		if err := store.CreateIdempotencyRecord(record); err != nil {
+			t.Fatalf("Failed to create record: %v", err)
+		}
+		record.StatusCode, record.ContentType, record.Body = 201, "application/json", []byte(`{"id":"1"}`)
+		record.ExpiresAt = time.Now().Add(time.Hour)
+		if err := store.SaveIdempotencyRecord(record); err != nil {
+			t.Fatalf("Failed to save record: %v", err)
+		}
+		got, err := store.GetIdempotencyRecord(record.Key)
+		if err != nil || got.StatusCode != 201 || got.ContentType != "application/json" || string(got.Body) != `{"id":"1"}` {
+			t.Errorf("Expected the saved response, got %+v, %v", got, err)
+		}
+	})
+
+	t.Run("IdempotencyExpiry", func(t *testing.T) {
+		expired := newRecord("expired", time.Now().Add(-2*time.Hour), time.Hour)
+		if err := store.CreateIdempotencyRecord(expired); err != nil {
+			t.Fatalf("Failed to create record: %v", err)
+		}
+		if _, err := store.GetIdempotencyRecord(expired.Key); !errors.Is(err, ErrIdempotencyRecordNotFound) {
+			t.Errorf("Expected an expired record to be hidden, got %v", err)
+		}
+		replacement := newRecord("expired", time.Now(), time.Hour)
+		replacement.RequestHash = "replacement"
+		if err := store.CreateIdempotencyRecord(replacement); err != nil {
+			t.Fatalf("Expected an expired key to be reusable, got %v", err)
+		}
+		if got, err := store.GetIdempotencyRecord(expired.Key); err != nil || got.RequestHash != "replacement" {
+			t.Errorf("Expected the replacement record, got %+v, %v", got, err)
+		}
+
+		stale := newRecord("stale", time.Now().Add(-2*time.Hour), time.Hour)
+		if err := store.SaveIdempotencyRecord(stale); err != nil {
+			t.Fatalf("Failed to save record: %v", err)
+		}
+		if removed, err := store.DeleteExpiredIdempotencyRecords(time.Now()); err != nil || removed < 1 {
+			t.Errorf("Expected the stale record to be removed, got %d, %v", removed, err)
+		}
+		if _, err := store.GetIdempotencyRecord(expired.Key); err != nil {
+			t.Errorf("Expected the unexpired record to be kept, got %v", err)
+		}
+	})
+
+	t.Run("IdempotencyDelete", func(t *testing.T) {
+		record := newRecord("delete", time.Now(), time.Hour)
+		if err := store.CreateIdempotencyRecord(record); err != nil {
+			t.Fatalf("Failed to create record: %v", err)
+		}
+		if err := store.DeleteIdempotencyRecord(record.Key); err != nil {
+			t.Fatalf("Failed to delete record: %v", err)
+		}
+		if _, err := store.GetIdempotencyRecord(record.Key); !errors.Is(err, ErrIdempotencyRecordNotFound) {
+			t.Errorf("Expected ErrIdempotencyRecordNotFound, got %v", err)
+		}
+		if err := store.CreateIdempotencyRecord(record); err != nil {
+			t.Errorf("Expected a deleted key to be reusable, got %v", err)
+		}
+	})
+}
+
+// testSearchCompliance checks that Search ranks and highlights matches and
+// follows writes to the tasks
+func testSearchCompliance(t *testing.T, storage Storage) {
+	newTask := func(id, title string) *models.Task {
+		return &models.Task{ID: id, Title: title, CreatedAt: time.Now(), Version: 1}
+	}
+	ids := func(results []SearchResult) []string {
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Task.ID)
+		}
+		return got
+	}
+	for _, task := range []*models.Task{
+		newTask("search_1", "Write quarterly zephyr report"),
+		newTask("search_2", "Zephyr zephyr planning"),
+		newTask("search_3", "Buy groceries for the quokka"),
+	} {
+		if err := storage.Create(task); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	t.Run("SearchRanking", func(t *testing.T) {
+		results, err := Search(storage, "zephyr", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if got := ids(results); len(got) != 2 || got[0] != "search_2" {
+			t.Fatalf("Expected search_2 then search_1, got %v", got)
+		}
+		if got := results[1].Highlight("[", "]", nil); got != "Write quarterly [zephyr] report" {
+			t.Errorf("Unexpected highlight %q", got)
+		}
+
+		results, err = Search(storage, "Quokka, zephyr!", 10)
+		if err != nil || len(results) != 3 {
+			t.Errorf("Expected tasks matching any word, got %v, %v", ids(results), err)
+		}
+		if results, err := Search(storage, "zephyr quokka", 1); err != nil || len(results) != 1 {
+			t.Errorf("Expected the limit to apply, got %v, %v", ids(results), err)
+		}
+		if results, err := Search(storage, " ,. ", 10); err != nil || len(results) != 0 {
+			t.Errorf("Expected no results for an empty query, got %v, %v", ids(results), err)
+		}
+	})
+
+	t.Run("SearchFollowsWrites", func(t *testing.T) {
+		task, err := storage.GetByID("search_3")
+		if err != nil {
+			t.Fatalf("Failed to get task: %v", err)
+		}
+		task.Title = "Buy zephyr snacks"
+		if err := storage.Update(task); err != nil {
+			t.Fatalf("Failed to update task: %v", err)
+		}
+		if err := storage.Delete("search_1"); err != nil {
+			t.Fatalf("Failed to delete task: %v", err)
+		}
+
+		results, err := Search(storage, "zephyr", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		got := ids(results)
+		if len(got) != 2 || !containsID(got, "search_2") || !containsID(got, "search_3") {
+			t.Errorf("Expected search_2 and search_3, got %v", got)
+		}
+		if results, _ := Search(storage, "quokka", 10); len(results) != 0 {
+			t.Errorf("Expected the old title not to match, got %v", ids(results))
+		}
+	})
+}
+
+func containsID(ids []string, id string) bool {
+	for _, got := range ids {
+		if got == id {
+			return true
+		}
+	}
+	return false
+}
+
+// testFindCompliance checks that Find selects the tasks matching query
+// filters. Every query names "quasar" so that other tasks in a shared
+// database do not match.
+func testFindCompliance(t *testing.T, storage Storage) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	tasks := []*models.Task{
+		{ID: "find_1", Title: "Quasar login bug", CreatedAt: now.AddDate(0, 0, -10), DueDate: at(24 * time.Hour), Version: 1},
+		{ID: "find_2", Title: "Quasar release notes", Done: true, CreatedAt: now, CompletedAt: at(-time.Hour), Version: 1},
+		{ID: "find_3", Title: "QUASAR 50%_off banner", CreatedAt: now, DueDate: at(10 * 24 * time.Hour), Version: 1},
+		{ID: "find_4", Title: "Quasar overdue report", CreatedAt: now, DueDate: at(-48 * time.Hour), Version: 1},
+	}
+	for _, task := range tasks {
+		if err := storage.Create(task); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "quasar", want: "find_1 find_2 find_3 find_4"},
+		{query: "quasar status:open", want: "find_1 find_3 find_4"},
+		{query: "quasar -status:open", want: "find_2"},
+		{query: "QUASAR LOGIN", want: "find_1"},
+		{query: `quasar "release notes" status:done`, want: "find_2"},
+		{query: "quasar -title:bug -notes", want: "find_3 find_4"},
+		{query: `quasar "%_off"`, want: "find_3"},
+		{query: `quasar "r_l"`, want: ""},
+		{query: "quasar id:find_2", want: "find_2"},
+		{query: "quasar due<today", want: "find_4"},
+		{query: "quasar due<=+1d", want: "find_1 find_4"},
+		{query: "quasar -due<=+1d", want: "find_2 find_3"},
+		{query: "quasar due>=today due<+1w", want: "find_1"},
+		{query: "quasar due:none", want: "find_2"},
+		{query: "quasar -due:none status:open", want: "find_1 find_3 find_4"},
+		{query: "quasar completed>=-1d", want: "find_2"},
+		{query: "quasar created<-5d", want: "find_1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filter, err := query.ParseFilter(tt.query, now)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %v", err)
+			}
+			found, err := Find(storage, filter)
+			if err != nil {
+				t.Fatalf("Find failed: %v", err)
+			}
+			var ids []string
+			for _, task := range found {
+				ids = append(ids, task.ID)
+			}
+			sort.Strings(ids)
+			if got := strings.Join(ids, " "); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -37,13 +37,13 @@ func TestStorageInterface(t *testing.T) {
 			helper.AssertNoError(err, "creating storage implementation")
 			defer storage.Close()
 
-			testStorageCompliance(t, helper, storage)
+			testStorageInterfaceCompliance(t, helper, storage)
 		})
 	}
 }
 
-// testStorageCompliance runs a comprehensive test suite against any Storage implementation
-func testStorageCompliance(t *testing.T, helper *TestHelper, storage Storage) {
+// testStorageInterfaceCompliance runs a comprehensive test suite against any Storage implementation
+func testStorageInterfaceCompliance(t *testing.T, helper *TestHelper, storage Storage) {
 	t.Run("CRUD operations", func(t *testing.T) {
 		testCRUDOperations(t, helper, storage)
 	})
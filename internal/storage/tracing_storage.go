@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+	"GoTask_Management/internal/tracing"
+)
+
+// TracingStorage decorates a Storage with spans and request-scoped debug logs.
+// Operations are attributed to the context bound with WithContext.
+type TracingStorage struct {
+	next   Storage
+	logger *slog.Logger
+	ctx    context.Context
+}
+
+// NewTracingStorage wraps next; logger defaults to slog.Default() when nil
+func NewTracingStorage(next Storage, logger *slog.Logger) *TracingStorage {
+	return &TracingStorage{
+		next:   next,
+		logger: loggerOrDefault(logger),
+		ctx:    context.Background(),
+	}
+}
+
+// WithContext implements ContextBinder
+func (ts *TracingStorage) WithContext(ctx context.Context) Storage {
+	return &TracingStorage{
+		next:   WithContext(ctx, ts.next),
+		logger: ts.logger,
+		ctx:    ctx,
+	}
+}
+
+// trace runs op inside a span named after the storage operation
+func (ts *TracingStorage) trace(operation, taskID string, op func() error) error {
+	ctx, span := tracing.StartSpan(ts.ctx, "storage."+operation)
+	defer span.End()
+	span.SetAttribute("storage.operation", operation)
+	if taskID != "" {
+		span.SetAttribute("task.id", taskID)
+	}
+
+	start := time.Now()
+	err := op()
+	span.RecordError(err)
+
+	attrs := []any{"operation", operation, "duration", time.Since(start)}
+	if taskID != "" {
+		attrs = append(attrs, "task_id", taskID)
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	ts.logger.DebugContext(ctx, "storage operation", attrs...)
+	return err
+}
+
+// Create implements Storage interface
+func (ts *TracingStorage) Create(task *models.Task) error {
+	return ts.trace("create", task.ID, func() error {
+		return ts.next.Create(task)
+	})
+}
+
+// GetAll implements Storage interface
+func (ts *TracingStorage) GetAll() ([]*models.Task, error) {
+	var tasks []*models.Task
+	err := ts.trace("get_all", "", func() error {
+		var err error
+		tasks, err = ts.next.GetAll()
+		return err
+	})
+	return tasks, err
+}
+
+// GetByID implements Storage interface
+func (ts *TracingStorage) GetByID(id string) (*models.Task, error) {
+	var task *models.Task
+	err := ts.trace("get_by_id", id, func() error {
+		var err error
+		task, err = ts.next.GetByID(id)
+		return err
+	})
+	return task, err
+}
+
+// Update implements Storage interface
+func (ts *TracingStorage) Update(task *models.Task) error {
+	return ts.trace("update", task.ID, func() error {
+		return ts.next.Update(task)
+	})
+}
+
+// Delete implements Storage interface
+func (ts *TracingStorage) Delete(id string) error {
+	return ts.trace("delete", id, func() error {
+		return ts.next.Delete(id)
+	})
+}
+
+// UpdateIfVersion implements Versioner, natively when the wrapped storage does
+func (ts *TracingStorage) UpdateIfVersion(task *models.Task, expected int64) error {
+	return ts.trace("update_if_version", task.ID, func() error {
+		return UpdateIfVersion(ts.ctx, ts.next, task, expected)
+	})
+}
+
+// DeleteIfVersion implements Versioner, natively when the wrapped storage does
+func (ts *TracingStorage) DeleteIfVersion(id string, expected int64) error {
+	return ts.trace("delete_if_version", id, func() error {
+		return DeleteIfVersion(ts.ctx, ts.next, id, expected)
+	})
+}
+
+// GetFresh implements FreshReader, reading past a cache in the wrapped storage
+func (ts *TracingStorage) GetFresh(id string) (*models.Task, error) {
+	var task *models.Task
+	err := ts.trace("get_fresh", id, func() error {
+		var err error
+		task, err = GetFresh(ts.next, id)
+		return err
+	})
+	return task, err
+}
+
+// Search implements Searcher, natively when the wrapped storage does
+func (ts *TracingStorage) Search(query string, limit int) ([]SearchResult, error) {
+	var results []SearchResult
+	err := ts.trace("search", "", func() error {
+		var err error
+		results, err = Search(ts.next, query, limit)
+		return err
+	})
+	return results, err
+}
+
+// Find implements Finder, natively when the wrapped storage does
+func (ts *TracingStorage) Find(filter *query.Filter) ([]*models.Task, error) {
+	var tasks []*models.Task
+	err := ts.trace("find", "", func() error {
+		var err error
+		tasks, err = Find(ts.next, filter)
+		return err
+	})
+	return tasks, err
+}
+
+// CreateMany implements Batcher, natively when the wrapped storage does
+func (ts *TracingStorage) CreateMany(tasks []*models.Task) error {
+	return ts.trace("create_many", "", func() error {
+		return CreateMany(ts.ctx, ts.next, tasks)
+	})
+}
+
+// UpdateMany implements Batcher, natively when the wrapped storage does
+func (ts *TracingStorage) UpdateMany(tasks []*models.Task) error {
+	return ts.trace("update_many", "", func() error {
+		return UpdateMany(ts.ctx, ts.next, tasks)
+	})
+}
+
+// DeleteMany implements Batcher, natively when the wrapped storage does
+func (ts *TracingStorage) DeleteMany(ids []string) error {
+	return ts.trace("delete_many", "", func() error {
+		return DeleteMany(ts.ctx, ts.next, ids)
+	})
+}
+
+// WithTx implements Transactor when the wrapped storage does, tracing the
+// transaction as a whole and each operation inside it
+func (ts *TracingStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	return ts.trace("transaction", "", func() error {
+		return WithTx(ctx, ts.next, func(tx Storage) error {
+			return fn(&TracingStorage{next: tx, logger: ts.logger, ctx: ts.ctx})
+		})
+	})
+}
+
+// Close implements Storage interface
+func (ts *TracingStorage) Close() error {
+	return ts.next.Close()
+}
+
+// HealthCheck forwards to the wrapped storage when it supports health checks
+func (ts *TracingStorage) HealthCheck() error {
+	if healthChecker, ok := ts.next.(interface{ HealthCheck() error }); ok {
+		return healthChecker.HealthCheck()
+	}
+	return nil
+}
+
+// Unwrap returns the decorated storage
+func (ts *TracingStorage) Unwrap() Storage {
+	return ts.next
+}
+
+// Verify that TracingStorage implements Storage interface
+var _ Storage = (*TracingStorage)(nil)
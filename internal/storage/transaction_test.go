@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"GoTask_Management/internal/metrics"
+)
+
+func TestWithTx(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	jsonStorage := func(name string) *JSONStorage {
+		store, err := NewJSONStorage(helper.TempFilePath(name))
+		helper.AssertNoError(err, "creating JSON storage")
+		return store
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		testTransactionCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		testTransactionCompliance(t, jsonStorage("tx.json"))
+	})
+
+	t.Run("wal", func(t *testing.T) {
+		store, err := NewWALStorage(WALConfig{Path: helper.TempFilePath("tx_wal.json")})
+		helper.AssertNoError(err, "creating WAL storage")
+		defer store.Close()
+		testTransactionCompliance(t, store)
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStorage(BoltConfig{Path: helper.TempFilePath("tx.db")})
+		helper.AssertNoError(err, "creating Bolt storage")
+		defer store.Close()
+		testTransactionCompliance(t, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("tx.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+		testTransactionCompliance(t, store)
+	})
+
+	t.Run("decorators", func(t *testing.T) {
+		var store Storage = jsonStorage("tx_decorated.json")
+		store = NewCachedStorage(store, CacheConfig{})
+		store = NewInstrumentedStorage(store, metrics.NewRegistry())
+		store = NewTracingStorage(store, nil)
+		testTransactionCompliance(t, store)
+	})
+
+	t.Run("cache does not keep tasks read in a transaction", func(t *testing.T) {
+		store := NewCachedStorage(jsonStorage("tx_cached.json"), CacheConfig{})
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("c1", "Committed")), "creating task")
+		store.GetByID("c1")
+
+		errAbort := errors.New("abort")
+		WithTx(context.Background(), store, func(tx Storage) error {
+			tx.Update(helper.CreateSampleTask("c1", "Uncommitted"))
+			tx.GetByID("c1")
+			return errAbort
+		})
+		if task, _ := store.GetByID("c1"); task == nil || task.Title != "Committed" {
+			t.Errorf("Expected the committed task, got %+v", task)
+		}
+	})
+
+	t.Run("wal batch survives reopening", func(t *testing.T) {
+		path := helper.TempFilePath("tx_replay.json")
+		store, err := NewWALStorage(WALConfig{Path: path, CompactEvery: 1000})
+		helper.AssertNoError(err, "creating WAL storage")
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("r1", "One")), "creating task")
+		err = WithTx(context.Background(), store, func(tx Storage) error {
+			if err := tx.Delete("r1"); err != nil {
+				return err
+			}
+			return tx.Create(helper.CreateSampleTask("r2", "Two"))
+		})
+		helper.AssertNoError(err, "committing transaction")
+		// Reopen without closing, so that the log is replayed rather than
+		// compacted into the snapshot
+		reopened, err := NewWALStorage(WALConfig{Path: path, CompactEvery: 1000})
+		helper.AssertNoError(err, "reopening WAL storage")
+		defer reopened.Close()
+		defer store.Close()
+
+		tasks, _ := reopened.GetAll()
+		if len(tasks) != 1 || tasks[0].ID != "r2" {
+			t.Errorf("Expected only r2 after replay, got %+v", tasks)
+		}
+	})
+
+	t.Run("canceled context rolls back", func(t *testing.T) {
+		store := NewMemoryStorage()
+		ctx, cancel := context.WithCancel(context.Background())
+		err := WithTx(ctx, store, func(tx Storage) error {
+			cancel()
+			return tx.Create(helper.CreateSampleTask("x1", "Canceled"))
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if _, err := store.GetByID("x1"); err == nil {
+			t.Error("Expected the transaction to be rolled back")
+		}
+	})
+
+	t.Run("unsupported storage", func(t *testing.T) {
+		store := struct{ Storage }{NewMemoryStorage()}
+		err := WithTx(context.Background(), store, func(tx Storage) error { return nil })
+		if !errors.Is(err, ErrTxUnsupported) {
+			t.Errorf("Expected ErrTxUnsupported, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"GoTask_Management/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned by conditional writes when the stored task
+// is not at the version the caller expected
+var ErrVersionConflict = errors.New("task has been modified")
+
+// Versioner is implemented by storages that can check the version of a task
+// and write it in one step, so that concurrent writers in other processes
+// cannot slip a change in between. Both methods return an error wrapping
+// ErrVersionConflict when the stored task is at another version.
+type Versioner interface {
+	// UpdateIfVersion replaces task if the stored task is at expected
+	UpdateIfVersion(task *models.Task, expected int64) error
+	// DeleteIfVersion deletes the task with id if it is at expected
+	DeleteIfVersion(id string, expected int64) error
+}
+
+// UpdateIfVersion replaces task if the stored task is at expected: natively
+// when store is a Versioner, and otherwise by checking the version in a
+// transaction, such as under the file lock of the JSON and WAL storages or
+// in a Bolt write transaction. Storages without either check and write in
+// two steps.
+func UpdateIfVersion(ctx context.Context, store Storage, task *models.Task, expected int64) error {
+	if versioner, ok := store.(Versioner); ok {
+		return versioner.UpdateIfVersion(task, expected)
+	}
+	return withVersion(ctx, store, task.ID, expected, func(tx Storage) error {
+		return tx.Update(task)
+	})
+}
+
+// DeleteIfVersion deletes the task with id if it is at expected, see UpdateIfVersion
+func DeleteIfVersion(ctx context.Context, store Storage, id string, expected int64) error {
+	if versioner, ok := store.(Versioner); ok {
+		return versioner.DeleteIfVersion(id, expected)
+	}
+	return withVersion(ctx, store, id, expected, func(tx Storage) error {
+		return tx.Delete(id)
+	})
+}
+
+// withVersion runs write after checking the version of the task with id,
+// in a transaction where store supports one
+func withVersion(ctx context.Context, store Storage, id string, expected int64, write func(tx Storage) error) error {
+	checked := func(tx Storage) error {
+		current, err := tx.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if current.Version != expected {
+			return fmt.Errorf("%w: task %s is at version %d", ErrVersionConflict, id, current.Version)
+		}
+		return write(tx)
+	}
+
+	err := WithTx(ctx, store, checked)
+	if errors.Is(err, ErrTxUnsupported) {
+		return checked(store)
+	}
+	return err
+}
+
+// versionMismatch explains why a conditional write of the task with id
+// matched nothing: the task no longer exists, or is at another version
+func versionMismatch(store Storage, id string, expected int64) error {
+	current, err := store.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if current.Version == expected {
+		// MySQL counts only changed rows, so rewriting a task unchanged affects none
+		return nil
+	}
+	return fmt.Errorf("%w: task %s is at version %d", ErrVersionConflict, id, current.Version)
+}
+
+// FreshReader is implemented by storages that cache reads, and by decorators
+// of them, to read a task as currently stored
+type FreshReader interface {
+	GetFresh(id string) (*models.Task, error)
+}
+
+// GetFresh returns the task with id as currently stored, reading past any
+// cache in front of the storage
+func GetFresh(store Storage, id string) (*models.Task, error) {
+	if reader, ok := store.(FreshReader); ok {
+		return reader.GetFresh(id)
+	}
+	return store.GetByID(id)
+}
+
+// updateGormTaskIfVersion implements UpdateIfVersion for PostgreSQL and MySQL
+func updateGormTaskIfVersion(store Storage, db *gorm.DB, task *models.Task, expected int64) error {
+	// Save would insert the task when the version does not match
+	result := db.Model(task).Where("version = ?", expected).Select("*").Updates(task)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update task: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return versionMismatch(store, task.ID, expected)
+	}
+	return nil
+}
+
+// deleteGormTaskIfVersion implements DeleteIfVersion for PostgreSQL and MySQL
+func deleteGormTaskIfVersion(store Storage, db *gorm.DB, id string, expected int64) error {
+	result := db.Delete(&models.Task{}, "id = ? AND version = ?", id, expected)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete task: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return versionMismatch(store, id, expected)
+	}
+	return nil
+}
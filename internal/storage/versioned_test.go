@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"GoTask_Management/internal/metrics"
+)
+
+func TestVersioned(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	jsonStorage := func(name string) *JSONStorage {
+		store, err := NewJSONStorage(helper.TempFilePath(name))
+		helper.AssertNoError(err, "creating JSON storage")
+		return store
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		testVersionedCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		testVersionedCompliance(t, jsonStorage("versioned.json"))
+	})
+
+	t.Run("wal", func(t *testing.T) {
+		store, err := NewWALStorage(WALConfig{Path: helper.TempFilePath("versioned_wal.json")})
+		helper.AssertNoError(err, "creating WAL storage")
+		defer store.Close()
+		testVersionedCompliance(t, store)
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStorage(BoltConfig{Path: helper.TempFilePath("versioned.db")})
+		helper.AssertNoError(err, "creating Bolt storage")
+		defer store.Close()
+		testVersionedCompliance(t, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("versioned.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+		testVersionedCompliance(t, store)
+	})
+
+	t.Run("decorators", func(t *testing.T) {
+		var store Storage = jsonStorage("versioned_decorated.json")
+		store = NewInstrumentedStorage(store, metrics.NewRegistry())
+		store = NewCachedStorage(store, CacheConfig{})
+		store = NewTracingStorage(store, nil)
+		testVersionedCompliance(t, store)
+	})
+
+	t.Run("checks the stored version behind a stale cache", func(t *testing.T) {
+		path := helper.TempFilePath("versioned_shared.json")
+		shared, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+		cached := NewCachedStorage(shared, CacheConfig{})
+		other := jsonStorage("versioned_shared.json")
+
+		helper.AssertNoError(cached.Create(helper.CreateSampleTask("v1", "Cached")), "creating task")
+		stale, _ := cached.GetByID("v1")
+
+		// Another process moves the task on while the cache holds version 1
+		changed, _ := other.GetByID("v1")
+		changed.Title, changed.Version = "Changed elsewhere", 2
+		helper.AssertNoError(other.Update(changed), "updating task elsewhere")
+
+		stale.Title, stale.Version = "Overwrite", 2
+		if err := UpdateIfVersion(context.Background(), cached, stale, 1); !errors.Is(err, ErrVersionConflict) {
+			t.Fatalf("Expected ErrVersionConflict, got %v", err)
+		}
+		fresh, err := GetFresh(cached, "v1")
+		helper.AssertNoError(err, "reading past the cache")
+		if fresh.Title != "Changed elsewhere" || fresh.Version != 2 {
+			t.Errorf("Expected the task as stored, got %+v", fresh)
+		}
+	})
+}
+
+// testVersionedCompliance checks conditional writes against store
+func testVersionedCompliance(t *testing.T, store Storage) {
+	ctx := context.Background()
+	helper := NewTestHelper(t)
+
+	task := helper.CreateSampleTask("cas_1", "Versioned")
+	task.Version = 1
+	helper.AssertNoError(store.Create(task), "creating task")
+
+	updated := *task
+	updated.Title, updated.Version = "Updated", 2
+	helper.AssertNoError(UpdateIfVersion(ctx, store, &updated, 1), "updating at the stored version")
+
+	stale := *task
+	stale.Title, stale.Version = "Stale", 2
+	if err := UpdateIfVersion(ctx, store, &stale, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict updating at a stale version, got %v", err)
+	}
+	if got, _ := store.GetByID(task.ID); got.Title != "Updated" || got.Version != 2 {
+		t.Errorf("Expected the stale update to be rejected, got %+v", got)
+	}
+
+	if err := DeleteIfVersion(ctx, store, task.ID, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict deleting at a stale version, got %v", err)
+	}
+	helper.AssertNoError(DeleteIfVersion(ctx, store, task.ID, 2), "deleting at the stored version")
+	if _, err := store.GetByID(task.ID); err == nil {
+		t.Error("Expected the task to be deleted")
+	}
+
+	missing := helper.CreateSampleTask("cas_missing", "Missing")
+	if err := UpdateIfVersion(ctx, store, missing, 0); err == nil || errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected a not found error for a missing task, got %v", err)
+	}
+	if err := DeleteIfVersion(ctx, store, missing.ID, 0); err == nil || errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected a not found error for a missing task, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ViewRecord is a saved view as stored. The views package owns the view
+// itself, which Data holds encoded as JSON.
+type ViewRecord struct {
+	ID        string    `json:"id" gorm:"primaryKey;size:64" bson:"_id"`
+	Data      []byte    `json:"data" gorm:"not null" bson:"data"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null" bson:"created_at"`
+}
+
+// TableName keeps the SQL table name independent of the type name
+func (ViewRecord) TableName() string {
+	return "saved_views"
+}
+
+// ViewStore is implemented by storages that can keep saved views next to the
+// tasks, so that every server and CLI sharing the database sees them
+type ViewStore interface {
+	// ListViews returns every view record, in no particular order
+	ListViews() ([]*ViewRecord, error)
+	// SaveView inserts or replaces the record with record.ID
+	SaveView(record *ViewRecord) error
+	// DeleteView removes the record with id, if any
+	DeleteView(id string) error
+}
+
+// AsViewStore returns the ViewStore behind store, looking through decorators
+// that expose the storage they wrap
+func AsViewStore(store Storage) (ViewStore, bool) {
+	for store != nil {
+		if s, ok := store.(ViewStore); ok {
+			return s, true
+		}
+		unwrapper, ok := store.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		store = unwrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// MemoryViewStore keeps view records in memory for MemoryStorage
+type MemoryViewStore struct {
+	mu      sync.Mutex
+	records map[string]*ViewRecord
+}
+
+// NewMemoryViewStore creates an empty MemoryViewStore
+func NewMemoryViewStore() *MemoryViewStore {
+	return &MemoryViewStore{records: make(map[string]*ViewRecord)}
+}
+
+// ListViews implements ViewStore
+func (s *MemoryViewStore) ListViews() ([]*ViewRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]*ViewRecord, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, copyViewRecord(record))
+	}
+	return records, nil
+}
+
+// SaveView implements ViewStore
+func (s *MemoryViewStore) SaveView(record *ViewRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.ID] = copyViewRecord(record)
+	return nil
+}
+
+// DeleteView implements ViewStore
+func (s *MemoryViewStore) DeleteView(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, id)
+	return nil
+}
+
+func copyViewRecord(record *ViewRecord) *ViewRecord {
+	copied := *record
+	copied.Data = append([]byte(nil), record.Data...)
+	return &copied
+}
+
+// fileViewStore keeps view records for the JSON and WAL storages in a file
+// next to the storage file, path+".views", under the storage's file lock
+type fileViewStore struct {
+	viewsPath string
+	viewsLock *fileLock
+}
+
+func newFileViewStore(path string, timeout time.Duration) *fileViewStore {
+	return &fileViewStore{viewsPath: path + ".views", viewsLock: newFileLock(path, timeout)}
+}
+
+// ListViews implements ViewStore
+func (s *fileViewStore) ListViews() ([]*ViewRecord, error) {
+	unlock, err := s.viewsLock.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	records, err := s.loadViews()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*ViewRecord, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+	return list, nil
+}
+
+// SaveView implements ViewStore
+func (s *fileViewStore) SaveView(record *ViewRecord) error {
+	return s.updateViews(func(records map[string]*ViewRecord) {
+		records[record.ID] = record
+	})
+}
+
+// DeleteView implements ViewStore
+func (s *fileViewStore) DeleteView(id string) error {
+	return s.updateViews(func(records map[string]*ViewRecord) {
+		delete(records, id)
+	})
+}
+
+// updateViews runs fn on the records under the exclusive file lock and
+// saves them
+func (s *fileViewStore) updateViews(fn func(records map[string]*ViewRecord)) error {
+	unlock, err := s.viewsLock.acquire(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := s.loadViews()
+	if err != nil {
+		return err
+	}
+	fn(records)
+	if err := writeSidecar(s.viewsPath, records, 0644); err != nil {
+		return fmt.Errorf("failed to save views: %w", err)
+	}
+	return nil
+}
+
+// loadViews reads the records; callers hold the file lock
+func (s *fileViewStore) loadViews() (map[string]*ViewRecord, error) {
+	records := make(map[string]*ViewRecord)
+	if err := readSidecar(s.viewsPath, &records); err != nil {
+		return nil, fmt.Errorf("failed to read views: %w", err)
+	}
+	return records, nil
+}
+
+// The gorm helpers below implement ViewStore for PostgreSQL and MySQL
+
+func listGormViews(db *gorm.DB) ([]*ViewRecord, error) {
+	var records []*ViewRecord
+	if err := db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+	return records, nil
+}
+
+func saveGormView(db *gorm.DB, record *ViewRecord) error {
+	if err := db.Save(record).Error; err != nil {
+		return fmt.Errorf("failed to save view: %w", err)
+	}
+	return nil
+}
+
+func deleteGormView(db *gorm.DB, id string) error {
+	if err := db.Delete(&ViewRecord{ID: id}).Error; err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	return nil
+}
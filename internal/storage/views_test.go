@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/metrics"
+)
+
+func TestViewStore(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	t.Run("memory", func(t *testing.T) {
+		testViewStoreCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		store, err := NewJSONStorage(helper.TempFilePath("views.json"))
+		helper.AssertNoError(err, "creating JSON storage")
+		testViewStoreCompliance(t, store)
+	})
+
+	t.Run("wal", func(t *testing.T) {
+		store, err := NewWALStorage(WALConfig{Path: helper.TempFilePath("views_wal.json")})
+		helper.AssertNoError(err, "creating WAL storage")
+		defer store.Close()
+		testViewStoreCompliance(t, store)
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStorage(BoltConfig{Path: helper.TempFilePath("views.db")})
+		helper.AssertNoError(err, "creating Bolt storage")
+		defer store.Close()
+		testViewStoreCompliance(t, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("views.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+		testViewStoreCompliance(t, store)
+	})
+
+	t.Run("found through decorators", func(t *testing.T) {
+		memory := NewMemoryStorage()
+		var store Storage = NewCachedStorage(memory, CacheConfig{})
+		store = NewInstrumentedStorage(store, metrics.NewRegistry())
+		store = NewTracingStorage(store, nil)
+
+		found, ok := AsViewStore(store)
+		if !ok || found != ViewStore(memory) {
+			t.Fatalf("Expected the wrapped MemoryStorage, got %T", found)
+		}
+	})
+
+	t.Run("shared by file storages on the same file", func(t *testing.T) {
+		path := helper.TempFilePath("views_shared.json")
+		first, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+		second, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+
+		helper.AssertNoError(first.SaveView(&ViewRecord{ID: "shared", Data: []byte(`{}`), CreatedAt: time.Now()}), "saving view")
+		if records, err := second.ListViews(); err != nil || len(records) != 1 {
+			t.Errorf("Expected the other storage to list the view, got %v, %v", records, err)
+		}
+	})
+}
+
+// testViewStoreCompliance checks that a ViewStore saves, replaces and
+// deletes view records
+func testViewStoreCompliance(t *testing.T, store ViewStore) {
+	// IDs are unique per run, for databases that outlive the test
+	prefix := fmt.Sprintf("view_%d_", time.Now().UnixNano())
+	find := func(id string) *ViewRecord {
+		records, err := store.ListViews()
+		if err != nil {
+			t.Fatalf("Failed to list views: %v", err)
+		}
+		for _, record := range records {
+			if record.ID == id {
+				return record
+			}
+		}
+		return nil
+	}
+
+	created := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	record := &ViewRecord{ID: prefix + "1", Data: []byte(`{"name":"Inbox"}`), CreatedAt: created}
+	if err := store.SaveView(record); err != nil {
+		t.Fatalf("Failed to save view: %v", err)
+	}
+	got := find(record.ID)
+	if got == nil || string(got.Data) != `{"name":"Inbox"}` || !got.CreatedAt.Equal(created) {
+		t.Fatalf("Expected the saved view, got %+v", got)
+	}
+
+	record.Data = []byte(`{"name":"Renamed"}`)
+	if err := store.SaveView(record); err != nil {
+		t.Fatalf("Failed to replace view: %v", err)
+	}
+	if got := find(record.ID); got == nil || string(got.Data) != `{"name":"Renamed"}` {
+		t.Errorf("Expected the replaced view, got %+v", got)
+	}
+
+	if err := store.DeleteView(record.ID); err != nil {
+		t.Fatalf("Failed to delete view: %v", err)
+	}
+	if got := find(record.ID); got != nil {
+		t.Errorf("Expected the view to be deleted, got %+v", got)
+	}
+	if err := store.DeleteView(record.ID); err != nil {
+		t.Errorf("Expected deleting a missing view to succeed, got %v", err)
+	}
+}
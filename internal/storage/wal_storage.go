@@ -0,0 +1,603 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"GoTask_Management/internal/models"
+)
+
+// DefaultWALCompactEvery is the log length that triggers compaction when
+// WALConfig.CompactEvery is zero
+const DefaultWALCompactEvery = 1000
+
+// WALConfig holds the configuration for WALStorage
+type WALConfig struct {
+	// Path is the snapshot file; the log is kept next to it with a .wal suffix
+	Path string
+	// CompactEvery is the number of logged writes after which the log is
+	// folded into the snapshot
+	CompactEvery int
+	// LockTimeout is how long operations wait for another process to
+	// release the files before failing with ErrLockTimeout
+	LockTimeout time.Duration
+	Logger      *slog.Logger
+}
+
+// WALStorage keeps tasks in memory and persists each write by appending it to
+// a write-ahead log and syncing it, instead of rewriting every task like
+// JSONStorage. The log is folded into a snapshot, in the JSONStorage file
+// format, once it grows past CompactEvery records and on Close. After a crash
+// mid-write, the incomplete record at the end of the log is discarded.
+//
+// Several processes may share the files: each operation takes the file lock
+// and first catches up with records other processes appended. Idempotency
+// records, saved views, webhooks and sent notifications are kept in files
+// next to the snapshot, as by JSONStorage.
+type WALStorage struct {
+	*fileIdempotencyStore
+	*fileViewStore
+	*fileWebhookStore
+	*fileNotificationStore
+
+	path         string
+	logPath      string
+	compactEvery int
+	logger       *slog.Logger
+	lock         *fileLock
+
+	mu    sync.Mutex
+	tasks []*models.Task
+	index map[string]int // task ID to position in tasks
+	log   *os.File
+	// snapshotInfo identifies the snapshot the tasks were loaded from; nil
+	// when there was none
+	snapshotInfo os.FileInfo
+	// logSize and records describe the complete records in the log
+	logSize int64
+	records int
+	// failed is set when the log could not be restored after a failed write
+	failed error
+}
+
+// walRecord is a logged write: the full task for "put", its ID for "delete",
+// and for "batch" the records of a transaction, which replay all or nothing
+type walRecord struct {
+	Op      string       `json:"op"`
+	Task    *models.Task `json:"task,omitempty"`
+	ID      string       `json:"id,omitempty"`
+	Records []walRecord  `json:"records,omitempty"`
+}
+
+// NewWALStorage opens the snapshot and log at config.Path, creating them if
+// needed, and replays the log
+func NewWALStorage(config WALConfig) (*WALStorage, error) {
+	if config.CompactEvery <= 0 {
+		config.CompactEvery = DefaultWALCompactEvery
+	}
+	ws := &WALStorage{
+		fileIdempotencyStore:  newFileIdempotencyStore(config.Path, config.LockTimeout),
+		fileViewStore:         newFileViewStore(config.Path, config.LockTimeout),
+		fileWebhookStore:      newFileWebhookStore(config.Path, config.LockTimeout),
+		fileNotificationStore: newFileNotificationStore(config.Path, config.LockTimeout),
+		path:                  config.Path,
+		logPath:               config.Path + ".wal",
+		compactEvery:          config.CompactEvery,
+		logger:                loggerOrDefault(config.Logger),
+		lock:                  newFileLock(config.Path, config.LockTimeout),
+		index:                 make(map[string]int),
+	}
+
+	unlock, err := ws.lock.acquire(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := ws.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	log, err := os.OpenFile(ws.logPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log: %w", err)
+	}
+	ws.log = log
+	if err := ws.replay(true); err != nil {
+		log.Close()
+		return nil, err
+	}
+
+	if ws.records >= ws.compactEvery {
+		if err := ws.compact(); err != nil {
+			log.Close()
+			return nil, err
+		}
+	}
+	return ws, nil
+}
+
+func (ws *WALStorage) loadSnapshot() error {
+	info, err := os.Stat(ws.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	data, err := os.ReadFile(ws.path)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	ws.snapshotInfo = info
+
+	var tasks []*models.Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	for _, task := range tasks {
+		ws.put(task)
+	}
+	return nil
+}
+
+// begin takes the file lock for an operation and catches up with writes
+// made by other processes. Callers hold ws.mu.
+func (ws *WALStorage) begin(writing bool) (func(), error) {
+	if ws.log == nil {
+		return nil, fmt.Errorf("storage is closed")
+	}
+	unlock, err := ws.lock.acquire(writing)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.refresh(writing); err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlock, nil
+}
+
+// refresh reloads everything when another process compacted the log into a
+// new snapshot, and otherwise replays the records appended since the last
+// operation
+func (ws *WALStorage) refresh(writing bool) error {
+	info, err := os.Stat(ws.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	logInfo, err := ws.log.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+
+	if !sameFileState(info, ws.snapshotInfo) || logInfo.Size() < ws.logSize {
+		ws.tasks, ws.index, ws.snapshotInfo = nil, make(map[string]int), nil
+		ws.logSize, ws.records = 0, 0
+		if err := ws.loadSnapshot(); err != nil {
+			return err
+		}
+	}
+	return ws.replay(writing)
+}
+
+// replay applies the complete records in the log after logSize. A writer also
+// truncates anything after the last one, which a crash left half written.
+func (ws *WALStorage) replay(writing bool) error {
+	if _, err := ws.log.Seek(ws.logSize, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek log: %w", err)
+	}
+	reader := bufio.NewReader(ws.log)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			break
+		}
+		record, decodeErr := decodeWALRecord(line)
+		if err != nil || decodeErr != nil {
+			if !writing {
+				break
+			}
+			if decodeErr == nil {
+				decodeErr = err
+			}
+			ws.logger.Warn("discarding incomplete write-ahead log tail",
+				"path", ws.logPath, "offset", ws.logSize, "error", decodeErr)
+			if err := ws.log.Truncate(ws.logSize); err != nil {
+				return fmt.Errorf("failed to truncate log: %w", err)
+			}
+			if err := ws.log.Sync(); err != nil {
+				return fmt.Errorf("failed to sync log: %w", err)
+			}
+			break
+		}
+		ws.apply(record)
+		ws.logSize += int64(len(line))
+		ws.records++
+	}
+
+	if _, err := ws.log.Seek(ws.logSize, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek log: %w", err)
+	}
+	return nil
+}
+
+// encodeWALRecord frames a record as a checksum and JSON on one line
+func encodeWALRecord(record walRecord) ([]byte, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	line := make([]byte, 0, len(data)+10)
+	line = fmt.Appendf(line, "%08x ", crc32.ChecksumIEEE(data))
+	line = append(line, data...)
+	return append(line, '\n'), nil
+}
+
+func decodeWALRecord(line []byte) (walRecord, error) {
+	var record walRecord
+	checksum, data, ok := bytes.Cut(bytes.TrimSuffix(line, []byte("\n")), []byte(" "))
+	if !ok || !bytes.HasSuffix(line, []byte("\n")) {
+		return record, fmt.Errorf("incomplete record")
+	}
+	want, err := strconv.ParseUint(string(checksum), 16, 32)
+	if err != nil || uint32(want) != crc32.ChecksumIEEE(data) {
+		return record, fmt.Errorf("checksum mismatch")
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, err
+	}
+	if !validWALRecord(record) {
+		return record, fmt.Errorf("invalid record")
+	}
+	return record, nil
+}
+
+// validWALRecord reports whether record, and every record in a batch, has
+// what its operation needs
+func validWALRecord(record walRecord) bool {
+	switch record.Op {
+	case "put":
+		return record.Task != nil
+	case "delete":
+		return record.ID != ""
+	case "batch":
+		for _, r := range record.Records {
+			if r.Op == "batch" || !validWALRecord(r) {
+				return false
+			}
+		}
+		return len(record.Records) > 0
+	}
+	return false
+}
+
+func (ws *WALStorage) apply(record walRecord) {
+	switch record.Op {
+	case "delete":
+		ws.remove(record.ID)
+	case "batch":
+		for _, r := range record.Records {
+			ws.apply(r)
+		}
+	default:
+		ws.put(record.Task)
+	}
+}
+
+// put stores a copy of task, keeping the position of an existing task
+func (ws *WALStorage) put(task *models.Task) {
+	stored := copyTask(task)
+	if i, ok := ws.index[task.ID]; ok {
+		ws.tasks[i] = stored
+		return
+	}
+	ws.index[task.ID] = len(ws.tasks)
+	ws.tasks = append(ws.tasks, stored)
+}
+
+func (ws *WALStorage) remove(id string) {
+	i, ok := ws.index[id]
+	if !ok {
+		return
+	}
+	ws.tasks = append(ws.tasks[:i], ws.tasks[i+1:]...)
+	delete(ws.index, id)
+	for j := i; j < len(ws.tasks); j++ {
+		ws.index[ws.tasks[j].ID] = j
+	}
+}
+
+// append logs record and syncs it, then applies it in memory
+func (ws *WALStorage) append(record walRecord) error {
+	if ws.failed != nil {
+		return ws.failed
+	}
+	if ws.log == nil {
+		return fmt.Errorf("storage is closed")
+	}
+
+	line, err := encodeWALRecord(record)
+	if err != nil {
+		return err
+	}
+	if _, err := ws.log.Write(line); err != nil {
+		return ws.rollback(err)
+	}
+	if err := ws.log.Sync(); err != nil {
+		return ws.rollback(err)
+	}
+	ws.logSize += int64(len(line))
+	ws.records++
+	ws.apply(record)
+
+	if ws.records >= ws.compactEvery {
+		if err := ws.compact(); err != nil {
+			// The write itself is durable in the log; compaction is retried
+			// after the next write
+			ws.logger.Warn("failed to compact write-ahead log", "path", ws.path, "error", err)
+		}
+	}
+	return nil
+}
+
+// rollback removes a partially written record, so later records are not
+// appended after it
+func (ws *WALStorage) rollback(cause error) error {
+	if err := ws.log.Truncate(ws.logSize); err != nil {
+		ws.failed = fmt.Errorf("write-ahead log is unusable after a failed write: %w", err)
+		return ws.failed
+	}
+	if _, err := ws.log.Seek(ws.logSize, io.SeekStart); err != nil {
+		ws.failed = fmt.Errorf("write-ahead log is unusable after a failed write: %w", err)
+		return ws.failed
+	}
+	return fmt.Errorf("failed to write log: %w", cause)
+}
+
+// compact writes every task to a new snapshot and empties the log. A crash
+// between the two leaves records that are already in the snapshot, which
+// replay again to the same state.
+func (ws *WALStorage) compact() error {
+	data, err := json.MarshalIndent(ws.tasks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if ws.tasks == nil {
+		data = []byte("[]")
+	}
+
+	tempFile := ws.path + ".tmp"
+	if err := writeFileSync(tempFile, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFile, ws.path); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(ws.path)); err != nil {
+		return err
+	}
+	info, err := os.Stat(ws.path)
+	if err != nil {
+		return err
+	}
+	ws.snapshotInfo = info
+
+	if err := ws.log.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := ws.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	ws.logSize, ws.records = 0, 0
+	return ws.log.Sync()
+}
+
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir makes a rename in dir durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// Create implements Storage interface
+func (ws *WALStorage) Create(task *models.Task) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	unlock, err := ws.begin(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, exists := ws.index[task.ID]; exists {
+		return fmt.Errorf("task already exists")
+	}
+	return ws.append(walRecord{Op: "put", Task: task})
+}
+
+// GetAll implements Storage interface
+func (ws *WALStorage) GetAll() ([]*models.Task, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	unlock, err := ws.begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	tasks := copyTasks(ws.tasks)
+	if tasks == nil {
+		tasks = make([]*models.Task, 0)
+	}
+	return tasks, nil
+}
+
+// GetByID implements Storage interface
+func (ws *WALStorage) GetByID(id string) (*models.Task, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	unlock, err := ws.begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	i, ok := ws.index[id]
+	if !ok {
+		return nil, fmt.Errorf("task not found")
+	}
+	return copyTask(ws.tasks[i]), nil
+}
+
+// Update implements Storage interface
+func (ws *WALStorage) Update(task *models.Task) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	unlock, err := ws.begin(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, exists := ws.index[task.ID]; !exists {
+		return fmt.Errorf("task not found")
+	}
+	return ws.append(walRecord{Op: "put", Task: task})
+}
+
+// Delete implements Storage interface
+func (ws *WALStorage) Delete(id string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	unlock, err := ws.begin(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, exists := ws.index[id]; !exists {
+		return fmt.Errorf("task not found")
+	}
+	return ws.append(walRecord{Op: "delete", ID: id})
+}
+
+// WithTx implements Transactor as a copy-on-write batch: fn works on a copy
+// of the tasks, and its changes are logged as one record when it succeeds
+func (ws *WALStorage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	unlock, err := ws.begin(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	batch := newMemoryBatch(ws.tasks)
+	if err := fn(batch); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var records []walRecord
+	after := indexTasks(batch.tasks)
+	for _, task := range ws.tasks {
+		if _, ok := after[task.ID]; !ok {
+			records = append(records, walRecord{Op: "delete", ID: task.ID})
+		}
+	}
+	for _, task := range batch.tasks {
+		if i, ok := ws.index[task.ID]; !ok || !sameTask(ws.tasks[i], task) {
+			records = append(records, walRecord{Op: "put", Task: task})
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return ws.append(walRecord{Op: "batch", Records: records})
+}
+
+// Compact folds the log into the snapshot now
+func (ws *WALStorage) Compact() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	unlock, err := ws.begin(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return ws.compact()
+}
+
+// HealthCheck reports a log that can no longer be written
+func (ws *WALStorage) HealthCheck() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.log == nil {
+		return fmt.Errorf("storage is closed")
+	}
+	return ws.failed
+}
+
+// Close compacts the log and closes it
+func (ws *WALStorage) Close() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.log == nil {
+		return nil
+	}
+	var err error
+	if ws.failed == nil {
+		var unlock func()
+		if unlock, err = ws.begin(true); err == nil {
+			if ws.records > 0 {
+				err = ws.compact()
+			}
+			unlock()
+		}
+	}
+	if closeErr := ws.log.Close(); err == nil {
+		err = closeErr
+	}
+	ws.log = nil
+	return err
+}
+
+// Verify that WALStorage implements Storage interface
+var _ Storage = (*WALStorage)(nil)
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWALStorage(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	open := func(path string, compactEvery int) *WALStorage {
+		t.Helper()
+		store, err := NewWALStorage(WALConfig{Path: path, CompactEvery: compactEvery})
+		if err != nil {
+			t.Fatalf("Failed to open WAL storage: %v", err)
+		}
+		return store
+	}
+
+	t.Run("passes storage compliance", func(t *testing.T) {
+		store := open(helper.TempFilePath("wal_compliance.json"), 0)
+		defer store.Close()
+		testStorageCompliance(t, store)
+	})
+
+	t.Run("replays the log after reopening", func(t *testing.T) {
+		path := helper.TempFilePath("wal_replay.json")
+		store := open(path, 0)
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("r1", "First")), "creating task")
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("r2", "Second")), "creating task")
+		task := helper.CreateSampleTask("r1", "First, renamed")
+		helper.AssertNoError(store.Update(task), "updating task")
+		helper.AssertNoError(store.Delete("r2"), "deleting task")
+		// Simulate a crash: the log is not compacted
+		store.log.Close()
+
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("Expected no snapshot before compaction, got %v", err)
+		}
+		reopened := open(path, 0)
+		defer reopened.Close()
+		tasks, _ := reopened.GetAll()
+		if len(tasks) != 1 || tasks[0].Title != "First, renamed" {
+			t.Errorf("Expected the renamed task only, got %+v", tasks)
+		}
+	})
+
+	t.Run("discards a torn write", func(t *testing.T) {
+		path := helper.TempFilePath("wal_torn.json")
+		store := open(path, 0)
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("t1", "Durable")), "creating task")
+		store.log.Close()
+
+		// A crash in the middle of appending the next record
+		line, _ := encodeWALRecord(walRecord{Op: "put", Task: helper.CreateSampleTask("t2", "Torn")})
+		f, _ := os.OpenFile(path+".wal", os.O_APPEND|os.O_WRONLY, 0644)
+		f.Write(line[:len(line)/2])
+		f.Close()
+
+		reopened := open(path, 0)
+		if tasks, _ := reopened.GetAll(); len(tasks) != 1 || tasks[0].ID != "t1" {
+			t.Fatalf("Expected only the durable task, got %+v", tasks)
+		}
+		// Later writes are not lost behind the torn record
+		helper.AssertNoError(reopened.Create(helper.CreateSampleTask("t3", "After")), "creating task")
+		reopened.log.Close()
+
+		again := open(path, 0)
+		defer again.Close()
+		if tasks, _ := again.GetAll(); len(tasks) != 2 {
+			t.Errorf("Expected 2 tasks, got %+v", tasks)
+		}
+	})
+
+	t.Run("rejects a corrupted record", func(t *testing.T) {
+		path := helper.TempFilePath("wal_corrupt.json")
+		store := open(path, 0)
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("c1", "Intact")), "creating task")
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("c2", "Corrupted")), "creating task")
+		store.log.Close()
+
+		data, _ := os.ReadFile(path + ".wal")
+		os.WriteFile(path+".wal", []byte(strings.Replace(string(data), "Corrupted", "Corrupteb", 1)), 0644)
+
+		reopened := open(path, 0)
+		defer reopened.Close()
+		if tasks, _ := reopened.GetAll(); len(tasks) != 1 || tasks[0].ID != "c1" {
+			t.Errorf("Expected the record with a bad checksum to be dropped, got %+v", tasks)
+		}
+	})
+
+	t.Run("compacts into a snapshot", func(t *testing.T) {
+		path := helper.TempFilePath("wal_compact.json")
+		store := open(path, 3)
+		for _, id := range []string{"k1", "k2", "k3", "k4"} {
+			helper.AssertNoError(store.Create(helper.CreateSampleTask(id, id)), "creating task")
+		}
+		if store.records != 1 {
+			t.Errorf("Expected the log to restart after 3 records, got %d records", store.records)
+		}
+
+		// The snapshot is readable by the JSON backend
+		snapshot, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "opening snapshot")
+		if tasks, _ := snapshot.GetAll(); len(tasks) != 3 {
+			t.Errorf("Expected 3 tasks in the snapshot, got %d", len(tasks))
+		}
+
+		helper.AssertNoError(store.Close(), "closing")
+		if info, _ := os.Stat(path + ".wal"); info.Size() != 0 {
+			t.Errorf("Expected an empty log after close, got %d bytes", info.Size())
+		}
+		reopened := open(path, 3)
+		defer reopened.Close()
+		if tasks, _ := reopened.GetAll(); len(tasks) != 4 || tasks[3].ID != "k4" {
+			t.Errorf("Expected all 4 tasks in order, got %+v", tasks)
+		}
+	})
+
+	t.Run("survives a crash between snapshot and log truncation", func(t *testing.T) {
+		path := helper.TempFilePath("wal_replay_twice.json")
+		store := open(path, 0)
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("d1", "Created")), "creating task")
+		helper.AssertNoError(store.Delete("d1"), "deleting task")
+		helper.AssertNoError(store.Create(helper.CreateSampleTask("d2", "Kept")), "creating task")
+		log, _ := os.ReadFile(path + ".wal")
+		helper.AssertNoError(store.Close(), "closing")
+
+		// Restore the log as if truncation never happened
+		os.WriteFile(path+".wal", log, 0644)
+		reopened := open(path, 0)
+		defer reopened.Close()
+		if tasks, _ := reopened.GetAll(); len(tasks) != 1 || tasks[0].ID != "d2" {
+			t.Errorf("Expected replay over the snapshot to give the same state, got %+v", tasks)
+		}
+	})
+
+	t.Run("opens an existing JSON file", func(t *testing.T) {
+		path := helper.TempFilePath("wal_from_json.json")
+		legacy, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+		helper.AssertNoError(legacy.Create(helper.CreateSampleTask("j1", "Migrated")), "creating task")
+
+		store := open(path, 0)
+		defer store.Close()
+		if task, err := store.GetByID("j1"); err != nil || task.Title != "Migrated" {
+			t.Errorf("Expected the existing task, got %+v, %v", task, err)
+		}
+	})
+
+	t.Run("guards stored tasks", func(t *testing.T) {
+		store := open(helper.TempFilePath("wal_copies.json"), 0)
+		defer store.Close()
+
+		task := helper.CreateSampleTask("g1", "Original")
+		helper.AssertNoError(store.Create(task), "creating task")
+		task.Title = "Mutated after create"
+		got, _ := store.GetByID("g1")
+		got.Title = "Mutated after get"
+
+		if got, _ := store.GetByID("g1"); got.Title != "Original" {
+			t.Errorf("Expected the stored task to be unaffected, got %q", got.Title)
+		}
+		helper.AssertError(store.Create(helper.CreateSampleTask("g1", "Duplicate")), true, "creating a duplicate")
+	})
+}
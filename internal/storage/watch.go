@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"time"
+
+	"GoTask_Management/internal/models"
+)
+
+// ChangeOp is the kind of write reported by a Watcher
+type ChangeOp string
+
+const (
+	ChangeCreate ChangeOp = "create"
+	ChangeUpdate ChangeOp = "update"
+	ChangeDelete ChangeOp = "delete"
+)
+
+// Change is a write to a storage, made by this process or another one
+type Change struct {
+	Op     ChangeOp
+	TaskID string
+	// Task is the task as stored after the write; nil for deletions
+	Task *models.Task
+}
+
+// Watcher is implemented by storages that report writes, including those
+// made by other processes sharing the same file or database
+type Watcher interface {
+	// Watch reports changes until ctx is done, then closes the channel.
+	// Errors while watching are logged and retried where possible; if
+	// watching cannot continue, the channel is closed early.
+	Watch(ctx context.Context) (<-chan Change, error)
+}
+
+// optionalWatcher is implemented by decorators that have a Watch method but
+// can only watch when the storage they wrap can
+type optionalWatcher interface {
+	canWatch() bool
+}
+
+// AsWatcher returns the Watcher behind store, looking through decorators
+// that expose the storage they wrap
+func AsWatcher(store Storage) (Watcher, bool) {
+	for store != nil {
+		if w, ok := store.(Watcher); ok {
+			if optional, ok := store.(optionalWatcher); !ok || optional.canWatch() {
+				return w, true
+			}
+		}
+		unwrapper, ok := store.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		store = unwrapper.Unwrap()
+	}
+	return nil, false
+}
+
+const (
+	// watchPollInterval is how often change logs are polled
+	watchPollInterval = time.Second
+	// watchRetryDelay is the pause before reconnecting a lost notification stream
+	watchRetryDelay = 5 * time.Second
+	// changeLogRetention is how long change log rows are kept for slower watchers
+	changeLogRetention = time.Hour
+	// changeLogGapTimeout is how long a gap in change log sequence numbers is
+	// waited on; writes still in flight fill it, rolled back ones never do
+	changeLogGapTimeout = 10 * time.Second
+)
+
+// sendChange delivers c unless ctx is done first
+func sendChange(ctx context.Context, changes chan<- Change, c Change) bool {
+	select {
+	case changes <- c:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// diffTasks returns the changes that turn before into after, ordered by task ID
+func diffTasks(before, after map[string]*models.Task) []Change {
+	var changes []Change
+	for id, task := range after {
+		old, existed := before[id]
+		switch {
+		case !existed:
+			changes = append(changes, Change{Op: ChangeCreate, TaskID: id, Task: task})
+		case !sameTask(old, task):
+			changes = append(changes, Change{Op: ChangeUpdate, TaskID: id, Task: task})
+		}
+	}
+	for id := range before {
+		if _, exists := after[id]; !exists {
+			changes = append(changes, Change{Op: ChangeDelete, TaskID: id})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].TaskID < changes[j].TaskID })
+	return changes
+}
+
+func indexTasks(tasks []*models.Task) map[string]*models.Task {
+	index := make(map[string]*models.Task, len(tasks))
+	for _, task := range tasks {
+		index[task.ID] = task
+	}
+	return index
+}
+
+// sameTask compares tasks by their stored form, which ignores time zone
+// pointers and monotonic clock readings
+func sameTask(a, b *models.Task) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
+
+// changeLog polls a task_changes table that database triggers append to on
+// every write, tracking the highest sequence number handled so far
+type changeLog struct {
+	db *sql.DB
+	// setup creates the table and triggers; statements must be idempotent
+	setup func(ctx context.Context) error
+	// prune deletes rows older than changeLogRetention
+	prune    string
+	get      func(id string) (*models.Task, error)
+	interval time.Duration
+	logger   *slog.Logger
+
+	// mark is the sequence number up to which every row has been handled
+	mark int64
+	// seen holds handled rows above mark, when a gap precedes them
+	seen     map[int64]bool
+	gapSince time.Time
+}
+
+func (l *changeLog) watch(ctx context.Context) (<-chan Change, error) {
+	if err := l.setup(ctx); err != nil {
+		return nil, err
+	}
+	if err := l.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) FROM task_changes`).Scan(&l.mark); err != nil {
+		return nil, err
+	}
+	l.seen = make(map[int64]bool)
+	if l.interval <= 0 {
+		l.interval = watchPollInterval
+	}
+
+	changes := make(chan Change)
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+		lastPrune := time.Now()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			batch, err := l.poll(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					l.logger.Warn("failed to poll task changes", "error", err)
+				}
+				continue
+			}
+			for _, c := range batch {
+				if !sendChange(ctx, changes, c) {
+					return
+				}
+			}
+
+			if time.Since(lastPrune) > changeLogRetention/4 {
+				lastPrune = time.Now()
+				if _, err := l.db.ExecContext(ctx, l.prune); err != nil && ctx.Err() == nil {
+					l.logger.Warn("failed to prune task changes", "error", err)
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// poll returns the changes logged since the last poll
+func (l *changeLog) poll(ctx context.Context) ([]Change, error) {
+	rows, err := l.db.QueryContext(ctx, `SELECT seq, task_id, op FROM task_changes WHERE seq > ? ORDER BY seq`, l.mark)
+	if err != nil {
+		return nil, err
+	}
+	type entry struct {
+		seq    int64
+		taskID string
+		op     ChangeOp
+	}
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.seq, &e.taskID, &e.op); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !l.seen[e.seq] {
+			entries = append(entries, e)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, e := range entries {
+		l.seen[e.seq] = true
+		c := Change{Op: e.op, TaskID: e.taskID}
+		if e.op != ChangeDelete {
+			task, err := l.get(e.taskID)
+			if err != nil {
+				// Deleted since; its own delete row follows
+				continue
+			}
+			c.Task = task
+		}
+		changes = append(changes, c)
+	}
+	l.advance()
+	return changes, nil
+}
+
+// advance moves the mark over handled rows. Rows above a gap are remembered
+// until the gap fills or times out.
+func (l *changeLog) advance() {
+	for l.seen[l.mark+1] {
+		delete(l.seen, l.mark+1)
+		l.mark++
+	}
+	if len(l.seen) == 0 {
+		l.gapSince = time.Time{}
+		return
+	}
+	if l.gapSince.IsZero() {
+		l.gapSince = time.Now()
+		return
+	}
+	if time.Since(l.gapSince) > changeLogGapTimeout {
+		for seq := range l.seen {
+			l.mark = max(l.mark, seq)
+		}
+		clear(l.seen)
+		l.gapSince = time.Time{}
+	}
+}
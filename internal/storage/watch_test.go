@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+)
+
+// testWatch checks that watcher reports writes made through writer, a
+// separate storage instance standing in for another process
+func testWatch(t *testing.T, watcher Watcher, writer Storage) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	changes, err := watcher.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+
+	expect := func(op ChangeOp, id, title string) {
+		t.Helper()
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				t.Fatalf("Expected a %s change, the channel was closed", op)
+			}
+			if change.Op != op || change.TaskID != id {
+				t.Fatalf("Expected %s of %s, got %s of %s", op, id, change.Op, change.TaskID)
+			}
+			if op == ChangeDelete {
+				if change.Task != nil {
+					t.Errorf("Expected no task for a deletion, got %+v", change.Task)
+				}
+			} else if change.Task == nil || change.Task.Title != title {
+				t.Errorf("Expected the task titled %q, got %+v", title, change.Task)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s of %s", op, id)
+		}
+	}
+
+	task := &models.Task{ID: "watched", Title: "Watched", CreatedAt: time.Now(), Version: 1}
+	if err := writer.Create(task); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	expect(ChangeCreate, task.ID, "Watched")
+
+	task.Title = "Renamed elsewhere"
+	task.Version++
+	if err := writer.Update(task); err != nil {
+		t.Fatalf("Failed to update task: %v", err)
+	}
+	expect(ChangeUpdate, task.ID, "Renamed elsewhere")
+
+	if err := writer.Delete(task.ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	expect(ChangeDelete, task.ID, "")
+
+	cancel()
+	select {
+	case _, ok := <-changes:
+		if ok {
+			t.Error("Expected no further changes")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the channel to close after cancellation")
+	}
+}
+
+func TestJSONStorage_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	watched, err := NewJSONStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	writer, err := NewJSONStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	testWatch(t, watched, writer)
+}
+
+func TestSQLiteStorage_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	watched, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer watched.Close()
+	watched.pollInterval = 20 * time.Millisecond
+
+	writer, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer writer.Close()
+
+	testWatch(t, watched, writer)
+}
+
+func TestPostgreSQLStorage_Watch(t *testing.T) {
+	if os.Getenv("POSTGRES_TEST_DSN") == "" {
+		t.Skip("PostgreSQL test skipped: POSTGRES_TEST_DSN not set")
+	}
+
+	config := PostgreSQLConfig{
+		Host:     getEnvOrDefault("POSTGRES_TEST_HOST", "localhost"),
+		Port:     5432,
+		User:     getEnvOrDefault("POSTGRES_TEST_USER", "postgres"),
+		Password: getEnvOrDefault("POSTGRES_TEST_PASSWORD", "password"),
+		DBName:   getEnvOrDefault("POSTGRES_TEST_DB", "gotask_test"),
+		SSLMode:  "disable",
+		TimeZone: "UTC",
+	}
+	watched, err := NewPostgreSQLStorage(config)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL storage: %v", err)
+	}
+	defer watched.Close()
+	writer, err := NewPostgreSQLStorage(config)
+	if err != nil {
+		t.Fatalf("Failed to create PostgreSQL storage: %v", err)
+	}
+	defer writer.Close()
+	cleanupPostgreSQL(t, writer)
+
+	testWatch(t, watched, writer)
+}
+
+func TestAsWatcher(t *testing.T) {
+	store, err := NewJSONStorage(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	if _, ok := AsWatcher(NewTracingStorage(store, nil)); !ok {
+		t.Error("Expected the watcher to be found through the decorator")
+	}
+	if _, ok := AsWatcher(NewTracingStorage(nil, nil)); ok {
+		t.Error("Expected no watcher without a watching storage")
+	}
+}
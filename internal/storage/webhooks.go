@@ -0,0 +1,348 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookSubscriptionRecord is a webhook subscription as stored. The webhook
+// package owns the subscription itself, which Data holds encoded as JSON.
+type WebhookSubscriptionRecord struct {
+	ID        string    `json:"id" gorm:"primaryKey;size:64" bson:"_id"`
+	Data      []byte    `json:"data" gorm:"not null" bson:"data"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null" bson:"created_at"`
+}
+
+// TableName keeps the SQL table name independent of the type name
+func (WebhookSubscriptionRecord) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// WebhookDeliveryRecord is a webhook delivery as stored, with Data holding
+// the delivery encoded as JSON
+type WebhookDeliveryRecord struct {
+	ID             string `json:"id" gorm:"primaryKey;size:64" bson:"_id"`
+	SubscriptionID string `json:"subscription_id" gorm:"size:64;not null;index" bson:"subscription_id"`
+	// Pending is set until the delivery succeeds or runs out of attempts
+	Pending   bool      `json:"pending" gorm:"not null" bson:"pending"`
+	Data      []byte    `json:"data" gorm:"not null" bson:"data"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null" bson:"created_at"`
+}
+
+// TableName keeps the SQL table name independent of the type name
+func (WebhookDeliveryRecord) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookStore is implemented by storages that can keep webhook
+// subscriptions and their delivery log next to the tasks, so that every
+// server sharing the database delivers to the same subscriptions
+type WebhookStore interface {
+	// ListWebhookSubscriptions returns every subscription record, in no
+	// particular order
+	ListWebhookSubscriptions() ([]*WebhookSubscriptionRecord, error)
+	// SaveWebhookSubscription inserts or replaces the record with record.ID
+	SaveWebhookSubscription(record *WebhookSubscriptionRecord) error
+	// DeleteWebhookSubscription removes the record with id, if any, and the
+	// deliveries made to it
+	DeleteWebhookSubscription(id string) error
+	// ListWebhookDeliveries returns the delivery records for subscriptionID,
+	// or every record when it is empty, in no particular order
+	ListWebhookDeliveries(subscriptionID string) ([]*WebhookDeliveryRecord, error)
+	// SaveWebhookDelivery inserts or replaces the record with record.ID
+	SaveWebhookDelivery(record *WebhookDeliveryRecord) error
+	// DeleteWebhookDeliveries removes the records with the given IDs, if any
+	DeleteWebhookDeliveries(ids []string) error
+}
+
+// AsWebhookStore returns the WebhookStore behind store, looking through
+// decorators that expose the storage they wrap
+func AsWebhookStore(store Storage) (WebhookStore, bool) {
+	for store != nil {
+		if s, ok := store.(WebhookStore); ok {
+			return s, true
+		}
+		unwrapper, ok := store.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		store = unwrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// webhookRecords holds every webhook record kept by the memory and file
+// stores
+type webhookRecords struct {
+	Subscriptions map[string]*WebhookSubscriptionRecord `json:"subscriptions"`
+	Deliveries    map[string]*WebhookDeliveryRecord     `json:"deliveries"`
+}
+
+func newWebhookRecords() *webhookRecords {
+	return &webhookRecords{
+		Subscriptions: make(map[string]*WebhookSubscriptionRecord),
+		Deliveries:    make(map[string]*WebhookDeliveryRecord),
+	}
+}
+
+func (r *webhookRecords) listSubscriptions() []*WebhookSubscriptionRecord {
+	list := make([]*WebhookSubscriptionRecord, 0, len(r.Subscriptions))
+	for _, record := range r.Subscriptions {
+		copied := *record
+		copied.Data = append([]byte(nil), record.Data...)
+		list = append(list, &copied)
+	}
+	return list
+}
+
+func (r *webhookRecords) deleteSubscription(id string) {
+	delete(r.Subscriptions, id)
+	for deliveryID, record := range r.Deliveries {
+		if record.SubscriptionID == id {
+			delete(r.Deliveries, deliveryID)
+		}
+	}
+}
+
+func (r *webhookRecords) listDeliveries(subscriptionID string) []*WebhookDeliveryRecord {
+	list := make([]*WebhookDeliveryRecord, 0)
+	for _, record := range r.Deliveries {
+		if subscriptionID != "" && record.SubscriptionID != subscriptionID {
+			continue
+		}
+		copied := *record
+		copied.Data = append([]byte(nil), record.Data...)
+		list = append(list, &copied)
+	}
+	return list
+}
+
+// MemoryWebhookStore keeps webhook records in memory for MemoryStorage
+type MemoryWebhookStore struct {
+	mu      sync.Mutex
+	records *webhookRecords
+}
+
+// NewMemoryWebhookStore creates an empty MemoryWebhookStore
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{records: newWebhookRecords()}
+}
+
+// ListWebhookSubscriptions implements WebhookStore
+func (s *MemoryWebhookStore) ListWebhookSubscriptions() ([]*WebhookSubscriptionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records.listSubscriptions(), nil
+}
+
+// SaveWebhookSubscription implements WebhookStore
+func (s *MemoryWebhookStore) SaveWebhookSubscription(record *WebhookSubscriptionRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *record
+	copied.Data = append([]byte(nil), record.Data...)
+	s.records.Subscriptions[record.ID] = &copied
+	return nil
+}
+
+// DeleteWebhookSubscription implements WebhookStore
+func (s *MemoryWebhookStore) DeleteWebhookSubscription(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records.deleteSubscription(id)
+	return nil
+}
+
+// ListWebhookDeliveries implements WebhookStore
+func (s *MemoryWebhookStore) ListWebhookDeliveries(subscriptionID string) ([]*WebhookDeliveryRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records.listDeliveries(subscriptionID), nil
+}
+
+// SaveWebhookDelivery implements WebhookStore
+func (s *MemoryWebhookStore) SaveWebhookDelivery(record *WebhookDeliveryRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *record
+	copied.Data = append([]byte(nil), record.Data...)
+	s.records.Deliveries[record.ID] = &copied
+	return nil
+}
+
+// DeleteWebhookDeliveries implements WebhookStore
+func (s *MemoryWebhookStore) DeleteWebhookDeliveries(ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.records.Deliveries, id)
+	}
+	return nil
+}
+
+// fileWebhookStore keeps webhook records for the JSON and WAL storages in a
+// file next to the storage file, path+".webhooks", under the storage's file
+// lock. The file holds signing secrets, so only its owner may read it.
+type fileWebhookStore struct {
+	webhooksPath string
+	webhooksLock *fileLock
+}
+
+func newFileWebhookStore(path string, timeout time.Duration) *fileWebhookStore {
+	return &fileWebhookStore{webhooksPath: path + ".webhooks", webhooksLock: newFileLock(path, timeout)}
+}
+
+// ListWebhookSubscriptions implements WebhookStore
+func (s *fileWebhookStore) ListWebhookSubscriptions() ([]*WebhookSubscriptionRecord, error) {
+	records, err := s.readWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	return records.listSubscriptions(), nil
+}
+
+// SaveWebhookSubscription implements WebhookStore
+func (s *fileWebhookStore) SaveWebhookSubscription(record *WebhookSubscriptionRecord) error {
+	return s.updateWebhooks(func(records *webhookRecords) {
+		records.Subscriptions[record.ID] = record
+	})
+}
+
+// DeleteWebhookSubscription implements WebhookStore
+func (s *fileWebhookStore) DeleteWebhookSubscription(id string) error {
+	return s.updateWebhooks(func(records *webhookRecords) {
+		records.deleteSubscription(id)
+	})
+}
+
+// ListWebhookDeliveries implements WebhookStore
+func (s *fileWebhookStore) ListWebhookDeliveries(subscriptionID string) ([]*WebhookDeliveryRecord, error) {
+	records, err := s.readWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	return records.listDeliveries(subscriptionID), nil
+}
+
+// SaveWebhookDelivery implements WebhookStore
+func (s *fileWebhookStore) SaveWebhookDelivery(record *WebhookDeliveryRecord) error {
+	return s.updateWebhooks(func(records *webhookRecords) {
+		records.Deliveries[record.ID] = record
+	})
+}
+
+// DeleteWebhookDeliveries implements WebhookStore
+func (s *fileWebhookStore) DeleteWebhookDeliveries(ids []string) error {
+	return s.updateWebhooks(func(records *webhookRecords) {
+		for _, id := range ids {
+			delete(records.Deliveries, id)
+		}
+	})
+}
+
+// readWebhooks reads the records under the shared file lock
+func (s *fileWebhookStore) readWebhooks() (*webhookRecords, error) {
+	unlock, err := s.webhooksLock.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return s.loadWebhooks()
+}
+
+// updateWebhooks runs fn on the records under the exclusive file lock and
+// saves them
+func (s *fileWebhookStore) updateWebhooks(fn func(records *webhookRecords)) error {
+	unlock, err := s.webhooksLock.acquire(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := s.loadWebhooks()
+	if err != nil {
+		return err
+	}
+	fn(records)
+	if err := writeSidecar(s.webhooksPath, records, 0600); err != nil {
+		return fmt.Errorf("failed to save webhooks: %w", err)
+	}
+	return nil
+}
+
+// loadWebhooks reads the records; callers hold the file lock
+func (s *fileWebhookStore) loadWebhooks() (*webhookRecords, error) {
+	records := newWebhookRecords()
+	if err := readSidecar(s.webhooksPath, records); err != nil {
+		return nil, fmt.Errorf("failed to read webhooks: %w", err)
+	}
+	if records.Subscriptions == nil {
+		records.Subscriptions = make(map[string]*WebhookSubscriptionRecord)
+	}
+	if records.Deliveries == nil {
+		records.Deliveries = make(map[string]*WebhookDeliveryRecord)
+	}
+	return records, nil
+}
+
+// The gorm helpers below implement WebhookStore for PostgreSQL and MySQL
+
+func listGormWebhookSubscriptions(db *gorm.DB) ([]*WebhookSubscriptionRecord, error) {
+	records := make([]*WebhookSubscriptionRecord, 0)
+	if err := db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return records, nil
+}
+
+func saveGormWebhookSubscription(db *gorm.DB, record *WebhookSubscriptionRecord) error {
+	if err := db.Save(record).Error; err != nil {
+		return fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func deleteGormWebhookSubscription(db *gorm.DB, id string) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&WebhookDeliveryRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&WebhookSubscriptionRecord{ID: id}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func listGormWebhookDeliveries(db *gorm.DB, subscriptionID string) ([]*WebhookDeliveryRecord, error) {
+	query := db
+	if subscriptionID != "" {
+		query = query.Where("subscription_id = ?", subscriptionID)
+	}
+	records := make([]*WebhookDeliveryRecord, 0)
+	if err := query.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return records, nil
+}
+
+func saveGormWebhookDelivery(db *gorm.DB, record *WebhookDeliveryRecord) error {
+	if err := db.Save(record).Error; err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func deleteGormWebhookDeliveries(db *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := db.Where("id IN ?", ids).Delete(&WebhookDeliveryRecord{}).Error; err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/metrics"
+)
+
+func TestWebhookStore(t *testing.T) {
+	helper := NewTestHelper(t)
+	defer helper.Cleanup()
+
+	t.Run("memory", func(t *testing.T) {
+		testWebhookStoreCompliance(t, NewMemoryStorage())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		store, err := NewJSONStorage(helper.TempFilePath("webhooks.json"))
+		helper.AssertNoError(err, "creating JSON storage")
+		testWebhookStoreCompliance(t, store)
+	})
+
+	t.Run("wal", func(t *testing.T) {
+		store, err := NewWALStorage(WALConfig{Path: helper.TempFilePath("webhooks_wal.json")})
+		helper.AssertNoError(err, "creating WAL storage")
+		defer store.Close()
+		testWebhookStoreCompliance(t, store)
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		store, err := NewBoltStorage(BoltConfig{Path: helper.TempFilePath("webhooks.db")})
+		helper.AssertNoError(err, "creating Bolt storage")
+		defer store.Close()
+		testWebhookStoreCompliance(t, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStorage(helper.TempFilePath("webhooks.sqlite"))
+		helper.AssertNoError(err, "creating SQLite storage")
+		defer store.Close()
+		testWebhookStoreCompliance(t, store)
+	})
+
+	t.Run("found through decorators", func(t *testing.T) {
+		memory := NewMemoryStorage()
+		var store Storage = NewCachedStorage(memory, CacheConfig{})
+		store = NewInstrumentedStorage(store, metrics.NewRegistry())
+		store = NewTracingStorage(store, nil)
+
+		found, ok := AsWebhookStore(store)
+		if !ok || found != WebhookStore(memory) {
+			t.Fatalf("Expected the wrapped MemoryStorage, got %T", found)
+		}
+	})
+
+	t.Run("shared by file storages on the same file", func(t *testing.T) {
+		path := helper.TempFilePath("webhooks_shared.json")
+		first, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+		second, err := NewJSONStorage(path)
+		helper.AssertNoError(err, "creating JSON storage")
+
+		record := &WebhookSubscriptionRecord{ID: "shared", Data: []byte(`{}`), CreatedAt: time.Now()}
+		helper.AssertNoError(first.SaveWebhookSubscription(record), "saving subscription")
+		if records, err := second.ListWebhookSubscriptions(); err != nil || len(records) != 1 {
+			t.Errorf("Expected the other storage to list the subscription, got %v, %v", records, err)
+		}
+
+		// The file holds signing secrets
+		info, err := os.Stat(path + ".webhooks")
+		helper.AssertNoError(err, "reading webhook file")
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Expected the webhook file to be readable by its owner only, got %v", perm)
+		}
+	})
+}
+
+// testWebhookStoreCompliance checks that a WebhookStore saves, lists and
+// deletes subscriptions and deliveries, and removes the deliveries of a
+// deleted subscription with it
+func testWebhookStoreCompliance(t *testing.T, store WebhookStore) {
+	// IDs are unique per run, for databases that outlive the test
+	prefix := fmt.Sprintf("wh_%d_", time.Now().UnixNano())
+	findSubscription := func(id string) *WebhookSubscriptionRecord {
+		records, err := store.ListWebhookSubscriptions()
+		if err != nil {
+			t.Fatalf("Failed to list webhook subscriptions: %v", err)
+		}
+		for _, record := range records {
+			if record.ID == id {
+				return record
+			}
+		}
+		return nil
+	}
+	deliveries := func(subscriptionID string) map[string]*WebhookDeliveryRecord {
+		records, err := store.ListWebhookDeliveries(subscriptionID)
+		if err != nil {
+			t.Fatalf("Failed to list webhook deliveries: %v", err)
+		}
+		found := make(map[string]*WebhookDeliveryRecord)
+		for _, record := range records {
+			if strings.HasPrefix(record.ID, prefix) {
+				found[record.ID] = record
+			}
+		}
+		return found
+	}
+
+	created := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	sub := &WebhookSubscriptionRecord{ID: prefix + "sub1", Data: []byte(`{"url":"https://a"}`), CreatedAt: created}
+	other := &WebhookSubscriptionRecord{ID: prefix + "sub2", Data: []byte(`{"url":"https://b"}`), CreatedAt: created}
+	for _, record := range []*WebhookSubscriptionRecord{sub, other} {
+		if err := store.SaveWebhookSubscription(record); err != nil {
+			t.Fatalf("Failed to save webhook subscription: %v", err)
+		}
+	}
+	got := findSubscription(sub.ID)
+	if got == nil || string(got.Data) != `{"url":"https://a"}` || !got.CreatedAt.Equal(created) {
+		t.Fatalf("Expected the saved subscription, got %+v", got)
+	}
+
+	sub.Data = []byte(`{"url":"https://c"}`)
+	if err := store.SaveWebhookSubscription(sub); err != nil {
+		t.Fatalf("Failed to replace webhook subscription: %v", err)
+	}
+	if got := findSubscription(sub.ID); got == nil || string(got.Data) != `{"url":"https://c"}` {
+		t.Errorf("Expected the replaced subscription, got %+v", got)
+	}
+
+	first := &WebhookDeliveryRecord{ID: prefix + "dlv1", SubscriptionID: sub.ID, Pending: true, Data: []byte(`{}`), CreatedAt: created}
+	second := &WebhookDeliveryRecord{ID: prefix + "dlv2", SubscriptionID: sub.ID, Data: []byte(`{}`), CreatedAt: created}
+	third := &WebhookDeliveryRecord{ID: prefix + "dlv3", SubscriptionID: other.ID, Data: []byte(`{}`), CreatedAt: created}
+	for _, record := range []*WebhookDeliveryRecord{first, second, third} {
+		if err := store.SaveWebhookDelivery(record); err != nil {
+			t.Fatalf("Failed to save webhook delivery: %v", err)
+		}
+	}
+	listed := deliveries(sub.ID)
+	if len(listed) != 2 || listed[first.ID] == nil || !listed[first.ID].Pending || listed[second.ID].Pending {
+		t.Fatalf("Expected the subscription's two deliveries, got %+v", listed)
+	}
+	if got := listed[first.ID]; got.SubscriptionID != sub.ID || !got.CreatedAt.Equal(created) {
+		t.Errorf("Expected the saved delivery, got %+v", got)
+	}
+	if all := deliveries(""); len(all) != 3 {
+		t.Errorf("Expected 3 deliveries in total, got %d", len(all))
+	}
+
+	first.Pending = false
+	if err := store.SaveWebhookDelivery(first); err != nil {
+		t.Fatalf("Failed to replace webhook delivery: %v", err)
+	}
+	if got := deliveries(sub.ID)[first.ID]; got == nil || got.Pending {
+		t.Errorf("Expected the replaced delivery, got %+v", got)
+	}
+
+	if err := store.DeleteWebhookDeliveries([]string{second.ID}); err != nil {
+		t.Fatalf("Failed to delete webhook deliveries: %v", err)
+	}
+	if listed := deliveries(sub.ID); len(listed) != 1 || listed[first.ID] == nil {
+		t.Errorf("Expected only the first delivery to remain, got %+v", listed)
+	}
+
+	if err := store.DeleteWebhookSubscription(sub.ID); err != nil {
+		t.Fatalf("Failed to delete webhook subscription: %v", err)
+	}
+	if got := findSubscription(sub.ID); got != nil {
+		t.Errorf("Expected the subscription to be deleted, got %+v", got)
+	}
+	if listed := deliveries(sub.ID); len(listed) != 0 {
+		t.Errorf("Expected the subscription's deliveries to be deleted, got %+v", listed)
+	}
+	if listed := deliveries(other.ID); len(listed) != 1 {
+		t.Errorf("Expected other subscriptions to keep their deliveries, got %+v", listed)
+	}
+	if err := store.DeleteWebhookSubscription(sub.ID); err != nil {
+		t.Errorf("Expected deleting a missing subscription to succeed, got %v", err)
+	}
+
+	if err := store.DeleteWebhookSubscription(other.ID); err != nil {
+		t.Fatalf("Failed to delete webhook subscription: %v", err)
+	}
+}
@@ -0,0 +1,377 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+)
+
+// BatchOp is the kind of change a BatchItem makes
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+var (
+	// ErrInvalidBatchItem is returned for items that are malformed, such as
+	// an unknown operation or an empty title
+	ErrInvalidBatchItem = errors.New("invalid batch item")
+	// ErrTaskNotFound is returned for items naming a task that does not exist
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrBatchAborted is the result of the items of an atomic batch that
+	// were not applied because another item failed
+	ErrBatchAborted = errors.New("batch rolled back")
+	// ErrEarlierItemFailed is the result of the items of a batch that were
+	// not applied because an earlier item on the same task failed to write
+	ErrEarlierItemFailed = errors.New("an earlier item on the same task failed")
+)
+
+// BatchItem is one change in a batch
+type BatchItem struct {
+	Op BatchOp
+	// ID is the task to update or delete
+	ID string
+	// Version, when set, makes an update or delete apply only if the task is
+	// still at this version
+	Version *int64
+	// Changes holds the fields of the task to create, which needs a title,
+	// or the partial update to apply
+	Changes Changes
+}
+
+// BatchResult is the outcome of one BatchItem: the created, updated or
+// deleted task, or the error that stopped it
+type BatchResult struct {
+	Task *models.Task
+	Err  error
+}
+
+// batchChange is a prepared item: the task before and after the change
+type batchChange struct {
+	index  int
+	op     BatchOp
+	before *models.Task
+	after  *models.Task
+	// versioned changes are written only if the stored task is still at
+	// before.Version
+	versioned bool
+}
+
+// taskID returns the ID of the task the change writes
+func (c batchChange) taskID() string {
+	if c.after != nil {
+		return c.after.ID
+	}
+	return c.before.ID
+}
+
+// ApplyBatch applies items in order and returns a result for each.
+// Consecutive items of the same kind are written together with the
+// storage's batch operations, so a large batch costs a few storage calls
+// rather than one per item.
+//
+// Unless atomic, every item succeeds or fails on its own. When atomic, the
+// items are applied in a transaction: if any fails, none are applied, the
+// others report ErrBatchAborted and the returned error is a
+// *storage.BatchError naming the failed item.
+func (s *Service) ApplyBatch(ctx context.Context, items []BatchItem, atomic bool) ([]BatchResult, error) {
+	ctx, span, store := s.begin(ctx, "ApplyBatch")
+	defer span.End()
+	span.SetAttribute("batch.size", len(items))
+	span.SetAttribute("batch.atomic", atomic)
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	results := make([]BatchResult, len(items))
+	var applied []batchChange
+	if atomic {
+		var err error
+		applied, err = s.applyAtomic(ctx, store, items, results)
+		if err != nil {
+			span.RecordError(err)
+			s.logger.WarnContext(ctx, "batch rolled back", "items", len(items), "error", err)
+			return results, err
+		}
+	} else {
+		applied = applyEach(ctx, store, items, results)
+	}
+
+	for _, change := range applied {
+		switch change.op {
+		case BatchCreate:
+			s.publish(ctx, events.TaskCreated, nil, change.after)
+		case BatchUpdate:
+			s.publish(ctx, updateEvent(change.before, change.after), change.before, change.after)
+		case BatchDelete:
+			s.publish(ctx, events.TaskDeleted, change.before, nil)
+		}
+	}
+	s.logger.InfoContext(ctx, "batch applied", "items", len(items), "applied", len(applied), "atomic", atomic)
+	return results, nil
+}
+
+// applyAtomic prepares and writes every item in one transaction
+func (s *Service) applyAtomic(ctx context.Context, store storage.Storage, items []BatchItem, results []BatchResult) ([]batchChange, error) {
+	var changes []batchChange
+	err := storage.WithTx(ctx, store, func(tx storage.Storage) error {
+		// The transaction may be retried, so start from clean results
+		clear(results)
+		var err error
+		if changes, err = prepareBatch(tx, items, results); err != nil {
+			return err
+		}
+		for _, run := range batchRuns(changes) {
+			err := writeBatchRun(ctx, tx, run)
+			var batchErr *storage.BatchError
+			if errors.As(err, &batchErr) && batchErr.Index < len(run) {
+				index := run[batchErr.Index].index
+				results[index] = BatchResult{Err: batchErr.Err}
+				return &storage.BatchError{Index: index, Err: batchErr.Err}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = BatchResult{Err: ErrBatchAborted}
+			}
+		}
+		return nil, err
+	}
+	return changes, nil
+}
+
+// applyEach writes runs of items together, falling back to one by one for
+// a run that fails so that only its failing items are rejected. Later items
+// were prepared on top of the failed ones, so those touching the same task
+// are rejected with ErrEarlierItemFailed rather than written.
+func applyEach(ctx context.Context, store storage.Storage, items []BatchItem, results []BatchResult) []batchChange {
+	changes, _ := prepareBatch(store, items, results)
+
+	// failed holds the tasks whose changes stopped applying
+	failed := make(map[string]bool)
+	reject := func(change batchChange, err error) {
+		results[change.index] = BatchResult{Err: err}
+		failed[change.taskID()] = true
+	}
+
+	applied := make([]batchChange, 0, len(changes))
+	for _, run := range batchRuns(changes) {
+		writable := make([]batchChange, 0, len(run))
+		for _, change := range run {
+			if failed[change.taskID()] {
+				reject(change, fmt.Errorf("%w: %s", ErrEarlierItemFailed, change.taskID()))
+				continue
+			}
+			writable = append(writable, change)
+		}
+		if len(writable) == 0 {
+			continue
+		}
+
+		err := writeBatchRun(ctx, store, writable)
+		if err == nil {
+			applied = append(applied, writable...)
+			continue
+		}
+		var batchErr *storage.BatchError
+		if writable[0].versioned && errors.As(err, &batchErr) {
+			// A run of one, already written alone
+			reject(writable[0], batchErr.Err)
+			continue
+		}
+		for _, change := range writable {
+			if failed[change.taskID()] {
+				reject(change, fmt.Errorf("%w: %s", ErrEarlierItemFailed, change.taskID()))
+				continue
+			}
+			if err := writeBatchChange(ctx, store, change); err != nil {
+				reject(change, err)
+				continue
+			}
+			applied = append(applied, change)
+		}
+	}
+	return applied
+}
+
+// prepareBatch validates items and works out each change, seeing the
+// changes of earlier items. Stored tasks are read past any cache, since
+// versioned items are checked against them. Failed items get an error
+// result and the first failure is returned as a *storage.BatchError.
+func prepareBatch(store storage.Storage, items []BatchItem, results []BatchResult) ([]batchChange, error) {
+	// pending holds the tasks changed by earlier items, nil once deleted
+	pending := make(map[string]*models.Task)
+	current := func(id string) (*models.Task, error) {
+		task, ok := pending[id]
+		if !ok {
+			stored, err := storage.GetFresh(store, id)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+			}
+			return stored, nil
+		}
+		if task == nil {
+			return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+		}
+		copied := *task
+		return &copied, nil
+	}
+
+	changes := make([]batchChange, 0, len(items))
+	var failed error
+	for i, item := range items {
+		change, err := prepareBatchItem(item, current)
+		if err != nil {
+			results[i] = BatchResult{Err: err}
+			if failed == nil {
+				failed = &storage.BatchError{Index: i, Err: err}
+			}
+			continue
+		}
+
+		change.index = i
+		if change.op == BatchDelete {
+			pending[change.before.ID] = nil
+			results[i] = BatchResult{Task: change.before}
+		} else {
+			pending[change.after.ID] = change.after
+			results[i] = BatchResult{Task: change.after}
+		}
+		changes = append(changes, change)
+	}
+	return changes, failed
+}
+
+func prepareBatchItem(item BatchItem, current func(id string) (*models.Task, error)) (batchChange, error) {
+	change := batchChange{op: item.Op}
+	if item.Changes.Title != nil && strings.TrimSpace(*item.Changes.Title) == "" {
+		return change, fmt.Errorf("%w: task title cannot be empty", ErrInvalidBatchItem)
+	}
+
+	switch item.Op {
+	case BatchCreate:
+		if item.Changes.Title == nil {
+			return change, fmt.Errorf("%w: task title cannot be empty", ErrInvalidBatchItem)
+		}
+		task := &models.Task{
+			ID:        generateID(),
+			Title:     *item.Changes.Title,
+			CreatedAt: time.Now(),
+			DueDate:   item.Changes.DueDate,
+			Version:   1,
+		}
+		if item.Changes.Done != nil {
+			setDone(task, *item.Changes.Done)
+		}
+		change.after = task
+		return change, nil
+
+	case BatchUpdate, BatchDelete:
+		if item.ID == "" {
+			return change, fmt.Errorf("%w: task ID is required", ErrInvalidBatchItem)
+		}
+		task, err := current(item.ID)
+		if err != nil {
+			return change, err
+		}
+		if item.Version != nil && task.Version != *item.Version {
+			return change, fmt.Errorf("%w: task %s is at version %d", ErrVersionConflict, item.ID, task.Version)
+		}
+
+		before := *task
+		change.before = &before
+		change.versioned = item.Version != nil
+		if item.Op == BatchDelete {
+			return change, nil
+		}
+		if item.Changes.Title != nil {
+			task.Title = *item.Changes.Title
+		}
+		if item.Changes.Done != nil {
+			setDone(task, *item.Changes.Done)
+		}
+		if item.Changes.DueDate != nil {
+			task.DueDate = item.Changes.DueDate
+		}
+		task.Version++
+		change.after = task
+		return change, nil
+	}
+
+	return change, fmt.Errorf("%w: unknown operation %q", ErrInvalidBatchItem, item.Op)
+}
+
+// batchRuns splits changes into runs of consecutive changes of one kind.
+// Versioned changes are written on their own, each in a run of one.
+func batchRuns(changes []batchChange) [][]batchChange {
+	var runs [][]batchChange
+	for start := 0; start < len(changes); {
+		end := start + 1
+		for !changes[start].versioned && end < len(changes) && changes[end].op == changes[start].op && !changes[end].versioned {
+			end++
+		}
+		runs = append(runs, changes[start:end])
+		start = end
+	}
+	return runs
+}
+
+// writeBatchRun writes a run with one storage batch operation, or a
+// versioned change with a conditional write
+func writeBatchRun(ctx context.Context, store storage.Storage, run []batchChange) error {
+	if run[0].versioned {
+		if err := writeBatchChange(ctx, store, run[0]); err != nil {
+			return &storage.BatchError{Index: 0, Err: err}
+		}
+		return nil
+	}
+	switch run[0].op {
+	case BatchCreate, BatchUpdate:
+		tasks := make([]*models.Task, len(run))
+		for i, change := range run {
+			tasks[i] = change.after
+		}
+		if run[0].op == BatchCreate {
+			return storage.CreateMany(ctx, store, tasks)
+		}
+		return storage.UpdateMany(ctx, store, tasks)
+	default:
+		ids := make([]string, len(run))
+		for i, change := range run {
+			ids[i] = change.before.ID
+		}
+		return storage.DeleteMany(ctx, store, ids)
+	}
+}
+
+// writeBatchChange writes one change. A versioned change fails with
+// ErrVersionConflict if the stored task has moved on since it was read.
+func writeBatchChange(ctx context.Context, store storage.Storage, change batchChange) error {
+	switch {
+	case change.op == BatchCreate:
+		return store.Create(change.after)
+	case change.op == BatchUpdate && change.versioned:
+		return storage.UpdateIfVersion(ctx, store, change.after, change.before.Version)
+	case change.op == BatchUpdate:
+		return store.Update(change.after)
+	case change.versioned:
+		return storage.DeleteIfVersion(ctx, store, change.before.ID, change.before.Version)
+	default:
+		return store.Delete(change.before.ID)
+	}
+}
@@ -0,0 +1,79 @@
+package task
+
+import (
+	"context"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/models"
+)
+
+// WithEventBus publishes task lifecycle events to bus
+func WithEventBus(bus *events.Bus) Option {
+	return func(s *Service) {
+		s.bus = bus
+	}
+}
+
+// publish queues a change with copies of the before and after states, so
+// subscribers cannot alter tasks held by the caller or the storage layer.
+// Callers hold s.mu; the event is delivered by unlock once it is released.
+func (s *Service) publish(ctx context.Context, eventType events.Type, before, after *models.Task) {
+	if after != nil {
+		s.remember(after.ID, after)
+	} else if before != nil {
+		s.remember(before.ID, nil)
+	}
+	if s.bus == nil {
+		return
+	}
+
+	event := events.Event{Type: eventType}
+	if before != nil {
+		snapshot := *before
+		event.Before, event.TaskID = &snapshot, before.ID
+	}
+	if after != nil {
+		snapshot := *after
+		event.After, event.TaskID = &snapshot, after.ID
+	}
+	s.pending = append(s.pending, pendingEvent{ctx: ctx, event: event})
+}
+
+// pendingEvent is an event waiting for s.mu to be released
+type pendingEvent struct {
+	ctx   context.Context
+	event events.Event
+}
+
+// unlock releases s.mu and then delivers the events queued while it was
+// held, so synchronous subscribers never run under the service lock and may
+// call back into the service. Events are delivered in the order they were
+// queued; if another goroutine is already delivering, it delivers these too.
+func (s *Service) unlock() {
+	if s.delivering || len(s.pending) == 0 {
+		s.mu.Unlock()
+		return
+	}
+
+	// The bus recovers panicking subscribers, so the loop always completes
+	s.delivering = true
+	for len(s.pending) > 0 {
+		queued := s.pending
+		s.pending = nil
+		s.mu.Unlock()
+		for _, p := range queued {
+			s.bus.Publish(p.ctx, p.event)
+		}
+		s.mu.Lock()
+	}
+	s.delivering = false
+	s.mu.Unlock()
+}
+
+// updateEvent reports completion when a task moves from open to done
+func updateEvent(before, after *models.Task) events.Type {
+	if after.Done && !before.Done {
+		return events.TaskCompleted
+	}
+	return events.TaskUpdated
+}
@@ -1,27 +1,82 @@
 package task
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"GoTask_Management/internal/events"
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
 	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/tracing"
 )
 
+// ErrVersionConflict is returned when a task changed after the version the
+// caller last saw. It is the storage error, so conflicts detected by the
+// storage's conditional writes match it too.
+var ErrVersionConflict = storage.ErrVersionConflict
+
 type Service struct {
 	storage storage.Storage
+	logger  *slog.Logger
+	bus     *events.Bus
+
+	// mu serialises read-modify-write cycles, so version checks and the
+	// order of published events hold for every change made in this process
+	mu sync.Mutex
+	// known holds the last state of each task while Watch runs, and deleted
+	// when recently deleted tasks were removed from it, swept for expired
+	// entries at most once per watchTombstoneTTL
+	known   map[string]*models.Task
+	deleted map[string]time.Time
+	swept   time.Time
+	// pending holds events published under mu, delivered by unlock
+	pending    []pendingEvent
+	delivering bool
+}
+
+// Option configures optional Service behaviour
+type Option func(*Service)
+
+// WithLogger sets the logger used for task lifecycle logs
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Service) {
+		s.logger = logger
+	}
 }
 
-func NewService(storage storage.Storage) *Service {
-	return &Service{
+func NewService(storage storage.Storage, opts ...Option) *Service {
+	s := &Service{
 		storage: storage,
+		logger:  slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
+}
+
+// begin starts a span for a service call and returns the storage bound to the span's context
+func (s *Service) begin(ctx context.Context, operation string) (context.Context, *tracing.Span, storage.Storage) {
+	ctx, span := tracing.StartSpan(ctx, "task.Service."+operation)
+	return ctx, span, storage.WithContext(ctx, s.storage)
 }
 
-func (s *Service) CreateTask(title string, dueDate *time.Time) (*models.Task, error) {
+func (s *Service) CreateTask(ctx context.Context, title string, dueDate *time.Time) (*models.Task, error) {
+	ctx, span, store := s.begin(ctx, "CreateTask")
+	defer span.End()
+
 	if strings.TrimSpace(title) == "" {
-		return nil, fmt.Errorf("task title cannot be empty")
+		err := fmt.Errorf("task title cannot be empty")
+		span.RecordError(err)
+		return nil, err
 	}
 
 	task := &models.Task{
@@ -30,18 +85,31 @@ func (s *Service) CreateTask(title string, dueDate *time.Time) (*models.Task, er
 		Done:      false,
 		CreatedAt: time.Now(),
 		DueDate:   dueDate,
+		Version:   1,
 	}
+	span.SetAttribute("task.id", task.ID)
+
+	s.mu.Lock()
+	defer s.unlock()
 
-	if err := s.storage.Create(task); err != nil {
+	if err := store.Create(task); err != nil {
+		span.RecordError(err)
+		s.logger.ErrorContext(ctx, "failed to create task", "error", err)
 		return nil, err
 	}
 
+	s.logger.InfoContext(ctx, "task created", "task_id", task.ID)
+	s.publish(ctx, events.TaskCreated, nil, task)
 	return task, nil
 }
 
-func (s *Service) ListTasks(status string) ([]*models.Task, error) {
-	tasks, err := s.storage.GetAll()
+func (s *Service) ListTasks(ctx context.Context, status string) ([]*models.Task, error) {
+	_, span, store := s.begin(ctx, "ListTasks")
+	defer span.End()
+
+	tasks, err := store.GetAll()
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 
@@ -59,48 +127,226 @@ func (s *Service) ListTasks(status string) ([]*models.Task, error) {
 	return filtered, nil
 }
 
-func (s *Service) GetTask(id string) (*models.Task, error) {
-	return s.storage.GetByID(id)
+// FindTasks returns the tasks matching filter, selected by the storage
+// where it can
+func (s *Service) FindTasks(ctx context.Context, filter *query.Filter) ([]*models.Task, error) {
+	_, span, store := s.begin(ctx, "FindTasks")
+	defer span.End()
+	span.SetAttribute("query.conditions", len(filter.Conditions))
+
+	tasks, err := storage.Find(store, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return tasks, nil
 }
 
-func (s *Service) UpdateTask(id string, title string, done bool, dueDate *time.Time) (*models.Task, error) {
-	task, err := s.storage.GetByID(id)
+func (s *Service) GetTask(ctx context.Context, id string) (*models.Task, error) {
+	_, span, store := s.begin(ctx, "GetTask")
+	defer span.End()
+	span.SetAttribute("task.id", id)
+
+	task, err := store.GetByID(id)
+	span.RecordError(err)
+	return task, err
+}
+
+func (s *Service) UpdateTask(ctx context.Context, id string, title string, done bool, dueDate *time.Time) (*models.Task, error) {
+	ctx, span, store := s.begin(ctx, "UpdateTask")
+	defer span.End()
+	span.SetAttribute("task.id", id)
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	task, err := store.GetByID(id)
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 
+	before := *task
 	if title != "" {
 		task.Title = title
 	}
-	task.Done = done
+	setDone(task, done)
 	if dueDate != nil {
 		task.DueDate = dueDate
 	}
+	task.Version++
 
-	if err := s.storage.Update(task); err != nil {
+	if err := store.Update(task); err != nil {
+		span.RecordError(err)
+		s.logger.ErrorContext(ctx, "failed to update task", "task_id", id, "error", err)
 		return nil, err
 	}
 
+	s.logger.InfoContext(ctx, "task updated", "task_id", id)
+	s.publish(ctx, updateEvent(&before, task), &before, task)
 	return task, nil
 }
 
-func (s *Service) MarkTaskDone(id string, done bool) error {
-	task, err := s.storage.GetByID(id)
+func (s *Service) MarkTaskDone(ctx context.Context, id string, done bool) error {
+	ctx, span, store := s.begin(ctx, "MarkTaskDone")
+	defer span.End()
+	span.SetAttribute("task.id", id)
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	task, err := store.GetByID(id)
 	if err != nil {
+		span.RecordError(err)
 		return err
 	}
 
-	task.Done = done
-	return s.storage.Update(task)
+	before := *task
+	setDone(task, done)
+	task.Version++
+	if err := store.Update(task); err != nil {
+		span.RecordError(err)
+		s.logger.ErrorContext(ctx, "failed to update task status", "task_id", id, "error", err)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "task status changed", "task_id", id, "done", done)
+	s.publish(ctx, updateEvent(&before, task), &before, task)
+	return nil
 }
 
-func (s *Service) DeleteTask(id string) error {
-	return s.storage.Delete(id)
+func (s *Service) DeleteTask(ctx context.Context, id string) error {
+	ctx, span, store := s.begin(ctx, "DeleteTask")
+	defer span.End()
+	span.SetAttribute("task.id", id)
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	// Deletion events carry the task as it was before removal
+	var deleted *models.Task
+	if s.bus != nil || s.known != nil {
+		task, err := store.GetByID(id)
+		if err != nil {
+			span.RecordError(err)
+			s.logger.WarnContext(ctx, "failed to delete task", "task_id", id, "error", err)
+			return err
+		}
+		deleted = task
+	}
+
+	if err := store.Delete(id); err != nil {
+		span.RecordError(err)
+		s.logger.WarnContext(ctx, "failed to delete task", "task_id", id, "error", err)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "task deleted", "task_id", id)
+	if deleted != nil {
+		s.publish(ctx, events.TaskDeleted, deleted, nil)
+	}
+	return nil
+}
+
+// Changes is a partial update; nil fields are left unchanged
+type Changes struct {
+	Title   *string
+	Done    *bool
+	DueDate *time.Time
 }
 
-func (s *Service) GetDueTasks(days int) ([]*models.Task, error) {
-	tasks, err := s.storage.GetAll()
+// UpdateTaskVersion applies changes only if the task is still at version,
+// returning an error wrapping ErrVersionConflict otherwise
+func (s *Service) UpdateTaskVersion(ctx context.Context, id string, version int64, changes Changes) (*models.Task, error) {
+	ctx, span, store := s.begin(ctx, "UpdateTaskVersion")
+	defer span.End()
+	span.SetAttribute("task.id", id)
+
+	if changes.Title != nil && strings.TrimSpace(*changes.Title) == "" {
+		err := fmt.Errorf("task title cannot be empty")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	// A cached copy may be older than the version the caller saw
+	task, err := storage.GetFresh(store, id)
 	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if task.Version != version {
+		err := fmt.Errorf("%w: task %s is at version %d", ErrVersionConflict, id, task.Version)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	before := *task
+	if changes.Title != nil {
+		task.Title = *changes.Title
+	}
+	if changes.Done != nil {
+		setDone(task, *changes.Done)
+	}
+	if changes.DueDate != nil {
+		task.DueDate = changes.DueDate
+	}
+	task.Version++
+
+	// The storage checks the version again as it writes, since other
+	// processes may change the task after it was read
+	if err := storage.UpdateIfVersion(ctx, store, task, version); err != nil {
+		span.RecordError(err)
+		s.logger.ErrorContext(ctx, "failed to update task", "task_id", id, "error", err)
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "task updated", "task_id", id, "version", task.Version)
+	s.publish(ctx, updateEvent(&before, task), &before, task)
+	return task, nil
+}
+
+// DeleteTaskVersion deletes a task only if it is still at version,
+// returning an error wrapping ErrVersionConflict otherwise
+func (s *Service) DeleteTaskVersion(ctx context.Context, id string, version int64) error {
+	ctx, span, store := s.begin(ctx, "DeleteTaskVersion")
+	defer span.End()
+	span.SetAttribute("task.id", id)
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	task, err := storage.GetFresh(store, id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if task.Version != version {
+		err := fmt.Errorf("%w: task %s is at version %d", ErrVersionConflict, id, task.Version)
+		span.RecordError(err)
+		return err
+	}
+
+	if err := storage.DeleteIfVersion(ctx, store, id, version); err != nil {
+		span.RecordError(err)
+		s.logger.WarnContext(ctx, "failed to delete task", "task_id", id, "error", err)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "task deleted", "task_id", id)
+	s.publish(ctx, events.TaskDeleted, task, nil)
+	return nil
+}
+
+func (s *Service) GetDueTasks(ctx context.Context, days int) ([]*models.Task, error) {
+	_, span, store := s.begin(ctx, "GetDueTasks")
+	defer span.End()
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 
@@ -117,9 +363,29 @@ func (s *Service) GetDueTasks(days int) ([]*models.Task, error) {
 	return dueTasks, nil
 }
 
-func (s *Service) GetTasksSummary() (int, int, int, error) {
-	tasks, err := s.storage.GetAll()
+// SearchTasks returns up to limit tasks whose titles match any word of query,
+// most relevant first, using the storage's full-text index when it has one
+func (s *Service) SearchTasks(ctx context.Context, query string, limit int) ([]storage.SearchResult, error) {
+	_, span, store := s.begin(ctx, "SearchTasks")
+	defer span.End()
+	span.SetAttribute("search.limit", limit)
+
+	results, err := storage.Search(store, query, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttribute("search.results", len(results))
+	return results, nil
+}
+
+func (s *Service) GetTasksSummary(ctx context.Context) (int, int, int, error) {
+	_, span, store := s.begin(ctx, "GetTasksSummary")
+	defer span.End()
+
+	tasks, err := store.GetAll()
 	if err != nil {
+		span.RecordError(err)
 		return 0, 0, 0, err
 	}
 
@@ -140,6 +406,66 @@ func (s *Service) GetTasksSummary() (int, int, int, error) {
 	return total, done, overdue, nil
 }
 
+// ListCompletedBefore returns done tasks completed before cutoff. Done tasks
+// without a completion time are not included; see BackfillCompletedAt.
+func (s *Service) ListCompletedBefore(ctx context.Context, cutoff time.Time) ([]*models.Task, error) {
+	_, span, store := s.begin(ctx, "ListCompletedBefore")
+	defer span.End()
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	completed := make([]*models.Task, 0)
+	for _, task := range tasks {
+		if task.Done && task.CompletedAt != nil && task.CompletedAt.Before(cutoff) {
+			completed = append(completed, task)
+		}
+	}
+
+	return completed, nil
+}
+
+// BackfillCompletedAt stamps done tasks that predate completion tracking with
+// the current time, so they become eligible for cleanup after the usual
+// retention period rather than never or immediately
+func (s *Service) BackfillCompletedAt(ctx context.Context) (int, error) {
+	ctx, span, store := s.begin(ctx, "BackfillCompletedAt")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	now := time.Now()
+	count := 0
+	for _, task := range tasks {
+		if !task.Done || task.CompletedAt != nil {
+			continue
+		}
+		task.CompletedAt = &now
+		task.Version++
+		if err := store.Update(task); err != nil {
+			span.RecordError(err)
+			return count, fmt.Errorf("failed to backfill task %s: %w", task.ID, err)
+		}
+		s.remember(task.ID, task)
+		count++
+	}
+
+	if count > 0 {
+		s.logger.InfoContext(ctx, "backfilled completion times", "tasks", count)
+	}
+	return count, nil
+}
+
 // HealthCheck performs a health check on the service and its dependencies
 func (s *Service) HealthCheck() error {
 	// Check if storage supports health checks
@@ -152,6 +478,32 @@ func (s *Service) HealthCheck() error {
 	return err
 }
 
+// setDone updates the done flag, recording when the task was completed
+func setDone(task *models.Task, done bool) {
+	switch {
+	case done && (!task.Done || task.CompletedAt == nil):
+		now := time.Now()
+		task.CompletedAt = &now
+	case !done:
+		task.CompletedAt = nil
+	}
+	task.Done = done
+}
+
+// lastID is the timestamp of the last generated ID
+var lastID atomic.Int64
+
+// generateID returns an ID from the current time in nanoseconds, moved past
+// the last ID when the clock has not advanced, so that tasks created in a
+// tight loop get distinct IDs
 func generateID() string {
-	return fmt.Sprintf("task_%d", time.Now().UnixNano())
+	for {
+		last, now := lastID.Load(), time.Now().UnixNano()
+		if now <= last {
+			now = last + 1
+		}
+		if lastID.CompareAndSwap(last, now) {
+			return fmt.Sprintf("task_%d", now)
+		}
+	}
 }
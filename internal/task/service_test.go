@@ -1,10 +1,22 @@
 package task
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/logging"
 	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+	"GoTask_Management/internal/storage"
+	"GoTask_Management/internal/tracing"
 )
 
 func TestNewService(t *testing.T) {
@@ -21,6 +33,7 @@ func TestNewService(t *testing.T) {
 }
 
 func TestService_CreateTask(t *testing.T) {
+	ctx := context.Background()
 	helper := NewTestHelper(t)
 	service := helper.GetService()
 
@@ -28,7 +41,7 @@ func TestService_CreateTask(t *testing.T) {
 		title := "Test Task"
 		dueDate := time.Now().Add(24 * time.Hour)
 
-		task, err := service.CreateTask(title, &dueDate)
+		task, err := service.CreateTask(ctx, title, &dueDate)
 		helper.AssertNoError(err, "creating task")
 
 		if task == nil {
@@ -59,7 +72,7 @@ func TestService_CreateTask(t *testing.T) {
 	t.Run("creates task without due date", func(t *testing.T) {
 		title := "Task without due date"
 
-		task, err := service.CreateTask(title, nil)
+		task, err := service.CreateTask(ctx, title, nil)
 		helper.AssertNoError(err, "creating task without due date")
 
 		if task.DueDate != nil {
@@ -68,7 +81,7 @@ func TestService_CreateTask(t *testing.T) {
 	})
 
 	t.Run("fails with empty title", func(t *testing.T) {
-		_, err := service.CreateTask("", nil)
+		_, err := service.CreateTask(ctx, "", nil)
 		helper.AssertError(err, true, "creating task with empty title")
 
 		if err.Error() != "task title cannot be empty" {
@@ -79,7 +92,7 @@ func TestService_CreateTask(t *testing.T) {
 	t.Run("handles storage error", func(t *testing.T) {
 		helper.GetMockStorage().SetError(true, "storage error")
 
-		_, err := service.CreateTask("Test Task", nil)
+		_, err := service.CreateTask(ctx, "Test Task", nil)
 		helper.AssertError(err, true, "creating task with storage error")
 
 		// Reset error state
@@ -88,6 +101,7 @@ func TestService_CreateTask(t *testing.T) {
 }
 
 func TestService_ListTasks(t *testing.T) {
+	ctx := context.Background()
 	helper := NewTestHelper(t)
 	service := helper.GetService()
 
@@ -101,7 +115,7 @@ func TestService_ListTasks(t *testing.T) {
 	helper.SeedMockStorage(tasks)
 
 	t.Run("lists all tasks", func(t *testing.T) {
-		allTasks, err := service.ListTasks("")
+		allTasks, err := service.ListTasks(ctx, "")
 		helper.AssertNoError(err, "listing all tasks")
 
 		if len(allTasks) != 4 {
@@ -110,7 +124,7 @@ func TestService_ListTasks(t *testing.T) {
 	})
 
 	t.Run("filters done tasks", func(t *testing.T) {
-		doneTasks, err := service.ListTasks("done")
+		doneTasks, err := service.ListTasks(ctx, "done")
 		helper.AssertNoError(err, "listing done tasks")
 
 		if len(doneTasks) != 2 {
@@ -125,7 +139,7 @@ func TestService_ListTasks(t *testing.T) {
 	})
 
 	t.Run("filters undone tasks", func(t *testing.T) {
-		undoneTasks, err := service.ListTasks("undone")
+		undoneTasks, err := service.ListTasks(ctx, "undone")
 		helper.AssertNoError(err, "listing undone tasks")
 
 		if len(undoneTasks) != 2 {
@@ -142,7 +156,7 @@ func TestService_ListTasks(t *testing.T) {
 	t.Run("handles storage error", func(t *testing.T) {
 		helper.GetMockStorage().SetError(true, "storage error")
 
-		_, err := service.ListTasks("")
+		_, err := service.ListTasks(ctx, "")
 		helper.AssertError(err, true, "listing tasks with storage error")
 
 		// Reset error state
@@ -150,7 +164,39 @@ func TestService_ListTasks(t *testing.T) {
 	})
 }
 
+func TestService_FindTasks(t *testing.T) {
+	ctx := context.Background()
+	helper := NewTestHelper(t)
+	service := helper.GetService()
+
+	helper.SeedMockStorage([]*models.Task{
+		helper.CreateSampleTask("task1", "Fix login bug"),
+		helper.CreateCompletedTask("task2", "Fix signup bug"),
+		helper.CreateOverdueTask("task3", "Write docs"),
+	})
+
+	t.Run("returns matching tasks", func(t *testing.T) {
+		filter, err := query.ParseFilter("status:open -docs", time.Now())
+		helper.AssertNoError(err, "parsing query")
+
+		tasks, err := service.FindTasks(ctx, filter)
+		helper.AssertNoError(err, "finding tasks")
+		if len(tasks) != 1 || tasks[0].ID != "task1" {
+			t.Errorf("Expected task1, got %+v", tasks)
+		}
+	})
+
+	t.Run("handles storage error", func(t *testing.T) {
+		helper.GetMockStorage().SetError(true, "storage error")
+		defer helper.GetMockStorage().SetError(false, "")
+
+		_, err := service.FindTasks(ctx, &query.Filter{})
+		helper.AssertError(err, true, "finding tasks with storage error")
+	})
+}
+
 func TestService_GetTask(t *testing.T) {
+	ctx := context.Background()
 	helper := NewTestHelper(t)
 	service := helper.GetService()
 
@@ -159,21 +205,21 @@ func TestService_GetTask(t *testing.T) {
 	helper.SeedMockStorage([]*models.Task{task})
 
 	t.Run("gets existing task", func(t *testing.T) {
-		retrievedTask, err := service.GetTask("test_task")
+		retrievedTask, err := service.GetTask(ctx, "test_task")
 		helper.AssertNoError(err, "getting existing task")
 
 		helper.AssertTaskEqual(task, retrievedTask)
 	})
 
 	t.Run("fails for non-existent task", func(t *testing.T) {
-		_, err := service.GetTask("non_existent")
+		_, err := service.GetTask(ctx, "non_existent")
 		helper.AssertError(err, true, "getting non-existent task")
 	})
 
 	t.Run("handles storage error", func(t *testing.T) {
 		helper.GetMockStorage().SetError(true, "storage error")
 
-		_, err := service.GetTask("test_task")
+		_, err := service.GetTask(ctx, "test_task")
 		helper.AssertError(err, true, "getting task with storage error")
 
 		// Reset error state
@@ -182,6 +228,7 @@ func TestService_GetTask(t *testing.T) {
 }
 
 func TestService_UpdateTask(t *testing.T) {
+	ctx := context.Background()
 	helper := NewTestHelper(t)
 	service := helper.GetService()
 
@@ -193,7 +240,7 @@ func TestService_UpdateTask(t *testing.T) {
 		newTitle := "Updated Task"
 		newDueDate := time.Now().Add(48 * time.Hour)
 
-		updatedTask, err := service.UpdateTask("update_task", newTitle, true, &newDueDate)
+		updatedTask, err := service.UpdateTask(ctx, "update_task", newTitle, true, &newDueDate)
 		helper.AssertNoError(err, "updating task")
 
 		if updatedTask.Title != newTitle {
@@ -215,7 +262,7 @@ func TestService_UpdateTask(t *testing.T) {
 	})
 
 	t.Run("updates with empty title keeps original", func(t *testing.T) {
-		updatedTask, err := service.UpdateTask("update_task", "", false, nil)
+		updatedTask, err := service.UpdateTask(ctx, "update_task", "", false, nil)
 		helper.AssertNoError(err, "updating task with empty title")
 
 		// Title should remain unchanged when empty string is provided
@@ -225,14 +272,14 @@ func TestService_UpdateTask(t *testing.T) {
 	})
 
 	t.Run("fails for non-existent task", func(t *testing.T) {
-		_, err := service.UpdateTask("non_existent", "New Title", false, nil)
+		_, err := service.UpdateTask(ctx, "non_existent", "New Title", false, nil)
 		helper.AssertError(err, true, "updating non-existent task")
 	})
 
 	t.Run("handles storage get error", func(t *testing.T) {
 		helper.GetMockStorage().SetError(true, "get error")
 
-		_, err := service.UpdateTask("update_task", "New Title", false, nil)
+		_, err := service.UpdateTask(ctx, "update_task", "New Title", false, nil)
 		helper.AssertError(err, true, "updating task with storage get error")
 
 		// Reset error state
@@ -241,6 +288,7 @@ func TestService_UpdateTask(t *testing.T) {
 }
 
 func TestService_MarkTaskDone(t *testing.T) {
+	ctx := context.Background()
 	helper := NewTestHelper(t)
 	service := helper.GetService()
 
@@ -249,36 +297,42 @@ func TestService_MarkTaskDone(t *testing.T) {
 	helper.SeedMockStorage([]*models.Task{task})
 
 	t.Run("marks task as done", func(t *testing.T) {
-		err := service.MarkTaskDone("mark_done_task", true)
+		err := service.MarkTaskDone(ctx, "mark_done_task", true)
 		helper.AssertNoError(err, "marking task as done")
 
 		// Verify task is marked as done in storage
-		updatedTask := helper.GetMockStorage().tasks["mark_done_task"]
+		updatedTask := helper.StoredTask("mark_done_task")
 		if !updatedTask.Done {
 			t.Error("Expected task to be marked as done")
 		}
+		if updatedTask.CompletedAt == nil || time.Since(*updatedTask.CompletedAt) > time.Minute {
+			t.Errorf("Expected completion time to be recorded, got %v", updatedTask.CompletedAt)
+		}
 	})
 
 	t.Run("marks task as undone", func(t *testing.T) {
-		err := service.MarkTaskDone("mark_done_task", false)
+		err := service.MarkTaskDone(ctx, "mark_done_task", false)
 		helper.AssertNoError(err, "marking task as undone")
 
 		// Verify task is marked as undone in storage
-		updatedTask := helper.GetMockStorage().tasks["mark_done_task"]
+		updatedTask := helper.StoredTask("mark_done_task")
 		if updatedTask.Done {
 			t.Error("Expected task to be marked as undone")
 		}
+		if updatedTask.CompletedAt != nil {
+			t.Errorf("Expected completion time to be cleared, got %v", updatedTask.CompletedAt)
+		}
 	})
 
 	t.Run("fails for non-existent task", func(t *testing.T) {
-		err := service.MarkTaskDone("non_existent", true)
+		err := service.MarkTaskDone(ctx, "non_existent", true)
 		helper.AssertError(err, true, "marking non-existent task as done")
 	})
 
 	t.Run("handles storage error", func(t *testing.T) {
 		helper.GetMockStorage().SetError(true, "storage error")
 
-		err := service.MarkTaskDone("mark_done_task", true)
+		err := service.MarkTaskDone(ctx, "mark_done_task", true)
 		helper.AssertError(err, true, "marking task done with storage error")
 
 		// Reset error state
@@ -287,6 +341,7 @@ func TestService_MarkTaskDone(t *testing.T) {
 }
 
 func TestService_DeleteTask(t *testing.T) {
+	ctx := context.Background()
 	helper := NewTestHelper(t)
 	service := helper.GetService()
 
@@ -295,25 +350,24 @@ func TestService_DeleteTask(t *testing.T) {
 	helper.SeedMockStorage([]*models.Task{task})
 
 	t.Run("deletes task successfully", func(t *testing.T) {
-		err := service.DeleteTask("delete_task")
+		err := service.DeleteTask(ctx, "delete_task")
 		helper.AssertNoError(err, "deleting task")
 
 		// Verify task is deleted from storage
-		_, exists := helper.GetMockStorage().tasks["delete_task"]
-		if exists {
+		if _, err := helper.GetMockStorage().GetByID("delete_task"); err == nil {
 			t.Error("Expected task to be deleted from storage")
 		}
 	})
 
 	t.Run("fails for non-existent task", func(t *testing.T) {
-		err := service.DeleteTask("non_existent")
+		err := service.DeleteTask(ctx, "non_existent")
 		helper.AssertError(err, true, "deleting non-existent task")
 	})
 
 	t.Run("handles storage error", func(t *testing.T) {
 		helper.GetMockStorage().SetError(true, "storage error")
 
-		err := service.DeleteTask("any_task")
+		err := service.DeleteTask(ctx, "any_task")
 		helper.AssertError(err, true, "deleting task with storage error")
 
 		// Reset error state
@@ -322,6 +376,7 @@ func TestService_DeleteTask(t *testing.T) {
 }
 
 func TestService_GetDueTasks(t *testing.T) {
+	ctx := context.Background()
 	helper := NewTestHelper(t)
 	service := helper.GetService()
 
@@ -336,7 +391,7 @@ func TestService_GetDueTasks(t *testing.T) {
 	helper.SeedMockStorage(tasks)
 
 	t.Run("gets tasks due within specified days", func(t *testing.T) {
-		dueTasks, err := service.GetDueTasks(2) // Next 2 days
+		dueTasks, err := service.GetDueTasks(ctx, 2) // Next 2 days
 		helper.AssertNoError(err, "getting due tasks")
 
 		// Should include: due_today, due_tomorrow, and overdue
@@ -369,7 +424,7 @@ func TestService_GetDueTasks(t *testing.T) {
 	})
 
 	t.Run("gets tasks due within 7 days", func(t *testing.T) {
-		dueTasks, err := service.GetDueTasks(7)
+		dueTasks, err := service.GetDueTasks(ctx, 7)
 		helper.AssertNoError(err, "getting due tasks within 7 days")
 
 		// Should include all tasks with due dates
@@ -381,11 +436,11 @@ func TestService_GetDueTasks(t *testing.T) {
 
 	t.Run("handles empty result", func(t *testing.T) {
 		// Clear storage and add only tasks without due dates
-		helper.GetMockStorage().tasks = make(map[string]*models.Task)
+		helper.GetMockStorage().Clear()
 		noDueDateTask := helper.CreateSampleTask("no_due", "No Due Date")
 		helper.SeedMockStorage([]*models.Task{noDueDateTask})
 
-		dueTasks, err := service.GetDueTasks(7)
+		dueTasks, err := service.GetDueTasks(ctx, 7)
 		helper.AssertNoError(err, "getting due tasks with no results")
 
 		if len(dueTasks) != 0 {
@@ -396,7 +451,7 @@ func TestService_GetDueTasks(t *testing.T) {
 	t.Run("handles storage error", func(t *testing.T) {
 		helper.GetMockStorage().SetError(true, "storage error")
 
-		_, err := service.GetDueTasks(7)
+		_, err := service.GetDueTasks(ctx, 7)
 		helper.AssertError(err, true, "getting due tasks with storage error")
 
 		// Reset error state
@@ -405,6 +460,7 @@ func TestService_GetDueTasks(t *testing.T) {
 }
 
 func TestService_GetTasksSummary(t *testing.T) {
+	ctx := context.Background()
 	helper := NewTestHelper(t)
 	service := helper.GetService()
 
@@ -426,7 +482,7 @@ func TestService_GetTasksSummary(t *testing.T) {
 	helper.SeedMockStorage(tasks)
 
 	t.Run("calculates summary correctly", func(t *testing.T) {
-		total, done, overdue, err := service.GetTasksSummary()
+		total, done, overdue, err := service.GetTasksSummary(ctx)
 		helper.AssertNoError(err, "getting tasks summary")
 
 		expectedTotal := 7
@@ -448,9 +504,9 @@ func TestService_GetTasksSummary(t *testing.T) {
 
 	t.Run("handles empty storage", func(t *testing.T) {
 		// Clear storage
-		helper.GetMockStorage().tasks = make(map[string]*models.Task)
+		helper.GetMockStorage().Clear()
 
-		total, done, overdue, err := service.GetTasksSummary()
+		total, done, overdue, err := service.GetTasksSummary(ctx)
 		helper.AssertNoError(err, "getting summary with empty storage")
 
 		if total != 0 || done != 0 || overdue != 0 {
@@ -461,7 +517,7 @@ func TestService_GetTasksSummary(t *testing.T) {
 	t.Run("handles storage error", func(t *testing.T) {
 		helper.GetMockStorage().SetError(true, "storage error")
 
-		_, _, _, err := service.GetTasksSummary()
+		_, _, _, err := service.GetTasksSummary(ctx)
 		helper.AssertError(err, true, "getting summary with storage error")
 
 		// Reset error state
@@ -469,6 +525,580 @@ func TestService_GetTasksSummary(t *testing.T) {
 	})
 }
 
+func TestService_SearchTasks(t *testing.T) {
+	ctx := context.Background()
+	helper := NewTestHelper(t)
+	service := helper.GetService()
+
+	helper.SeedMockStorage([]*models.Task{
+		helper.CreateSampleTask("task1", "Plan the sprint"),
+		helper.CreateSampleTask("task2", "Sprint sprint review"),
+		helper.CreateSampleTask("task3", "Buy milk"),
+	})
+
+	t.Run("returns matching tasks by relevance", func(t *testing.T) {
+		results, err := service.SearchTasks(ctx, "sprint", 10)
+		helper.AssertNoError(err, "searching tasks")
+
+		if len(results) != 2 || results[0].Task.ID != "task2" || results[1].Task.ID != "task1" {
+			t.Fatalf("Expected task2 then task1, got %+v", results)
+		}
+		if got := results[1].Highlight("*", "*", nil); got != "Plan the *sprint*" {
+			t.Errorf("Unexpected highlight %q", got)
+		}
+	})
+
+	t.Run("handles storage error", func(t *testing.T) {
+		helper.GetMockStorage().SetError(true, "storage error")
+		defer helper.GetMockStorage().SetError(false, "")
+
+		_, err := service.SearchTasks(ctx, "sprint", 10)
+		helper.AssertError(err, true, "searching with storage error")
+	})
+}
+
+func TestService_ListCompletedBefore(t *testing.T) {
+	ctx := context.Background()
+	helper := NewTestHelper(t)
+	service := helper.GetService()
+
+	old := time.Now().AddDate(0, 0, -40)
+	recent := time.Now().AddDate(0, 0, -5)
+
+	oldTask := helper.CreateCompletedTask("old", "Old")
+	oldTask.CompletedAt = &old
+	recentTask := helper.CreateCompletedTask("recent", "Recent")
+	recentTask.CompletedAt = &recent
+	legacyTask := helper.CreateCompletedTask("legacy", "Legacy")
+	pendingTask := helper.CreateSampleTask("pending", "Pending")
+	helper.SeedMockStorage([]*models.Task{oldTask, recentTask, legacyTask, pendingTask})
+
+	tasks, err := service.ListCompletedBefore(ctx, time.Now().AddDate(0, 0, -30))
+	helper.AssertNoError(err, "listing completed tasks")
+	if len(tasks) != 1 || tasks[0].ID != "old" {
+		t.Errorf("Expected only the old task, got %v", tasks)
+	}
+
+	count, err := service.BackfillCompletedAt(ctx)
+	helper.AssertNoError(err, "backfilling completion times")
+	if count != 1 {
+		t.Errorf("Expected 1 backfilled task, got %d", count)
+	}
+	if helper.StoredTask("legacy").CompletedAt == nil {
+		t.Error("Expected legacy task to get a completion time")
+	}
+	if got := helper.StoredTask("old").CompletedAt; !got.Equal(old) {
+		t.Errorf("Expected existing completion time to be kept, got %v", got)
+	}
+}
+
+func TestService_Events(t *testing.T) {
+	ctx := context.Background()
+	helper := NewTestHelper(t)
+
+	bus := events.NewBus()
+	defer bus.Close()
+	rec := events.Record(t, bus)
+	service := NewService(helper.GetMockStorage(), WithEventBus(bus))
+
+	task, err := service.CreateTask(ctx, "Evented", nil)
+	helper.AssertNoError(err, "creating task")
+	_, err = service.UpdateTask(ctx, task.ID, "Renamed", false, nil)
+	helper.AssertNoError(err, "updating task")
+	helper.AssertNoError(service.MarkTaskDone(ctx, task.ID, true), "completing task")
+	helper.AssertNoError(service.MarkTaskDone(ctx, task.ID, true), "completing task again")
+	helper.AssertNoError(service.DeleteTask(ctx, task.ID), "deleting task")
+
+	rec.AssertTypes(t, events.TaskCreated, events.TaskUpdated, events.TaskCompleted, events.TaskUpdated, events.TaskDeleted)
+	got := rec.Events()
+
+	if got[0].Before != nil || got[0].After == nil || got[0].TaskID != task.ID {
+		t.Errorf("Expected a creation event with only an after snapshot, got %+v", got[0])
+	}
+	if got[1].Before.Title != "Evented" || got[1].After.Title != "Renamed" {
+		t.Errorf("Expected before and after titles, got %q and %q", got[1].Before.Title, got[1].After.Title)
+	}
+	if got[2].Before.Done || !got[2].After.Done || got[2].After.CompletedAt == nil {
+		t.Errorf("Expected a completion snapshot pair, got %+v and %+v", got[2].Before, got[2].After)
+	}
+	if got[4].After != nil || got[4].Before.Title != "Renamed" || !got[4].Before.Done {
+		t.Errorf("Expected deletion event to carry the last task state, got %+v", got[4])
+	}
+
+	t.Run("snapshots are copies", func(t *testing.T) {
+		rec.Reset()
+		task, err := service.CreateTask(ctx, "Original", nil)
+		helper.AssertNoError(err, "creating task")
+		task.Title = "Mutated"
+		if title := rec.Events()[0].After.Title; title != "Original" {
+			t.Errorf("Expected the snapshot to be unaffected, got %q", title)
+		}
+	})
+
+	t.Run("no event on failure", func(t *testing.T) {
+		rec.Reset()
+		if err := service.DeleteTask(ctx, "non_existent"); err == nil {
+			t.Fatal("Expected error deleting non-existent task")
+		}
+		if _, err := service.CreateTask(ctx, " ", nil); err == nil {
+			t.Fatal("Expected error creating task without title")
+		}
+		rec.AssertNone(t)
+	})
+
+	t.Run("subscribers run outside the service lock", func(t *testing.T) {
+		bus := events.NewBus()
+		defer bus.Close()
+		service := NewService(helper.GetMockStorage(), WithEventBus(bus))
+
+		// Completing every created task from a synchronous subscriber would
+		// deadlock if events were delivered while the service lock is held
+		var order []events.Type
+		bus.Subscribe("complete", func(ctx context.Context, event events.Event) {
+			order = append(order, event.Type)
+			if event.Type == events.TaskCreated {
+				if err := service.MarkTaskDone(ctx, event.TaskID, true); err != nil {
+					t.Errorf("Expected the subscriber to update the task, got %v", err)
+				}
+			}
+		})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			task, err := service.CreateTask(ctx, "Reentrant", nil)
+			helper.AssertNoError(err, "creating task")
+			got, err := service.GetTask(ctx, task.ID)
+			helper.AssertNoError(err, "getting task")
+			if !got.Done {
+				t.Error("Expected the subscriber to have completed the task")
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out: subscriber deadlocked on the service lock")
+		}
+		if len(order) != 2 || order[0] != events.TaskCreated || order[1] != events.TaskCompleted {
+			t.Errorf("Expected created then completed, got %v", order)
+		}
+	})
+}
+
+func TestService_Versions(t *testing.T) {
+	ctx := context.Background()
+	helper := NewTestHelper(t)
+	service := helper.GetService()
+
+	task, err := service.CreateTask(ctx, "Versioned", nil)
+	helper.AssertNoError(err, "creating task")
+	if task.Version != 1 {
+		t.Fatalf("Expected a new task at version 1, got %d", task.Version)
+	}
+
+	title, done := "Renamed", true
+	task, err = service.UpdateTaskVersion(ctx, task.ID, 1, Changes{Title: &title})
+	helper.AssertNoError(err, "updating at the current version")
+	if task.Title != "Renamed" || task.Version != 2 {
+		t.Errorf("Expected the renamed task at version 2, got %q at %d", task.Title, task.Version)
+	}
+
+	// A stale writer must not overwrite the rename
+	if _, err := service.UpdateTaskVersion(ctx, task.ID, 1, Changes{Done: &done}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict for a stale version, got %v", err)
+	}
+	if err := service.DeleteTaskVersion(ctx, task.ID, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict deleting at a stale version, got %v", err)
+	}
+	empty := " "
+	if _, err := service.UpdateTaskVersion(ctx, task.ID, 2, Changes{Title: &empty}); err == nil {
+		t.Error("Expected an error for an empty title")
+	}
+
+	// Unchecked changes still advance the version
+	helper.AssertNoError(service.MarkTaskDone(ctx, task.ID, true), "completing task")
+	task, err = service.UpdateTask(ctx, task.ID, "", true, nil)
+	helper.AssertNoError(err, "updating task")
+	if task.Version != 4 || task.Title != "Renamed" {
+		t.Errorf("Expected version 4 with the title kept, got %d %q", task.Version, task.Title)
+	}
+
+	helper.AssertNoError(service.DeleteTaskVersion(ctx, task.ID, 4), "deleting at the current version")
+	if _, err := service.GetTask(ctx, task.ID); err == nil {
+		t.Error("Expected the task to be deleted")
+	}
+
+	t.Run("versions are checked past the cache", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tasks.json")
+		shared, err := storage.NewJSONStorage(path)
+		helper.AssertNoError(err, "creating storage")
+		service := NewService(storage.NewCachedStorage(shared, storage.CacheConfig{}))
+		other := NewService(shared)
+
+		task, err := service.CreateTask(ctx, "Shared", nil)
+		helper.AssertNoError(err, "creating task")
+		service.GetTask(ctx, task.ID)
+
+		// Another process renames the task while the cache holds version 1
+		title := "Renamed elsewhere"
+		_, err = other.UpdateTaskVersion(ctx, task.ID, 1, Changes{Title: &title})
+		helper.AssertNoError(err, "updating elsewhere")
+
+		if _, err := service.UpdateTaskVersion(ctx, task.ID, 1, Changes{Done: &done}); !errors.Is(err, ErrVersionConflict) {
+			t.Errorf("Expected ErrVersionConflict behind a stale cache, got %v", err)
+		}
+		updated, err := service.UpdateTaskVersion(ctx, task.ID, 2, Changes{Done: &done})
+		helper.AssertNoError(err, "updating at the stored version")
+		if updated.Title != "Renamed elsewhere" || !updated.Done || updated.Version != 3 {
+			t.Errorf("Expected the change applied to the stored task, got %+v", updated)
+		}
+	})
+}
+
+func TestService_ApplyBatch(t *testing.T) {
+	ctx := context.Background()
+	ptr := func(s string) *string { return &s }
+
+	t.Run("applies items in order", func(t *testing.T) {
+		helper := NewTestHelper(t)
+		helper.SeedMockStorage([]*models.Task{helper.CreateSampleTask("b1", "First"), helper.CreateSampleTask("b2", "Second")})
+		bus := events.NewBus()
+		defer bus.Close()
+		rec := events.Record(t, bus)
+		service := NewService(helper.GetMockStorage(), WithEventBus(bus))
+
+		done, version := true, int64(0)
+		results, err := service.ApplyBatch(ctx, []BatchItem{
+			{Op: BatchCreate, Changes: Changes{Title: ptr("Created")}},
+			{Op: BatchCreate, Changes: Changes{Title: ptr("Created done"), Done: &done}},
+			{Op: BatchUpdate, ID: "b1", Version: &version, Changes: Changes{Title: ptr("Renamed")}},
+			{Op: BatchUpdate, ID: "b1", Changes: Changes{Done: &done}},
+			{Op: BatchDelete, ID: "b2"},
+		}, false)
+		helper.AssertNoError(err, "applying batch")
+
+		for i, result := range results {
+			if result.Err != nil || result.Task == nil {
+				t.Errorf("Expected item %d to succeed, got %+v", i, result)
+			}
+		}
+		if results[0].Task.ID == results[1].Task.ID {
+			t.Error("Expected created tasks to get distinct IDs")
+		}
+		if !results[1].Task.Done || results[1].Task.CompletedAt == nil {
+			t.Errorf("Expected the second task to be created done, got %+v", results[1].Task)
+		}
+		// The second update sees the first
+		if stored := helper.StoredTask("b1"); stored.Title != "Renamed" || !stored.Done || stored.Version != 2 {
+			t.Errorf("Expected b1 renamed and done at version 2, got %+v", stored)
+		}
+		if _, err := helper.GetMockStorage().GetByID("b2"); err == nil {
+			t.Error("Expected b2 to be deleted")
+		}
+		rec.AssertTypes(t, events.TaskCreated, events.TaskCreated, events.TaskUpdated, events.TaskCompleted, events.TaskDeleted)
+	})
+
+	t.Run("rejects failing items on their own", func(t *testing.T) {
+		helper := NewTestHelper(t)
+		helper.SeedMockStorage([]*models.Task{helper.CreateSampleTask("p1", "Partial")})
+		stale := int64(7)
+
+		results, err := helper.GetService().ApplyBatch(ctx, []BatchItem{
+			{Op: BatchCreate, Changes: Changes{Title: ptr("Kept")}},
+			{Op: BatchCreate, Changes: Changes{Title: ptr(" ")}},
+			{Op: BatchUpdate, ID: "missing", Changes: Changes{Title: ptr("Missing")}},
+			{Op: BatchDelete, ID: "p1", Version: &stale},
+			{Op: "archive", ID: "p1"},
+		}, false)
+		helper.AssertNoError(err, "applying batch")
+
+		if results[0].Err != nil {
+			t.Errorf("Expected the valid item to succeed, got %v", results[0].Err)
+		}
+		if !errors.Is(results[1].Err, ErrInvalidBatchItem) || !errors.Is(results[4].Err, ErrInvalidBatchItem) {
+			t.Errorf("Expected ErrInvalidBatchItem, got %v and %v", results[1].Err, results[4].Err)
+		}
+		if !errors.Is(results[2].Err, ErrTaskNotFound) {
+			t.Errorf("Expected ErrTaskNotFound, got %v", results[2].Err)
+		}
+		if !errors.Is(results[3].Err, ErrVersionConflict) {
+			t.Errorf("Expected ErrVersionConflict, got %v", results[3].Err)
+		}
+		if tasks, _ := helper.GetMockStorage().GetAll(); len(tasks) != 2 {
+			t.Errorf("Expected p1 and the created task, got %d tasks", len(tasks))
+		}
+	})
+
+	t.Run("rejects later items on a task whose write failed", func(t *testing.T) {
+		helper := NewTestHelper(t)
+		helper.SeedMockStorage([]*models.Task{helper.CreateSampleTask("f1", "Failing"), helper.CreateSampleTask("f2", "Other")})
+		store := rejectingStorage{Storage: helper.GetMockStorage(), title: "Rejected"}
+		done := true
+
+		results, err := NewService(store).ApplyBatch(ctx, []BatchItem{
+			{Op: BatchUpdate, ID: "f1", Changes: Changes{Title: ptr("Rejected")}},
+			{Op: BatchUpdate, ID: "f2", Changes: Changes{Done: &done}},
+			{Op: BatchCreate, Changes: Changes{Title: ptr("Created")}},
+			{Op: BatchUpdate, ID: "f1", Changes: Changes{Done: &done}},
+			{Op: BatchDelete, ID: "f1"},
+		}, false)
+		helper.AssertNoError(err, "applying batch")
+
+		if results[0].Err == nil || results[1].Err != nil || results[2].Err != nil {
+			t.Errorf("Expected only the first item to fail, got %+v", results[:3])
+		}
+		// The later items were prepared on top of the rejected update
+		if !errors.Is(results[3].Err, ErrEarlierItemFailed) || !errors.Is(results[4].Err, ErrEarlierItemFailed) {
+			t.Errorf("Expected ErrEarlierItemFailed, got %v and %v", results[3].Err, results[4].Err)
+		}
+		if stored := helper.StoredTask("f1"); stored.Title != "Failing" || stored.Done || stored.Version != 0 {
+			t.Errorf("Expected f1 unchanged, got %+v", stored)
+		}
+		if stored := helper.StoredTask("f2"); !stored.Done {
+			t.Errorf("Expected f2 done, got %+v", stored)
+		}
+	})
+
+	t.Run("versioned items fail when the task changes after it was read", func(t *testing.T) {
+		helper := NewTestHelper(t)
+		helper.SeedMockStorage([]*models.Task{helper.CreateSampleTask("r1", "Raced")})
+		store := &racingStorage{Storage: helper.GetMockStorage(), id: "r1", title: "Changed elsewhere"}
+		version := int64(0)
+
+		results, err := NewService(store).ApplyBatch(ctx, []BatchItem{
+			{Op: BatchUpdate, ID: "r1", Version: &version, Changes: Changes{Title: ptr("Overwrite")}},
+		}, false)
+		helper.AssertNoError(err, "applying batch")
+
+		if !errors.Is(results[0].Err, ErrVersionConflict) {
+			t.Errorf("Expected ErrVersionConflict, got %v", results[0].Err)
+		}
+		if stored := helper.StoredTask("r1"); stored.Title != "Changed elsewhere" || stored.Version != 1 {
+			t.Errorf("Expected the other writer's change to be kept, got %+v", stored)
+		}
+	})
+
+	t.Run("versioned items are checked past the cache", func(t *testing.T) {
+		helper := NewTestHelper(t)
+		helper.SeedMockStorage([]*models.Task{helper.CreateSampleTask("c1", "Cached")})
+		cached := storage.NewCachedStorage(helper.GetMockStorage(), storage.CacheConfig{})
+		service := NewService(cached)
+		if _, err := cached.GetByID("c1"); err != nil {
+			t.Fatalf("Failed to read task: %v", err)
+		}
+
+		// Another process moves the task on while the cache holds version 0
+		changed := helper.StoredTask("c1")
+		changed.Title, changed.Version = "Changed elsewhere", 1
+		helper.AssertNoError(helper.GetMockStorage().Update(changed), "updating task elsewhere")
+
+		version := int64(0)
+		for _, atomic := range []bool{false, true} {
+			results, _ := service.ApplyBatch(ctx, []BatchItem{
+				{Op: BatchDelete, ID: "c1", Version: &version},
+			}, atomic)
+			if !errors.Is(results[0].Err, ErrVersionConflict) {
+				t.Errorf("atomic=%v: expected ErrVersionConflict, got %v", atomic, results[0].Err)
+			}
+		}
+		helper.StoredTask("c1")
+	})
+
+	t.Run("atomic batch applies nothing when an item fails", func(t *testing.T) {
+		helper := NewTestHelper(t)
+		helper.SeedMockStorage([]*models.Task{helper.CreateSampleTask("a1", "Atomic")})
+		bus := events.NewBus()
+		defer bus.Close()
+		rec := events.Record(t, bus)
+		service := NewService(helper.GetMockStorage(), WithEventBus(bus))
+
+		results, err := service.ApplyBatch(ctx, []BatchItem{
+			{Op: BatchCreate, Changes: Changes{Title: ptr("Rolled back")}},
+			{Op: BatchUpdate, ID: "a1", Changes: Changes{Title: ptr("Rolled back")}},
+			{Op: BatchDelete, ID: "missing"},
+		}, true)
+
+		var batchErr *storage.BatchError
+		if !errors.As(err, &batchErr) || batchErr.Index != 2 || !errors.Is(err, ErrTaskNotFound) {
+			t.Fatalf("Expected item 2 to fail with ErrTaskNotFound, got %v", err)
+		}
+		if !errors.Is(results[0].Err, ErrBatchAborted) || !errors.Is(results[1].Err, ErrBatchAborted) {
+			t.Errorf("Expected the other items to be aborted, got %+v", results)
+		}
+		if tasks, _ := helper.GetMockStorage().GetAll(); len(tasks) != 1 || tasks[0].Title != "Atomic" {
+			t.Errorf("Expected only the unchanged a1, got %+v", tasks)
+		}
+		rec.AssertNone(t)
+	})
+
+	t.Run("atomic batch commits", func(t *testing.T) {
+		helper := NewTestHelper(t)
+		helper.SeedMockStorage([]*models.Task{helper.CreateSampleTask("v1", "Versioned")})
+		version := int64(0)
+		results, err := helper.GetService().ApplyBatch(ctx, []BatchItem{
+			{Op: BatchCreate, Changes: Changes{Title: ptr("One")}},
+			{Op: BatchCreate, Changes: Changes{Title: ptr("Two")}},
+			{Op: BatchUpdate, ID: "v1", Version: &version, Changes: Changes{Title: ptr("Renamed")}},
+		}, true)
+		helper.AssertNoError(err, "applying batch")
+		if len(results) != 3 || results[1].Task == nil {
+			t.Fatalf("Expected three results, got %+v", results)
+		}
+		helper.StoredTask(results[1].Task.ID)
+		if stored := helper.StoredTask("v1"); stored.Title != "Renamed" || stored.Version != 1 {
+			t.Errorf("Expected the versioned update, got %+v", stored)
+		}
+	})
+
+	t.Run("storage errors", func(t *testing.T) {
+		helper := NewTestHelper(t)
+		helper.GetMockStorage().SetError(true, "storage down")
+
+		results, err := helper.GetService().ApplyBatch(ctx, []BatchItem{{Op: BatchCreate, Changes: Changes{Title: ptr("Down")}}}, false)
+		helper.AssertNoError(err, "applying a non-atomic batch")
+		if results[0].Err == nil {
+			t.Error("Expected the item to fail")
+		}
+
+		if _, err := helper.GetService().ApplyBatch(ctx, []BatchItem{{Op: BatchCreate, Changes: Changes{Title: ptr("Down")}}}, true); err == nil {
+			t.Error("Expected the atomic batch to fail")
+		}
+	})
+}
+
+// rejectingStorage fails updates that set title, and hides the batch
+// operations of the storage it wraps
+type rejectingStorage struct {
+	storage.Storage
+	title string
+}
+
+func (r rejectingStorage) Update(task *models.Task) error {
+	if task.Title == r.title {
+		return errors.New("update rejected")
+	}
+	return r.Storage.Update(task)
+}
+
+// racingStorage changes the task with id, as another process would, right
+// after it is first read
+type racingStorage struct {
+	storage.Storage
+	id, title string
+	raced     bool
+}
+
+func (r *racingStorage) GetByID(id string) (*models.Task, error) {
+	task, err := r.Storage.GetByID(id)
+	if err != nil || id != r.id || r.raced {
+		return task, err
+	}
+	r.raced = true
+	changed := *task
+	changed.Title, changed.Version = r.title, task.Version+1
+	return task, r.Storage.Update(&changed)
+}
+
+// fakeWatcher hands the changes sent on it to Service.Watch
+type fakeWatcher chan storage.Change
+
+func (w fakeWatcher) Watch(ctx context.Context) (<-chan storage.Change, error) {
+	return w, nil
+}
+
+func TestService_Watch(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, err := storage.NewJSONStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	// external writes to the same file, as another process would
+	external, err := storage.NewJSONStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	bus := events.NewBus()
+	defer bus.Close()
+	service := NewService(store, WithEventBus(bus))
+	existing, err := service.CreateTask(ctx, "Existing", nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	rec := events.Record(t, bus)
+
+	watcher := make(fakeWatcher)
+	done := make(chan error, 1)
+	go func() { done <- service.Watch(ctx, watcher) }()
+
+	// The watcher echoes writes made before and during the watch
+	watcher <- storage.Change{Op: storage.ChangeCreate, TaskID: existing.ID, Task: existing}
+
+	edited := *existing
+	edited.Title, edited.Version = "Edited elsewhere", 2
+	if err := external.Update(&edited); err != nil {
+		t.Fatalf("Failed to update task: %v", err)
+	}
+	watcher <- storage.Change{Op: storage.ChangeUpdate, TaskID: edited.ID, Task: &edited}
+	got := rec.WaitFor(t, 1, time.Second)
+	if got[0].Type != events.TaskUpdated || got[0].Before.Title != "Existing" || got[0].After.Title != "Edited elsewhere" {
+		t.Fatalf("Expected the external update with the previous state, got %+v", got[0])
+	}
+
+	mine, err := service.UpdateTask(ctx, existing.ID, "Mine", false, nil)
+	if err != nil {
+		t.Fatalf("Failed to update task: %v", err)
+	}
+	watcher <- storage.Change{Op: storage.ChangeUpdate, TaskID: mine.ID, Task: &edited}
+	watcher <- storage.Change{Op: storage.ChangeUpdate, TaskID: mine.ID, Task: mine}
+
+	created := &models.Task{ID: "external", Title: "Created elsewhere", CreatedAt: time.Now(), Version: 1}
+	watcher <- storage.Change{Op: storage.ChangeCreate, TaskID: created.ID, Task: created}
+	watcher <- storage.Change{Op: storage.ChangeDelete, TaskID: created.ID}
+	rec.WaitFor(t, 4, time.Second)
+
+	if err := service.DeleteTask(ctx, mine.ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	watcher <- storage.Change{Op: storage.ChangeUpdate, TaskID: mine.ID, Task: mine}
+	watcher <- storage.Change{Op: storage.ChangeDelete, TaskID: mine.ID}
+
+	close(watcher)
+	if err := <-done; err == nil {
+		t.Error("Expected an error when the watcher stops early")
+	}
+
+	rec.AssertTypes(t, events.TaskUpdated, events.TaskUpdated, events.TaskCreated, events.TaskDeleted, events.TaskDeleted)
+	if deleted := rec.Events()[3]; deleted.Before == nil || deleted.Before.Title != "Created elsewhere" {
+		t.Errorf("Expected the external deletion to carry the last known state, got %+v", deleted)
+	}
+}
+
+func TestService_RememberForgetsDeletedTasks(t *testing.T) {
+	service := NewService(storage.NewMemoryStorage())
+	service.known = make(map[string]*models.Task)
+	service.deleted = make(map[string]time.Time)
+
+	service.remember("old", &models.Task{ID: "old"})
+	service.remember("old", nil)
+	if _, ok := service.known["old"]; ok {
+		t.Error("Expected the deleted task to be forgotten")
+	}
+	if _, ok := service.deleted["old"]; !ok {
+		t.Error("Expected a tombstone for the deleted task")
+	}
+
+	// Tombstones older than the TTL are swept on a later deletion
+	service.deleted["old"] = time.Now().Add(-2 * watchTombstoneTTL)
+	service.swept = time.Time{}
+	service.remember("new", &models.Task{ID: "new"})
+	service.remember("new", nil)
+	if _, ok := service.deleted["old"]; ok || len(service.deleted) != 1 || len(service.known) != 0 {
+		t.Errorf("Expected only the recent tombstone to be kept, got %v and %v", service.deleted, service.known)
+	}
+}
+
 func TestGenerateID(t *testing.T) {
 	t.Run("generates unique IDs", func(t *testing.T) {
 		// Generate multiple IDs to increase chance of uniqueness
@@ -500,16 +1130,28 @@ func TestGenerateID(t *testing.T) {
 			t.Errorf("Expected 10 unique IDs, got %d", len(ids))
 		}
 	})
+
+	t.Run("generates unique IDs in a tight loop", func(t *testing.T) {
+		ids := make(map[string]bool)
+		for i := 0; i < 10000; i++ {
+			id := generateID()
+			if ids[id] {
+				t.Fatalf("Generated duplicate ID: %s", id)
+			}
+			ids[id] = true
+		}
+	})
 }
 
 func TestService_EdgeCases(t *testing.T) {
+	ctx := context.Background()
 	helper := NewTestHelper(t)
 	service := helper.GetService()
 
 	t.Run("handles tasks with special characters", func(t *testing.T) {
 		specialTitle := "Task with special chars: áéíóú ñ 中文 🚀 \"quotes\" 'apostrophes'"
 
-		task, err := service.CreateTask(specialTitle, nil)
+		task, err := service.CreateTask(ctx, specialTitle, nil)
 		helper.AssertNoError(err, "creating task with special characters")
 
 		if task.Title != specialTitle {
@@ -523,7 +1165,7 @@ func TestService_EdgeCases(t *testing.T) {
 			longTitle = longTitle[:i] + "a" + longTitle[i+1:]
 		}
 
-		task, err := service.CreateTask(longTitle, nil)
+		task, err := service.CreateTask(ctx, longTitle, nil)
 		helper.AssertNoError(err, "creating task with long title")
 
 		if task.Title != longTitle {
@@ -535,7 +1177,7 @@ func TestService_EdgeCases(t *testing.T) {
 		// Very far future date
 		futureDate := time.Date(2100, 12, 31, 23, 59, 59, 0, time.UTC)
 
-		task, err := service.CreateTask("Future Task", &futureDate)
+		task, err := service.CreateTask(ctx, "Future Task", &futureDate)
 		helper.AssertNoError(err, "creating task with future date")
 
 		if task.DueDate == nil || !task.DueDate.Equal(futureDate) {
@@ -545,7 +1187,7 @@ func TestService_EdgeCases(t *testing.T) {
 		// Very old date
 		oldDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
 
-		task2, err := service.CreateTask("Old Task", &oldDate)
+		task2, err := service.CreateTask(ctx, "Old Task", &oldDate)
 		helper.AssertNoError(err, "creating task with old date")
 
 		if task2.DueDate == nil || !task2.DueDate.Equal(oldDate) {
@@ -553,3 +1195,56 @@ func TestService_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestService_ContextPropagation(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	var spans bytes.Buffer
+	var logs bytes.Buffer
+	tracer := tracing.NewTracer("test", tracing.NewStdoutExporter(&spans))
+	logger := slog.New(logging.WithContextAttrs(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	service := NewService(storage.NewTracingStorage(helper.GetMockStorage(), logger), WithLogger(logger))
+
+	ctx := tracing.ContextWithRequestID(context.Background(), "req-7")
+	ctx, root := tracer.Start(ctx, "request")
+	task, err := service.CreateTask(ctx, "Traced task", nil)
+	helper.AssertNoError(err, "creating traced task")
+	root.End()
+
+	names := make(map[string]tracing.SpanData)
+	for _, line := range strings.Split(strings.TrimSpace(spans.String()), "\n") {
+		var span tracing.SpanData
+		if err := json.Unmarshal([]byte(line), &span); err != nil {
+			t.Fatalf("Failed to decode span: %v", err)
+		}
+		names[span.Name] = span
+	}
+
+	serviceSpan, ok := names["task.Service.CreateTask"]
+	if !ok {
+		t.Fatalf("Expected service span, got %v", names)
+	}
+	storageSpan, ok := names["storage.create"]
+	if !ok {
+		t.Fatalf("Expected storage span, got %v", names)
+	}
+	if serviceSpan.ParentSpanID != root.SpanID() || storageSpan.ParentSpanID != serviceSpan.SpanID {
+		t.Error("Expected request -> service -> storage span hierarchy")
+	}
+	if storageSpan.Attributes["task.id"] != task.ID {
+		t.Errorf("Expected storage span to record task ID, got %v", storageSpan.Attributes)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log record: %v", err)
+		}
+		if record["request_id"] != "req-7" {
+			t.Errorf("Expected every log record to carry the request ID, got %v", record)
+		}
+	}
+	if !strings.Contains(logs.String(), `"msg":"storage operation"`) || !strings.Contains(logs.String(), `"msg":"task created"`) {
+		t.Errorf("Expected storage and service log records, got %s", logs.String())
+	}
+}
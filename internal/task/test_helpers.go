@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -9,111 +10,126 @@ import (
 	"GoTask_Management/internal/storage"
 )
 
-// MockStorage implements the storage.Storage interface for testing
+// MockStorage is the in-memory storage with a switch that makes every
+// operation fail, for testing how the service handles storage errors
 type MockStorage struct {
-	tasks       map[string]*models.Task
+	*storage.MemoryStorage
 	shouldError bool
 	errorMsg    string
 }
 
-// NewMockStorage creates a new mock storage
+// NewMockStorage creates an empty MockStorage
 func NewMockStorage() *MockStorage {
-	return &MockStorage{
-		tasks: make(map[string]*models.Task),
-	}
+	return &MockStorage{MemoryStorage: storage.NewMemoryStorage()}
+}
+
+// SetError configures the storage to return errors
+func (e *MockStorage) SetError(shouldError bool, errorMsg string) {
+	e.shouldError = shouldError
+	e.errorMsg = errorMsg
 }
 
-// SetError configures the mock to return errors
-func (m *MockStorage) SetError(shouldError bool, errorMsg string) {
-	m.shouldError = shouldError
-	m.errorMsg = errorMsg
+// Clear removes all tasks
+func (e *MockStorage) Clear() {
+	e.MemoryStorage = storage.NewMemoryStorage()
 }
 
 // Create implements storage.Storage
-func (m *MockStorage) Create(task *models.Task) error {
-	if m.shouldError {
-		return errors.New(m.errorMsg)
+func (e *MockStorage) Create(task *models.Task) error {
+	if e.shouldError {
+		return errors.New(e.errorMsg)
 	}
-	m.tasks[task.ID] = task
-	return nil
+	return e.MemoryStorage.Create(task)
 }
 
 // GetAll implements storage.Storage
-func (m *MockStorage) GetAll() ([]*models.Task, error) {
-	if m.shouldError {
-		return nil, errors.New(m.errorMsg)
+func (e *MockStorage) GetAll() ([]*models.Task, error) {
+	if e.shouldError {
+		return nil, errors.New(e.errorMsg)
 	}
-	
-	tasks := make([]*models.Task, 0, len(m.tasks))
-	for _, task := range m.tasks {
-		tasks = append(tasks, task)
-	}
-	return tasks, nil
+	return e.MemoryStorage.GetAll()
 }
 
 // GetByID implements storage.Storage
-func (m *MockStorage) GetByID(id string) (*models.Task, error) {
-	if m.shouldError {
-		return nil, errors.New(m.errorMsg)
-	}
-	
-	task, exists := m.tasks[id]
-	if !exists {
-		return nil, errors.New("task not found")
+func (e *MockStorage) GetByID(id string) (*models.Task, error) {
+	if e.shouldError {
+		return nil, errors.New(e.errorMsg)
 	}
-	return task, nil
+	return e.MemoryStorage.GetByID(id)
 }
 
 // Update implements storage.Storage
-func (m *MockStorage) Update(task *models.Task) error {
-	if m.shouldError {
-		return errors.New(m.errorMsg)
+func (e *MockStorage) Update(task *models.Task) error {
+	if e.shouldError {
+		return errors.New(e.errorMsg)
 	}
-	
-	if _, exists := m.tasks[task.ID]; !exists {
-		return errors.New("task not found")
-	}
-	m.tasks[task.ID] = task
-	return nil
+	return e.MemoryStorage.Update(task)
 }
 
 // Delete implements storage.Storage
-func (m *MockStorage) Delete(id string) error {
-	if m.shouldError {
-		return errors.New(m.errorMsg)
+func (e *MockStorage) Delete(id string) error {
+	if e.shouldError {
+		return errors.New(e.errorMsg)
 	}
-	
-	if _, exists := m.tasks[id]; !exists {
-		return errors.New("task not found")
+	return e.MemoryStorage.Delete(id)
+}
+
+// CreateMany implements storage.Batcher
+func (e *MockStorage) CreateMany(tasks []*models.Task) error {
+	if e.shouldError {
+		return errors.New(e.errorMsg)
+	}
+	return e.MemoryStorage.CreateMany(tasks)
+}
+
+// UpdateMany implements storage.Batcher
+func (e *MockStorage) UpdateMany(tasks []*models.Task) error {
+	if e.shouldError {
+		return errors.New(e.errorMsg)
 	}
-	delete(m.tasks, id)
-	return nil
+	return e.MemoryStorage.UpdateMany(tasks)
+}
+
+// DeleteMany implements storage.Batcher
+func (e *MockStorage) DeleteMany(ids []string) error {
+	if e.shouldError {
+		return errors.New(e.errorMsg)
+	}
+	return e.MemoryStorage.DeleteMany(ids)
+}
+
+// WithTx implements storage.Transactor
+func (e *MockStorage) WithTx(ctx context.Context, fn func(tx storage.Storage) error) error {
+	if e.shouldError {
+		return errors.New(e.errorMsg)
+	}
+	return e.MemoryStorage.WithTx(ctx, fn)
 }
 
 // Close implements storage.Storage
-func (m *MockStorage) Close() error {
-	if m.shouldError {
-		return errors.New(m.errorMsg)
+func (e *MockStorage) Close() error {
+	if e.shouldError {
+		return errors.New(e.errorMsg)
 	}
-	return nil
+	return e.MemoryStorage.Close()
 }
 
 // TestHelper provides utilities for task service testing
 type TestHelper struct {
-	t           *testing.T
-	mockStorage *MockStorage
-	service     *Service
+	t       *testing.T
+	storage *MockStorage
+	service *Service
 }
 
 // NewTestHelper creates a new test helper
 func NewTestHelper(t *testing.T) *TestHelper {
-	mockStorage := NewMockStorage()
-	service := NewService(mockStorage)
+	store := NewMockStorage()
+	service := NewService(store)
 	
 	return &TestHelper{
-		t:           t,
-		mockStorage: mockStorage,
-		service:     service,
+		t:       t,
+		storage: store,
+		service: service,
 	}
 }
 
@@ -124,7 +140,7 @@ func (h *TestHelper) GetService() *Service {
 
 // GetMockStorage returns the mock storage
 func (h *TestHelper) GetMockStorage() *MockStorage {
-	return h.mockStorage
+	return h.storage
 }
 
 // CreateSampleTask creates a sample task for testing
@@ -178,8 +194,19 @@ func (h *TestHelper) CreateMultipleTasks(count int) []*models.Task {
 // SeedMockStorage adds tasks to the mock storage
 func (h *TestHelper) SeedMockStorage(tasks []*models.Task) {
 	for _, task := range tasks {
-		h.mockStorage.tasks[task.ID] = task
+		if err := h.storage.Create(task); err != nil {
+			h.t.Fatalf("Failed to seed task %s: %v", task.ID, err)
+		}
+	}
+}
+
+// StoredTask returns the task as stored, failing the test if it is missing
+func (h *TestHelper) StoredTask(id string) *models.Task {
+	task, err := h.storage.GetByID(id)
+	if err != nil {
+		h.t.Fatalf("Failed to get stored task %s: %v", id, err)
 	}
+	return task
 }
 
 // AssertTaskEqual compares two tasks for equality
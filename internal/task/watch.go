@@ -0,0 +1,106 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+)
+
+// watchTombstoneTTL is how long the IDs of deleted tasks are remembered while
+// watching, to ignore late echoes of the writes that preceded a deletion.
+// Watchers report writes within seconds, so older tombstones are dropped.
+const watchTombstoneTTL = time.Minute
+
+// Watch publishes events for changes other processes make to the storage,
+// such as the CLI or another server instance, until ctx is done. Changes made
+// through this service are reported by the watcher too; they are recognised
+// by their version and not published twice.
+func (s *Service) Watch(ctx context.Context, watcher storage.Watcher) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes, err := watcher.Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Snapshot after the watcher started, so no change falls in between
+	tasks, err := s.storage.GetAll()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.known = make(map[string]*models.Task, len(tasks))
+	s.deleted = make(map[string]time.Time)
+	for _, task := range tasks {
+		s.known[task.ID] = task
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.known, s.deleted = nil, nil
+		s.mu.Unlock()
+	}()
+
+	for change := range changes {
+		s.apply(ctx, change)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return errors.New("storage watch ended unexpectedly")
+}
+
+// apply publishes change unless this service already knows about it
+func (s *Service) apply(ctx context.Context, change storage.Change) {
+	s.mu.Lock()
+	defer s.unlock()
+
+	known, seen := s.known[change.TaskID]
+	_, deleted := s.deleted[change.TaskID]
+	switch {
+	case deleted || (!seen && change.Op == storage.ChangeDelete):
+		// Already deleted, or an echo of writes that preceded the deletion
+	case change.Op == storage.ChangeDelete:
+		s.logger.InfoContext(ctx, "task deleted externally", "task_id", change.TaskID)
+		s.publish(ctx, events.TaskDeleted, known, nil)
+	case !seen:
+		s.logger.InfoContext(ctx, "task created externally", "task_id", change.TaskID)
+		s.publish(ctx, events.TaskCreated, nil, change.Task)
+	case change.Task.Version > known.Version:
+		s.logger.InfoContext(ctx, "task updated externally", "task_id", change.TaskID)
+		s.publish(ctx, updateEvent(known, change.Task), known, change.Task)
+	}
+}
+
+// remember records the latest state of a task while Watch runs. Deleted
+// tasks are forgotten, leaving a tombstone for watchTombstoneTTL. Callers
+// hold s.mu.
+func (s *Service) remember(id string, task *models.Task) {
+	if s.known == nil {
+		return
+	}
+	if task != nil {
+		snapshot := *task
+		s.known[id] = &snapshot
+		return
+	}
+
+	now := time.Now()
+	delete(s.known, id)
+	s.deleted[id] = now
+	if now.Sub(s.swept) < watchTombstoneTTL {
+		return
+	}
+	s.swept = now
+	for id, at := range s.deleted {
+		if now.Sub(at) > watchTombstoneTTL {
+			delete(s.deleted, id)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	tracerKey
+	spanKey
+)
+
+// RequestIDHeader is the header used to accept and return request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits accepted client IDs to a safe charset and length so
+// they can be logged and echoed without escaping
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// IsValidRequestID reports whether a client-supplied request ID can be reused
+func IsValidRequestID(id string) bool {
+	return validRequestID.MatchString(id)
+}
+
+// NewRequestID generates a random 128-bit request ID
+func NewRequestID() string {
+	return randomHex(16)
+}
+
+// ContextWithTracer returns a copy of ctx whose spans are recorded by tracer
+func ContextWithTracer(ctx context.Context, tracer *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey, tracer)
+}
+
+// TracerFromContext returns the tracer carried by ctx, or nil
+func TracerFromContext(ctx context.Context) *Tracer {
+	if ctx == nil {
+		return nil
+	}
+	tracer, _ := ctx.Value(tracerKey).(*Tracer)
+	return tracer
+}
+
+// SpanFromContext returns the current span carried by ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("tracing: failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
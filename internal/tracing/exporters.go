@@ -0,0 +1,238 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// StdoutExporter writes each finished span as one JSON line
+type StdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStdoutExporter creates an exporter writing JSON lines to w
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{w: w}
+}
+
+// ExportSpan implements Exporter
+func (e *StdoutExporter) ExportSpan(span SpanData) {
+	data, err := json.Marshal(span)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.w.Write(append(data, '\n'))
+}
+
+// Shutdown implements Exporter
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// OTLPConfig configures the OTLP/HTTP exporter
+type OTLPConfig struct {
+	Endpoint      string // e.g. http://localhost:4318/v1/traces
+	ServiceName   string
+	BatchSize     int           // spans per request, default 100
+	FlushInterval time.Duration // default 5s
+	Client        *http.Client
+	Logger        *slog.Logger
+}
+
+// OTLPExporter batches spans and posts them to an OTLP/HTTP collector using
+// the JSON protobuf encoding
+type OTLPExporter struct {
+	config OTLPConfig
+	spans  chan SpanData
+	done   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// NewOTLPExporter starts a background batcher that sends spans to config.Endpoint
+func NewOTLPExporter(config OTLPConfig) *OTLPExporter {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	e := &OTLPExporter{
+		config: config,
+		spans:  make(chan SpanData, config.BatchSize*10),
+		done:   make(chan struct{}),
+	}
+
+	e.wg.Add(1)
+	go e.run()
+	return e
+}
+
+// ExportSpan implements Exporter. Spans are dropped when the queue is full so
+// that tracing never blocks request handling.
+func (e *OTLPExporter) ExportSpan(span SpanData) {
+	select {
+	case e.spans <- span:
+	default:
+		e.config.Logger.Warn("dropping span, OTLP export queue is full", "span", span.Name)
+	}
+}
+
+// Shutdown flushes queued spans and stops the background batcher
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.once.Do(func() { close(e.done) })
+
+	finished := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *OTLPExporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, e.config.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			e.config.Logger.Warn("failed to export spans", "count", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-e.spans:
+			batch = append(batch, span)
+			if len(batch) >= e.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.done:
+			for {
+				select {
+				case span := <-e.spans:
+					batch = append(batch, span)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *OTLPExporter) send(batch []SpanData) error {
+	body, err := json.Marshal(otlpPayload(e.config.ServiceName, batch))
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.config.Client.Post(e.config.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// otlpPayload converts spans to an ExportTraceServiceRequest in OTLP/JSON form
+func otlpPayload(serviceName string, spans []SpanData) map[string]interface{} {
+	otlpSpans := make([]map[string]interface{}, 0, len(spans))
+	for _, span := range spans {
+		s := map[string]interface{}{
+			"traceId":           span.TraceID,
+			"spanId":            span.SpanID,
+			"name":              span.Name,
+			"kind":              1, // SPAN_KIND_INTERNAL
+			"startTimeUnixNano": strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			"attributes":        otlpAttributes(span.Attributes),
+			"status": map[string]interface{}{
+				"code":    span.StatusCode,
+				"message": span.StatusMessage,
+			},
+		}
+		if span.ParentSpanID != "" {
+			s["parentSpanId"] = span.ParentSpanID
+		}
+		otlpSpans = append(otlpSpans, s)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]interface{}{"service.name": serviceName}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "GoTask_Management/internal/tracing"},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func otlpAttributes(attributes map[string]interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(attributes))
+	for key, value := range attributes {
+		var v map[string]interface{}
+		switch typed := value.(type) {
+		case string:
+			v = map[string]interface{}{"stringValue": typed}
+		case bool:
+			v = map[string]interface{}{"boolValue": typed}
+		case int:
+			v = map[string]interface{}{"intValue": strconv.Itoa(typed)}
+		case int64:
+			v = map[string]interface{}{"intValue": strconv.FormatInt(typed, 10)}
+		case float64:
+			v = map[string]interface{}{"doubleValue": typed}
+		default:
+			v = map[string]interface{}{"stringValue": fmt.Sprint(typed)}
+		}
+		out = append(out, map[string]interface{}{"key": key, "value": v})
+	}
+	return out
+}
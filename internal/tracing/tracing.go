@@ -0,0 +1,237 @@
+// Package tracing provides request ID propagation and lightweight,
+// OpenTelemetry-style spans that can be exported to stdout or to a local
+// OTLP/HTTP collector.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status codes follow the OpenTelemetry span status values
+const (
+	StatusUnset = 0
+	StatusOK    = 1
+	StatusError = 2
+)
+
+// Exporter receives finished spans
+type Exporter interface {
+	ExportSpan(span SpanData)
+	Shutdown(ctx context.Context) error
+}
+
+// SpanData is the immutable snapshot of a finished span handed to exporters
+type SpanData struct {
+	TraceID       string                 `json:"trace_id"`
+	SpanID        string                 `json:"span_id"`
+	ParentSpanID  string                 `json:"parent_span_id,omitempty"`
+	Name          string                 `json:"name"`
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       time.Time              `json:"end_time"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	StatusCode    int                    `json:"status_code"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+}
+
+// Duration returns how long the span lasted
+func (d SpanData) Duration() time.Duration {
+	return d.EndTime.Sub(d.StartTime)
+}
+
+// Tracer creates spans and hands them to an exporter when they end
+type Tracer struct {
+	serviceName string
+	exporter    Exporter
+}
+
+// NewTracer creates a tracer that exports finished spans to exporter
+func NewTracer(serviceName string, exporter Exporter) *Tracer {
+	return &Tracer{serviceName: serviceName, exporter: exporter}
+}
+
+// ServiceName returns the service name spans are attributed to
+func (t *Tracer) ServiceName() string {
+	return t.serviceName
+}
+
+// Shutdown flushes and stops the exporter
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil || t.exporter == nil {
+		return nil
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+// Start begins a span as a child of the span in ctx, if any, and returns a
+// context carrying both the tracer and the new span
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		tracer:     t,
+		spanID:     randomHex(8),
+		name:       name,
+		start:      time.Now(),
+		attributes: make(map[string]interface{}),
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else if remote, ok := ctx.Value(remoteParentKey{}).(remoteParent); ok {
+		span.traceID = remote.traceID
+		span.parentID = remote.spanID
+	} else {
+		span.traceID = randomHex(16)
+	}
+
+	ctx = ContextWithTracer(ctx, t)
+	return context.WithValue(ctx, spanKey, span), span
+}
+
+// StartSpan starts a span using the tracer carried by ctx. When ctx has no
+// tracer the returned span is nil, and all Span methods are no-ops on nil.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	return TracerFromContext(ctx).Start(ctx, name)
+}
+
+// Span is a single timed operation within a trace
+type Span struct {
+	tracer   *Tracer
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	start    time.Time
+
+	mu            sync.Mutex
+	attributes    map[string]interface{}
+	statusCode    int
+	statusMessage string
+	ended         bool
+}
+
+// TraceID returns the hex trace ID, or an empty string for a nil span
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.traceID
+}
+
+// SpanID returns the hex span ID, or an empty string for a nil span
+func (s *Span) SpanID() string {
+	if s == nil {
+		return ""
+	}
+	return s.spanID
+}
+
+// SetAttribute records a key/value pair on the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+// RecordError marks the span as failed; nil errors are ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusCode = StatusError
+	s.statusMessage = err.Error()
+}
+
+// SetStatus sets the span status explicitly
+func (s *Span) SetStatus(code int, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusCode = code
+	s.statusMessage = message
+}
+
+// End finishes the span and exports it. Calling End more than once has no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	attributes := make(map[string]interface{}, len(s.attributes))
+	for k, v := range s.attributes {
+		attributes[k] = v
+	}
+	data := SpanData{
+		TraceID:       s.traceID,
+		SpanID:        s.spanID,
+		ParentSpanID:  s.parentID,
+		Name:          s.name,
+		StartTime:     s.start,
+		EndTime:       time.Now(),
+		Attributes:    attributes,
+		StatusCode:    s.statusCode,
+		StatusMessage: s.statusMessage,
+	}
+	s.mu.Unlock()
+
+	if s.tracer.exporter != nil {
+		s.tracer.exporter.ExportSpan(data)
+	}
+}
+
+type remoteParentKey struct{}
+
+type remoteParent struct {
+	traceID string
+	spanID  string
+}
+
+// ContextWithTraceParent parses a W3C traceparent header and, when valid,
+// returns a context whose next root span joins the caller's trace
+func ContextWithTraceParent(ctx context.Context, header string) context.Context {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ctx
+	}
+	if !isLowerHex(parts[1]) || !isLowerHex(parts[2]) ||
+		parts[1] == strings.Repeat("0", 32) || parts[2] == strings.Repeat("0", 16) {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteParentKey{}, remoteParent{traceID: parts[1], spanID: parts[2]})
+}
+
+// TraceParent formats the W3C traceparent header for span
+func TraceParent(span *Span) string {
+	if span == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", span.traceID, span.spanID)
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func decodeSpans(t *testing.T, data []byte) []SpanData {
+	t.Helper()
+
+	var spans []SpanData
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var span SpanData
+		if err := json.Unmarshal([]byte(line), &span); err != nil {
+			t.Fatalf("Failed to decode span %q: %v", line, err)
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("builds parent child relationships", func(t *testing.T) {
+		var out bytes.Buffer
+		tracer := NewTracer("test", NewStdoutExporter(&out))
+
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, child := StartSpan(ctx, "child")
+		child.SetAttribute("task.id", "task_1")
+		child.RecordError(errors.New("boom"))
+		child.End()
+		root.End()
+		root.End() // second End is ignored
+
+		spans := decodeSpans(t, out.Bytes())
+		if len(spans) != 2 {
+			t.Fatalf("Expected 2 spans, got %d", len(spans))
+		}
+
+		childData, rootData := spans[0], spans[1]
+		if childData.TraceID != rootData.TraceID {
+			t.Error("Expected child to share the root trace ID")
+		}
+		if childData.ParentSpanID != rootData.SpanID {
+			t.Error("Expected child parent to be the root span")
+		}
+		if rootData.ParentSpanID != "" {
+			t.Error("Expected root span to have no parent")
+		}
+		if childData.StatusCode != StatusError || childData.StatusMessage != "boom" {
+			t.Errorf("Expected error status, got %d %q", childData.StatusCode, childData.StatusMessage)
+		}
+		if childData.Attributes["task.id"] != "task_1" {
+			t.Errorf("Expected task.id attribute, got %v", childData.Attributes)
+		}
+	})
+
+	t.Run("is a no-op without a tracer in context", func(t *testing.T) {
+		ctx, span := StartSpan(context.Background(), "orphan")
+		if span != nil {
+			t.Error("Expected nil span without tracer")
+		}
+		span.SetAttribute("key", "value")
+		span.RecordError(errors.New("ignored"))
+		span.End()
+		if SpanFromContext(ctx) != nil {
+			t.Error("Expected no span in context")
+		}
+	})
+
+	t.Run("joins remote trace from traceparent", func(t *testing.T) {
+		tracer := NewTracer("test", nil)
+		ctx := ContextWithTraceParent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		_, span := tracer.Start(ctx, "remote child")
+
+		if span.TraceID() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("Expected remote trace ID, got %s", span.TraceID())
+		}
+		if span.parentID != "00f067aa0ba902b7" {
+			t.Errorf("Expected remote parent span ID, got %s", span.parentID)
+		}
+		if got := TraceParent(span); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanID()+"-01" {
+			t.Errorf("Unexpected traceparent %s", got)
+		}
+	})
+
+	t.Run("ignores invalid traceparent", func(t *testing.T) {
+		tracer := NewTracer("test", nil)
+		for _, header := range []string{"", "garbage", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"} {
+			_, span := tracer.Start(ContextWithTraceParent(context.Background(), header), "root")
+			if span.parentID != "" {
+				t.Errorf("Expected new root span for %q", header)
+			}
+		}
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	id := NewRequestID()
+	if len(id) != 32 || !IsValidRequestID(id) {
+		t.Errorf("Expected 32 character valid ID, got %q", id)
+	}
+
+	ctx := ContextWithRequestID(context.Background(), id)
+	if RequestIDFromContext(ctx) != id {
+		t.Error("Expected request ID to round-trip through context")
+	}
+	if RequestIDFromContext(context.Background()) != "" {
+		t.Error("Expected empty request ID for bare context")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []map[string]interface{}
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("Collector received invalid JSON: %v", err)
+		}
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(OTLPConfig{
+		Endpoint:      collector.URL + "/v1/traces",
+		ServiceName:   "gotask-test",
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+	})
+	tracer := NewTracer("gotask-test", exporter)
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	child.SetAttribute("count", 3)
+	child.End()
+	root.End()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Failed to shut down exporter: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 1 {
+		t.Fatalf("Expected 1 export request, got %d", len(payloads))
+	}
+
+	resourceSpans := payloads[0]["resourceSpans"].([]interface{})
+	scopeSpans := resourceSpans[0].(map[string]interface{})["scopeSpans"].([]interface{})
+	spans := scopeSpans[0].(map[string]interface{})["spans"].([]interface{})
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans in payload, got %d", len(spans))
+	}
+
+	first := spans[0].(map[string]interface{})
+	if first["name"] != "child" || first["parentSpanId"] != root.SpanID() || first["traceId"] != root.TraceID() {
+		t.Errorf("Unexpected child span payload: %v", first)
+	}
+}
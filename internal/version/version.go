@@ -0,0 +1,13 @@
+// Package version exposes build information injected at link time, e.g.
+//
+//	go build -ldflags "-X GoTask_Management/internal/version.Version=v1.2.0 -X GoTask_Management/internal/version.GitCommit=abc123"
+package version
+
+var (
+	// Version is the release version of the build
+	Version = "dev"
+	// GitCommit is the short commit hash the binary was built from
+	GitCommit = "unknown"
+	// BuildTime is the UTC time the binary was built
+	BuildTime = "unknown"
+)
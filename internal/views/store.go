@@ -0,0 +1,215 @@
+package views
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"GoTask_Management/internal/storage"
+)
+
+// Store holds saved views in the storage backend, so every server and CLI
+// sharing the database sees the same views. Each call reads the views from
+// the backend. Names are checked for duplicates within this process only:
+// two processes creating a view with the same name at once may both succeed.
+type Store struct {
+	records storage.ViewStore
+
+	// mu orders changes made through this store
+	mu sync.Mutex
+}
+
+// NewStore creates a store keeping its views in records
+func NewStore(records storage.ViewStore) *Store {
+	return &Store{records: records}
+}
+
+// Views returns the views visible to owner, oldest first: their own and
+// shared views. An empty owner returns every view.
+func (s *Store) Views(owner string) ([]SavedView, error) {
+	all, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	views := []SavedView{}
+	for _, v := range all {
+		if owner == "" || v.VisibleTo(owner) {
+			views = append(views, v)
+		}
+	}
+	return views, nil
+}
+
+// View returns the view with the given ID
+func (s *Store) View(id string) (SavedView, error) {
+	all, err := s.load()
+	if err != nil {
+		return SavedView{}, err
+	}
+	if i := index(all, id); i >= 0 {
+		return all[i], nil
+	}
+	return SavedView{}, ErrViewNotFound
+}
+
+// Lookup returns the view named name that owner runs: their own view, or
+// else the oldest shared view with the name
+func (s *Store) Lookup(name, owner string) (SavedView, error) {
+	all, err := s.load()
+	if err != nil {
+		return SavedView{}, err
+	}
+
+	var shared *SavedView
+	for i, v := range all {
+		if !strings.EqualFold(v.Name, name) {
+			continue
+		}
+		if v.Owner == owner {
+			return v, nil
+		}
+		if v.Shared && shared == nil {
+			shared = &all[i]
+		}
+	}
+	if shared == nil {
+		return SavedView{}, ErrViewNotFound
+	}
+	return *shared, nil
+}
+
+// Create validates and saves a new view, assigning its ID and timestamps
+func (s *Store) Create(view SavedView) (SavedView, error) {
+	view.Name = strings.TrimSpace(view.Name)
+	if err := view.Validate(); err != nil {
+		return SavedView{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.load()
+	if err != nil {
+		return SavedView{}, err
+	}
+	if taken(all, view) {
+		return SavedView{}, fmt.Errorf("%w: %q", ErrDuplicateView, view.Name)
+	}
+
+	view.ID = newID()
+	view.CreatedAt = time.Now().UTC()
+	view.UpdatedAt = view.CreatedAt
+	if err := s.save(view); err != nil {
+		return SavedView{}, err
+	}
+	return view, nil
+}
+
+// Update validates and replaces the view with the ID of view, keeping its
+// creation time
+func (s *Store) Update(view SavedView) (SavedView, error) {
+	view.Name = strings.TrimSpace(view.Name)
+	if err := view.Validate(); err != nil {
+		return SavedView{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.load()
+	if err != nil {
+		return SavedView{}, err
+	}
+	i := index(all, view.ID)
+	if i < 0 {
+		return SavedView{}, ErrViewNotFound
+	}
+	if taken(all, view) {
+		return SavedView{}, fmt.Errorf("%w: %q", ErrDuplicateView, view.Name)
+	}
+
+	view.CreatedAt = all[i].CreatedAt
+	view.UpdatedAt = time.Now().UTC()
+	if err := s.save(view); err != nil {
+		return SavedView{}, err
+	}
+	return view, nil
+}
+
+// Delete removes the view with the given ID
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	if index(all, id) < 0 {
+		return ErrViewNotFound
+	}
+	if err := s.records.DeleteView(id); err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	return nil
+}
+
+// load returns every view, oldest first
+func (s *Store) load() ([]SavedView, error) {
+	records, err := s.records.ListViews()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved views: %w", err)
+	}
+
+	views := make([]SavedView, 0, len(records))
+	for _, record := range records {
+		var view SavedView
+		if err := json.Unmarshal(record.Data, &view); err != nil {
+			return nil, fmt.Errorf("invalid saved view %s: %w", record.ID, err)
+		}
+		view.ID = record.ID
+		views = append(views, view)
+	}
+	sort.SliceStable(views, func(i, j int) bool {
+		if !views[i].CreatedAt.Equal(views[j].CreatedAt) {
+			return views[i].CreatedAt.Before(views[j].CreatedAt)
+		}
+		return views[i].ID < views[j].ID
+	})
+	return views, nil
+}
+
+// save writes view to the storage backend
+func (s *Store) save(view SavedView) error {
+	data, err := json.Marshal(view)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved view: %w", err)
+	}
+	record := &storage.ViewRecord{ID: view.ID, Data: data, CreatedAt: view.CreatedAt}
+	if err := s.records.SaveView(record); err != nil {
+		return fmt.Errorf("failed to save view: %w", err)
+	}
+	return nil
+}
+
+// index returns the position of the view with the given ID in views, or -1
+func index(views []SavedView, id string) int {
+	for i, v := range views {
+		if v.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// taken reports whether the owner of view has another view with its name,
+// ignoring case
+func taken(views []SavedView, view SavedView) bool {
+	for _, v := range views {
+		if v.ID != view.ID && v.Owner == view.Owner && strings.EqualFold(v.Name, view.Name) {
+			return true
+		}
+	}
+	return false
+}
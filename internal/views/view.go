@@ -0,0 +1,155 @@
+// Package views stores saved views: named task queries with a sort order
+// that can be shared between users and evaluated on demand.
+package views
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+)
+
+var (
+	// ErrViewNotFound is returned for unknown view IDs and names
+	ErrViewNotFound = errors.New("saved view not found")
+	// ErrInvalidView wraps validation failures
+	ErrInvalidView = errors.New("invalid saved view")
+	// ErrDuplicateView is returned when an owner already has a view with the name
+	ErrDuplicateView = errors.New("saved view name already in use")
+)
+
+// Sort orders accepted by SavedView.Sort; a leading "-" reverses the order
+var sortFields = map[string]bool{
+	"created":   true,
+	"due":       true,
+	"completed": true,
+	"title":     true,
+}
+
+// SavedView is a named query over tasks, e.g. "status:open due<=+7d"
+type SavedView struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Owner string `json:"owner,omitempty"`
+	// Query uses the language of GET /api/v1/tasks?q=; empty matches every task
+	Query string `json:"query"`
+	// Sort is created, due, completed or title, prefixed with "-" for
+	// descending order; empty keeps the order of the storage
+	Sort string `json:"sort,omitempty"`
+	// Shared views are listed for and can be run by every owner
+	Shared    bool      `json:"shared"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the name, query and sort order of the view
+func (v SavedView) Validate() error {
+	if strings.TrimSpace(v.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidView)
+	}
+	if _, err := v.Filter(time.Now()); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidView, err)
+	}
+	if v.Sort != "" && !sortFields[strings.TrimPrefix(v.Sort, "-")] {
+		return fmt.Errorf("%w: invalid sort %q (expected created, due, completed or title, optionally prefixed with -)", ErrInvalidView, v.Sort)
+	}
+	return nil
+}
+
+// Filter compiles the query of the view, resolving relative dates such as
+// "today" against now
+func (v SavedView) Filter(now time.Time) (*query.Filter, error) {
+	return query.ParseFilter(v.Query, now)
+}
+
+// VisibleTo reports whether owner may list and run the view
+func (v SavedView) VisibleTo(owner string) bool {
+	return v.Shared || v.Owner == owner
+}
+
+// TaskFinder selects the tasks matching a filter, as the task service does
+// for GET /api/v1/tasks?q=
+type TaskFinder interface {
+	FindTasks(ctx context.Context, filter *query.Filter) ([]*models.Task, error)
+}
+
+// Evaluate returns the tasks matching the view in its sort order. The query
+// is compiled against now and run through finder, so a view returns the same
+// tasks as listing with its query.
+func (v SavedView) Evaluate(ctx context.Context, finder TaskFinder, now time.Time) ([]*models.Task, error) {
+	filter, err := v.Filter(now)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := finder.FindTasks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	SortTasks(tasks, v.Sort)
+	return tasks, nil
+}
+
+// SortTasks orders tasks by a SavedView sort order. Tasks without the time
+// being sorted on come last in either direction, and ties keep their order.
+func SortTasks(tasks []*models.Task, order string) {
+	field := strings.TrimPrefix(order, "-")
+	desc := field != order
+
+	var less func(a, b *models.Task) bool
+	switch field {
+	case "created":
+		less = func(a, b *models.Task) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case "due":
+		less = byTime(func(t *models.Task) *time.Time { return t.DueDate })
+	case "completed":
+		less = byTime(func(t *models.Task) *time.Time { return t.CompletedAt })
+	case "title":
+		less = func(a, b *models.Task) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	default:
+		return
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if field == "due" || field == "completed" {
+			// Missing times sort last regardless of direction
+			if missing(a, field) != missing(b, field) {
+				return missing(b, field)
+			}
+		}
+		if desc {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+// byTime orders tasks by an optional time; tasks without it compare equal
+func byTime(get func(*models.Task) *time.Time) func(a, b *models.Task) bool {
+	return func(a, b *models.Task) bool {
+		ta, tb := get(a), get(b)
+		return ta != nil && tb != nil && ta.Before(*tb)
+	}
+}
+
+func missing(task *models.Task, field string) bool {
+	if field == "due" {
+		return task.DueDate == nil
+	}
+	return task.CompletedAt == nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("view_%d", time.Now().UnixNano())
+	}
+	return "view_" + hex.EncodeToString(b)
+}
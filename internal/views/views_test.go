@@ -0,0 +1,239 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/query"
+	"GoTask_Management/internal/storage"
+)
+
+func TestSavedView_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		view    SavedView
+		wantErr bool
+	}{
+		{name: "valid", view: SavedView{Name: "This week", Query: "status:open due<=+7d", Sort: "due"}},
+		{name: "empty query", view: SavedView{Name: "Everything", Sort: "-created"}},
+		{name: "missing name", view: SavedView{Name: "  ", Query: "status:open"}, wantErr: true},
+		{name: "invalid query", view: SavedView{Name: "Bad", Query: "priority:high"}, wantErr: true},
+		{name: "invalid sort", view: SavedView{Name: "Bad", Sort: "priority"}, wantErr: true},
+		{name: "bare minus", view: SavedView{Name: "Bad", Sort: "-"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.view.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidView) {
+				t.Errorf("Expected ErrInvalidView, got %v", err)
+			}
+		})
+	}
+
+	var qerr *query.Error
+	if err := (SavedView{Name: "Bad", Query: "status:blocked"}).Validate(); !errors.As(err, &qerr) {
+		t.Errorf("Expected the query error to be kept, got %v", err)
+	}
+}
+
+func TestSortTasks(t *testing.T) {
+	base := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := base.AddDate(0, 0, days)
+		return &t
+	}
+	tasks := func() []*models.Task {
+		return []*models.Task{
+			{ID: "a", Title: "beta", CreatedAt: base, DueDate: at(3)},
+			{ID: "b", Title: "Alpha", CreatedAt: base.Add(time.Hour)},
+			{ID: "c", Title: "gamma", CreatedAt: base.Add(-time.Hour), DueDate: at(1), CompletedAt: at(0)},
+			{ID: "d", Title: "delta", CreatedAt: base.Add(2 * time.Hour)},
+		}
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{order: "", want: []string{"a", "b", "c", "d"}},
+		{order: "created", want: []string{"c", "a", "b", "d"}},
+		{order: "-created", want: []string{"d", "b", "a", "c"}},
+		{order: "due", want: []string{"c", "a", "b", "d"}},
+		{order: "-due", want: []string{"a", "c", "b", "d"}},
+		{order: "completed", want: []string{"c", "a", "b", "d"}},
+		{order: "title", want: []string{"b", "a", "d", "c"}},
+		{order: "-title", want: []string{"c", "d", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			sorted := tasks()
+			SortTasks(sorted, tt.order)
+			for i, task := range sorted {
+				if task.ID != tt.want[i] {
+					t.Fatalf("SortTasks(%q) = %v, want %v", tt.order, ids(sorted), tt.want)
+				}
+			}
+		})
+	}
+}
+
+// finder records the filter it is given and returns tasks matching it
+type finder struct {
+	tasks  []*models.Task
+	filter *query.Filter
+}
+
+func (f *finder) FindTasks(ctx context.Context, filter *query.Filter) ([]*models.Task, error) {
+	f.filter = filter
+	var matched []*models.Task
+	for _, task := range f.tasks {
+		if filter.Match(task) {
+			matched = append(matched, task)
+		}
+	}
+	return matched, nil
+}
+
+func TestSavedView_Evaluate(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	soon, later := now.AddDate(0, 0, 2), now.AddDate(0, 0, 5)
+	f := &finder{tasks: []*models.Task{
+		{ID: "later", Title: "Later", DueDate: &later},
+		{ID: "done", Title: "Done", Done: true, DueDate: &soon},
+		{ID: "soon", Title: "Soon", DueDate: &soon},
+		{ID: "far", Title: "Far", DueDate: &[]time.Time{now.AddDate(0, 1, 0)}[0]},
+	}}
+
+	view := SavedView{Name: "This week", Query: "status:open due<=+7d", Sort: "due"}
+	tasks, err := view.Evaluate(context.Background(), f, now)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if got := ids(tasks); len(got) != 2 || got[0] != "soon" || got[1] != "later" {
+		t.Errorf("Evaluate = %v, want [soon later]", got)
+	}
+	if f.filter == nil || len(f.filter.Conditions) != 2 {
+		t.Errorf("Expected the compiled query to be passed to FindTasks, got %+v", f.filter)
+	}
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	records, err := storage.NewJSONStorage(path)
+	if err != nil {
+		t.Fatalf("NewJSONStorage failed: %v", err)
+	}
+	store := NewStore(records)
+
+	mine, err := store.Create(SavedView{Name: " Inbox ", Owner: "alice", Query: "status:open"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if mine.ID == "" || mine.Name != "Inbox" || mine.CreatedAt.IsZero() || !mine.UpdatedAt.Equal(mine.CreatedAt) {
+		t.Errorf("Expected an ID, a trimmed name and timestamps, got %+v", mine)
+	}
+	shared, err := store.Create(SavedView{Name: "Inbox", Owner: "bob", Query: "due<today", Shared: true})
+	if err != nil {
+		t.Fatalf("Create failed for another owner: %v", err)
+	}
+	private, _ := store.Create(SavedView{Name: "Private", Owner: "bob"})
+
+	t.Run("rejects duplicate names per owner", func(t *testing.T) {
+		if _, err := store.Create(SavedView{Name: "inbox", Owner: "alice"}); !errors.Is(err, ErrDuplicateView) {
+			t.Errorf("Expected ErrDuplicateView, got %v", err)
+		}
+		if _, err := store.Update(SavedView{ID: private.ID, Name: "INBOX", Owner: "bob"}); !errors.Is(err, ErrDuplicateView) {
+			t.Errorf("Expected ErrDuplicateView on rename, got %v", err)
+		}
+	})
+
+	t.Run("lists views visible to an owner", func(t *testing.T) {
+		if all, err := store.Views(""); err != nil || len(all) != 3 {
+			t.Errorf("Expected 3 views, got %d, %v", len(all), err)
+		}
+		alice, _ := store.Views("alice")
+		if got := viewIDs(alice); len(got) != 2 || got[0] != mine.ID || got[1] != shared.ID {
+			t.Errorf("Expected alice's view and the shared view, got %v", got)
+		}
+		if got, _ := store.Views("carol"); len(got) != 1 || got[0].ID != shared.ID {
+			t.Errorf("Expected only the shared view, got %+v", got)
+		}
+	})
+
+	t.Run("looks up own views before shared views", func(t *testing.T) {
+		if v, err := store.Lookup("inbox", "alice"); err != nil || v.ID != mine.ID {
+			t.Errorf("Lookup for alice = %+v, %v", v, err)
+		}
+		if v, err := store.Lookup("Inbox", "carol"); err != nil || v.ID != shared.ID {
+			t.Errorf("Lookup for carol = %+v, %v", v, err)
+		}
+		if _, err := store.Lookup("Private", "carol"); !errors.Is(err, ErrViewNotFound) {
+			t.Errorf("Expected ErrViewNotFound for another owner's private view, got %v", err)
+		}
+	})
+
+	t.Run("updates", func(t *testing.T) {
+		updated, err := store.Update(SavedView{ID: mine.ID, Name: "Inbox", Owner: "alice", Query: "status:open", Sort: "-created"})
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if !updated.CreatedAt.Equal(mine.CreatedAt) || updated.Sort != "-created" {
+			t.Errorf("Expected the creation time to be kept, got %+v", updated)
+		}
+		if _, err := store.Update(SavedView{ID: "view_missing", Name: "x"}); !errors.Is(err, ErrViewNotFound) {
+			t.Errorf("Expected ErrViewNotFound, got %v", err)
+		}
+		if _, err := store.Update(SavedView{ID: mine.ID, Name: "Inbox", Query: `"open`}); !errors.Is(err, ErrInvalidView) {
+			t.Errorf("Expected ErrInvalidView, got %v", err)
+		}
+	})
+
+	t.Run("deletes", func(t *testing.T) {
+		if err := store.Delete(private.ID); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if err := store.Delete(private.ID); !errors.Is(err, ErrViewNotFound) {
+			t.Errorf("Expected ErrViewNotFound, got %v", err)
+		}
+	})
+
+	t.Run("persists in the storage", func(t *testing.T) {
+		other, err := storage.NewJSONStorage(path)
+		if err != nil {
+			t.Fatalf("NewJSONStorage failed: %v", err)
+		}
+		reopened := NewStore(other)
+		v, err := reopened.View(mine.ID)
+		if err != nil || v.Sort != "-created" || !v.CreatedAt.Equal(mine.CreatedAt) {
+			t.Errorf("Expected the updated view from another store, got %+v, %v", v, err)
+		}
+		if all, _ := reopened.Views(""); len(all) != 2 {
+			t.Errorf("Expected 2 views from another store, got %d", len(all))
+		}
+	})
+}
+
+func ids(tasks []*models.Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func viewIDs(views []SavedView) []string {
+	ids := make([]string, len(views))
+	for i, v := range views {
+		ids[i] = v.ID
+	}
+	return ids
+}
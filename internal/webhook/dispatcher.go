@@ -0,0 +1,355 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"GoTask_Management/internal/events"
+)
+
+// maxBackoff caps the delay between attempts
+const maxBackoff = time.Hour
+
+// RetryPolicy controls how failed deliveries are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per delivery
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles after each failure
+	Backoff time.Duration
+}
+
+// Dispatcher manages subscriptions and delivers task events to them
+type Dispatcher struct {
+	store  *Store
+	client *http.Client
+	retry  RetryPolicy
+	logger *slog.Logger
+	now    func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	inflight map[string]bool
+}
+
+// Option configures optional Dispatcher behaviour
+type Option func(*Dispatcher)
+
+// WithLogger sets the logger used for deliveries
+func WithLogger(logger *slog.Logger) Option {
+	return func(d *Dispatcher) {
+		d.logger = logger
+	}
+}
+
+// WithClient sets the HTTP client used for deliveries
+func WithClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithRetry sets the retry policy for failed deliveries
+func WithRetry(policy RetryPolicy) Option {
+	return func(d *Dispatcher) {
+		d.retry = policy
+	}
+}
+
+// NewDispatcher creates a dispatcher backed by store. Events are delivered as
+// soon as they are handled; Start resumes deliveries left pending by a restart.
+func NewDispatcher(store *Store, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:    store,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		retry:    RetryPolicy{MaxAttempts: 5, Backoff: 10 * time.Second},
+		logger:   slog.Default(),
+		now:      time.Now,
+		inflight: make(map[string]bool),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.retry.MaxAttempts < 1 {
+		d.retry.MaxAttempts = 1
+	}
+
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	return d
+}
+
+// Start resumes pending deliveries
+func (d *Dispatcher) Start() {
+	pending, err := d.store.Pending()
+	if err != nil {
+		d.logger.Error("failed to resume webhook deliveries", "error", err)
+		return
+	}
+	for _, delivery := range pending {
+		d.dispatch(delivery)
+	}
+}
+
+// Stop cancels retries in progress and waits for deliveries to return. Pending
+// deliveries stay in the log and are resumed by the next Start.
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Subscribe validates and saves a subscription, generating its ID and, if
+// none is given, its secret
+func (d *Dispatcher) Subscribe(sub Subscription) (Subscription, error) {
+	if err := sub.Validate(); err != nil {
+		return Subscription{}, err
+	}
+	if sub.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return Subscription{}, err
+		}
+		sub.Secret = secret
+	}
+	sub.ID = newID("wh_")
+	sub.CreatedAt = d.now()
+
+	if err := d.store.AddSubscription(sub); err != nil {
+		return Subscription{}, err
+	}
+	return sub, nil
+}
+
+// Subscriptions returns all subscriptions
+func (d *Dispatcher) Subscriptions() ([]Subscription, error) {
+	return d.store.Subscriptions()
+}
+
+// Subscription returns one subscription
+func (d *Dispatcher) Subscription(id string) (Subscription, error) {
+	return d.store.Subscription(id)
+}
+
+// Unsubscribe removes a subscription and its delivery log
+func (d *Dispatcher) Unsubscribe(id string) error {
+	return d.store.DeleteSubscription(id)
+}
+
+// Deliveries returns the delivery log of a subscription, newest first
+func (d *Dispatcher) Deliveries(subscriptionID string) ([]Delivery, error) {
+	if _, err := d.store.Subscription(subscriptionID); err != nil {
+		return nil, err
+	}
+	return d.store.Deliveries(subscriptionID)
+}
+
+// Redeliver sends the payload of an earlier delivery again as a new delivery
+func (d *Dispatcher) Redeliver(subscriptionID, deliveryID string) (Delivery, error) {
+	original, err := d.store.Delivery(subscriptionID, deliveryID)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	delivery := Delivery{
+		ID:             newID("dlv_"),
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         StatusPending,
+		CreatedAt:      d.now(),
+		RedeliveryOf:   original.ID,
+	}
+	if err := d.store.SaveDelivery(delivery); err != nil {
+		return Delivery{}, err
+	}
+	d.dispatch(delivery)
+	return delivery, nil
+}
+
+// HandleEvent queues a delivery of event to every matching subscription. It
+// is an events.Handler.
+func (d *Dispatcher) HandleEvent(ctx context.Context, event events.Event) {
+	all, err := d.store.Subscriptions()
+	if err != nil {
+		d.logger.ErrorContext(ctx, "failed to read webhook subscriptions", "event", event.Type, "error", err)
+		return
+	}
+	var subs []Subscription
+	for _, sub := range all {
+		if sub.Wants(event.Type) {
+			subs = append(subs, sub)
+		}
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	body := Payload{
+		ID:         newID("evt_"),
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt.UTC(),
+		Task:       *event.Task(),
+	}
+	if event.After != nil {
+		body.Previous = event.Before
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		d.logger.ErrorContext(ctx, "failed to encode webhook payload", "event", event.Type, "error", err)
+		return
+	}
+
+	for _, sub := range subs {
+		delivery := Delivery{
+			ID:             newID("dlv_"),
+			SubscriptionID: sub.ID,
+			EventID:        body.ID,
+			Event:          event.Type,
+			Payload:        payload,
+			Status:         StatusPending,
+			CreatedAt:      d.now(),
+		}
+		if err := d.store.SaveDelivery(delivery); err != nil {
+			d.logger.ErrorContext(ctx, "failed to record webhook delivery", "subscription_id", sub.ID, "error", err)
+			continue
+		}
+		d.dispatch(delivery)
+	}
+}
+
+// dispatch delivers in the background unless the delivery is already in flight
+func (d *Dispatcher) dispatch(delivery Delivery) {
+	if d.ctx.Err() != nil {
+		return
+	}
+
+	d.mu.Lock()
+	if d.inflight[delivery.ID] {
+		d.mu.Unlock()
+		return
+	}
+	d.inflight[delivery.ID] = true
+	d.mu.Unlock()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer func() {
+			d.mu.Lock()
+			delete(d.inflight, delivery.ID)
+			d.mu.Unlock()
+		}()
+		d.deliver(delivery)
+	}()
+}
+
+// deliver makes the remaining attempts for a delivery, waiting with
+// exponential backoff between them
+func (d *Dispatcher) deliver(delivery Delivery) {
+	sub, err := d.store.Subscription(delivery.SubscriptionID)
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		// Unsubscribed while pending; the delivery log went with it
+		return
+	}
+	if err != nil {
+		// Left pending, to be resumed by the next Start
+		d.logger.Error("failed to read webhook subscription", "delivery_id", delivery.ID, "error", err)
+		return
+	}
+
+	for delivery.Attempts < d.retry.MaxAttempts {
+		if delivery.Attempts > 0 {
+			select {
+			case <-time.After(d.backoff(delivery.Attempts)):
+			case <-d.ctx.Done():
+				return
+			}
+		}
+
+		status, err := d.send(sub, delivery)
+		now := d.now()
+		delivery.Attempts++
+		delivery.LastAttemptAt = &now
+		delivery.ResponseStatus = status
+		delivery.Error = ""
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+
+		switch {
+		case err == nil:
+			delivery.Status = StatusSucceeded
+		case delivery.Attempts >= d.retry.MaxAttempts:
+			delivery.Status = StatusFailed
+		}
+
+		if saveErr := d.store.SaveDelivery(delivery); saveErr != nil {
+			d.logger.Error("failed to record webhook delivery", "delivery_id", delivery.ID, "error", saveErr)
+		}
+
+		if err == nil {
+			d.logger.Info("webhook delivered", "subscription_id", sub.ID, "delivery_id", delivery.ID, "event", delivery.Event, "attempts", delivery.Attempts)
+			return
+		}
+		d.logger.Warn("webhook delivery failed", "subscription_id", sub.ID, "delivery_id", delivery.ID, "event", delivery.Event, "attempt", delivery.Attempts, "error", err)
+	}
+
+	// Resumed with no attempts left, e.g. after max_attempts was lowered
+	if delivery.Status == StatusPending {
+		delivery.Status = StatusFailed
+		if err := d.store.SaveDelivery(delivery); err != nil {
+			d.logger.Error("failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+		}
+	}
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.retry.Backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+func (d *Dispatcher) send(sub Subscription, delivery Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoTask-Webhook")
+	req.Header.Set(HeaderEvent, string(delivery.Event))
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
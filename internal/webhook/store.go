@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"GoTask_Management/internal/storage"
+)
+
+// maxDeliveries bounds the delivery log; the oldest finished deliveries are dropped first
+const maxDeliveries = 1000
+
+// Store holds subscriptions and the delivery log in the storage backend, so
+// every server sharing the database delivers to the same subscriptions. Each
+// call reads the records from the backend. Deliveries are checked against
+// their subscription within this process only: a delivery saved while
+// another process removes its subscription is dropped when the log is next
+// trimmed.
+type Store struct {
+	records storage.WebhookStore
+
+	// mu orders changes made through this store
+	mu sync.Mutex
+}
+
+// NewStore creates a store keeping its subscriptions and deliveries in records
+func NewStore(records storage.WebhookStore) *Store {
+	return &Store{records: records}
+}
+
+// Subscriptions returns all subscriptions, oldest first
+func (s *Store) Subscriptions() ([]Subscription, error) {
+	records, err := s.records.ListWebhookSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook subscriptions: %w", err)
+	}
+
+	subs := make([]Subscription, 0, len(records))
+	for _, record := range records {
+		var sub Subscription
+		if err := json.Unmarshal(record.Data, &sub); err != nil {
+			return nil, fmt.Errorf("invalid webhook subscription %s: %w", record.ID, err)
+		}
+		sub.ID = record.ID
+		subs = append(subs, sub)
+	}
+	sort.SliceStable(subs, func(i, j int) bool {
+		if !subs[i].CreatedAt.Equal(subs[j].CreatedAt) {
+			return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+		}
+		return subs[i].ID < subs[j].ID
+	})
+	return subs, nil
+}
+
+// Subscription returns the subscription with the given ID
+func (s *Store) Subscription(id string) (Subscription, error) {
+	subs, err := s.Subscriptions()
+	if err != nil {
+		return Subscription{}, err
+	}
+	for _, sub := range subs {
+		if sub.ID == id {
+			return sub, nil
+		}
+	}
+	return Subscription{}, ErrSubscriptionNotFound
+}
+
+// AddSubscription saves a new subscription
+func (s *Store) AddSubscription(sub Subscription) error {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook subscription: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := &storage.WebhookSubscriptionRecord{ID: sub.ID, Data: data, CreatedAt: sub.CreatedAt}
+	if err := s.records.SaveWebhookSubscription(record); err != nil {
+		return fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription and its deliveries
+func (s *Store) DeleteSubscription(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.Subscription(id); err != nil {
+		return err
+	}
+	if err := s.records.DeleteWebhookSubscription(id); err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// Deliveries returns the deliveries for a subscription, newest first
+func (s *Store) Deliveries(subscriptionID string) ([]Delivery, error) {
+	deliveries, err := s.deliveries(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	return deliveries, nil
+}
+
+// Pending returns deliveries that have not finished, e.g. after a restart,
+// oldest first
+func (s *Store) Pending() ([]Delivery, error) {
+	deliveries, err := s.deliveries("")
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+
+	var pending []Delivery
+	for _, d := range deliveries {
+		if d.Status == StatusPending {
+			pending = append(pending, d)
+		}
+	}
+	return pending, nil
+}
+
+// Delivery returns the delivery with the given ID made to a subscription
+func (s *Store) Delivery(subscriptionID, id string) (Delivery, error) {
+	deliveries, err := s.deliveries(subscriptionID)
+	if err != nil {
+		return Delivery{}, err
+	}
+	for _, d := range deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return Delivery{}, ErrDeliveryNotFound
+}
+
+// SaveDelivery inserts or replaces a delivery. Deliveries for removed
+// subscriptions are rejected so late results do not resurrect their log.
+func (s *Store) SaveDelivery(d Delivery) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook delivery: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.Subscription(d.SubscriptionID); err != nil {
+		return err
+	}
+
+	record := &storage.WebhookDeliveryRecord{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		Pending:        d.Status == StatusPending,
+		Data:           data,
+		CreatedAt:      d.CreatedAt,
+	}
+	if err := s.records.SaveWebhookDelivery(record); err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	return s.trim()
+}
+
+// trim drops deliveries for removed subscriptions, and then the oldest
+// finished deliveries beyond maxDeliveries; callers hold mu
+func (s *Store) trim() error {
+	records, err := s.records.ListWebhookDeliveries("")
+	if err != nil {
+		return fmt.Errorf("failed to read webhook deliveries: %w", err)
+	}
+	// Read after the deliveries: each was saved after its subscription, so
+	// a subscription missing now has been removed
+	subscribed, err := s.subscribed()
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].CreatedAt.Equal(records[j].CreatedAt) {
+			return records[i].CreatedAt.Before(records[j].CreatedAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+
+	var drop []string
+	kept := 0
+	for _, record := range records {
+		if !subscribed[record.SubscriptionID] {
+			drop = append(drop, record.ID)
+			continue
+		}
+		kept++
+	}
+	excess := kept - maxDeliveries
+	for _, record := range records {
+		if excess <= 0 {
+			break
+		}
+		if subscribed[record.SubscriptionID] && !record.Pending {
+			drop = append(drop, record.ID)
+			excess--
+		}
+	}
+	if len(drop) == 0 {
+		return nil
+	}
+	if err := s.records.DeleteWebhookDeliveries(drop); err != nil {
+		return fmt.Errorf("failed to trim webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// subscribed returns the IDs of all subscriptions
+func (s *Store) subscribed() (map[string]bool, error) {
+	subs, err := s.Subscriptions()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(subs))
+	for _, sub := range subs {
+		ids[sub.ID] = true
+	}
+	return ids, nil
+}
+
+// deliveries decodes the deliveries for subscriptionID, or every delivery
+// when it is empty
+func (s *Store) deliveries(subscriptionID string) ([]Delivery, error) {
+	records, err := s.records.ListWebhookDeliveries(subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]Delivery, 0, len(records))
+	for _, record := range records {
+		var d Delivery
+		if err := json.Unmarshal(record.Data, &d); err != nil {
+			return nil, fmt.Errorf("invalid webhook delivery %s: %w", record.ID, err)
+		}
+		d.ID = record.ID
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
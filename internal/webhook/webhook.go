@@ -0,0 +1,150 @@
+// Package webhook delivers task lifecycle events to subscribed HTTP
+// endpoints, signing each request and retrying failed deliveries.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/models"
+)
+
+// Headers set on every delivery
+const (
+	HeaderEvent     = "X-GoTask-Event"
+	HeaderDelivery  = "X-GoTask-Delivery"
+	HeaderTimestamp = "X-GoTask-Timestamp"
+	HeaderSignature = "X-GoTask-Signature"
+)
+
+var (
+	// ErrSubscriptionNotFound is returned for unknown subscription IDs
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	// ErrDeliveryNotFound is returned for unknown delivery IDs
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrInvalidSubscription wraps validation failures
+	ErrInvalidSubscription = errors.New("invalid webhook subscription")
+)
+
+// Subscription sends the selected events to URL
+type Subscription struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret signs deliveries; it is generated when left empty
+	Secret string `json:"secret,omitempty"`
+	// Events filters which events are sent; empty means all
+	Events    []events.Type `json:"events,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// Wants reports whether the subscription receives events of the given type
+func (s Subscription) Wants(eventType events.Type) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the URL and event filter
+func (s Subscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidSubscription)
+	}
+
+	for _, e := range s.Events {
+		if !e.Valid() {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidSubscription, e)
+		}
+	}
+	return nil
+}
+
+// Redacted returns a copy without the secret, for listing
+func (s Subscription) Redacted() Subscription {
+	s.Secret = ""
+	return s
+}
+
+// Status is the state of a delivery
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Delivery records an event sent, or being sent, to one subscription
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	Event          events.Type     `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         Status          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	// RedeliveryOf is the delivery this one repeats
+	RedeliveryOf string `json:"redelivery_of,omitempty"`
+}
+
+// Payload is the JSON body of a delivery. ID identifies the event and is the
+// same for every subscription and redelivery, so receivers can de-duplicate.
+type Payload struct {
+	ID         string      `json:"id"`
+	Type       events.Type `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	// Task is the task after the change, or before it for deletions
+	Task models.Task `json:"task"`
+	// Previous is the task before an update or completion
+	Previous *models.Task `json:"previous,omitempty"`
+}
+
+// Sign returns the signature header value for body sent at timestamp: the
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header in constant time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+func newID(prefix string) string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%s%d", prefix, time.Now().UnixNano())
+	}
+	return prefix + hex.EncodeToString(b)
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,334 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"GoTask_Management/internal/events"
+	"GoTask_Management/internal/models"
+	"GoTask_Management/internal/storage"
+)
+
+// receiver is a webhook endpoint that fails the first failures requests
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+	received chan struct{}
+}
+
+func newReceiver(t *testing.T, failures int) (*receiver, *httptest.Server) {
+	t.Helper()
+
+	r := &receiver{failures: failures, received: make(chan struct{}, 100)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		fail := r.failures > 0
+		r.failures--
+		r.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		r.received <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+func (r *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for request %d of %d", i+1, n)
+		}
+	}
+}
+
+func newTestDispatcher(t *testing.T, store *Store) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(store, WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+	t.Cleanup(d.Stop)
+	return d
+}
+
+// waitFor polls until the delivery reaches a final status
+func waitFor(t *testing.T, d *Dispatcher, subID string, status Status) Delivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		deliveries, err := d.Deliveries(subID)
+		if err != nil {
+			t.Fatalf("Deliveries() error = %v", err)
+		}
+		if len(deliveries) > 0 && deliveries[0].Status == status {
+			return deliveries[0]
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("delivery did not reach %s", status)
+	return Delivery{}
+}
+
+func testEvent(eventType events.Type) events.Event {
+	task := &models.Task{ID: "task_1", Title: "Ship it"}
+	event := events.Event{Type: eventType, TaskID: task.ID, OccurredAt: time.Now()}
+	switch eventType {
+	case events.TaskCreated:
+		event.After = task
+	case events.TaskDeleted:
+		event.Before = task
+	default:
+		previous := *task
+		previous.Title = "Ship it soon"
+		event.Before, event.After = &previous, task
+	}
+	return event
+}
+
+func TestSubscription_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     Subscription
+		wantErr bool
+	}{
+		{"valid", Subscription{URL: "https://example.com/hook", Events: []events.Type{events.TaskCreated}}, false},
+		{"all events", Subscription{URL: "http://localhost:9000"}, false},
+		{"relative url", Subscription{URL: "/hook"}, true},
+		{"bad scheme", Subscription{URL: "ftp://example.com"}, true},
+		{"unknown event", Subscription{URL: "https://example.com", Events: []events.Type{"task.exploded"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sub.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSubscription) {
+				t.Errorf("expected ErrInvalidSubscription, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDispatcher_SignedDelivery(t *testing.T) {
+	rec, server := newReceiver(t, 0)
+	store := NewStore(storage.NewMemoryWebhookStore())
+	d := newTestDispatcher(t, store)
+
+	sub, err := d.Subscribe(Subscription{URL: server.URL, Events: []events.Type{events.TaskCompleted}})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if sub.Secret == "" || sub.ID == "" {
+		t.Fatal("expected a generated ID and secret")
+	}
+
+	d.HandleEvent(context.Background(), testEvent(events.TaskCreated))
+	d.HandleEvent(context.Background(), testEvent(events.TaskCompleted))
+	rec.wait(t, 1)
+	delivery := waitFor(t, d, sub.ID, StatusSucceeded)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.requests) != 1 {
+		t.Fatalf("expected only the subscribed event, got %d requests", len(rec.requests))
+	}
+	req, body := rec.requests[0], rec.bodies[0]
+
+	if req.Header.Get(HeaderEvent) != string(events.TaskCompleted) || req.Header.Get(HeaderDelivery) != delivery.ID {
+		t.Errorf("unexpected headers %v", req.Header)
+	}
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if !Verify(sub.Secret, timestamp, body, req.Header.Get(HeaderSignature)) {
+		t.Error("signature did not verify")
+	}
+	if Verify("wrong", timestamp, body, req.Header.Get(HeaderSignature)) {
+		t.Error("signature verified with the wrong secret")
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.Type != events.TaskCompleted || payload.Task.ID != "task_1" || payload.ID != delivery.EventID || payload.Previous == nil || payload.Previous.Title != "Ship it soon" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	rec, server := newReceiver(t, 2)
+	store := NewStore(storage.NewMemoryWebhookStore())
+	d := newTestDispatcher(t, store)
+	sub, _ := d.Subscribe(Subscription{URL: server.URL})
+
+	d.HandleEvent(context.Background(), testEvent(events.TaskUpdated))
+	rec.wait(t, 3)
+
+	delivery := waitFor(t, d, sub.ID, StatusSucceeded)
+	if delivery.Attempts != 3 || delivery.ResponseStatus != http.StatusNoContent || delivery.Error != "" {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+}
+
+func TestDispatcher_GivesUpAndRedelivers(t *testing.T) {
+	rec, server := newReceiver(t, 3)
+	store := NewStore(storage.NewMemoryWebhookStore())
+	d := newTestDispatcher(t, store)
+	sub, _ := d.Subscribe(Subscription{URL: server.URL})
+
+	d.HandleEvent(context.Background(), testEvent(events.TaskDeleted))
+	rec.wait(t, 3)
+	failed := waitFor(t, d, sub.ID, StatusFailed)
+	if failed.Attempts != 3 || failed.ResponseStatus != http.StatusInternalServerError {
+		t.Errorf("unexpected delivery %+v", failed)
+	}
+
+	redelivery, err := d.Redeliver(sub.ID, failed.ID)
+	if err != nil {
+		t.Fatalf("Redeliver() error = %v", err)
+	}
+	rec.wait(t, 1)
+	got := waitFor(t, d, sub.ID, StatusSucceeded)
+	if got.ID != redelivery.ID || got.RedeliveryOf != failed.ID || got.EventID != failed.EventID {
+		t.Errorf("unexpected redelivery %+v", got)
+	}
+
+	if _, err := d.Redeliver("wh_other", failed.ID); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Errorf("expected ErrDeliveryNotFound for another subscription, got %v", err)
+	}
+}
+
+func TestDispatcher_backoff(t *testing.T) {
+	d := NewDispatcher(nil, WithRetry(RetryPolicy{MaxAttempts: 10, Backoff: time.Second}))
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 20: maxBackoff} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestStore_PersistsAndResumes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	rec, server := newReceiver(t, 0)
+
+	records, err := storage.NewJSONStorage(path)
+	if err != nil {
+		t.Fatalf("NewJSONStorage() error = %v", err)
+	}
+	store := NewStore(records)
+	sub := Subscription{ID: "wh_1", URL: server.URL, Secret: "s3cret"}
+	if err := store.AddSubscription(sub); err != nil {
+		t.Fatalf("AddSubscription() error = %v", err)
+	}
+	pending := Delivery{ID: "dlv_1", SubscriptionID: "wh_1", Event: events.TaskCreated, Payload: json.RawMessage(`{}`), Status: StatusPending, CreatedAt: time.Now()}
+	if err := store.SaveDelivery(pending); err != nil {
+		t.Fatalf("SaveDelivery() error = %v", err)
+	}
+	if err := store.SaveDelivery(Delivery{ID: "dlv_2", SubscriptionID: "wh_gone"}); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("expected ErrSubscriptionNotFound, got %v", err)
+	}
+
+	// Another process opening the same storage sees the subscription
+	reopenedRecords, err := storage.NewJSONStorage(path)
+	if err != nil {
+		t.Fatalf("NewJSONStorage() error = %v", err)
+	}
+	reopened := NewStore(reopenedRecords)
+	if got, _ := reopened.Subscription("wh_1"); got.Secret != "s3cret" {
+		t.Errorf("expected the secret to persist, got %+v", got)
+	}
+
+	d := newTestDispatcher(t, reopened)
+	d.Start()
+	rec.wait(t, 1)
+	waitFor(t, d, "wh_1", StatusSucceeded)
+
+	if err := d.Unsubscribe("wh_1"); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	if _, err := d.Deliveries("wh_1"); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("expected ErrSubscriptionNotFound, got %v", err)
+	}
+}
+
+func TestStore_FailedSaveKeepsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	records, err := storage.NewJSONStorage(path)
+	if err != nil {
+		t.Fatalf("NewJSONStorage() error = %v", err)
+	}
+	store := NewStore(records)
+	if err := store.AddSubscription(Subscription{ID: "wh_1", URL: "https://example.com/hook"}); err != nil {
+		t.Fatalf("AddSubscription() error = %v", err)
+	}
+
+	// A directory in the way of the temporary file makes every save fail
+	if err := os.Mkdir(path+".webhooks.tmp", 0755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	if err := store.AddSubscription(Subscription{ID: "wh_2", URL: "https://example.com/hook"}); err == nil {
+		t.Fatal("expected AddSubscription to fail")
+	}
+	if err := store.SaveDelivery(Delivery{ID: "dlv_1", SubscriptionID: "wh_1", Status: StatusPending}); err == nil {
+		t.Fatal("expected SaveDelivery to fail")
+	}
+	if err := store.DeleteSubscription("wh_1"); err == nil {
+		t.Fatal("expected DeleteSubscription to fail")
+	}
+
+	if subs, err := store.Subscriptions(); err != nil || len(subs) != 1 || subs[0].ID != "wh_1" {
+		t.Errorf("expected only the saved subscription, got %+v, %v", subs, err)
+	}
+	if pending, err := store.Pending(); err != nil || len(pending) != 0 {
+		t.Errorf("expected no unsaved deliveries, got %+v, %v", pending, err)
+	}
+}
+
+func TestStore_TrimsDeliveriesOfRemovedSubscriptions(t *testing.T) {
+	records := storage.NewMemoryWebhookStore()
+	store := NewStore(records)
+	for _, id := range []string{"wh_1", "wh_2"} {
+		if err := store.AddSubscription(Subscription{ID: id, URL: "https://example.com/hook"}); err != nil {
+			t.Fatalf("AddSubscription() error = %v", err)
+		}
+	}
+	if err := store.SaveDelivery(Delivery{ID: "dlv_1", SubscriptionID: "wh_1", Status: StatusPending}); err != nil {
+		t.Fatalf("SaveDelivery() error = %v", err)
+	}
+
+	// Another process removes the subscription without this store's check
+	if err := records.DeleteWebhookSubscription("wh_1"); err != nil {
+		t.Fatalf("DeleteWebhookSubscription() error = %v", err)
+	}
+	if err := records.SaveWebhookDelivery(&storage.WebhookDeliveryRecord{ID: "dlv_2", SubscriptionID: "wh_1", Pending: true, Data: []byte(`{}`)}); err != nil {
+		t.Fatalf("SaveWebhookDelivery() error = %v", err)
+	}
+
+	if err := store.SaveDelivery(Delivery{ID: "dlv_3", SubscriptionID: "wh_2", Status: StatusPending}); err != nil {
+		t.Fatalf("SaveDelivery() error = %v", err)
+	}
+	pending, err := store.Pending()
+	if err != nil || len(pending) != 1 || pending[0].ID != "dlv_3" {
+		t.Errorf("expected only the delivery to wh_2 to remain, got %+v, %v", pending, err)
+	}
+}
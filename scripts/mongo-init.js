@@ -42,6 +42,15 @@ db.createCollection('tasks', {
         due_date: {
           bsonType: ['date', 'null'],
           description: 'Due date must be a date or null'
+        },
+        // The Go driver stores models.Task.CompletedAt, which has no bson tag, as completedat
+        completedat: {
+          bsonType: ['date', 'null'],
+          description: 'Completion timestamp must be a date or null'
+        },
+        version: {
+          bsonType: ['int', 'long'],
+          description: 'Version must be an integer'
         }
       }
     }
@@ -56,6 +65,7 @@ db.tasks.createIndex({ 'created_at': -1 });
 db.tasks.createIndex({ 'due_date': 1 });
 db.tasks.createIndex({ 'done': 1 });
 db.tasks.createIndex({ 'done': 1, 'due_date': 1 });
+db.tasks.createIndex({ 'completedat': 1 });
 
 // Create a text index for full-text search on title
 db.tasks.createIndex({ 'title': 'text' });
@@ -219,9 +229,10 @@ db.system.js.save({
     
     const cutoffDate = new Date(Date.now() - daysOld * 24 * 60 * 60 * 1000);
     
+    // Age is measured from completion; tasks without completedat are kept
     const result = db.tasks.deleteMany({
       done: true,
-      created_at: { $lt: cutoffDate }
+      completedat: { $lt: cutoffDate }
     });
     
     return {
@@ -267,6 +278,6 @@ db.task_audit_log.createIndex({ 'timestamp': -1 });
 
 print('MongoDB database initialized successfully for GoTask Management');
 print('Created collections: tasks, overdue_tasks (view), upcoming_tasks (view), task_audit_log');
-print('Created indexes on: id (unique), created_at, due_date, done, title (text)');
+print('Created indexes on: id (unique), created_at, due_date, done, completedat, title (text)');
 print('Created stored functions: getTaskStatistics, cleanupOldCompletedTasks, getCompletionRate');
 print('Created user: gotask_user with readWrite permissions');
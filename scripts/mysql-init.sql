@@ -15,7 +15,10 @@ CREATE TABLE IF NOT EXISTS tasks (
     done BOOLEAN DEFAULT FALSE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     due_date TIMESTAMP NULL,
+    completed_at TIMESTAMP NULL,
+    version BIGINT NOT NULL DEFAULT 0,
     INDEX idx_tasks_due_date (due_date),
+    INDEX idx_tasks_completed_at (completed_at),
     INDEX idx_tasks_done (done),
     INDEX idx_tasks_created_at (created_at),
     INDEX idx_tasks_done_due_date (done, due_date)
@@ -40,9 +43,10 @@ CREATE PROCEDURE CleanupOldCompletedTasks(IN days_old INT)
 BEGIN
     DECLARE deleted_count INT DEFAULT 0;
     
+    -- Age is measured from completion; tasks without completed_at are kept
     DELETE FROM tasks 
     WHERE done = TRUE 
-    AND created_at < DATE_SUB(NOW(), INTERVAL days_old DAY);
+    AND completed_at < DATE_SUB(NOW(), INTERVAL days_old DAY);
     
     SET deleted_count = ROW_COUNT();
     SELECT deleted_count as deleted_tasks;
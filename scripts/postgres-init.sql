@@ -22,6 +22,13 @@ BEGIN
         
         -- Create index on created_at for ordering
         CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks(created_at);
+
+        -- Track completion time for retention cleanup
+        ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+        CREATE INDEX IF NOT EXISTS idx_tasks_completed_at ON tasks(completed_at);
+
+        -- Incremented on every change for optimistic concurrency checks
+        ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
     END IF;
 END
 $$;
@@ -45,9 +52,10 @@ RETURNS INTEGER AS $$
 DECLARE
     deleted_count INTEGER;
 BEGIN
+    -- Age is measured from completion; tasks without completed_at are kept
     DELETE FROM tasks 
     WHERE done = true 
-    AND created_at < NOW() - INTERVAL '1 day' * days_old;
+    AND completed_at < NOW() - INTERVAL '1 day' * days_old;
     
     GET DIAGNOSTICS deleted_count = ROW_COUNT;
     RETURN deleted_count;